@@ -0,0 +1,303 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/internal/help"
+)
+
+// batchMode is set while soma batch executes commands. The runtime
+// is then already initialized and authenticated.
+var batchMode bool
+
+// batchVariable matches variable references ${name}
+var batchVariable = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// batchResult is the output line for every executed command
+type batchResult struct {
+	Line       int      `json:"line"`
+	Command    string   `json:"command"`
+	ExitStatus int      `json:"exitStatus"`
+	Error      string   `json:"error,omitempty"`
+	JobIDs     []string `json:"jobIDs,omitempty"`
+	Output     string   `json:"output,omitempty"`
+}
+
+func registerBatch(app cli.App) *cli.App {
+	app.Commands = append(app.Commands,
+		[]cli.Command{
+			{
+				Name:        `batch`,
+				Usage:       `Run a file of soma commands in one process`,
+				Description: help.Text(`batch::`),
+				Action:      runtime(batchRun),
+			},
+		}...,
+	)
+	return &app
+}
+
+// batchRun function
+// soma batch [${file}]
+func batchRun(c *cli.Context) error {
+	var (
+		input   io.Reader
+		err     error
+		lineNo  int
+		abort   = true
+		pending []string
+	)
+
+	if batchMode {
+		return fmt.Errorf(`soma batch can not be nested`)
+	}
+
+	switch c.Args().First() {
+	case ``, `-`:
+		input = os.Stdin
+	default:
+		var f *os.File
+		if f, err = os.Open(c.Args().First()); err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	batchMode = true
+	adm.RecordJobs(true)
+	defer func() {
+		batchMode = false
+		adm.RecordJobs(false)
+	}()
+
+	globals := batchGlobalFlags(c)
+	vars := map[string]string{}
+	enc := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(input)
+
+	for scanner.Scan() {
+		lineNo++
+		res := batchResult{Line: lineNo}
+
+		var args []string
+		res.Command, args, err = parseBatchLine(scanner.Text(), vars)
+		switch {
+		case err != nil:
+			res.ExitStatus = 1
+			res.Error = err.Error()
+			enc.Encode(&res)
+			if abort {
+				return err
+			}
+			continue
+		case len(args) == 0:
+			// empty lines and comments are not commands
+			continue
+		}
+
+		switch args[0] {
+		case `set`:
+			// set ${name} ${value}
+			if len(args) != 3 {
+				err = fmt.Errorf(`Syntax: set ${name} ${value}`)
+				break
+			}
+			vars[args[1]] = args[2]
+		case `on-error`:
+			// on-error continue|abort
+			if len(args) != 2 || (args[1] != `continue` && args[1] != `abort`) {
+				err = fmt.Errorf(`Syntax: on-error continue|abort`)
+				break
+			}
+			abort = args[1] == `abort`
+		case `wait`:
+			// wait for all jobs created so far
+			res.JobIDs = pending
+			pending = nil
+			err = adm.WaitForJobs(res.JobIDs)
+		default:
+			if args[0] == c.App.Name {
+				args = args[1:]
+			}
+			var out string
+			out, err = batchCapture(func() error {
+				return c.App.Run(append(append(
+					[]string{c.App.Name}, globals...), args...))
+			})
+			res.Output = strings.TrimSpace(out)
+			res.JobIDs = adm.RecordedJobs()
+			pending = append(pending, res.JobIDs...)
+		}
+
+		if err != nil {
+			res.ExitStatus = 1
+			res.Error = err.Error()
+		}
+		enc.Encode(&res)
+		if err != nil && abort {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// batchGlobalFlags returns the global flags soma batch was called
+// with, which are passed on to every command of the batch
+func batchGlobalFlags(c *cli.Context) []string {
+	for i, arg := range os.Args {
+		if arg == c.Command.Name && i > 0 {
+			return os.Args[1:i]
+		}
+	}
+	return []string{}
+}
+
+// batchCapture runs f while capturing everything it writes to
+// stdout
+func batchCapture(f func() error) (string, error) {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		return ``, err
+	}
+
+	captured := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		captured <- buf.String()
+	}()
+
+	os.Stdout = w
+	err = f()
+	os.Stdout = stdout
+	w.Close()
+	out := <-captured
+	r.Close()
+	return out, err
+}
+
+// parseBatchLine expands the variable references of line and splits
+// it into arguments. Empty lines and comments have no arguments.
+func parseBatchLine(line string, vars map[string]string) (string,
+	[]string, error) {
+	line = strings.TrimSpace(line)
+	if line == `` || strings.HasPrefix(line, `#`) {
+		return line, nil, nil
+	}
+
+	command, err := expandBatchLine(line, vars)
+	if err != nil {
+		return line, nil, err
+	}
+	args, err := splitBatchLine(command)
+	return command, args, err
+}
+
+// expandBatchLine replaces the variable references ${name} in line
+// with the values from vars. References escaped as \${name} are not
+// expanded, referencing an undefined variable is an error.
+func expandBatchLine(line string, vars map[string]string) (string,
+	error) {
+	var (
+		expanded strings.Builder
+		last     int
+	)
+
+	for _, m := range batchVariable.FindAllStringSubmatchIndex(line, -1) {
+		// an odd number of preceding backslashes escapes the
+		// reference
+		escapes := 0
+		for i := m[0] - 1; i >= 0 && line[i] == '\\'; i-- {
+			escapes++
+		}
+		if escapes%2 == 1 {
+			continue
+		}
+
+		value, ok := vars[line[m[2]:m[3]]]
+		if !ok {
+			return ``, fmt.Errorf("Undefined variable %s",
+				line[m[0]:m[1]])
+		}
+		expanded.WriteString(line[last:m[0]])
+		expanded.WriteString(value)
+		last = m[1]
+	}
+	expanded.WriteString(line[last:])
+	return expanded.String(), nil
+}
+
+// splitBatchLine splits a batch line into arguments. Arguments are
+// separated by whitespace, single and double quotes group words
+// into one argument. Outside of single quotes, a backslash escapes
+// the following character.
+func splitBatchLine(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		quote   rune
+		inArg   bool
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'' && r == quote:
+			quote = 0
+		case quote == '\'':
+			current.WriteRune(r)
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	switch {
+	case escaped:
+		return nil, fmt.Errorf(`Incomplete escape at end of line`)
+	case quote != 0:
+		return nil, fmt.Errorf(`Unterminated quote`)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
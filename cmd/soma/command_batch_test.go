@@ -0,0 +1,236 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codegangsta/cli"
+)
+
+func TestParseBatchLine(t *testing.T) {
+	vars := map[string]string{
+		`bucket`: `example-prod`,
+		`spaced`: `two words`,
+	}
+
+	tests := []struct {
+		name    string
+		line    string
+		command string
+		args    []string
+		err     bool
+	}{
+		{name: `empty line`, line: ``},
+		{name: `whitespace`, line: " \t "},
+		{name: `comment`, line: `# bucket create ${undefined}`,
+			command: `# bucket create ${undefined}`},
+		{name: `indented comment`, line: `  # wait`,
+			command: `# wait`},
+		{
+			name: `words`, line: "  bucket\tshow  example ",
+			command: "bucket\tshow  example",
+			args:    []string{`bucket`, `show`, `example`},
+		},
+		{
+			name: `hash inside a command`, line: `tag add #ops`,
+			command: `tag add #ops`,
+			args:    []string{`tag`, `add`, `#ops`},
+		},
+		{
+			name: `double quotes`, line: `set name "a 'b' c"`,
+			command: `set name "a 'b' c"`,
+			args:    []string{`set`, `name`, `a 'b' c`},
+		},
+		{
+			name: `single quotes`, line: `set name 'a "b" c'`,
+			command: `set name 'a "b" c'`,
+			args:    []string{`set`, `name`, `a "b" c`},
+		},
+		{
+			name: `empty quotes`, line: `set name ''`,
+			command: `set name ''`,
+			args:    []string{`set`, `name`, ``},
+		},
+		{
+			name: `quotes join words`, line: `set name a"b c"d`,
+			command: `set name a"b c"d`,
+			args:    []string{`set`, `name`, `ab cd`},
+		},
+		{name: `unterminated quote`, line: `set name "a b`, err: true},
+		{
+			name: `escaped space`, line: `set name a\ b`,
+			command: `set name a\ b`,
+			args:    []string{`set`, `name`, `a b`},
+		},
+		{
+			name: `escaped quote`, line: `set name "a \"b\""`,
+			command: `set name "a \"b\""`,
+			args:    []string{`set`, `name`, `a "b"`},
+		},
+		{
+			name: `escaped backslash`, line: `set name a\\b`,
+			command: `set name a\\b`,
+			args:    []string{`set`, `name`, `a\b`},
+		},
+		{
+			name: `backslash in single quotes`, line: `set name 'a\b'`,
+			command: `set name 'a\b'`,
+			args:    []string{`set`, `name`, `a\b`},
+		},
+		{name: `escape at end of line`, line: `set name a\`, err: true},
+		{
+			name: `variable`, line: `node assign web01 to ${bucket}`,
+			command: `node assign web01 to example-prod`,
+			args: []string{`node`, `assign`, `web01`, `to`,
+				`example-prod`},
+		},
+		{
+			name: `variable inside a word`, line: `set name ${bucket}-01`,
+			command: `set name example-prod-01`,
+			args:    []string{`set`, `name`, `example-prod-01`},
+		},
+		{
+			name: `unquoted variable with whitespace`, line: `x ${spaced}`,
+			command: `x two words`,
+			args:    []string{`x`, `two`, `words`},
+		},
+		{
+			name: `quoted variable with whitespace`, line: `x "${spaced}"`,
+			command: `x "two words"`,
+			args:    []string{`x`, `two words`},
+		},
+		{name: `undefined variable`, line: `x ${nope}`, err: true},
+		{
+			name: `escaped variable`, line: `x \${nope}`,
+			command: `x \${nope}`,
+			args:    []string{`x`, `${nope}`},
+		},
+		{
+			name: `escaped backslash before variable`,
+			line: `x \\${bucket}`, command: `x \\example-prod`,
+			args: []string{`x`, `\example-prod`},
+		},
+		{
+			name: `not a variable`, line: `x ${1st} $bucket`,
+			command: `x ${1st} $bucket`,
+			args:    []string{`x`, `${1st}`, `$bucket`},
+		},
+	}
+
+	for _, test := range tests {
+		command, args, err := parseBatchLine(test.line, vars)
+		if (err != nil) != test.err {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if test.err {
+			continue
+		}
+		if command != test.command {
+			t.Errorf("%s: expected command %q, got %q",
+				test.name, test.command, command)
+		}
+		if !reflect.DeepEqual(args, test.args) {
+			t.Errorf("%s: expected arguments %q, got %q",
+				test.name, test.args, args)
+		}
+	}
+}
+
+func TestBatchRunResults(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `soma-batch`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, `test.soma`)
+	if err = ioutil.WriteFile(file, []byte(strings.Join([]string{
+		`# comment`,
+		``,
+		`on-error continue`,
+		`set greeting "hello world"`,
+		`echo "${greeting}"`,
+		`soma echo ${undefined}`,
+		`set greeting`,
+		`fail`,
+		`wait`,
+		`on-error abort`,
+		`echo "unterminated`,
+		`echo never`,
+	}, "\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := cli.NewApp()
+	app.Name = `soma`
+	app.Writer = ioutil.Discard
+	app.Commands = []cli.Command{
+		{Name: `batch`, Action: batchRun},
+		{Name: `echo`, Action: func(c *cli.Context) error {
+			fmt.Println(strings.Join(c.Args(), ` `))
+			return nil
+		}},
+		{Name: `fail`, Action: func(c *cli.Context) error {
+			return fmt.Errorf(`failed`)
+		}},
+	}
+
+	var runErr error
+	out, err := batchCapture(func() error {
+		runErr = app.Run([]string{`soma`, `batch`, file})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runErr == nil || runErr.Error() != `Unterminated quote` {
+		t.Errorf("Expected the batch to abort, got %v", runErr)
+	}
+
+	expected := []batchResult{
+		{Line: 3, Command: `on-error continue`},
+		{Line: 4, Command: `set greeting "hello world"`},
+		{Line: 5, Command: `echo "hello world"`, Output: `hello world`},
+		{Line: 6, Command: `soma echo ${undefined}`, ExitStatus: 1,
+			Error: `Undefined variable ${undefined}`},
+		{Line: 7, Command: `set greeting`, ExitStatus: 1,
+			Error: `Syntax: set ${name} ${value}`},
+		{Line: 8, Command: `fail`, ExitStatus: 1, Error: `failed`},
+		{Line: 9, Command: `wait`},
+		{Line: 10, Command: `on-error abort`},
+		{Line: 11, Command: `echo "unterminated`, ExitStatus: 1,
+			Error: `Unterminated quote`},
+	}
+
+	// one result line per command and directive
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d result lines, got %d:\n%s",
+			len(expected), len(lines), out)
+	}
+	for i := range lines {
+		res := batchResult{}
+		if err = json.Unmarshal([]byte(lines[i]), &res); err != nil {
+			t.Fatalf("Result line %d: %s", i, err)
+		}
+		if !reflect.DeepEqual(res, expected[i]) {
+			t.Errorf("Result line %d: expected %+v, got %+v",
+				i, expected[i], res)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
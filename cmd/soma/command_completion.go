@@ -0,0 +1,98 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/help"
+)
+
+// shell completion scripts. All scripts call soma with the arguments
+// entered so far and the --generate-bash-completion flag, which
+// prints the completion candidates one per line.
+const (
+	completionBash = `# bash completion for soma
+_soma_complete() {
+    local cur opts
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    opts=$( "${COMP_WORDS[@]:0:$COMP_CWORD}" --generate-bash-completion 2>/dev/null )
+    COMPREPLY=( $(compgen -W "${opts}" -- "${cur}") )
+    return 0
+}
+complete -o default -F _soma_complete soma
+`
+
+	completionZsh = `#compdef soma
+# zsh completion for soma
+_soma_complete() {
+    local -a opts
+    opts=("${(@f)$(${words[@]:0:#words[@]-1} --generate-bash-completion 2>/dev/null)}")
+    if [[ "${opts[1]}" != "" ]]; then
+        compadd -a opts
+    else
+        _files
+    fi
+}
+compdef _soma_complete soma
+`
+
+	completionFish = `# fish completion for soma
+function __soma_complete
+    set -l args (commandline -opc)
+    command soma $args[2..-1] --generate-bash-completion 2>/dev/null
+end
+complete -c soma -f -a '(__soma_complete)'
+`
+)
+
+func registerCompletion(app cli.App) *cli.App {
+	app.Commands = append(app.Commands,
+		[]cli.Command{
+			{
+				Name:        `completion`,
+				Usage:       `SUBCOMMANDS to generate shell completion scripts`,
+				Description: help.Text(`completion::`),
+				Subcommands: []cli.Command{
+					{
+						Name:        `bash`,
+						Usage:       `Print the bash completion script`,
+						Description: help.Text(`completion::`),
+						Action:      completionScript(completionBash),
+					},
+					{
+						Name:        `zsh`,
+						Usage:       `Print the zsh completion script`,
+						Description: help.Text(`completion::`),
+						Action:      completionScript(completionZsh),
+					},
+					{
+						Name:        `fish`,
+						Usage:       `Print the fish completion script`,
+						Description: help.Text(`completion::`),
+						Action:      completionScript(completionFish),
+					},
+				},
+			},
+		}...,
+	)
+	return &app
+}
+
+// completionScript returns an action that prints script. It does
+// not require the runtime, since no server is contacted.
+func completionScript(script string) func(*cli.Context) error {
+	return func(c *cli.Context) error {
+		fmt.Print(script)
+		return nil
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
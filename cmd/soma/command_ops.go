@@ -63,6 +63,12 @@ func registerOps(app cli.App) *cli.App {
 								Description: help.Text(`OpsRepositoryRestart`),
 								Action:      runtime(cmdOpsRepoRestart),
 							},
+							{
+								Name:        `fsck`,
+								Usage:       `Compare the TreeKeeper tree of a repository with the database`,
+								Description: help.Text(`repository-config::fsck`),
+								Action:      runtime(cmdOpsRepoFsck),
+							},
 						},
 					},
 					// -> settings loglevel/opendoor/...
@@ -286,6 +292,20 @@ func cmdOpsRepoRebuild(c *cli.Context) error {
 	return cmdOpsRepo(c, req)
 }
 
+func cmdOpsRepoFsck(c *cli.Context) error {
+	if err := adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+
+	repoID, err := adm.LookupRepoID(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/repository/%s/fsck", repoID)
+	return adm.Perform(`get`, path, `show`, nil, c)
+}
+
 func cmdOpsRepo(c *cli.Context, req proto.Request) error {
 
 	// lookup requested repository
@@ -23,6 +23,7 @@ type Config struct {
 	AsyncWait  bool         `json:"async.wait,string"`
 	JobSave    bool         `json:"save.jobs,string"`
 	ProcJSON   string       `json:"json.output.processor"`
+	CmplTTL    uint         `json:"completion.ttl,string"`
 	Auth       AuthConfig   `json:"auth"`
 	AdminAuth  AuthConfig   `json:"admin.auth"`
 	BoltDB     ConfigBoltDB `json:"boltdb"`
@@ -53,6 +54,7 @@ type RunTimeConfig struct {
 	CertPath      string        `json:"-"`
 	TimeoutBoltDB time.Duration `json:"-"`
 	TimeoutResty  time.Duration `json:"-"`
+	CompletionTTL time.Duration `json:"-"`
 	Logger        *log.Logger   `json:"-"`
 }
 
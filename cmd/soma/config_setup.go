@@ -99,6 +99,12 @@ func configSetup(c *cli.Context) error {
 	Cfg.Run.TimeoutBoltDB = time.Duration(Cfg.BoltDB.Timeout) * time.Second
 	Cfg.Run.TimeoutResty = time.Duration(Cfg.Timeout) * time.Second
 
+	// cached object names for shell completion expire quickly
+	if Cfg.CmplTTL == 0 {
+		Cfg.CmplTTL = 60
+	}
+	Cfg.Run.CompletionTTL = time.Duration(Cfg.CmplTTL) * time.Second
+
 	Cfg.Run.SomaAPI, err = url.Parse(Cfg.API)
 	if err != nil {
 		return fmt.Errorf(
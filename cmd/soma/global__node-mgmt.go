@@ -117,6 +117,7 @@ func registerNodes(app cli.App) *cli.App {
 						Usage:        `Assign a node to configuration bucket`,
 						Description:  help.Text(`node::assign`),
 						Action:       runtime(nodeAssign),
+						Flags:        budgetFlags,
 						BashComplete: comptime(bashCompNodeAssign),
 					},
 					{
@@ -126,11 +127,36 @@ func registerNodes(app cli.App) *cli.App {
 						Action:       runtime(nodeUnassign),
 						BashComplete: comptime(bashCompNodeUnassign),
 					},
+					{
+						Name:         `move`,
+						Usage:        `Move a node to a different bucket, group or cluster`,
+						Description:  help.Text(`node::move`),
+						Action:       runtime(nodeMove),
+						Flags:        budgetFlags,
+						BashComplete: comptime(bashCompNodeMove),
+					},
+					{
+						Name:         `set-state`,
+						Usage:        `Move a node into a lifecycle state`,
+						Description:  help.Text(`node::set-state`),
+						Action:       runtime(nodeSetState),
+						Flags:        budgetFlags,
+						BashComplete: comptime(bashCompNodeSetState),
+					},
+					{
+						Name:         `clear-state`,
+						Usage:        `Return a node from its lifecycle state`,
+						Description:  help.Text(`node::clear-state`),
+						Action:       runtime(nodeClearState),
+						Flags:        budgetFlags,
+						BashComplete: comptime(bashCompNodeClearState),
+					},
 					{
 						Name:         `dumptree`,
 						Usage:        `List the node as a tree`,
 						Description:  help.Text(`node-config::tree`),
 						Action:       runtime(nodeConfigTree),
+						Flags:        treeGraphFlags,
 						BashComplete: comptime(bashCompNodeConfigTree),
 					},
 					{
@@ -138,6 +164,13 @@ func registerNodes(app cli.App) *cli.App {
 						Usage:       `SUBCOMMANDS for properties on nodes`,
 						Description: help.Text(`node-config::`),
 						Subcommands: []cli.Command{
+							{
+								Name:         `history`,
+								Usage:        `Show all properties the node ever had`,
+								Description:  help.Text(`node-config::property-history`),
+								Action:       runtime(nodeConfigPropertyHistory),
+								BashComplete: comptime(bashCompNodeConfigHistory),
+							},
 							{
 								Name:        `create`,
 								Usage:       `SUBCOMMANDS to create properties`,
@@ -148,13 +181,15 @@ func registerNodes(app cli.App) *cli.App {
 										Usage:        `Add a system property to a node`,
 										Description:  help.Text(`node-config::property-create`),
 										Action:       runtime(nodeConfigPropertyCreateSystem),
-										BashComplete: cmpl.PropertyCreateInValue,
+										Flags:        budgetFlags,
+										BashComplete: comptime(bashCompPropertySystem(nodeNames, []string{`on`, `in`, `value`, `view`, `inheritance`, `childrenonly`})),
 									},
 									{
 										Name:         `service`,
 										Usage:        `Add a service property to a node`,
 										Description:  help.Text(`node-config::property-create`),
 										Action:       runtime(nodeConfigPropertyCreateService),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateInValue,
 									},
 									{
@@ -162,6 +197,7 @@ func registerNodes(app cli.App) *cli.App {
 										Usage:        `Add an oncall property to a node`,
 										Description:  help.Text(`node-config::property-create`),
 										Action:       runtime(nodeConfigPropertyCreateOncall),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateIn,
 									},
 									{
@@ -169,6 +205,7 @@ func registerNodes(app cli.App) *cli.App {
 										Usage:        `Add a custom property to a node`,
 										Description:  help.Text(`node-config::property-create`),
 										Action:       runtime(nodeConfigPropertyCreateCustom),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateIn,
 									},
 								},
@@ -183,7 +220,7 @@ func registerNodes(app cli.App) *cli.App {
 										Usage:        `Delete a system property from a node`,
 										Description:  help.Text(`node-config::property-destroy`),
 										Action:       runtime(nodeConfigPropertyDestroySystem),
-										BashComplete: cmpl.PropertyOnInView,
+										BashComplete: comptime(bashCompPropertySystem(nodeNames, []string{`on`, `in`, `view`})),
 									},
 									{
 										Name:         `service`,
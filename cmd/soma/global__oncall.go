@@ -12,6 +12,7 @@ package main // import "github.com/mjolnir42/soma/cmd/soma"
 import (
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/codegangsta/cli"
 	"github.com/mjolnir42/soma/internal/adm"
@@ -87,6 +88,54 @@ func registerOncall(app cli.App) *cli.App {
 							},
 						},
 					},
+					{
+						Name:         `current`,
+						Usage:        `Show who is on duty for an oncall duty team`,
+						Description:  help.Text(`oncall::current`),
+						Action:       runtime(oncallCurrent),
+						BashComplete: cmpl.OncallCurrent,
+					},
+					{
+						Name:        `rotation`,
+						Usage:       `SUBCOMMANDS to manage the rotation schedule of an oncall duty team`,
+						Description: help.Text(`oncall::`),
+						Subcommands: []cli.Command{
+							{
+								Name:         `set`,
+								Usage:        `Set the rotation schedule of an oncall duty team`,
+								Description:  help.Text(`oncall::rotation-set`),
+								Action:       runtime(oncallRotationSet),
+								BashComplete: cmpl.OncallRotationSet,
+							},
+							{
+								Name:        `clear`,
+								Usage:       `Remove the rotation schedule of an oncall duty team`,
+								Description: help.Text(`oncall::rotation-clear`),
+								Action:      runtime(oncallRotationClear),
+							},
+						},
+					},
+					{
+						Name:        `override`,
+						Usage:       `SUBCOMMANDS to manage oncall duty overrides`,
+						Description: help.Text(`oncall::`),
+						Subcommands: []cli.Command{
+							{
+								Name:         `add`,
+								Usage:        `Put a member on duty for a period of time`,
+								Description:  help.Text(`oncall::override-add`),
+								Action:       runtime(oncallOverrideAdd),
+								BashComplete: cmpl.OncallOverrideAdd,
+							},
+							{
+								Name:         `remove`,
+								Usage:        `Remove an override`,
+								Description:  help.Text(`oncall::override-remove`),
+								Action:       runtime(oncallOverrideRemove),
+								BashComplete: cmpl.From,
+							},
+						},
+					},
 				},
 			},
 		}...,
@@ -307,4 +356,193 @@ func oncallMemberList(c *cli.Context) error {
 	return adm.Perform(`get`, path, `list`, nil, c)
 }
 
+// oncallCurrent function
+// soma oncall current ${oncall} [at ${time}]
+func oncallCurrent(c *cli.Context) error {
+	opts := map[string][]string{}
+	if err := adm.ParseVariadicArguments(
+		opts,
+		[]string{},
+		[]string{`at`},
+		[]string{},
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	oncallID, err := adm.LookupOncallID(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/oncall/%s/current", url.QueryEscape(oncallID))
+	if len(opts[`at`]) > 0 {
+		var at time.Time
+		if at, err = time.Parse(time.RFC3339, opts[`at`][0]); err != nil {
+			return fmt.Errorf("Invalid timestamp %s: %s",
+				opts[`at`][0], err.Error())
+		}
+		path = fmt.Sprintf("%s?at=%s", path,
+			url.QueryEscape(at.Format(time.RFC3339)))
+	}
+	return adm.Perform(`get`, path, `show`, nil, c)
+}
+
+// oncallRotationSet function
+// soma oncall rotation set ${oncall} period daily|weekly handover ${HH:MM} start ${YYYY-MM-DD} timezone ${tz} member ${user} [member ${user} ...]
+func oncallRotationSet(c *cli.Context) error {
+	opts := map[string][]string{}
+	if err := adm.ParseVariadicArguments(
+		opts,
+		[]string{`member`},
+		[]string{`period`, `handover`, `start`, `timezone`},
+		[]string{`period`, `handover`, `start`, `timezone`, `member`},
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	oncallID, err := adm.LookupOncallID(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	switch opts[`period`][0] {
+	case `daily`, `weekly`:
+	default:
+		return fmt.Errorf("Invalid rotation period %s, must be"+
+			" daily or weekly", opts[`period`][0])
+	}
+	if _, err = time.Parse(`15:04`, opts[`handover`][0]); err != nil {
+		return fmt.Errorf("Invalid handover time %s: %s",
+			opts[`handover`][0], err.Error())
+	}
+	if _, err = time.Parse(`2006-01-02`, opts[`start`][0]); err != nil {
+		return fmt.Errorf("Invalid start date %s: %s",
+			opts[`start`][0], err.Error())
+	}
+	if _, err = time.LoadLocation(opts[`timezone`][0]); err != nil {
+		return fmt.Errorf("Invalid timezone %s: %s",
+			opts[`timezone`][0], err.Error())
+	}
+
+	req := proto.NewOncallRequest()
+	req.Oncall.ID = oncallID
+	req.Oncall.Rotation = &proto.OncallRotation{
+		Period:   opts[`period`][0],
+		Handover: opts[`handover`][0],
+		Start:    opts[`start`][0],
+		TimeZone: opts[`timezone`][0],
+		Members:  []proto.OncallMember{},
+	}
+	for _, user := range opts[`member`] {
+		var userID string
+		if userID, err = adm.LookupUserID(user); err != nil {
+			return err
+		}
+		req.Oncall.Rotation.Members = append(
+			req.Oncall.Rotation.Members,
+			proto.OncallMember{UserID: userID},
+		)
+	}
+
+	path := fmt.Sprintf("/oncall/%s/rotation", url.QueryEscape(oncallID))
+	return adm.Perform(`putbody`, path, `command`, req, c)
+}
+
+// oncallRotationClear function
+// soma oncall rotation clear ${oncall}
+func oncallRotationClear(c *cli.Context) error {
+	if err := adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+
+	oncallID, err := adm.LookupOncallID(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/oncall/%s/rotation", url.QueryEscape(oncallID))
+	return adm.Perform(`delete`, path, `command`, nil, c)
+}
+
+// oncallOverrideAdd function
+// soma oncall override add ${oncall} user ${user} from ${time} until ${time}
+func oncallOverrideAdd(c *cli.Context) error {
+	opts := map[string][]string{}
+	if err := adm.ParseVariadicArguments(
+		opts,
+		[]string{},
+		[]string{`user`, `from`, `until`},
+		[]string{`user`, `from`, `until`},
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	var err error
+	var oncallID, userID string
+	var from, until time.Time
+	if oncallID, err = adm.LookupOncallID(c.Args().First()); err != nil {
+		return err
+	}
+	if userID, err = adm.LookupUserID(opts[`user`][0]); err != nil {
+		return err
+	}
+	if from, err = time.Parse(time.RFC3339, opts[`from`][0]); err != nil {
+		return fmt.Errorf("Invalid timestamp %s: %s",
+			opts[`from`][0], err.Error())
+	}
+	if until, err = time.Parse(time.RFC3339, opts[`until`][0]); err != nil {
+		return fmt.Errorf("Invalid timestamp %s: %s",
+			opts[`until`][0], err.Error())
+	}
+	if !from.Before(until) {
+		return fmt.Errorf(`Override must end after it starts`)
+	}
+
+	req := proto.NewOncallRequest()
+	req.Oncall.ID = oncallID
+	req.Oncall.Overrides = &[]proto.OncallOverride{
+		proto.OncallOverride{
+			UserID: userID,
+			Start:  from.Format(time.RFC3339),
+			End:    until.Format(time.RFC3339),
+		},
+	}
+
+	path := fmt.Sprintf("/oncall/%s/override/", url.QueryEscape(oncallID))
+	return adm.Perform(`postbody`, path, `command`, req, c)
+}
+
+// oncallOverrideRemove function
+// soma oncall override remove ${overrideID} from ${oncall}
+func oncallOverrideRemove(c *cli.Context) error {
+	opts := map[string][]string{}
+	if err := adm.ParseVariadicArguments(
+		opts,
+		[]string{},
+		[]string{`from`},
+		[]string{`from`},
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	if !adm.IsUUID(c.Args().First()) {
+		return fmt.Errorf("Override ID %s is not a UUID",
+			c.Args().First())
+	}
+	oncallID, err := adm.LookupOncallID(opts[`from`][0])
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/oncall/%s/override/%s",
+		url.QueryEscape(oncallID),
+		url.QueryEscape(c.Args().First()),
+	)
+	return adm.Perform(`delete`, path, `command`, nil, c)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
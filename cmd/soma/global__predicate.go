@@ -14,6 +14,7 @@ import (
 
 	"github.com/codegangsta/cli"
 	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/internal/cmpl"
 	"github.com/mjolnir42/soma/internal/help"
 	"github.com/mjolnir42/soma/lib/proto"
 )
@@ -27,10 +28,11 @@ func registerPredicates(app cli.App) *cli.App {
 				Description: help.Text(`predicate::`),
 				Subcommands: []cli.Command{
 					{
-						Name:        `add`,
-						Usage:       `Add a threshold predicate`,
-						Description: help.Text(`predicate::add`),
-						Action:      runtime(predicateAdd),
+						Name:         `add`,
+						Usage:        `Add a threshold predicate`,
+						Description:  help.Text(`predicate::add`),
+						Action:       runtime(predicateAdd),
+						BashComplete: cmpl.PredicateAdd,
 					},
 					{
 						Name:        `remove`,
@@ -58,9 +60,20 @@ func registerPredicates(app cli.App) *cli.App {
 }
 
 // predicateAdd function
-// soma predicate add ${pred}
+// soma predicate add ${pred} [escalation ${direction}]
 func predicateAdd(c *cli.Context) error {
-	if err := adm.VerifySingleArgument(c); err != nil {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`escalation`}
+	mandatoryOptions := []string{}
+
+	if err := adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
 		return err
 	}
 
@@ -71,6 +84,19 @@ func predicateAdd(c *cli.Context) error {
 	req := proto.NewPredicateRequest()
 	req.Predicate.Symbol = c.Args().First()
 
+	if _, ok := opts[`escalation`]; ok {
+		switch opts[`escalation`][0] {
+		case proto.EscalationRising,
+			proto.EscalationFalling,
+			proto.EscalationNone:
+		default:
+			return fmt.Errorf("Illegal value for escalation: %s."+
+				" Accepted: rising, falling, none",
+				opts[`escalation`][0])
+		}
+		req.Predicate.Escalation = opts[`escalation`][0]
+	}
+
 	return adm.Perform(`postbody`, `/predicate/`, `command`, req, c)
 }
 
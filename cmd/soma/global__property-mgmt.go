@@ -37,7 +37,14 @@ func registerProperty(app cli.App) *cli.App {
 								Usage:        `Add a per-repository custom property`,
 								Description:  help.Text(`property-custom::add`),
 								Action:       runtime(propertyMgmtCustomAdd),
-								BashComplete: cmpl.To,
+								BashComplete: cmpl.PropertyCustomAdd,
+							},
+							{
+								Name:         `set-schema`,
+								Usage:        `Set or clear the value schema of a per-repository custom property`,
+								Description:  help.Text(`property-custom::set-schema`),
+								Action:       runtime(propertyMgmtCustomSetSchema),
+								BashComplete: cmpl.PropertyCustomSetSchema,
 							},
 							{
 								Name:         `remove`,
@@ -31,6 +31,13 @@ func registerRepository(app cli.App) *cli.App {
 						Action:       runtime(repositoryMgmtCreate),
 						BashComplete: cmpl.Team,
 					},
+					{
+						Name:         `clone`,
+						Usage:        `Clone an existing repository into a new repository`,
+						Description:  help.Text(`repository::clone`),
+						Action:       runtime(repositoryClone),
+						BashComplete: cmpl.To,
+					},
 					{
 						Name:         `destroy`,
 						Usage:        `Destroy an existing repository`,
@@ -77,12 +84,19 @@ func registerRepository(app cli.App) *cli.App {
 						Usage:       `Display the repository as tree`,
 						Description: help.Text(`repository-config::tree`),
 						Action:      runtime(repositoryConfigTree),
+						Flags:       treeGraphFlags,
 					},
 					{
 						Name:        `property`,
 						Usage:       `SUBCOMMANDS for properties on repositories`,
 						Description: help.Text(`repository::`),
 						Subcommands: []cli.Command{
+							{
+								Name:        `history`,
+								Usage:       `Show all properties the repository ever had`,
+								Description: help.Text(`repository-config::property-history`),
+								Action:      runtime(repositoryConfigPropertyHistory),
+							},
 							{
 								Name:        `create`,
 								Usage:       `SUBCOMMANDS to create properties`,
@@ -93,13 +107,15 @@ func registerRepository(app cli.App) *cli.App {
 										Usage:        `Add a system property to a repository`,
 										Description:  help.Text(`repository-config::property-create`),
 										Action:       runtime(repositoryConfigPropertyCreateSystem),
-										BashComplete: cmpl.PropertyCreateValue,
+										Flags:        budgetFlags,
+										BashComplete: comptime(bashCompPropertySystem(repositoryNames, []string{`on`, `value`, `view`, `inheritance`, `childrenonly`})),
 									},
 									{
 										Name:         `custom`,
 										Usage:        `Add a custom property to a repository`,
 										Description:  help.Text(`repository-config::property-create`),
 										Action:       runtime(repositoryConfigPropertyCreateCustom),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateValue,
 									},
 									{
@@ -107,6 +123,7 @@ func registerRepository(app cli.App) *cli.App {
 										Usage:        `Add a service property to a repository`,
 										Description:  help.Text(`repository-config::property-create`),
 										Action:       runtime(repositoryConfigPropertyCreateService),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreate,
 									},
 									{
@@ -114,6 +131,7 @@ func registerRepository(app cli.App) *cli.App {
 										Usage:        `Add an oncall property to a repository`,
 										Description:  help.Text(`repository-config::property-create`),
 										Action:       runtime(repositoryConfigPropertyCreateOncall),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreate,
 									},
 								},
@@ -128,7 +146,7 @@ func registerRepository(app cli.App) *cli.App {
 										Usage:        `Destroy a system property from a repository`,
 										Description:  help.Text(`repository-config::property-destroy`),
 										Action:       runtime(repositoryConfigPropertyDestroySystem),
-										BashComplete: cmpl.PropertyOnView,
+										BashComplete: comptime(bashCompPropertySystem(repositoryNames, []string{`on`, `view`})),
 									},
 									{
 										Name:         `custom`,
@@ -84,6 +84,20 @@ func registerServer(app cli.App) *cli.App {
 						Action:       runtime(serverNull),
 						BashComplete: cmpl.Datacenter,
 					},
+					{
+						Name:         `drift`,
+						Usage:        `Show differences between SOMA and the external inventory`,
+						Description:  help.Text(`inventory::drift`),
+						Action:       runtime(inventoryDrift),
+						BashComplete: cmpl.None,
+					},
+					{
+						Name:         `reconcile`,
+						Usage:        `Reconcile servers and nodes with the external inventory`,
+						Description:  help.Text(`inventory::reconcile`),
+						Action:       runtime(inventoryReconcile),
+						BashComplete: cmpl.InventoryReconcile,
+					},
 				},
 			},
 		}...,
@@ -336,4 +350,42 @@ func serverNull(c *cli.Context) error {
 	return adm.Perform(`postbody`, `/server/null`, `command`, req, c)
 }
 
+// inventoryDrift function
+// soma server drift
+func inventoryDrift(c *cli.Context) error {
+	if err := adm.VerifyNoArgument(c); err != nil {
+		return err
+	}
+
+	return adm.Perform(`get`, `/inventory/drift`, `inventory::drift`,
+		nil, c)
+}
+
+// inventoryReconcile function
+// soma server reconcile [dry-run ${bool}]
+func inventoryReconcile(c *cli.Context) error {
+	opts := map[string][]string{}
+	if err := adm.ParseVariadicArguments(
+		opts,
+		[]string{},
+		[]string{`dry-run`},
+		[]string{},
+		c.Args(),
+	); err != nil {
+		return err
+	}
+
+	req := proto.NewInventoryRequest()
+	if len(opts[`dry-run`]) > 0 {
+		if err := adm.ValidateBool(opts[`dry-run`][0],
+			&req.Flags.DryRun); err != nil {
+			return fmt.Errorf("Argument to dry-run must be a"+
+				" boolean: %s", err.Error())
+		}
+	}
+
+	return adm.Perform(`postbody`, `/inventory/reconcile`,
+		`inventory::reconcile`, req, c)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
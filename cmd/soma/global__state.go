@@ -46,6 +46,13 @@ func registerStates(app cli.App) *cli.App {
 						Action:       runtime(cmdStateRename),
 						BashComplete: cmpl.To,
 					},
+					{
+						Name:         `update`,
+						Usage:        `Update the check handling of an object state`,
+						Description:  help.Text(`state::update`),
+						Action:       runtime(cmdStateUpdate),
+						BashComplete: comptime(bashCompStateUpdate),
+					},
 					{
 						Name:        `list`,
 						Usage:       `List all object states`,
@@ -131,6 +138,65 @@ func cmdStateRename(c *cli.Context) error {
 	return adm.Perform(`putbody`, path, `command`, req, c)
 }
 
+// cmdStateUpdate function
+// soma state update ${state} checks ${handling} [view ${view} ...] [unassign-after ${seconds}]
+func cmdStateUpdate(c *cli.Context) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{`view`}
+	uniqueOptions := []string{`checks`, `unassign-after`}
+	mandatoryOptions := []string{`checks`}
+
+	if err := adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	if err := adm.ValidateNoSlash(c.Args().First()); err != nil {
+		return err
+	}
+
+	req := proto.NewStateRequest()
+	req.State.Name = c.Args().First()
+	req.State.CheckHandling = opts[`checks`][0]
+	switch req.State.CheckHandling {
+	case proto.StateChecksActive, proto.StateChecksSuspend,
+		proto.StateChecksDeprovision:
+		if len(opts[`view`]) > 0 {
+			return fmt.Errorf("Syntax error, views are only valid"+
+				" for checks %s", proto.StateChecksRestrict)
+		}
+	case proto.StateChecksRestrict:
+		if len(opts[`view`]) == 0 {
+			return fmt.Errorf("Syntax error, checks %s requires"+
+				" at least one view", proto.StateChecksRestrict)
+		}
+	default:
+		return fmt.Errorf("Syntax error, invalid check handling: %s",
+			req.State.CheckHandling)
+	}
+	for _, view := range opts[`view`] {
+		if err := adm.ValidateView(view); err != nil {
+			return err
+		}
+		req.State.Views = append(req.State.Views, view)
+	}
+	if len(opts[`unassign-after`]) > 0 {
+		if err := adm.ValidateLBoundUint64(opts[`unassign-after`][0],
+			&req.State.UnassignAfter, 0); err != nil {
+			return err
+		}
+	}
+
+	esc := url.QueryEscape(c.Args().First())
+	path := fmt.Sprintf("/state/%s", esc)
+	return adm.Perform(`patchbody`, path, `command`, req, c)
+}
+
 // cmdStateList function
 // somaadm state list
 func cmdStateList(c *cli.Context) error {
@@ -0,0 +1,126 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// apiKeyMgmtAdd function
+// soma user-mgmt apikey add ${name} to ${user} permission ${perm} [expires ${time}]
+func apiKeyMgmtAdd(c *cli.Context) error {
+	opts := map[string][]string{}
+	if err := adm.ParseVariadicArguments(
+		opts,
+		[]string{`permission`},
+		[]string{`to`, `expires`},
+		[]string{`to`, `permission`},
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	var err error
+	var userID string
+	if userID, err = adm.LookupUserID(opts[`to`][0]); err != nil {
+		return err
+	}
+
+	req := proto.NewAPIKeyRequest()
+	req.APIKey.Name = c.Args().First()
+	req.APIKey.UserID = userID
+	req.APIKey.Permissions = []proto.Permission{}
+
+	for _, ref := range opts[`permission`] {
+		permissionSlice := strings.Split(ref, `::`)
+		if len(permissionSlice) != 2 {
+			return fmt.Errorf("Invalid split of permission into %s",
+				permissionSlice)
+		}
+		if err = adm.ValidateCategory(permissionSlice[0]); err != nil {
+			return err
+		}
+		req.APIKey.Permissions = append(req.APIKey.Permissions,
+			proto.Permission{
+				Category: permissionSlice[0],
+				Name:     permissionSlice[1],
+			})
+	}
+
+	if len(opts[`expires`]) > 0 {
+		var expires time.Time
+		if expires, err = time.Parse(
+			time.RFC3339, opts[`expires`][0],
+		); err != nil {
+			return fmt.Errorf("Invalid expiry timestamp %s: %s",
+				opts[`expires`][0], err.Error())
+		}
+		req.APIKey.ExpiresAt = expires.UTC().Format(time.RFC3339)
+	}
+
+	path := fmt.Sprintf("/user/%s/apikey/", url.QueryEscape(userID))
+	return adm.Perform(`postbody`, path, `apikey-mgmt::add`, req, c)
+}
+
+// apiKeyMgmtList function
+// soma user-mgmt apikey list ${user}
+func apiKeyMgmtList(c *cli.Context) error {
+	if err := adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+
+	var err error
+	var userID string
+	if userID, err = adm.LookupUserID(c.Args().First()); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/user/%s/apikey/", url.QueryEscape(userID))
+	return adm.Perform(`get`, path, `apikey-mgmt::list`, nil, c)
+}
+
+// apiKeyMgmtRevoke function
+// soma user-mgmt apikey revoke ${name} from ${user}
+func apiKeyMgmtRevoke(c *cli.Context) error {
+	opts := map[string][]string{}
+	if err := adm.ParseVariadicArguments(
+		opts,
+		[]string{},
+		[]string{`from`},
+		[]string{`from`},
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	var err error
+	var userID, keyID string
+	if userID, err = adm.LookupUserID(opts[`from`][0]); err != nil {
+		return err
+	}
+	if keyID, err = adm.LookupAPIKeyID(
+		userID, c.Args().First(),
+	); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/user/%s/apikey/%s",
+		url.QueryEscape(userID),
+		url.QueryEscape(keyID),
+	)
+	return adm.Perform(`delete`, path, `apikey-mgmt::revoke`, nil, c)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -71,6 +71,13 @@ func registerUserMgmt(app cli.App) *cli.App {
 						Description: help.Text(`user-mgmt::sync`),
 						Action:      runtime(userMgmtSync),
 					},
+					{
+						Name:         `directory-sync`,
+						Usage:        `Synchronize users and teams from the LDAP directory`,
+						Description:  help.Text(`directory-sync::sync`),
+						Action:       runtime(directorySync),
+						BashComplete: cmpl.DirectorySync,
+					},
 					{
 						Name:        `activate`,
 						Usage:       `Activate an inactive user account`,
@@ -96,6 +103,33 @@ func registerUserMgmt(app cli.App) *cli.App {
 							},
 						},
 					},
+					{
+						Name:        `apikey`,
+						Usage:       `SUBCOMMANDS for API key management`,
+						Description: help.Text(`apikey-mgmt::`),
+						Subcommands: []cli.Command{
+							{
+								Name:         `add`,
+								Usage:        `Issue a new API key for a system user`,
+								Description:  help.Text(`apikey-mgmt::add`),
+								Action:       runtime(apiKeyMgmtAdd),
+								BashComplete: cmpl.APIKeyAdd,
+							},
+							{
+								Name:        `list`,
+								Usage:       `List the API keys of a system user`,
+								Description: help.Text(`apikey-mgmt::list`),
+								Action:      runtime(apiKeyMgmtList),
+							},
+							{
+								Name:         `revoke`,
+								Usage:        `Revoke an API key`,
+								Description:  help.Text(`apikey-mgmt::revoke`),
+								Action:       runtime(apiKeyMgmtRevoke),
+								BashComplete: cmpl.From,
+							},
+						},
+					},
 					{
 						Name:        `password`,
 						Usage:       `SUBCOMMANDS for password management`,
@@ -367,4 +401,31 @@ func userMgmtSync(c *cli.Context) error {
 	return adm.Perform(`get`, `/sync/user/`, `list`, nil, c)
 }
 
+// directorySync function
+// soma user-mgmt directory-sync [dry-run ${bool}]
+func directorySync(c *cli.Context) error {
+	opts := map[string][]string{}
+	if err := adm.ParseVariadicArguments(
+		opts,
+		[]string{},
+		[]string{`dry-run`},
+		[]string{},
+		c.Args(),
+	); err != nil {
+		return err
+	}
+
+	req := proto.NewDirectorySyncRequest()
+	if len(opts[`dry-run`]) > 0 {
+		if err := adm.ValidateBool(opts[`dry-run`][0],
+			&req.Flags.DryRun); err != nil {
+			return fmt.Errorf("Argument to dry-run must be a"+
+				" boolean: %s", err.Error())
+		}
+	}
+
+	return adm.Perform(`postbody`, `/sync/directory/`,
+		`directory-sync::sync`, req, c)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
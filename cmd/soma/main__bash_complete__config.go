@@ -0,0 +1,142 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/cmpl"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// bashCompBucket calls the completion for bucket commands of the form
+// ${bucket} in ${repository}
+func bashCompBucket(c *cli.Context) {
+	cmpl.Dynamic(c, bucketNames, []string{`in`},
+		map[string]func() []string{
+			`in`: repositoryNames,
+		})
+}
+
+// bashCompBucketCreate calls the completion for bucket::create
+func bashCompBucketCreate(c *cli.Context) {
+	cmpl.Dynamic(c, nil, []string{`in`, `environment`},
+		map[string]func() []string{
+			`in`: repositoryNames,
+		})
+}
+
+// bashCompDirectInRepository calls the completion for commands of the
+// form in ${repository}
+func bashCompDirectInRepository(c *cli.Context) {
+	cmpl.DynamicDirect(c, []string{`in`},
+		map[string]func() []string{
+			`in`: repositoryNames,
+		})
+}
+
+// bashCompDirectInBucket calls the completion for commands of the
+// form in ${bucket}
+func bashCompDirectInBucket(c *cli.Context) {
+	cmpl.DynamicDirect(c, []string{`in`},
+		map[string]func() []string{
+			`in`: bucketNames,
+		})
+}
+
+// bashCompInBucket calls the completion for commands of the form
+// ${name} in ${bucket}, where name is a new object
+func bashCompInBucket(c *cli.Context) {
+	cmpl.Dynamic(c, nil, []string{`in`},
+		map[string]func() []string{
+			`in`: bucketNames,
+		})
+}
+
+// bashCompGroup calls the completion for group commands of the form
+// ${group} in ${bucket}
+func bashCompGroup(c *cli.Context) {
+	cmpl.Dynamic(c, groupNames, []string{`in`},
+		map[string]func() []string{
+			`in`: bucketNames,
+		})
+}
+
+// bashCompCluster calls the completion for cluster commands of the
+// form ${cluster} in ${bucket}
+func bashCompCluster(c *cli.Context) {
+	cmpl.Dynamic(c, clusterNames, []string{`in`},
+		map[string]func() []string{
+			`in`: bucketNames,
+		})
+}
+
+// bashCompBucketTree calls the completion for bucket::tree
+func bashCompBucketTree(c *cli.Context) {
+	cmpl.Dynamic(c, bucketNames, []string{`in`, `at`},
+		map[string]func() []string{
+			`in`: repositoryNames,
+		})
+}
+
+// bashCompGroupTree calls the completion for group-config::tree
+func bashCompGroupTree(c *cli.Context) {
+	cmpl.Dynamic(c, groupNames, []string{`in`, `at`},
+		map[string]func() []string{
+			`in`: bucketNames,
+		})
+}
+
+// bashCompClusterTree calls the completion for cluster-config::tree
+func bashCompClusterTree(c *cli.Context) {
+	cmpl.Dynamic(c, clusterNames, []string{`in`, `at`},
+		map[string]func() []string{
+			`in`: bucketNames,
+		})
+}
+
+// bashCompCheckConfig calls the completion for check-config commands
+// of the form ${check} in ${repository}
+func bashCompCheckConfig(c *cli.Context) {
+	cmpl.Dynamic(c, checkConfigNames, []string{`in`},
+		map[string]func() []string{
+			`in`: repositoryNames,
+		})
+}
+
+// bashCompStateUpdate calls the completion for state::update
+// commands of the form ${state} checks ${handling} [view ${view}]
+func bashCompStateUpdate(c *cli.Context) {
+	cmpl.Dynamic(c, stateNames, []string{`checks`, `view`, `unassign-after`},
+		map[string]func() []string{
+			`checks`: func() []string {
+				return []string{
+					proto.StateChecksActive,
+					proto.StateChecksSuspend,
+					proto.StateChecksDeprovision,
+					proto.StateChecksRestrict,
+				}
+			},
+			`view`: viewNames,
+		})
+}
+
+// bashCompPropertySystem returns the completion for property create
+// and destroy commands of system properties. The objects the property
+// is set on are completed by names.
+func bashCompPropertySystem(names func() []string, keywords []string) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		cmpl.Dynamic(c, systemPropertyNames, keywords,
+			map[string]func() []string{
+				`on`:   names,
+				`in`:   bucketNames,
+				`view`: viewNames,
+			})
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
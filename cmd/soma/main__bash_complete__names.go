@@ -0,0 +1,216 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"fmt"
+	"net/url"
+
+	resty "gopkg.in/resty.v0"
+
+	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// cachedNames returns the object names of kind for shell completion.
+// The names are served from the local boltdb cache if possible,
+// otherwise they are fetched from the SOMA server and cached for the
+// configured completion TTL.
+func cachedNames(kind string, fetch func() []string) []string {
+	if names, err := store.Completion(kind); err == nil {
+		return names
+	}
+
+	names := fetch()
+	if len(names) > 0 {
+		if err := store.SaveCompletion(
+			kind, names, Cfg.Run.CompletionTTL,
+		); err != nil {
+			pushError(err)
+		}
+	}
+	return names
+}
+
+// completionFetch returns the result of a list request to path. On
+// errors nil is returned and the error is pushed onto the error stack.
+func completionFetch(path string) *proto.Result {
+	var err error
+	var resp *resty.Response
+	res := &proto.Result{}
+
+	if resp, err = adm.GetReq(path); err != nil {
+		pushError(err)
+		return nil
+	}
+
+	if err = adm.DecodedResponse(resp, res); err != nil {
+		pushError(err)
+		return nil
+	}
+	return res
+}
+
+// repositoryNames returns the names of all repositories
+func repositoryNames() []string {
+	return cachedNames(`repository`, func() (names []string) {
+		for _, repo := range repositoryFetch() {
+			names = append(names, repo.Name)
+		}
+		return
+	})
+}
+
+// bucketNames returns the names of all buckets
+func bucketNames() []string {
+	return cachedNames(`bucket`, func() (names []string) {
+		for _, bucket := range bucketFetch() {
+			names = append(names, bucket.Name)
+		}
+		return
+	})
+}
+
+// groupNames returns the names of all groups
+func groupNames() []string {
+	return cachedNames(`group`, func() (names []string) {
+		for _, bucket := range bucketFetch() {
+			res := completionFetch(fmt.Sprintf(
+				"/repository/%s/bucket/%s/group/",
+				url.QueryEscape(bucket.RepositoryID),
+				url.QueryEscape(bucket.ID),
+			))
+			if res == nil || res.Groups == nil {
+				continue
+			}
+			for _, group := range *res.Groups {
+				names = append(names, group.Name)
+			}
+		}
+		return
+	})
+}
+
+// clusterNames returns the names of all clusters
+func clusterNames() []string {
+	return cachedNames(`cluster`, func() (names []string) {
+		for _, bucket := range bucketFetch() {
+			res := completionFetch(fmt.Sprintf(
+				"/repository/%s/bucket/%s/cluster/",
+				url.QueryEscape(bucket.RepositoryID),
+				url.QueryEscape(bucket.ID),
+			))
+			if res == nil || res.Clusters == nil {
+				continue
+			}
+			for _, cluster := range *res.Clusters {
+				names = append(names, cluster.Name)
+			}
+		}
+		return
+	})
+}
+
+// nodeNames returns the names of all nodes
+func nodeNames() []string {
+	return cachedNames(`node`, func() []string {
+		return filterNodeName(nodeFetch())
+	})
+}
+
+// checkConfigNames returns the names of all check configurations
+func checkConfigNames() []string {
+	return cachedNames(`checkconfig`, func() (names []string) {
+		for _, repo := range repositoryFetch() {
+			res := completionFetch(fmt.Sprintf("/checkconfig/%s/",
+				url.QueryEscape(repo.ID)))
+			if res == nil || res.CheckConfigs == nil {
+				continue
+			}
+			for _, check := range *res.CheckConfigs {
+				names = append(names, check.Name)
+			}
+		}
+		return
+	})
+}
+
+// systemPropertyNames returns the names of all system properties
+func systemPropertyNames() []string {
+	return cachedNames(`property/system`, func() (names []string) {
+		res := completionFetch(fmt.Sprintf("/property-mgmt/%s/",
+			proto.PropertyTypeSystem))
+		if res == nil || res.Properties == nil {
+			return
+		}
+		for _, prop := range *res.Properties {
+			if prop.System != nil {
+				names = append(names, prop.System.Name)
+			}
+		}
+		return
+	})
+}
+
+// viewNames returns the names of all views
+func viewNames() []string {
+	return cachedNames(`view`, func() (names []string) {
+		res := completionFetch(`/view/`)
+		if res == nil || res.Views == nil {
+			return
+		}
+		for _, view := range *res.Views {
+			names = append(names, view.Name)
+		}
+		return
+	})
+}
+
+// stateNames returns the names of all object states
+func stateNames() []string {
+	return cachedNames(`state`, func() (names []string) {
+		res := completionFetch(`/state/`)
+		if res == nil || res.States == nil {
+			return
+		}
+		for _, state := range *res.States {
+			names = append(names, state.Name)
+		}
+		return
+	})
+}
+
+// repositoryFetch returns all repositories from the SOMA server
+func repositoryFetch() []proto.Repository {
+	res := completionFetch(`/repository/`)
+	if res == nil || res.Repositories == nil {
+		return []proto.Repository{}
+	}
+	return *res.Repositories
+}
+
+// bucketFetch returns the buckets of all repositories from the SOMA
+// server
+func bucketFetch() []proto.Bucket {
+	buckets := []proto.Bucket{}
+	for _, repo := range repositoryFetch() {
+		res := completionFetch(fmt.Sprintf("/repository/%s/bucket/",
+			url.QueryEscape(repo.ID)))
+		if res == nil || res.Buckets == nil {
+			continue
+		}
+		for _, bucket := range *res.Buckets {
+			// bucket lists do not always carry the repository
+			bucket.RepositoryID = repo.ID
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
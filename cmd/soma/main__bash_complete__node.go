@@ -22,43 +22,85 @@ import (
 // bashCompNode feeds cmpl with information retrieved via the
 // full execution runtime, allowing completion of node names
 func bashCompNode(c *cli.Context) {
-	cmpl.GenericDataOnly(c, filterNodeName(nodeFetch()))
+	cmpl.GenericDataOnly(c, nodeNames())
 }
 
 // bashCompNodeAssign calls the completion for node::assign commands
-// with keywords and node name data
+// with keywords, node name and bucket name data
 func bashCompNodeAssign(c *cli.Context) {
-	cmpl.Augmented(c, `to`, filterNodeName(nodeFetch()))
+	cmpl.Dynamic(c, nodeNames, []string{`to`},
+		map[string]func() []string{
+			`to`: bucketNames,
+		})
 }
 
 // bashCompNodeUnassign calls the completion for node::unassign commands
-// with keywords and node name data
+// with keywords, node name and bucket name data
 func bashCompNodeUnassign(c *cli.Context) {
-	cmpl.Augmented(c, `from`, filterNodeName(nodeFetch()))
+	cmpl.Dynamic(c, nodeNames, []string{`from`},
+		map[string]func() []string{
+			`from`: bucketNames,
+		})
+}
+
+// bashCompNodeMove calls the completion for node::move commands
+// with keywords, node name and the names of the possible targets
+func bashCompNodeMove(c *cli.Context) {
+	cmpl.Dynamic(c, nodeNames, []string{`to`, `group`, `cluster`},
+		map[string]func() []string{
+			`to`:      bucketNames,
+			`group`:   groupNames,
+			`cluster`: clusterNames,
+		})
+}
+
+// bashCompNodeSetState calls the completion for node::set-state
+// commands with keywords, node name and state name data
+func bashCompNodeSetState(c *cli.Context) {
+	cmpl.Dynamic(c, nodeNames, []string{`to`},
+		map[string]func() []string{
+			`to`: stateNames,
+		})
+}
+
+// bashCompNodeClearState calls the completion for node::clear-state
+// commands with node name data
+func bashCompNodeClearState(c *cli.Context) {
+	cmpl.Dynamic(c, nodeNames, []string{}, nil)
 }
 
 // bashCompNodeRepossess calls the completion for node-mgmt::repossess
 // commands with keywords and name data
 func bashCompNodeRepossess(c *cli.Context) {
-	cmpl.Augmented(c, `to`, filterNodeName(nodeFetch()))
+	cmpl.Augmented(c, `to`, nodeNames())
 }
 
 // bashCompNodeRename calls the completion for node-mgmt::rename
 // commands with keywords and name data
 func bashCompNodeRename(c *cli.Context) {
-	cmpl.Augmented(c, `to`, filterNodeName(nodeFetch()))
+	cmpl.Augmented(c, `to`, nodeNames())
 }
 
 // bashCompNodeRelocate calls the completion for node-mgmt::relocate
 // commands with keywords and name data
 func bashCompNodeRelocate(c *cli.Context) {
-	cmpl.Augmented(c, `to`, filterNodeName(nodeFetch()))
+	cmpl.Augmented(c, `to`, nodeNames())
 }
 
 // bashCompNodeConfigTree calls the completion for node-config::tree
 // commands with keywords and node name data
 func bashCompNodeConfigTree(c *cli.Context) {
-	cmpl.Augmented(c, `in`, filterNodeName(nodeFetch()))
+	cmpl.Dynamic(c, nodeNames, []string{`in`, `at`},
+		map[string]func() []string{
+			`in`: bucketNames,
+		})
+}
+
+// bashCompNodeConfigHistory calls the completion for
+// node-config::property-history commands with keywords and node name
+// data
+func bashCompNodeConfigHistory(c *cli.Context) {
+	cmpl.Augmented(c, `in`, nodeNames())
 }
 
 // nodeFetch returns a slice of possible nodes, queried from the SOMA
@@ -61,7 +61,7 @@ func registerCapability(app cli.App) *cli.App {
 
 // capabilityDeclare function
 // soma capability declare ${monitoring} view ${view} \
-//      metric ${metric} thresholds ${num}
+//      metric ${metric} thresholds ${num} [interval ${sec}]
 func capabilityDeclare(c *cli.Context) error {
 	opts := map[string][]string{}
 	multipleAllowed := []string{}
@@ -69,6 +69,7 @@ func capabilityDeclare(c *cli.Context) error {
 		`metric`,
 		`view`,
 		`thresholds`,
+		`interval`,
 	}
 	mandatoryOptions := []string{
 		`metric`,
@@ -86,7 +87,7 @@ func capabilityDeclare(c *cli.Context) error {
 		return err
 	}
 
-	var thresholds uint64
+	var thresholds, interval uint64
 	var err error
 
 	if err = adm.ValidateLBoundUint64(
@@ -95,6 +96,14 @@ func capabilityDeclare(c *cli.Context) error {
 	); err != nil {
 		return err
 	}
+	if _, ok := opts[`interval`]; ok {
+		if err = adm.ValidateLBoundUint64(
+			opts[`interval`][0],
+			&interval, 1,
+		); err != nil {
+			return err
+		}
+	}
 	if err = adm.ValidateNotUUID(c.Args().First()); err != nil {
 		return err
 	}
@@ -118,6 +127,7 @@ func capabilityDeclare(c *cli.Context) error {
 	req.Capability.Metric = opts[`metric`][0]
 	req.Capability.View = opts[`view`][0]
 	req.Capability.Thresholds = thresholds
+	req.Capability.MinInterval = interval
 	if req.Capability.MonitoringID, err = adm.LookupMonitoringID(
 		c.Args().First()); err != nil {
 		return err
@@ -41,11 +41,15 @@ func registerCommands(app cli.App) *cli.App {
 
 	app = *registerAction(app)
 	app = *registerAttributes(app)
+	app = *registerBatch(app)
 	app = *registerBucket(app)
+	app = *registerCache(app)
 	app = *registerCapability(app)
 	app = *registerCategories(app)
+	app = *registerChangeSets(app)
 	app = *registerChecks(app)
 	app = *registerClusters(app)
+	app = *registerCompletion(app)
 	app = *registerDatacenters(app)
 	app = *registerEntities(app)
 	app = *registerEnvironments(app)
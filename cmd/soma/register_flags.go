@@ -43,6 +43,10 @@ func registerFlags(app cli.App) *cli.App {
 			Name:  "volatile, o",
 			Usage: "Do not ensure that the BoltDB structure exists",
 		},
+		cli.BoolFlag{
+			Name:  `no-cache`,
+			Usage: `Do not use the local name lookup cache`,
+		},
 		cli.BoolFlag{
 			Name:   `doublelogout`,
 			Usage:  `(internal) logout called without actually being logged in`,
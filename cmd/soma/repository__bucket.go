@@ -34,14 +34,14 @@ func registerBucket(app cli.App) *cli.App {
 						Usage:        `Create a new bucket inside a repository`,
 						Description:  help.Text(`bucket::create`),
 						Action:       runtime(bucketCreate),
-						BashComplete: cmpl.BucketCreate,
+						BashComplete: comptime(bashCompBucketCreate),
 					},
 					{
 						Name:         `destroy`,
 						Usage:        `Mark an existing bucket as deleted`,
 						Description:  help.Text(`bucket::destroy`),
 						Action:       runtime(bucketDestroy),
-						BashComplete: cmpl.In,
+						BashComplete: comptime(bashCompBucket),
 					},
 					//{
 					//Name:         "rename",
@@ -54,21 +54,22 @@ func registerBucket(app cli.App) *cli.App {
 						Usage:        `List all bucket in a repository`,
 						Description:  help.Text(`bucket::list`),
 						Action:       runtime(bucketList),
-						BashComplete: cmpl.DirectIn,
+						BashComplete: comptime(bashCompDirectInRepository),
 					},
 					{
 						Name:         `show`,
 						Usage:        `Show full information about a specific bucket`,
 						Description:  help.Text(`bucket::show`),
 						Action:       runtime(bucketShow),
-						BashComplete: cmpl.In,
+						BashComplete: comptime(bashCompBucket),
 					},
 					{
 						Name:         `dumptree`,
 						Usage:        `Display the bucket as tree`,
-						Description:  help.Text(`bucket::show`),
+						Description:  help.Text(`bucket::tree`),
 						Action:       runtime(bucketTree),
-						BashComplete: cmpl.In,
+						Flags:        treeGraphFlags,
+						BashComplete: comptime(bashCompBucketTree),
 					},
 					//{
 					//Name:   `instances`,
@@ -80,6 +81,13 @@ func registerBucket(app cli.App) *cli.App {
 						Usage:       `SUBCOMMANDS for properties on buckets`,
 						Description: help.Text(`bucket::`),
 						Subcommands: []cli.Command{
+							{
+								Name:         `history`,
+								Usage:        `Show all properties the bucket ever had`,
+								Description:  help.Text(`bucket::property-history`),
+								Action:       runtime(bucketPropertyHistory),
+								BashComplete: comptime(bashCompBucket),
+							},
 							{
 								Name:        `create`,
 								Usage:       `SUBCOMMANDS to create properties`,
@@ -90,13 +98,15 @@ func registerBucket(app cli.App) *cli.App {
 										Usage:        `Add a system property to a bucket`,
 										Description:  help.Text(`bucket::property-create`),
 										Action:       runtime(bucketPropertyCreateSystem),
-										BashComplete: cmpl.PropertyCreateValue,
+										Flags:        budgetFlags,
+										BashComplete: comptime(bashCompPropertySystem(bucketNames, []string{`on`, `value`, `view`, `inheritance`, `childrenonly`})),
 									},
 									{
 										Name:         `custom`,
 										Usage:        `Add a custom property to a bucket`,
 										Description:  help.Text(`bucket::property-create`),
 										Action:       runtime(bucketPropertyCreateCustom),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateValue,
 									},
 									{
@@ -104,6 +114,7 @@ func registerBucket(app cli.App) *cli.App {
 										Usage:        `Add a service property to a bucket`,
 										Description:  help.Text(`bucket::property-create`),
 										Action:       runtime(bucketPropertyCreateService),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreate,
 									},
 									{
@@ -111,6 +122,7 @@ func registerBucket(app cli.App) *cli.App {
 										Usage:        `Add an oncall property to a bucket`,
 										Description:  help.Text(`bucket::property-create`),
 										Action:       runtime(bucketPropertyCreateOncall),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreate,
 									},
 								},
@@ -125,7 +137,7 @@ func registerBucket(app cli.App) *cli.App {
 										Usage:        `Destroy a system property from a bucket`,
 										Description:  help.Text(`bucket::property-destroy`),
 										Action:       runtime(bucketPropertyDestroySystem),
-										BashComplete: cmpl.PropertyOnView,
+										BashComplete: comptime(bashCompPropertySystem(bucketNames, []string{`on`, `view`})),
 									},
 									{
 										Name:         `custom`,
@@ -411,11 +423,11 @@ func bucketSearch(c *cli.Context) error {
 }
 
 // bucketTree function
-// soma bucket dumptree ${bucket} [in ${repository}]
+// soma bucket dumptree ${bucket} [in ${repository}] [at ${time}]
 func bucketTree(c *cli.Context) error {
 	opts := map[string][]string{}
 	multipleAllowed := []string{}
-	uniqueOptions := []string{`in`}
+	uniqueOptions := []string{`in`, `at`}
 	mandatoryOptions := []string{}
 
 	if err := adm.ParseVariadicArguments(
@@ -452,7 +464,10 @@ func bucketTree(c *cli.Context) error {
 		url.QueryEscape(repositoryID),
 		url.QueryEscape(bucketID),
 	)
-	return adm.Perform(`get`, path, `bucket::tree`, nil, c)
+	if path, err = treeAt(path, opts); err != nil {
+		return err
+	}
+	return treeExport(c, path, `bucket::tree`)
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -54,4 +54,9 @@ func bucketPropertyDestroyOncall(c *cli.Context) error {
 	return variousPropertyDestroy(c, proto.PropertyTypeOncall, proto.EntityBucket)
 }
 
+// bucketPropertyHistory function
+func bucketPropertyHistory(c *cli.Context) error {
+	return variousPropertyHistory(c, proto.EntityBucket)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,86 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/internal/cmpl"
+	"github.com/mjolnir42/soma/internal/help"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func registerChangeSets(app cli.App) *cli.App {
+	app.Commands = append(app.Commands,
+		[]cli.Command{
+			{
+				Name:  `changeset`,
+				Usage: `SUBCOMMANDS for transactional change sets`,
+				Subcommands: []cli.Command{
+					{
+						Name:         `apply`,
+						Usage:        `Apply a change set to a repository`,
+						Description:  help.Text(`changeset::apply`),
+						Action:       runtime(changeSetApply),
+						Flags:        budgetFlags,
+						BashComplete: cmpl.To,
+					},
+				},
+			},
+		}...,
+	)
+	return &app
+}
+
+// changeSetApply function
+// soma changeset apply ${file} to ${repository}
+func changeSetApply(c *cli.Context) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`to`}
+	mandatoryOptions := []string{`to`}
+
+	var err error
+	if err = adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	var repoID string
+	if repoID, err = adm.LookupRepoID(opts[`to`][0]); err != nil {
+		return err
+	}
+
+	var data []byte
+	if data, err = ioutil.ReadFile(c.Args().First()); err != nil {
+		return err
+	}
+	req := proto.NewChangeSetRequest()
+	if err = json.Unmarshal(data, req.ChangeSet); err != nil {
+		return err
+	}
+	req.ChangeSet.RepositoryID = repoID
+	req.Flags.OverrideBudget = c.Bool(`override-budget`)
+
+	path := fmt.Sprintf("/repository/%s/changeset",
+		url.QueryEscape(repoID),
+	)
+	return adm.Perform(`postbody`, path, `changeset::apply`, req, c)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -10,7 +10,9 @@
 package main // import "github.com/mjolnir42/soma/cmd/soma"
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/url"
 
 	"github.com/codegangsta/cli"
@@ -18,6 +20,7 @@ import (
 	"github.com/mjolnir42/soma/internal/cmpl"
 	"github.com/mjolnir42/soma/internal/help"
 	"github.com/mjolnir42/soma/lib/proto"
+	"gopkg.in/resty.v0"
 )
 
 func registerChecks(app cli.App) *cli.App {
@@ -33,6 +36,7 @@ func registerChecks(app cli.App) *cli.App {
 						Description:  help.Text(`check-config::create`),
 						Action:       runtime(checkConfigCreate),
 						BashComplete: cmpl.CheckConfigCreate,
+						Flags:        budgetFlags,
 					},
 					{
 						Name:         `destroy`,
@@ -41,19 +45,64 @@ func registerChecks(app cli.App) *cli.App {
 						Action:       runtime(checkConfigDestroy),
 						BashComplete: cmpl.CheckConfigDestroy,
 					},
+					{
+						Name:         `disable`,
+						Usage:        `Disable a check configuration and deprovision its instances`,
+						Description:  help.Text(`check-config::disable`),
+						Action:       runtime(checkConfigDisable),
+						BashComplete: comptime(bashCompCheckConfig),
+					},
+					{
+						Name:         `enable`,
+						Usage:        `Enable a disabled check configuration`,
+						Description:  help.Text(`check-config::enable`),
+						Action:       runtime(checkConfigEnable),
+						Flags:        budgetFlags,
+						BashComplete: comptime(bashCompCheckConfig),
+					},
+					{
+						Name:         `evaluate`,
+						Usage:        `Show how the constraints of a check configuration evaluate`,
+						Description:  help.Text(`check-config::evaluate`),
+						Action:       runtime(checkConfigEvaluate),
+						BashComplete: comptime(bashCompCheckConfig),
+					},
+					{
+						Name:         `export`,
+						Usage:        `Export check configurations as signed bundle`,
+						Description:  help.Text(`check-config::export`),
+						Action:       runtime(checkConfigExport),
+						BashComplete: cmpl.CheckConfigExport,
+					},
+					{
+						Name:         `import`,
+						Usage:        `Import a signed bundle of check configurations`,
+						Description:  help.Text(`check-config::import`),
+						Action:       runtime(checkConfigImport),
+						Flags:        budgetFlags,
+						BashComplete: cmpl.CheckConfigImport,
+					},
 					{
 						Name:         `list`,
 						Usage:        `List check configurations in a repository`,
 						Description:  help.Text(`check-config::list`),
 						Action:       runtime(checkConfigList),
-						BashComplete: cmpl.DirectIn,
+						BashComplete: comptime(bashCompDirectInRepository),
 					},
 					{
 						Name:         `show`,
 						Usage:        `Show details about a check configuration`,
 						Description:  help.Text(`check-config::show`),
 						Action:       runtime(checkConfigShow),
-						BashComplete: cmpl.In,
+						BashComplete: comptime(bashCompCheckConfig),
+					},
+					{
+						Name:         `update`,
+						Usage:        `Update a check configuration in place`,
+						Description:  help.Text(`check-config::update`),
+						Action:       runtime(checkConfigUpdate),
+						Flags:        budgetFlags,
+						BashComplete: cmpl.CheckConfigUpdate,
 					},
 				},
 			},
@@ -190,6 +239,8 @@ func checkConfigCreate(c *cli.Context) error {
 		return err
 	}
 
+	req.Flags.OverrideBudget = c.Bool(`override-budget`)
+
 	path := fmt.Sprintf("/checkconfig/%s/",
 		url.QueryEscape(req.CheckConfig.RepositoryID),
 	)
@@ -308,4 +359,331 @@ func checkConfigShow(c *cli.Context) error {
 	return adm.Perform(`get`, path, `check-config::list`, nil, c)
 }
 
+// checkConfigUpdate function
+// soma check-config update ${name} in ${repository} [interval ${sec}] [extern ${id}] [enabled true|false] [threshold ...]
+func checkConfigUpdate(c *cli.Context) error {
+	var err error
+	var enabled bool
+	opts := map[string][]string{}
+	thresholds := []proto.CheckConfigThreshold{}
+	req := proto.NewCheckConfigRequest()
+
+	if err = adm.ParseVariadicCheckUpdateArguments(
+		opts,
+		&thresholds,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	var repoID, checkID string
+	if repoID, err = adm.LookupRepoID(opts[`in`][0]); err != nil {
+		return err
+	}
+	if checkID, _, err = adm.LookupCheckConfigID(c.Args().First(),
+		repoID, ``); err != nil {
+		return err
+	}
+
+	// optional argument: interval
+	if iv, ok := opts[`interval`]; ok {
+		if err = adm.ValidateLBoundUint64(iv[0],
+			&req.CheckConfig.Interval, 1); err != nil {
+			return err
+		}
+	}
+
+	// optional argument: extern
+	if ex, ok := opts[`extern`]; ok {
+		if err = adm.ValidateRuneCount(ex[0], 64); err != nil {
+			return err
+		}
+		req.CheckConfig.ExternalID = ex[0]
+	}
+
+	// optional argument: enabled
+	if en, ok := opts[`enabled`]; ok {
+		if err = adm.ValidateBool(en[0], &enabled); err != nil {
+			return err
+		}
+		req.Flags.Enable = enabled
+		req.Flags.Disable = !enabled
+	}
+
+	// optional argument: threshold, replaces all thresholds
+	if len(thresholds) > 0 {
+		if req.CheckConfig.Thresholds, err = adm.ValidateThresholds(
+			thresholds,
+		); err != nil {
+			return err
+		}
+	}
+	req.Flags.OverrideBudget = c.Bool(`override-budget`)
+
+	path := fmt.Sprintf("/checkconfig/%s/%s",
+		url.QueryEscape(repoID),
+		url.QueryEscape(checkID),
+	)
+	return adm.Perform(`patchbody`, path, `check-config::update`, req, c)
+}
+
+// checkConfigEnable function
+// soma check-config enable ${check} in ${repository}
+func checkConfigEnable(c *cli.Context) error {
+	return checkConfigToggle(c, true)
+}
+
+// checkConfigDisable function
+// soma check-config disable ${check} in ${repository}
+func checkConfigDisable(c *cli.Context) error {
+	return checkConfigToggle(c, false)
+}
+
+// checkConfigToggle switches the enabled flag of a check
+// configuration via an in place update
+func checkConfigToggle(c *cli.Context, enable bool) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`in`}
+	mandatoryOptions := []string{`in`}
+
+	var err error
+	if err = adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	var repoID, checkID string
+	if repoID, err = adm.LookupRepoID(opts[`in`][0]); err != nil {
+		return err
+	}
+	if checkID, _, err = adm.LookupCheckConfigID(c.Args().First(),
+		repoID, ``); err != nil {
+		return err
+	}
+
+	req := proto.NewCheckConfigRequest()
+	req.Flags.Enable = enable
+	req.Flags.Disable = !enable
+	req.Flags.OverrideBudget = c.Bool(`override-budget`)
+
+	path := fmt.Sprintf("/checkconfig/%s/%s",
+		url.QueryEscape(repoID),
+		url.QueryEscape(checkID),
+	)
+	return adm.Perform(`patchbody`, path, `check-config::update`, req, c)
+}
+
+// checkConfigEvaluate function
+// soma check-config evaluate ${check} in ${repository}
+func checkConfigEvaluate(c *cli.Context) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`in`}
+	mandatoryOptions := []string{`in`}
+
+	var err error
+	if err = adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	var repoID, checkID string
+	if repoID, err = adm.LookupRepoID(opts[`in`][0]); err != nil {
+		return err
+	}
+	if checkID, _, err = adm.LookupCheckConfigID(c.Args().First(),
+		repoID, ``); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/checkconfig/%s/%s/evaluation",
+		url.QueryEscape(repoID),
+		url.QueryEscape(checkID),
+	)
+	return adm.Perform(`get`, path, `show`, nil, c)
+}
+
+// checkConfigExport function
+// soma check-config export in ${repository} to ${file} [check ${name} ...]
+func checkConfigExport(c *cli.Context) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{`check`}
+	uniqueOptions := []string{`in`, `to`}
+	mandatoryOptions := []string{`in`, `to`}
+
+	var err error
+	if err = adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		adm.AllArguments(c),
+	); err != nil {
+		return err
+	}
+
+	var repoID string
+	if repoID, err = adm.LookupRepoID(opts[`in`][0]); err != nil {
+		return err
+	}
+
+	req := proto.NewCheckConfigFilter()
+	req.Filter.CheckConfig.Names = opts[`check`]
+
+	path := fmt.Sprintf("/export/checkconfig/%s/",
+		url.QueryEscape(repoID),
+	)
+	resp, err := adm.PostReqBody(req, path)
+	if err != nil {
+		return err
+	}
+	res := proto.Result{}
+	if err = adm.DecodedResponse(resp, &res); err != nil {
+		return err
+	}
+	if res.Bundles == nil || len(*res.Bundles) != 1 {
+		return fmt.Errorf(`Server did not return a check configuration bundle`)
+	}
+
+	var data []byte
+	if data, err = json.MarshalIndent((*res.Bundles)[0], ``, `  `); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(opts[`to`][0], data, 0600); err != nil {
+		return err
+	}
+	return adm.MockOK(`check-config::export`, c)
+}
+
+// checkConfigImport function
+// soma check-config import ${file} into ${repository} [dry-run true|false]
+//
+// The server only computes the import plan, since check configurations
+// can not be updated in place. Unless this is a dry-run, the plan is
+// applied by creating new check configurations and replacing changed
+// ones.
+func checkConfigImport(c *cli.Context) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`into`, `dry-run`}
+	mandatoryOptions := []string{`into`}
+
+	var err error
+	if err = adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	dryRun := false
+	if len(opts[`dry-run`]) > 0 {
+		if err = adm.ValidateBool(opts[`dry-run`][0], &dryRun); err != nil {
+			return err
+		}
+	}
+
+	var repoID string
+	if repoID, err = adm.LookupRepoID(opts[`into`][0]); err != nil {
+		return err
+	}
+
+	var data []byte
+	if data, err = ioutil.ReadFile(c.Args().First()); err != nil {
+		return err
+	}
+	req := proto.NewBundleRequest()
+	if err = json.Unmarshal(data, req.Bundle); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/import/checkconfig/%s/",
+		url.QueryEscape(repoID),
+	)
+	resp, err := adm.PostReqBody(req, path)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		return adm.FormatOut(c, resp.Body(), `check-config::import`)
+	}
+
+	res := proto.Result{}
+	if err = adm.DecodedResponse(resp, &res); err != nil {
+		return err
+	}
+	if res.Imports == nil || len(*res.Imports) != 1 {
+		return fmt.Errorf(`Server did not return an import report`)
+	}
+	report := (*res.Imports)[0]
+
+	// refuse to apply partial imports
+	for _, entry := range report.Entries {
+		if entry.Action == `error` || entry.Action == `conflict` {
+			adm.FormatOut(c, resp.Body(), `check-config::import`)
+			return fmt.Errorf("Check configuration %s can not be"+
+				" imported, aborting", entry.Name)
+		}
+	}
+
+	jobs := []string{}
+	for _, entry := range report.Entries {
+		var applied *resty.Response
+		switch entry.Action {
+		case `change`:
+			// changed check configurations are updated in place, which
+			// keeps the existing check instances
+			update := proto.NewCheckConfigRequest()
+			update.CheckConfig.Interval = entry.CheckConfig.Interval
+			update.CheckConfig.ExternalID = entry.CheckConfig.ExternalID
+			update.CheckConfig.Thresholds = entry.CheckConfig.Thresholds
+			update.Flags.OverrideBudget = c.Bool(`override-budget`)
+			applied, err = adm.PatchReqBody(update, fmt.Sprintf(
+				"/checkconfig/%s/%s",
+				url.QueryEscape(repoID),
+				url.QueryEscape(entry.ExistingID),
+			))
+		case `create`:
+			create := proto.NewCheckConfigRequest()
+			create.CheckConfig = entry.CheckConfig
+			create.Flags.OverrideBudget = c.Bool(`override-budget`)
+			applied, err = adm.PostReqBody(create, fmt.Sprintf(
+				"/checkconfig/%s/", url.QueryEscape(repoID),
+			))
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("Import of check configuration %s"+
+				" failed: %s", entry.Name, err.Error())
+		}
+		jobRes := proto.Result{}
+		if err = adm.DecodedResponse(applied, &jobRes); err != nil {
+			return fmt.Errorf("Import of check configuration %s"+
+				" failed: %s", entry.Name, err.Error())
+		}
+		if jobRes.JobID != `` {
+			jobs = append(jobs, jobRes.JobID)
+		}
+	}
+	if err = adm.WaitForJobs(jobs); err != nil {
+		return err
+	}
+	return adm.FormatOut(c, resp.Body(), `check-config::import`)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -33,35 +33,36 @@ func registerClusters(app cli.App) *cli.App {
 						Usage:        `List all clusters in a bucket`,
 						Description:  help.Text(`cluster-config::list`),
 						Action:       runtime(clusterConfigList),
-						BashComplete: cmpl.DirectIn,
+						BashComplete: comptime(bashCompDirectInBucket),
 					},
 					{
 						Name:         `show`,
 						Usage:        `Show details about a cluster`,
 						Description:  help.Text(`cluster-config::show`),
 						Action:       runtime(clusterConfigShow),
-						BashComplete: cmpl.In,
+						BashComplete: comptime(bashCompCluster),
 					},
 					{
 						Name:         `create`,
 						Usage:        `Create a new cluster in a bucket`,
 						Description:  help.Text(`cluster-config::create`),
 						Action:       runtime(clusterConfigCreate),
-						BashComplete: cmpl.In,
+						BashComplete: comptime(bashCompInBucket),
 					},
 					{
 						Name:         `dumptree`,
 						Usage:        `Display the cluster as tree`,
 						Description:  help.Text(`cluster-config::tree`),
 						Action:       runtime(clusterConfigTree),
-						BashComplete: cmpl.In,
+						Flags:        treeGraphFlags,
+						BashComplete: comptime(bashCompClusterTree),
 					},
 					{
 						Name:         `destroy`,
 						Usage:        `Destroy a cluster`,
 						Description:  help.Text(`cluster-config::destroy`),
 						Action:       runtime(clusterConfigDestroy),
-						BashComplete: cmpl.In,
+						BashComplete: comptime(bashCompCluster),
 					},
 					{
 						Name:        `member`,
@@ -73,6 +74,7 @@ func registerClusters(app cli.App) *cli.App {
 								Usage:        `Assign a node to a cluster`,
 								Description:  help.Text(`cluster-config::member-assign`),
 								Action:       runtime(clusterConfigMemberAssign),
+								Flags:        budgetFlags,
 								BashComplete: cmpl.InTo,
 							},
 							{
@@ -96,6 +98,13 @@ func registerClusters(app cli.App) *cli.App {
 						Usage:       `SUBCOMMANDS for properties on clusters`,
 						Description: help.Text(`cluster-config::`),
 						Subcommands: []cli.Command{
+							{
+								Name:         `history`,
+								Usage:        `Show all properties the cluster ever had`,
+								Description:  help.Text(`cluster-config::property-history`),
+								Action:       runtime(clusterConfigPropertyHistory),
+								BashComplete: comptime(bashCompCluster),
+							},
 							{
 								Name:        `create`,
 								Usage:       `SUBCOMMANDS to create properties`,
@@ -106,13 +115,15 @@ func registerClusters(app cli.App) *cli.App {
 										Usage:        `Add a system property to a cluster`,
 										Description:  help.Text(`cluster-config::property-create`),
 										Action:       runtime(clusterConfigPropertyCreateSystem),
-										BashComplete: cmpl.PropertyCreateInValue,
+										Flags:        budgetFlags,
+										BashComplete: comptime(bashCompPropertySystem(clusterNames, []string{`on`, `in`, `value`, `view`, `inheritance`, `childrenonly`})),
 									},
 									{
 										Name:         `service`,
 										Usage:        `Add a service property to a cluster`,
 										Description:  help.Text(`cluster-config::property-create`),
 										Action:       runtime(clusterConfigPropertyCreateService),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateInValue,
 									},
 									{
@@ -120,6 +131,7 @@ func registerClusters(app cli.App) *cli.App {
 										Usage:        `Add an oncall property to a cluster`,
 										Description:  help.Text(`cluster-config::property-create`),
 										Action:       runtime(clusterConfigPropertyCreateOncall),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateIn,
 									},
 									{
@@ -127,6 +139,7 @@ func registerClusters(app cli.App) *cli.App {
 										Usage:        `Add a custom property to a cluster`,
 										Description:  help.Text(`cluster-config::property-create`),
 										Action:       runtime(clusterConfigPropertyCreateCustom),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateIn,
 									},
 								},
@@ -141,7 +154,7 @@ func registerClusters(app cli.App) *cli.App {
 										Usage:        `Delete a system property from a cluster`,
 										Description:  help.Text(`cluster-config::property-destroy`),
 										Action:       runtime(clusterConfigPropertyDestroySystem),
-										BashComplete: cmpl.PropertyOnInView,
+										BashComplete: comptime(bashCompPropertySystem(clusterNames, []string{`on`, `in`, `view`})),
 									},
 									{
 										Name:         `service`,
@@ -336,11 +349,11 @@ func clusterConfigDestroy(c *cli.Context) error {
 }
 
 // clusterConfigTree function
-// soma cluster dumptree ${cluster} in ${bucket}
+// soma cluster dumptree ${cluster} in ${bucket} [at ${time}]
 func clusterConfigTree(c *cli.Context) error {
 	opts := map[string][]string{}
 	multipleAllowed := []string{}
-	uniqueOptions := []string{`in`}
+	uniqueOptions := []string{`in`, `at`}
 	mandatoryOptions := []string{`in`}
 
 	if err := adm.ParseVariadicArguments(
@@ -373,7 +386,10 @@ func clusterConfigTree(c *cli.Context) error {
 		url.QueryEscape(bucketID),
 		url.QueryEscape(clusterID),
 	)
-	return adm.Perform(`get`, path, `cluster-config::tree`, nil, c)
+	if path, err = treeAt(path, opts); err != nil {
+		return err
+	}
+	return treeExport(c, path, `cluster-config::tree`)
 }
 
 // clusterConfigMemberAssign function
@@ -435,6 +451,7 @@ func clusterConfigMemberAssign(c *cli.Context) error {
 		ID:     nodeID,
 		Config: nodeConfig,
 	}}
+	req.Flags.OverrideBudget = c.Bool(`override-budget`)
 
 	path := fmt.Sprintf("/repository/%s/bucket/%s/cluster/%s/member/",
 		url.QueryEscape(repositoryID),
@@ -54,4 +54,9 @@ func clusterConfigPropertyDestroyOncall(c *cli.Context) error {
 	return variousPropertyDestroy(c, proto.PropertyTypeOncall, proto.EntityCluster)
 }
 
+// clusterConfigPropertyHistory function
+func clusterConfigPropertyHistory(c *cli.Context) error {
+	return variousPropertyHistory(c, proto.EntityCluster)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
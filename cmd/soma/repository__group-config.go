@@ -33,41 +33,49 @@ func registerGroups(app cli.App) *cli.App {
 						Usage:        `Create a new group`,
 						Description:  help.Text(`group-config::create`),
 						Action:       runtime(groupConfigCreate),
-						BashComplete: cmpl.In,
+						BashComplete: comptime(bashCompInBucket),
 					},
 					{
 						Name:         `destroy`,
 						Usage:        `Destroy an existing group inside a tree bucket`,
 						Description:  help.Text(`group-config::destroy`),
 						Action:       runtime(groupConfigDestroy),
-						BashComplete: cmpl.In,
+						BashComplete: comptime(bashCompGroup),
 					},
 					{
 						Name:         `list`,
 						Usage:        `List all groups in a bucket`,
 						Description:  help.Text(`group-config::create`),
 						Action:       runtime(groupConfigList),
-						BashComplete: cmpl.DirectIn,
+						BashComplete: comptime(bashCompDirectInBucket),
 					},
 					{
 						Name:         `show`,
 						Usage:        `Show full details about a specific group`,
 						Description:  help.Text(`group-config::show`),
 						Action:       runtime(groupConfigShow),
-						BashComplete: cmpl.In,
+						BashComplete: comptime(bashCompGroup),
 					},
 					{
 						Name:         `dumptree`,
 						Usage:        `Display the group as tree`,
 						Description:  help.Text(`group-config::tree`),
 						Action:       runtime(groupConfigTree),
-						BashComplete: cmpl.In,
+						Flags:        treeGraphFlags,
+						BashComplete: comptime(bashCompGroupTree),
 					},
 					{
 						Name:        `property`,
 						Usage:       `SUBCOMMANDS for properties on groups`,
 						Description: help.Text(`group-config::`),
 						Subcommands: []cli.Command{
+							{
+								Name:         `history`,
+								Usage:        `Show all properties the group ever had`,
+								Description:  help.Text(`group-config::property-history`),
+								Action:       runtime(groupConfigPropertyHistory),
+								BashComplete: comptime(bashCompGroup),
+							},
 							{
 								Name:        `create`,
 								Usage:       `SUBCOMMANDS to create properties`,
@@ -78,13 +86,15 @@ func registerGroups(app cli.App) *cli.App {
 										Usage:        `Add a system property to a group`,
 										Description:  help.Text(`group-config::property-create`),
 										Action:       runtime(groupConfigPropertyCreateSystem),
-										BashComplete: cmpl.PropertyCreateInValue,
+										Flags:        budgetFlags,
+										BashComplete: comptime(bashCompPropertySystem(groupNames, []string{`on`, `in`, `value`, `view`, `inheritance`, `childrenonly`})),
 									},
 									{
 										Name:         `service`,
 										Usage:        `Add a service property to a group`,
 										Description:  help.Text(`group-config::property-create`),
 										Action:       runtime(groupConfigPropertyCreateService),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateInValue,
 									},
 									{
@@ -92,6 +102,7 @@ func registerGroups(app cli.App) *cli.App {
 										Usage:        `Add an oncall property to a group`,
 										Description:  help.Text(`group-config::property-create`),
 										Action:       runtime(groupConfigPropertyCreateOncall),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateIn,
 									},
 									{
@@ -99,6 +110,7 @@ func registerGroups(app cli.App) *cli.App {
 										Usage:        `Add a custom property to a group`,
 										Description:  help.Text(`group-config::property-create`),
 										Action:       runtime(groupConfigPropertyCreateCustom),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.PropertyCreateIn,
 									},
 								},
@@ -113,7 +125,7 @@ func registerGroups(app cli.App) *cli.App {
 										Usage:        `Delete a system property from a group`,
 										Description:  help.Text(`group-config::property-destroy`),
 										Action:       runtime(groupConfigPropertyDestroySystem),
-										BashComplete: cmpl.PropertyOnInView,
+										BashComplete: comptime(bashCompPropertySystem(groupNames, []string{`on`, `in`, `view`})),
 									},
 									{
 										Name:         `service`,
@@ -162,6 +174,7 @@ func registerGroups(app cli.App) *cli.App {
 										Usage:        `Assign a group to another group`,
 										Description:  help.Text(`group-config::member-assign`),
 										Action:       runtime(groupConfigMemberAssignGroup),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.InTo,
 									},
 									{
@@ -169,6 +182,7 @@ func registerGroups(app cli.App) *cli.App {
 										Usage:        `Assign a cluster to a group`,
 										Description:  help.Text(`group-config::member-assign`),
 										Action:       runtime(groupConfigMemberAssignCluster),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.InTo,
 									},
 									{
@@ -176,6 +190,7 @@ func registerGroups(app cli.App) *cli.App {
 										Usage:        `Assign a node to a group`,
 										Description:  help.Text(`group-config::member-assign`),
 										Action:       runtime(groupConfigMemberAssignNode),
+										Flags:        budgetFlags,
 										BashComplete: cmpl.InTo,
 									},
 								},
@@ -381,11 +396,11 @@ func groupConfigShow(c *cli.Context) error {
 }
 
 // groupConfigTree function
-// soma group dumptree ${group} in ${bucket}
+// soma group dumptree ${group} in ${bucket} [at ${time}]
 func groupConfigTree(c *cli.Context) error {
 	opts := map[string][]string{}
 	multipleAllowed := []string{}
-	uniqueOptions := []string{`in`}
+	uniqueOptions := []string{`in`, `at`}
 	mandatoryOptions := []string{`in`}
 
 	if err := adm.ParseVariadicArguments(
@@ -416,7 +431,10 @@ func groupConfigTree(c *cli.Context) error {
 		url.QueryEscape(bucketID),
 		url.QueryEscape(groupID),
 	)
-	return adm.Perform(`get`, path, `group-config::tree`, nil, c)
+	if path, err = treeAt(path, opts); err != nil {
+		return err
+	}
+	return treeExport(c, path, `group-config::tree`)
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
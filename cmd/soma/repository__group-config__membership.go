@@ -136,6 +136,7 @@ func groupConfigMemberAssign(c *cli.Context, childEntity string) error {
 			ID: childID,
 		})
 	}
+	req.Flags.OverrideBudget = c.Bool(`override-budget`)
 
 	path := fmt.Sprintf("/repository/%s/bucket/%s/group/%s/member/%s/",
 		url.QueryEscape(repositoryID),
@@ -54,4 +54,9 @@ func groupConfigPropertyDestroyOncall(c *cli.Context) error {
 	return variousPropertyDestroy(c, proto.PropertyTypeOncall, proto.EntityGroup)
 }
 
+// groupConfigPropertyHistory function
+func groupConfigPropertyHistory(c *cli.Context) error {
+	return variousPropertyHistory(c, proto.EntityGroup)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -18,11 +18,11 @@ import (
 )
 
 // nodeConfigTree function
-// soma node dumptree ${node} [in ${bucket}]
+// soma node dumptree ${node} [in ${bucket}] [at ${time}]
 func nodeConfigTree(c *cli.Context) error {
 	opts := map[string][]string{}
 	multipleAllowed := []string{}
-	uniqueOptions := []string{`in`}
+	uniqueOptions := []string{`in`, `at`}
 	mandatoryOptions := []string{}
 
 	// check deferred errors
@@ -69,7 +69,10 @@ func nodeConfigTree(c *cli.Context) error {
 		url.QueryEscape(config.BucketID),
 		url.QueryEscape(nodeID),
 	)
-	return adm.Perform(`get`, path, `node-config::tree`, nil, c)
+	if path, err = treeAt(path, opts); err != nil {
+		return err
+	}
+	return treeExport(c, path, `node-config::tree`)
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
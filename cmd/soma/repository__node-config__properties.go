@@ -49,6 +49,11 @@ func nodeConfigPropertyDestroyOncall(c *cli.Context) error {
 	return variousPropertyDestroy(c, proto.PropertyTypeOncall, proto.EntityNode)
 }
 
+// nodeConfigPropertyHistory function
+func nodeConfigPropertyHistory(c *cli.Context) error {
+	return variousPropertyHistory(c, proto.EntityNode)
+}
+
 // nodeConfigPropertyDestroyCustom function
 func nodeConfigPropertyDestroyCustom(c *cli.Context) error {
 	return variousPropertyDestroy(c, proto.PropertyTypeCustom, proto.EntityNode)
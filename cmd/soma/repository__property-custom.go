@@ -11,6 +11,7 @@ package main // import "github.com/mjolnir42/soma/cmd/soma"
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 
 	"github.com/codegangsta/cli"
 	"github.com/mjolnir42/soma/internal/adm"
@@ -18,11 +19,16 @@ import (
 )
 
 // propertyMgmtCustomAdd function
-// soma property-mgmt custom add ${property} to ${repository}
+// soma property-mgmt custom add ${property} to ${repository} \
+//      [type ${type}] \
+//      [enum ${value}, ...] \
+//      [regex ${regex}] \
+//      [min ${num}] \
+//      [max ${num}]
 func propertyMgmtCustomAdd(c *cli.Context) error {
 	opts := map[string][]string{}
-	multipleAllowed := []string{}
-	uniqueOptions := []string{`to`}
+	multipleAllowed := []string{`enum`}
+	uniqueOptions := []string{`to`, `type`, `regex`, `min`, `max`}
 	mandatoryOptions := []string{`to`}
 
 	if err := adm.ParseVariadicArguments(
@@ -43,6 +49,11 @@ func propertyMgmtCustomAdd(c *cli.Context) error {
 	req := proto.NewCustomPropertyRequest()
 	req.Property.Custom.Name = c.Args().First()
 	req.Property.Custom.RepositoryID = repositoryID
+	if req.Property.Custom.Schema, err = customPropertySchema(
+		opts,
+	); err != nil {
+		return err
+	}
 
 	path := fmt.Sprintf("/repository/%s/property-mgmt/%s/",
 		url.QueryEscape(repositoryID),
@@ -51,6 +62,60 @@ func propertyMgmtCustomAdd(c *cli.Context) error {
 	return adm.Perform(`postbody`, path, `command`, req, c)
 }
 
+// propertyMgmtCustomSetSchema function
+// soma property-mgmt custom set-schema ${property} in ${repository} \
+//      [type ${type}] \
+//      [enum ${value}, ...] \
+//      [regex ${regex}] \
+//      [min ${num}] \
+//      [max ${num}]
+func propertyMgmtCustomSetSchema(c *cli.Context) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{`enum`}
+	uniqueOptions := []string{`in`, `type`, `regex`, `min`, `max`}
+	mandatoryOptions := []string{`in`}
+
+	if err := adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	repositoryID, err := adm.LookupRepoID(opts[`in`][0])
+	if err != nil {
+		return err
+	}
+
+	propertyID, err := adm.LookupCustomPropertyID(
+		c.Args().First(),
+		repositoryID,
+	)
+	if err != nil {
+		return err
+	}
+
+	req := proto.NewCustomPropertyRequest()
+	req.Property.Custom.ID = propertyID
+	req.Property.Custom.Name = c.Args().First()
+	req.Property.Custom.RepositoryID = repositoryID
+	if req.Property.Custom.Schema, err = customPropertySchema(
+		opts,
+	); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/repository/%s/property-mgmt/%s/%s",
+		url.QueryEscape(repositoryID),
+		url.QueryEscape(proto.PropertyTypeCustom),
+		url.QueryEscape(propertyID),
+	)
+	return adm.Perform(`patchbody`, path, `command`, req, c)
+}
+
 // propertyMgmtCustomRemove function
 // soma property-mgmt custom remove ${property} from ${repository}
 func propertyMgmtCustomRemove(c *cli.Context) error {
@@ -159,4 +224,51 @@ func propertyMgmtCustomList(c *cli.Context) error {
 	return adm.Perform(`get`, path, `list`, nil, c)
 }
 
+// customPropertySchema assembles the value schema of a custom
+// property from the parsed command arguments. It returns nil if no
+// schema argument was given.
+func customPropertySchema(opts map[string][]string) (*proto.PropertySchema, error) {
+	schema := &proto.PropertySchema{}
+	given := false
+
+	if len(opts[`type`]) > 0 {
+		schema.Type = opts[`type`][0]
+		given = true
+	}
+	if len(opts[`enum`]) > 0 {
+		schema.Enum = opts[`enum`]
+		given = true
+	}
+	if len(opts[`regex`]) > 0 {
+		schema.Regex = opts[`regex`][0]
+		given = true
+	}
+	for _, bound := range []struct {
+		name  string
+		value **float64
+	}{
+		{`min`, &schema.Minimum},
+		{`max`, &schema.Maximum},
+	} {
+		if len(opts[bound.name]) == 0 {
+			continue
+		}
+		num, err := strconv.ParseFloat(opts[bound.name][0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Argument to %s must be a"+
+				" number: %s", bound.name, err.Error())
+		}
+		*bound.value = &num
+		given = true
+	}
+
+	if !given {
+		return nil, nil
+	}
+	if err := schema.Verify(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -116,7 +116,7 @@ func repositoryConfigTree(c *cli.Context) error {
 	path := fmt.Sprintf("/repository/%s/tree", url.QueryEscape(
 		repositoryID,
 	))
-	return adm.Perform(`get`, path, `tree`, nil, c)
+	return treeExport(c, path, `tree`)
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -66,4 +66,10 @@ func repositoryConfigPropertyDestroyOncall(c *cli.Context) error {
 	return variousPropertyDestroy(c, proto.PropertyTypeOncall, proto.EntityRepository)
 }
 
+// repositoryConfigPropertyHistory function
+// soma repository property history ${repository}
+func repositoryConfigPropertyHistory(c *cli.Context) error {
+	return variousPropertyHistory(c, proto.EntityRepository)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
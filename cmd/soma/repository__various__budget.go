@@ -0,0 +1,23 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"github.com/codegangsta/cli"
+)
+
+// budgetFlags are the flags of all commands whose jobs can create
+// check instances
+var budgetFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  `override-budget`,
+		Usage: `Allow to exceed the check instance budgets`,
+	},
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
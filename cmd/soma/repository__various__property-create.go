@@ -212,6 +212,20 @@ func variousPropertyCreate(c *cli.Context, propertyType, entity string) error {
 			RepositoryID: repoID,
 			Value:        opts[`value`][0],
 		}
+
+		// validate the value locally against the schema of the
+		// custom property, the server enforces it as well
+		schema, err := adm.LookupCustomPropertySchema(customID, repoID)
+		if err != nil {
+			return err
+		}
+		if schema != nil {
+			if err = schema.Validate(prop.Custom.Value); err != nil {
+				return fmt.Errorf("Invalid value for custom"+
+					" property %s: %s", prop.Custom.Name,
+					err.Error())
+			}
+		}
 	}
 
 	// request assembly
@@ -243,6 +257,7 @@ func variousPropertyCreate(c *cli.Context, propertyType, entity string) error {
 		req.Repository.ID = repoID
 		req.Repository.Properties = &[]proto.Property{prop}
 	}
+	req.Flags.OverrideBudget = c.Bool(`override-budget`)
 
 	var path string
 	switch entity {
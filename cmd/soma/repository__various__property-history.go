@@ -0,0 +1,155 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// variousPropertyHistory is the generic function for showing the
+// property history of tree objects
+func variousPropertyHistory(c *cli.Context, entity string) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`in`}
+	mandatoryOptions := []string{}
+
+	switch entity {
+	case proto.EntityRepository:
+		uniqueOptions = []string{}
+	case proto.EntityGroup, proto.EntityCluster:
+		mandatoryOptions = append(mandatoryOptions, `in`)
+	case proto.EntityBucket, proto.EntityNode:
+	default:
+		return fmt.Errorf("Unknown entity: %s", entity)
+	}
+
+	if err := adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	var (
+		repositoryID, bucketID, objectID, path string
+		err                                    error
+	)
+
+	switch entity {
+	case proto.EntityRepository:
+		if repositoryID, err = adm.LookupRepoID(c.Args().First()); err != nil {
+			return err
+		}
+		path = fmt.Sprintf("/repository/%s/property/history",
+			url.QueryEscape(repositoryID),
+		)
+	case proto.EntityBucket:
+		if bucketID, err = adm.LookupBucketID(c.Args().First()); err != nil {
+			return err
+		}
+		if repositoryID, err = adm.LookupRepoByBucket(bucketID); err != nil {
+			return err
+		}
+		// optional argument, must be correct if provided
+		if _, ok := opts[`in`]; ok {
+			var repositoryControlID string
+			if repositoryControlID, err = adm.LookupRepoID(opts[`in`][0]); err != nil {
+				return err
+			} else if repositoryControlID != repositoryID {
+				return fmt.Errorf("bucket %s is not in repository %s", c.Args().First(), opts[`in`][0])
+			}
+		}
+		path = fmt.Sprintf("/repository/%s/bucket/%s/property/history",
+			url.QueryEscape(repositoryID),
+			url.QueryEscape(bucketID),
+		)
+	case proto.EntityGroup:
+		if bucketID, err = adm.LookupBucketID(opts[`in`][0]); err != nil {
+			return err
+		}
+		if repositoryID, err = adm.LookupRepoByBucket(bucketID); err != nil {
+			return err
+		}
+		if objectID, err = adm.LookupGroupID(c.Args().First(), bucketID); err != nil {
+			return err
+		}
+		path = fmt.Sprintf("/repository/%s/bucket/%s/group/%s/property/history",
+			url.QueryEscape(repositoryID),
+			url.QueryEscape(bucketID),
+			url.QueryEscape(objectID),
+		)
+	case proto.EntityCluster:
+		if bucketID, err = adm.LookupBucketID(opts[`in`][0]); err != nil {
+			return err
+		}
+		if repositoryID, err = adm.LookupRepoByBucket(bucketID); err != nil {
+			return err
+		}
+		if objectID, err = adm.LookupClusterID(c.Args().First(), bucketID); err != nil {
+			return err
+		}
+		path = fmt.Sprintf("/repository/%s/bucket/%s/cluster/%s/property/history",
+			url.QueryEscape(repositoryID),
+			url.QueryEscape(bucketID),
+			url.QueryEscape(objectID),
+		)
+	case proto.EntityNode:
+		config := &proto.NodeConfig{}
+		if objectID, err = adm.LookupNodeID(c.Args().First()); err != nil {
+			return err
+		}
+		if config, err = adm.LookupNodeConfig(objectID); err != nil {
+			return err
+		}
+		// optional argument, must be correct if provided
+		if _, ok := opts[`in`]; ok {
+			if bucketID, err = adm.LookupBucketID(opts[`in`][0]); err != nil {
+				return err
+			} else if bucketID != config.BucketID {
+				return fmt.Errorf("Invalid request: node %s is in bucket %s, not %s",
+					c.Args().First(),
+					config.BucketID,
+					bucketID,
+				)
+			}
+		}
+		path = fmt.Sprintf("/repository/%s/bucket/%s/node/%s/property/history",
+			url.QueryEscape(config.RepositoryID),
+			url.QueryEscape(config.BucketID),
+			url.QueryEscape(objectID),
+		)
+	}
+	return adm.Perform(`get`, path, `list`, nil, c)
+}
+
+// treeAt appends the point in time requested via the optional at
+// argument to the tree export path
+func treeAt(path string, opts map[string][]string) (string, error) {
+	if len(opts[`at`]) == 0 {
+		return path, nil
+	}
+	at, err := time.Parse(time.RFC3339, opts[`at`][0])
+	if err != nil {
+		return ``, fmt.Errorf("Invalid timestamp %s: %s",
+			opts[`at`][0], err.Error())
+	}
+	return fmt.Sprintf("%s?at=%s", path,
+		url.QueryEscape(at.Format(time.RFC3339))), nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,68 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// treeGraphFlags are the flags of all dumptree commands
+var treeGraphFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  `graph, g`,
+		Usage: `Export the tree as graph instead of JSON`,
+	},
+	cli.StringFlag{
+		Name:  `format, f`,
+		Value: proto.GraphFormatDOT,
+		Usage: `Graph format: dot, graphml or jsongraph`,
+	},
+}
+
+// treeExport requests the tree export at path. If requested via
+// --graph, the tree is exported as graph and printed unmodified so it
+// can be piped into graphviz
+func treeExport(c *cli.Context, path, tmpl string) error {
+	if !c.Bool(`graph`) {
+		return adm.Perform(`get`, path, tmpl, nil, c)
+	}
+
+	format := c.String(`format`)
+	if !proto.ValidGraphFormat(format) {
+		return fmt.Errorf("Invalid graph format %s, must be one of:"+
+			" dot, graphml, jsongraph", format)
+	}
+	sep := `?`
+	if strings.Contains(path, `?`) {
+		sep = `&`
+	}
+
+	resp, err := adm.GetReq(fmt.Sprintf("%s%sformat=%s",
+		path, sep, url.QueryEscape(format)))
+	if err != nil {
+		return err
+	}
+
+	// errors are still reported as regular JSON result
+	res := proto.Result{}
+	if json.Unmarshal(resp.Body(), &res) == nil && res.StatusCode >= 300 {
+		return adm.FormatOut(c, resp.Body(), tmpl)
+	}
+	_, err = os.Stdout.Write(resp.Body())
+	return err
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
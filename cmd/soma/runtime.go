@@ -87,6 +87,7 @@ func initCommon(c *cli.Context) {
 	adm.ActivateAsyncWait(Cfg.AsyncWait)
 	adm.AutomaticJobSave(Cfg.JobSave)
 	adm.ConfigureCache(&store)
+	adm.DisableLookupCache(c.GlobalBool(`no-cache`))
 	adm.ConfigureJSONPostProcessor(Cfg.ProcJSON)
 }
 
@@ -113,6 +114,11 @@ func runtime(action cli.ActionFunc) cli.ActionFunc {
 		var token string
 		var cred *auth.Token
 
+		// soma batch has already initialized and authenticated
+		if batchMode {
+			return runAction(action, c)
+		}
+
 		// common initialization
 		initCommon(c)
 
@@ -199,10 +205,23 @@ func runtime(action cli.ActionFunc) cli.ActionFunc {
 	skipForLogout:
 		// set token for basic auth
 		Client = Client.SetBasicAuth(Cfg.Auth.User, token)
+		store.ScopeLookups(Cfg.Run.SomaAPI.String(), Cfg.Auth.User)
 
 		// run action
-		return action(c)
+		return runAction(action, c)
+	}
+}
+
+// runAction runs action. If it fails, the cached lookup results it
+// used are discarded since the failure may have been caused by an
+// outdated result, for example a mismatch between the owners of two
+// objects.
+func runAction(action cli.ActionFunc, c *cli.Context) error {
+	err := action(c)
+	if err != nil {
+		adm.InvalidateUsedLookups()
 	}
+	return err
 }
 
 // comptime is runtime as a different type
@@ -291,6 +310,7 @@ func comptime(completion cli.BashCompleteFunc) cli.BashCompleteFunc {
 
 		// set token for basic auth
 		Client = Client.SetBasicAuth(Cfg.Auth.User, token)
+		store.ScopeLookups(Cfg.Run.SomaAPI.String(), Cfg.Auth.User)
 
 		// run action
 		completion(c)
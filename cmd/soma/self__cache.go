@@ -0,0 +1,69 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main // import "github.com/mjolnir42/soma/cmd/soma"
+
+import (
+	"encoding/json"
+
+	"github.com/codegangsta/cli"
+	"github.com/mjolnir42/soma/internal/adm"
+	"github.com/mjolnir42/soma/internal/cmpl"
+	"github.com/mjolnir42/soma/internal/help"
+)
+
+func registerCache(app cli.App) *cli.App {
+	app.Commands = append(app.Commands,
+		[]cli.Command{
+			{
+				Name:        `cache`,
+				Usage:       `SUBCOMMANDS for the local lookup cache`,
+				Description: help.Text(`cache::`),
+				Subcommands: []cli.Command{
+					{
+						Name:         `show`,
+						Usage:        `Show the cached name lookups`,
+						Description:  help.Text(`cache::`),
+						Action:       runtime(clientlocalCacheShow),
+						BashComplete: cmpl.None,
+					},
+					{
+						Name:         `clear`,
+						Usage:        `Remove all cached lookups and completions`,
+						Description:  help.Text(`cache::`),
+						Action:       runtime(clientlocalCacheClear),
+						BashComplete: cmpl.None,
+					},
+				},
+			},
+		}...,
+	)
+	return &app
+}
+
+// clientlocalCacheShow function
+// soma cache show
+func clientlocalCacheShow(c *cli.Context) error {
+	entries, err := store.Lookups()
+	if err != nil {
+		return err
+	}
+
+	enc, err := json.Marshal(&entries)
+	if err != nil {
+		return err
+	}
+	return adm.FormatOut(c, enc, `list`)
+}
+
+// clientlocalCacheClear function
+// soma cache clear
+func clientlocalCacheClear(c *cli.Context) error {
+	return store.ClearCache()
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
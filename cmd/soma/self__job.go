@@ -232,18 +232,51 @@ func jobShow(c *cli.Context) error {
 	return adm.Perform(`get`, path, `show`, nil, c)
 }
 
+// jobWait function
+// soma job wait ${jobID} [${jobID} ...] [mode all|any] [timeout ${seconds}]
 func jobWait(c *cli.Context) error {
-	if err := adm.VerifySingleArgument(c); err != nil {
-		return err
+	if c.NArg() == 0 {
+		return fmt.Errorf("Missing arguments: job wait requires at least one jobID")
 	}
 
-	if !adm.IsUUID(c.Args().First()) {
-		return fmt.Errorf("Argument is not a UUID: %s",
-			c.Args().First())
+	req := proto.NewJobWaitRequest()
+	args := adm.AllArguments(c)
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case `mode`, `timeout`:
+			if i+1 >= len(args) {
+				return fmt.Errorf("Syntax error, missing value for %s",
+					args[i])
+			}
+		}
+		switch args[i] {
+		case `mode`:
+			i++
+			switch args[i] {
+			case proto.JobWaitAll, proto.JobWaitAny:
+				req.JobWait.Mode = args[i]
+			default:
+				return fmt.Errorf("Invalid wait mode: %s", args[i])
+			}
+		case `timeout`:
+			i++
+			if err := adm.ValidateLBoundUint64(args[i],
+				&req.JobWait.Timeout, 1); err != nil {
+				return err
+			}
+		default:
+			if !adm.IsUUID(args[i]) {
+				return fmt.Errorf("Argument is not a UUID: %s",
+					args[i])
+			}
+			req.JobWait.IDList = append(req.JobWait.IDList, args[i])
+		}
+	}
+	if len(req.JobWait.IDList) == 0 {
+		return fmt.Errorf("Missing arguments: job wait requires at least one jobID")
 	}
 
-	path := fmt.Sprintf("/job/byID/%s/_processed", c.Args().First())
-	return adm.Perform(`get`, path, `wait`, nil, c)
+	return adm.Perform(`postbody`, `/job/wait`, `list`, req, c)
 }
 
 func clientlocalJobListOutstanding(c *cli.Context) error {
@@ -114,6 +114,7 @@ func nodeAssign(c *cli.Context) error {
 	req.Node.Config = &proto.NodeConfig{}
 	req.Node.Config.RepositoryID = repoID
 	req.Node.Config.BucketID = bucketID
+	req.Flags.OverrideBudget = c.Bool(`override-budget`)
 
 	path := fmt.Sprintf("/node/%s/config",
 		url.QueryEscape(nodeID),
@@ -166,4 +167,129 @@ func nodeUnassign(c *cli.Context) error {
 	return adm.Perform(`delete`, path, `node::unassign`, nil, c)
 }
 
+// nodeMove function
+// soma node move ${node} to ${bucket} [group ${group}|cluster ${cluster}]
+func nodeMove(c *cli.Context) error {
+	opts := map[string][]string{}
+	if err := adm.VariadicArguments(`node::move`, c, &opts); err != nil {
+		return err
+	}
+
+	// check deferred errors
+	if err := popError(); err != nil {
+		return err
+	}
+
+	var (
+		err                      error
+		bucketID, repoID, nodeID string
+	)
+	config := &proto.NodeConfig{}
+	if nodeID, err = adm.LookupNodeID(c.Args().First()); err != nil {
+		return err
+	}
+	if config, err = adm.LookupNodeConfig(nodeID); err != nil {
+		return err
+	}
+	if bucketID, err = adm.LookupBucketID(opts[`to`][0]); err != nil {
+		return err
+	}
+	if repoID, err = adm.LookupRepoByBucket(bucketID); err != nil {
+		return err
+	}
+	if repoID != config.RepositoryID {
+		return fmt.Errorf(
+			`Cannot move node since the target bucket is in a` +
+				` different repository.`)
+	}
+
+	req := proto.NewNodeRequest()
+	req.Node.ID = nodeID
+	req.Node.Config = &proto.NodeConfig{}
+	req.Node.Config.RepositoryID = repoID
+	req.Node.Config.BucketID = bucketID
+
+	switch {
+	case len(opts[`group`]) > 0 && len(opts[`cluster`]) > 0:
+		return fmt.Errorf(
+			`Cannot move node into a group and a cluster.`)
+	case len(opts[`group`]) > 0:
+		req.Group = &proto.Group{}
+		if req.Group.ID, err = adm.LookupGroupID(
+			opts[`group`][0], bucketID); err != nil {
+			return err
+		}
+	case len(opts[`cluster`]) > 0:
+		req.Cluster = &proto.Cluster{}
+		if req.Cluster.ID, err = adm.LookupClusterID(
+			opts[`cluster`][0], bucketID); err != nil {
+			return err
+		}
+	}
+	req.Flags.OverrideBudget = c.Bool(`override-budget`)
+
+	path := fmt.Sprintf("/repository/%s/bucket/%s/node/%s/config/move",
+		url.QueryEscape(config.RepositoryID),
+		url.QueryEscape(config.BucketID),
+		url.QueryEscape(nodeID),
+	)
+	return adm.Perform(`postbody`, path, `node::move`, req, c)
+}
+
+// nodeSetState function
+// soma node set-state ${node} to ${state}
+func nodeSetState(c *cli.Context) error {
+	opts := map[string][]string{}
+	if err := adm.VariadicArguments(`node::set-state`, c, &opts); err != nil {
+		return err
+	}
+
+	if err := adm.ValidateNoSlash(opts[`to`][0]); err != nil {
+		return err
+	}
+	return nodeLifecycle(c, opts[`to`][0])
+}
+
+// nodeClearState function
+// soma node clear-state ${node}
+func nodeClearState(c *cli.Context) error {
+	if err := adm.VerifySingleArgument(c); err != nil {
+		return err
+	}
+	return nodeLifecycle(c, ``)
+}
+
+// nodeLifecycle moves the node into lifecycle state. An empty state
+// returns the node to normal operation.
+func nodeLifecycle(c *cli.Context, state string) error {
+	// check deferred errors
+	if err := popError(); err != nil {
+		return err
+	}
+
+	var (
+		err    error
+		nodeID string
+	)
+	config := &proto.NodeConfig{}
+	if nodeID, err = adm.LookupNodeID(c.Args().First()); err != nil {
+		return err
+	}
+	if config, err = adm.LookupNodeConfig(nodeID); err != nil {
+		return err
+	}
+
+	req := proto.NewNodeRequest()
+	req.Node.ID = nodeID
+	req.Node.Lifecycle = state
+	req.Flags.OverrideBudget = c.Bool(`override-budget`)
+
+	path := fmt.Sprintf("/repository/%s/bucket/%s/node/%s/config/lifecycle",
+		url.QueryEscape(config.RepositoryID),
+		url.QueryEscape(config.BucketID),
+		url.QueryEscape(nodeID),
+	)
+	return adm.Perform(`putbody`, path, `node::lifecycle`, req, c)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
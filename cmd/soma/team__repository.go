@@ -18,6 +18,47 @@ import (
 	"github.com/mjolnir42/soma/lib/proto"
 )
 
+// repositoryClone function
+// soma repository clone ${repository} to ${newName}
+func repositoryClone(c *cli.Context) error {
+	opts := map[string][]string{}
+	multipleAllowed := []string{}
+	uniqueOptions := []string{`to`}
+	mandatoryOptions := []string{`to`}
+
+	if err := adm.ParseVariadicArguments(
+		opts,
+		multipleAllowed,
+		uniqueOptions,
+		mandatoryOptions,
+		c.Args().Tail(),
+	); err != nil {
+		return err
+	}
+
+	req := proto.NewRepositoryRequest()
+	req.Repository.Name = opts[`to`][0]
+
+	repositoryID, err := adm.LookupRepoID(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	// the new repository is owned by the team of the source
+	// repository
+	var teamID string
+	if err := adm.LookupTeamByRepo(repositoryID, &teamID); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf(
+		"/team/%s/repository/%s/clone",
+		url.QueryEscape(teamID),
+		url.QueryEscape(repositoryID),
+	)
+	return adm.Perform(`postbody`, path, `command`, req, c)
+}
+
 // repositoryDestroy function
 // soma repository destroy ${repository} [from ${team}]
 func repositoryDestroy(c *cli.Context) error {
@@ -54,10 +54,10 @@ func connectToDatabase(appLog, errLog *log.Logger) {
 
 	// required schema versions
 	required := map[string]int64{
-		"inventory": 201811150001,
+		"inventory": 202610190001,
 		"root":      201605160001,
-		`auth`:      201811150001,
-		`soma`:      201903130001,
+		`auth`:      202610190001,
+		`soma`:      202610190007,
 	}
 
 	if rows, err = conn.Query(stmt.DatabaseSchemaVersion); err != nil {
@@ -51,6 +51,8 @@ func commandInitialize(done chan<- bool, printOnly bool, verbose bool, version s
 
 	createTablesJobs(printOnly, verbose)
 
+	createTablesPropertyHistory(printOnly, verbose)
+
 	createTablesSchemaVersion(printOnly, verbose)
 
 	schemaInserts(printOnly, verbose)
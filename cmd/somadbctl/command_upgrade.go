@@ -12,12 +12,14 @@ const MaxInt = int(^uint(0) >> 1)
 var UpgradeVersions = map[string]map[int]func(int, string, bool) int{
 	`inventory`: map[int]func(int, string, bool) int{
 		201605060001: upgradeInventoryTo201811150001,
+		201811150001: upgradeInventoryTo202610190001,
 	},
 	`auth`: map[int]func(int, string, bool) int{
 		201605060001: upgradeAuthTo201605150002,
 		201605150002: upgradeAuthTo201605190001,
 		201605190001: upgradeAuthTo201711080001,
 		201711080001: upgradeAuthTo201811150001,
+		201811150001: upgradeAuthTo202610190001,
 	},
 	`soma`: map[int]func(int, string, bool) int{
 		201605060001: upgradeSomaTo201605210001,
@@ -45,6 +47,13 @@ var UpgradeVersions = map[string]map[int]func(int, string, bool) int{
 		201811120002: upgradeSomaTo201811150001,
 		201811150001: upgradeSomaTo201901300001,
 		201901300001: upgradeSomaTo201903130001,
+		201903130001: upgradeSomaTo202610190001,
+		202610190001: upgradeSomaTo202610190002,
+		202610190002: upgradeSomaTo202610190003,
+		202610190003: upgradeSomaTo202610190004,
+		202610190004: upgradeSomaTo202610190005,
+		202610190005: upgradeSomaTo202610190006,
+		202610190006: upgradeSomaTo202610190007,
 	},
 	`root`: map[int]func(int, string, bool) int{
 		000000000001: installRoot201605150001,
@@ -195,6 +204,143 @@ func upgradeInventoryTo201811150001(curr int, tool string, printOnly bool) int {
 	return 201811150001
 }
 
+func upgradeInventoryTo202610190001(curr int, tool string, printOnly bool) int {
+	if curr != 201811150001 {
+		return 0
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS inventory.oncall_rotation ( oncall_id uuid NOT NULL, period varchar(16) NOT NULL, handover varchar(5) NOT NULL, start_date date NOT NULL, time_zone varchar(64) NOT NULL, created_by uuid NOT NULL, created_at timestamptz(3) NOT NULL DEFAULT now(), CONSTRAINT _oncall_rotation_primary_key PRIMARY KEY (oncall_id), CONSTRAINT _oncall_rotation_creator_exists FOREIGN KEY(created_by) REFERENCES inventory.user(id) DEFERRABLE, CONSTRAINT _oncall_rotation_oncall_exists FOREIGN KEY (oncall_id) REFERENCES inventory.oncall_team (id) ON DELETE CASCADE DEFERRABLE, CONSTRAINT _oncall_rotation_valid_period CHECK( period IN ( 'daily', 'weekly' ) ), CONSTRAINT _oncall_rotation_valid_handover CHECK( handover ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$' ), CONSTRAINT _oncall_rotation_timezone_utc CHECK( EXTRACT( TIMEZONE FROM created_at ) = '0' ));`,
+		`CREATE TABLE IF NOT EXISTS inventory.oncall_rotation_member ( oncall_id uuid NOT NULL, user_id uuid NOT NULL, position smallint NOT NULL, CONSTRAINT _oncall_rotation_member_rotation_exists FOREIGN KEY (oncall_id) REFERENCES inventory.oncall_rotation (oncall_id) ON DELETE CASCADE DEFERRABLE, CONSTRAINT _oncall_rotation_member_is_member FOREIGN KEY (user_id, oncall_id) REFERENCES inventory.oncall_membership (user_id, oncall_id) ON DELETE CASCADE DEFERRABLE, CONSTRAINT _oncall_rotation_member_unique_position UNIQUE (oncall_id, position), CONSTRAINT _oncall_rotation_member_only_once UNIQUE (oncall_id, user_id));`,
+		`CREATE TABLE IF NOT EXISTS inventory.oncall_override ( id uuid NOT NULL DEFAULT public.gen_random_uuid(), oncall_id uuid NOT NULL, user_id uuid NOT NULL, starts_at timestamptz(3) NOT NULL, ends_at timestamptz(3) NOT NULL, created_by uuid NOT NULL, created_at timestamptz(3) NOT NULL DEFAULT now(), CONSTRAINT _oncall_override_primary_key PRIMARY KEY (id), CONSTRAINT _oncall_override_creator_exists FOREIGN KEY(created_by) REFERENCES inventory.user(id) DEFERRABLE, CONSTRAINT _oncall_override_is_member FOREIGN KEY (user_id, oncall_id) REFERENCES inventory.oncall_membership (user_id, oncall_id) ON DELETE CASCADE DEFERRABLE, CONSTRAINT _oncall_override_valid_period CHECK( starts_at < ends_at ), CONSTRAINT _oncall_override_timezone_utc CHECK( EXTRACT( TIMEZONE FROM created_at ) = '0' ), CONSTRAINT _oncall_override_start_utc CHECK( EXTRACT( TIMEZONE FROM starts_at ) = '0' ), CONSTRAINT _oncall_override_end_utc CHECK( EXTRACT( TIMEZONE FROM ends_at ) = '0' ));`,
+		`GRANT SELECT, INSERT, UPDATE, DELETE ON inventory.oncall_rotation, inventory.oncall_rotation_member, inventory.oncall_override TO soma_svc;`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('inventory', 202610190001, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 202610190001
+}
+
+func upgradeSomaTo202610190002(curr int, tool string, printOnly bool) int {
+	if curr != 202610190001 {
+		return 0
+	}
+	stmts := []string{
+		`ALTER TABLE soma.monitoring_capabilities ADD COLUMN min_interval integer NOT NULL DEFAULT 1;`,
+		`ALTER TABLE soma.monitoring_capabilities ADD CHECK ( min_interval > 0 );`,
+		`ALTER TABLE soma.job ADD COLUMN warnings jsonb NOT NULL DEFAULT '[]'::jsonb;`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 202610190002, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 202610190002
+}
+
+func upgradeSomaTo202610190003(curr int, tool string, printOnly bool) int {
+	if curr != 202610190002 {
+		return 0
+	}
+	stmts := []string{
+		`ALTER TABLE soma.configuration_predicates ADD COLUMN escalation varchar(16) NOT NULL DEFAULT 'none';`,
+		`ALTER TABLE soma.configuration_predicates ADD CHECK ( escalation IN ( 'rising', 'falling', 'none' ) );`,
+		`UPDATE soma.configuration_predicates SET escalation = 'rising' WHERE predicate IN ( '>', '>=' );`,
+		`UPDATE soma.configuration_predicates SET escalation = 'falling' WHERE predicate IN ( '<', '<=' );`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 202610190003, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 202610190003
+}
+
+func upgradeSomaTo202610190004(curr int, tool string, printOnly bool) int {
+	if curr != 202610190003 {
+		return 0
+	}
+	stmts := []string{
+		// the enabled flag was never set by clients and is now used
+		// to disable check configurations
+		`UPDATE soma.check_configurations SET enabled = 'yes'::boolean;`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 202610190004, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 202610190004
+}
+
+func upgradeSomaTo202610190005(curr int, tool string, printOnly bool) int {
+	if curr != 202610190004 {
+		return 0
+	}
+	stmts := []string{
+		`ALTER TABLE soma.object_states ADD COLUMN check_handling varchar(16) NOT NULL DEFAULT 'active';`,
+		`ALTER TABLE soma.object_states ADD CHECK ( check_handling IN ( 'active', 'suspend', 'deprovision', 'restrict' ) );`,
+		`ALTER TABLE soma.object_states ADD COLUMN restrict_views varchar(64)[] NOT NULL DEFAULT '{}';`,
+		`ALTER TABLE soma.object_states ADD COLUMN unassign_after integer NOT NULL DEFAULT 0;`,
+		`ALTER TABLE soma.object_states ADD CHECK ( unassign_after >= 0 );`,
+		`ALTER TABLE soma.nodes ADD COLUMN lifecycle_state varchar(64) NULL REFERENCES soma.object_states ( object_state ) DEFERRABLE;`,
+		`ALTER TABLE soma.nodes ADD COLUMN lifecycle_state_since timestamptz(3) NULL;`,
+		`ALTER TABLE soma.nodes ADD COLUMN lifecycle_state_by varchar(256) NULL;`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 202610190005, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 202610190005
+}
+
+func upgradeSomaTo202610190006(curr int, tool string, printOnly bool) int {
+	if curr != 202610190005 {
+		return 0
+	}
+	stmts := []string{
+		`ALTER TABLE soma.custom_properties ADD COLUMN value_schema jsonb NULL;`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 202610190006, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 202610190006
+}
+
+func upgradeSomaTo202610190007(curr int, tool string, printOnly bool) int {
+	if curr != 202610190006 {
+		return 0
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS soma.property_history ( id uuid NOT NULL DEFAULT public.gen_random_uuid(), instance_id uuid NOT NULL, repository_id uuid NOT NULL, object_id uuid NOT NULL, object_type varchar(64) NOT NULL, property_type varchar(64) NOT NULL, view varchar(64) NOT NULL, source_instance_id uuid NOT NULL, source_object_type varchar(64) NOT NULL, source_object_id uuid NOT NULL, inheritance_enabled boolean NOT NULL, children_only boolean NOT NULL, custom_property_id uuid NULL, system_property varchar(128) NULL, service_id uuid NULL, oncall_duty_id uuid NULL, value text NULL, created_by_job uuid NULL, created_at timestamptz(3) NOT NULL DEFAULT NOW()::timestamptz(3), deleted_by_job uuid NULL, deleted_at timestamptz(3) NULL, CONSTRAINT _property_history_primary_key PRIMARY KEY (id), CONSTRAINT _property_history_object_type FOREIGN KEY ( object_type ) REFERENCES soma.object_types ( object_type ) DEFERRABLE, CONSTRAINT _property_history_source_type FOREIGN KEY ( source_object_type ) REFERENCES soma.object_types ( object_type ) DEFERRABLE, CONSTRAINT _property_history_created_job FOREIGN KEY ( created_by_job ) REFERENCES soma.job ( id ) DEFERRABLE, CONSTRAINT _property_history_deleted_job FOREIGN KEY ( deleted_by_job ) REFERENCES soma.job ( id ) DEFERRABLE, CONSTRAINT _property_history_property_type CHECK ( property_type IN ( 'custom', 'system', 'service', 'oncall' ) ), CONSTRAINT _property_history_deletion CHECK ( ( deleted_at IS NULL ) = ( deleted_by_job IS NULL ) ), CONSTRAINT _property_history_timeline CHECK ( deleted_at IS NULL OR deleted_at >= created_at ));`,
+		`CREATE INDEX _property_history_by_object ON soma.property_history ( object_id, created_at, deleted_at );`,
+		`CREATE UNIQUE INDEX _property_history_open_instance ON soma.property_history ( instance_id ) WHERE deleted_at IS NULL;`,
+		`GRANT SELECT, INSERT, UPDATE, DELETE ON soma.property_history TO soma_svc;`,
+	}
+	// the history of existing property instances starts with this
+	// upgrade, they are recorded without creating job
+	for _, obj := range []string{`repository`, `bucket`, `group`, `cluster`, `node`} {
+		for _, prop := range []struct {
+			typ, table, columns string
+		}{
+			{`custom`, obj + `_custom_properties`, `p.custom_property_id, NULL::varchar, NULL::uuid, NULL::uuid, p.value`},
+			{`system`, obj + `_system_properties`, `NULL::uuid, p.system_property, NULL::uuid, NULL::uuid, p.value`},
+			{`service`, obj + `_service_property`, `NULL::uuid, NULL::varchar, p.service_id, NULL::uuid, NULL::text`},
+			{`oncall`, obj + `_oncall_properties`, `NULL::uuid, NULL::varchar, NULL::uuid, p.oncall_duty_id, NULL::text`},
+		} {
+			if obj == `node` && prop.typ == `oncall` {
+				prop.table = `node_oncall_property`
+			}
+			stmts = append(stmts, fmt.Sprintf("INSERT INTO soma.property_history ( instance_id, repository_id, object_id, object_type, property_type, view, source_instance_id, source_object_type, source_object_id, inheritance_enabled, children_only, custom_property_id, system_property, service_id, oncall_duty_id, value ) SELECT p.instance_id, p.repository_id, p.%s_id, '%s', '%s', p.view, p.source_instance_id, spi.source_object_type, spi.source_object_id, p.inheritance_enabled, p.children_only, %s FROM soma.%s p JOIN soma.property_instances spi ON p.instance_id = spi.instance_id;",
+				obj, obj, prop.typ, prop.columns, prop.table,
+			))
+		}
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 202610190007, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 202610190007
+}
+
 func upgradeAuthTo201605150002(curr int, tool string, printOnly bool) int {
 	if curr != 201605060001 {
 		return 0
@@ -290,6 +436,25 @@ func upgradeAuthTo201811150001(curr int, tool string, printOnly bool) int {
 	return 201811150001
 }
 
+func upgradeAuthTo202610190001(curr int, tool string, printOnly bool) int {
+	if curr != 201811150001 {
+		return 0
+	}
+
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS auth.api_keys ( id uuid NOT NULL DEFAULT public.gen_random_uuid(), user_id uuid NOT NULL REFERENCES inventory.user ( id ) ON DELETE CASCADE DEFERRABLE, name varchar(256) NOT NULL, key_hash varchar(256) NOT NULL, created_at timestamptz(3) NOT NULL DEFAULT NOW(), created_by uuid NOT NULL REFERENCES inventory.user ( id ) DEFERRABLE, valid_until timestamptz(3) NOT NULL, last_used_at timestamptz(3) NULL, revoked_at timestamptz(3) NULL, revoked_by uuid NULL REFERENCES inventory.user ( id ) DEFERRABLE, CONSTRAINT _api_key_primary_key PRIMARY KEY (id), CONSTRAINT _api_key_unique_hash UNIQUE (key_hash), CHECK( EXTRACT( TIMEZONE FROM created_at ) = '0' ), CHECK( EXTRACT( TIMEZONE FROM valid_until ) = '0' ), CHECK( EXTRACT( TIMEZONE FROM last_used_at ) = '0' ), CHECK( EXTRACT( TIMEZONE FROM revoked_at ) = '0' ), CHECK( ( revoked_at IS NULL ) = ( revoked_by IS NULL ) ) );`,
+		`CREATE UNIQUE INDEX _api_key_unique_active_name ON auth.api_keys ( user_id, name ) WHERE revoked_at IS NULL;`,
+		`CREATE TABLE IF NOT EXISTS auth.api_key_permissions ( api_key_id uuid NOT NULL REFERENCES auth.api_keys ( id ) ON DELETE CASCADE DEFERRABLE, permission_id uuid NOT NULL REFERENCES soma.permission ( id ) ON DELETE CASCADE DEFERRABLE, CONSTRAINT _api_key_permission_unique UNIQUE ( api_key_id, permission_id ) );`,
+		`GRANT SELECT, INSERT, UPDATE, DELETE ON auth.api_keys, auth.api_key_permissions TO soma_svc;`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('auth', 202610190001, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+
+	return 202610190001
+}
+
 func upgradeSomaTo201605210001(curr int, tool string, printOnly bool) int {
 	if curr != 201605060001 {
 		return 0
@@ -1139,6 +1304,22 @@ func upgradeSomaTo201903130001(curr int, tool string, printOnly bool) int {
 	return 201903130001
 }
 
+func upgradeSomaTo202610190001(curr int, tool string, printOnly bool) int {
+	if curr != 201903130001 {
+		return 0
+	}
+	stmts := []string{
+		`ALTER TABLE soma.job ADD COLUMN attempts integer NOT NULL DEFAULT 0;`,
+		`UPDATE soma.job SET attempts = 1 WHERE started_at IS NOT NULL;`,
+		`INSERT INTO soma.job_result ( name, created_by ) VALUES ( 'orphaned'::varchar, '00000000-0000-0000-0000-000000000000'::uuid ) ON CONFLICT ( name ) DO NOTHING;`,
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("INSERT INTO public.schema_versions (schema, version, description) VALUES ('soma', 202610190001, 'Upgrade - somadbctl %s');", tool),
+	)
+	executeUpgrades(stmts, printOnly)
+	return 202610190001
+}
+
 func installRoot201605150001(curr int, tool string, printOnly bool) int {
 	if curr != 000000000001 {
 		return 0
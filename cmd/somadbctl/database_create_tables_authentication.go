@@ -231,6 +231,37 @@ create table if not exists auth.password_reset (
            OR ( user_id IS     NULL AND admin_id IS     NULL AND tool_id IS NOT NULL ) )
 );`
 	queries[idx] = "createTablePasswordReset"
+	idx++
+
+	queryMap[`createTableAPIKeys`] = `
+create table if not exists auth.api_keys (
+    id                          uuid            NOT NULL DEFAULT public.gen_random_uuid(),
+    user_id                     uuid            NOT NULL REFERENCES inventory.user ( id ) ON DELETE CASCADE DEFERRABLE,
+    name                        varchar(256)    NOT NULL,
+    key_hash                    varchar(256)    NOT NULL,
+    created_at                  timestamptz(3)  NOT NULL DEFAULT NOW(),
+    created_by                  uuid            NOT NULL REFERENCES inventory.user ( id ) DEFERRABLE,
+    valid_until                 timestamptz(3)  NOT NULL,
+    last_used_at                timestamptz(3)  NULL,
+    revoked_at                  timestamptz(3)  NULL,
+    revoked_by                  uuid            NULL REFERENCES inventory.user ( id ) DEFERRABLE,
+    CONSTRAINT _api_key_primary_key PRIMARY KEY (id),
+    CONSTRAINT _api_key_unique_hash UNIQUE (key_hash),
+    CHECK( EXTRACT( TIMEZONE FROM created_at )   = '0' ),
+    CHECK( EXTRACT( TIMEZONE FROM valid_until )  = '0' ),
+    CHECK( EXTRACT( TIMEZONE FROM last_used_at ) = '0' ),
+    CHECK( EXTRACT( TIMEZONE FROM revoked_at )   = '0' ),
+    CHECK( ( revoked_at IS NULL ) = ( revoked_by IS NULL ) )
+);`
+	queries[idx] = `createTableAPIKeys`
+	idx++
+
+	queryMap[`createIndexUniqueActiveAPIKeyName`] = `
+create unique index _api_key_unique_active_name
+    on auth.api_keys ( user_id, name )
+    where revoked_at IS NULL
+;`
+	queries[idx] = `createIndexUniqueActiveAPIKeyName`
 
 	performDatabaseTask(printOnly, verbose, queries, queryMap)
 }
@@ -36,7 +36,9 @@ func createTablesChecks(printOnly bool, verbose bool) {
 
 	queryMap["createTableConfigurationPredicates"] = `
 create table if not exists soma.configuration_predicates (
-    predicate                   varchar(4)      PRIMARY KEY
+    predicate                   varchar(4)      PRIMARY KEY,
+    escalation                  varchar(16)     NOT NULL DEFAULT 'none',
+    CHECK ( escalation IN ( 'rising', 'falling', 'none' ) )
 );`
 	queries[idx] = "createTableConfigurationPredicates"
 	idx++
@@ -158,6 +158,58 @@ create table if not exists inventory.oncall_membership (
     CONSTRAINT _oncall_membership_user_exists FOREIGN KEY (user_id) REFERENCES inventory.user (id) ON DELETE CASCADE DEFERRABLE
 );`
 	queries[idx] = `create__inventory.oncall_membership`
+	idx++
+
+	queryMap[`create__inventory.oncall_rotation`] = `
+create table if not exists inventory.oncall_rotation (
+    oncall_id                   uuid            NOT NULL,
+    period                      varchar(16)     NOT NULL,
+    handover                    varchar(5)      NOT NULL,
+    start_date                  date            NOT NULL,
+    time_zone                   varchar(64)     NOT NULL,
+    created_by                  uuid            NOT NULL,
+    created_at                  timestamptz(3)  NOT NULL DEFAULT now(),
+    CONSTRAINT _oncall_rotation_primary_key PRIMARY KEY (oncall_id),
+    CONSTRAINT _oncall_rotation_creator_exists FOREIGN KEY(created_by) REFERENCES inventory.user(id) DEFERRABLE,
+    CONSTRAINT _oncall_rotation_oncall_exists FOREIGN KEY (oncall_id) REFERENCES inventory.oncall_team (id) ON DELETE CASCADE DEFERRABLE,
+    CONSTRAINT _oncall_rotation_valid_period CHECK( period IN ( 'daily', 'weekly' ) ),
+    CONSTRAINT _oncall_rotation_valid_handover CHECK( handover ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$' ),
+    CONSTRAINT _oncall_rotation_timezone_utc CHECK( EXTRACT( TIMEZONE FROM created_at ) = '0' )
+);`
+	queries[idx] = `create__inventory.oncall_rotation`
+	idx++
+
+	queryMap[`create__inventory.oncall_rotation_member`] = `
+create table if not exists inventory.oncall_rotation_member (
+    oncall_id                   uuid            NOT NULL,
+    user_id                     uuid            NOT NULL,
+    position                    smallint        NOT NULL,
+    CONSTRAINT _oncall_rotation_member_rotation_exists FOREIGN KEY (oncall_id) REFERENCES inventory.oncall_rotation (oncall_id) ON DELETE CASCADE DEFERRABLE,
+    CONSTRAINT _oncall_rotation_member_is_member FOREIGN KEY (user_id, oncall_id) REFERENCES inventory.oncall_membership (user_id, oncall_id) ON DELETE CASCADE DEFERRABLE,
+    CONSTRAINT _oncall_rotation_member_unique_position UNIQUE (oncall_id, position),
+    CONSTRAINT _oncall_rotation_member_only_once UNIQUE (oncall_id, user_id)
+);`
+	queries[idx] = `create__inventory.oncall_rotation_member`
+	idx++
+
+	queryMap[`create__inventory.oncall_override`] = `
+create table if not exists inventory.oncall_override (
+    id                          uuid            NOT NULL DEFAULT public.gen_random_uuid(),
+    oncall_id                   uuid            NOT NULL,
+    user_id                     uuid            NOT NULL,
+    starts_at                   timestamptz(3)  NOT NULL,
+    ends_at                     timestamptz(3)  NOT NULL,
+    created_by                  uuid            NOT NULL,
+    created_at                  timestamptz(3)  NOT NULL DEFAULT now(),
+    CONSTRAINT _oncall_override_primary_key PRIMARY KEY (id),
+    CONSTRAINT _oncall_override_creator_exists FOREIGN KEY(created_by) REFERENCES inventory.user(id) DEFERRABLE,
+    CONSTRAINT _oncall_override_is_member FOREIGN KEY (user_id, oncall_id) REFERENCES inventory.oncall_membership (user_id, oncall_id) ON DELETE CASCADE DEFERRABLE,
+    CONSTRAINT _oncall_override_valid_period CHECK( starts_at < ends_at ),
+    CONSTRAINT _oncall_override_timezone_utc CHECK( EXTRACT( TIMEZONE FROM created_at ) = '0' ),
+    CONSTRAINT _oncall_override_start_utc CHECK( EXTRACT( TIMEZONE FROM starts_at ) = '0' ),
+    CONSTRAINT _oncall_override_end_utc CHECK( EXTRACT( TIMEZONE FROM ends_at ) = '0' )
+);`
+	queries[idx] = `create__inventory.oncall_override`
 
 	performDatabaseTask(printOnly, verbose, queries, queryMap)
 }
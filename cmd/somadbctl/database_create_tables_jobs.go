@@ -61,6 +61,8 @@ create table if not exists soma.job (
     user_id                     uuid            NOT NULL,
     team_id                     uuid            NOT NULL,
     error                       text            NOT NULL DEFAULT '',
+    warnings                    jsonb           NOT NULL DEFAULT '[]'::jsonb,
+    attempts                    integer         NOT NULL DEFAULT 0,
     queued_at                   timestamptz(3)  NOT NULL DEFAULT NOW()::timestamptz(3),
     started_at                  timestamptz(3),
     finished_at                 timestamptz(3),
@@ -24,7 +24,12 @@ create table if not exists soma.environments (
 
 	queryMap["createTableObjectStates"] = `
 create table if not exists soma.object_states (
-    object_state                varchar(64)     PRIMARY KEY
+    object_state                varchar(64)     PRIMARY KEY,
+    check_handling              varchar(16)     NOT NULL DEFAULT 'active',
+    restrict_views              varchar(64)[]   NOT NULL DEFAULT '{}',
+    unassign_after              integer         NOT NULL DEFAULT 0,
+    CHECK ( check_handling IN ( 'active', 'suspend', 'deprovision', 'restrict' ) ),
+    CHECK ( unassign_after >= 0 )
 );`
 	queries[idx] = "createTableObjectStates"
 	idx++
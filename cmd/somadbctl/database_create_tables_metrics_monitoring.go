@@ -80,8 +80,10 @@ create table if not exists soma.monitoring_capabilities (
     capability_metric           varchar(512)    NOT NULL REFERENCES soma.metrics ( metric ) DEFERRABLE,
     capability_view             varchar(64)     NOT NULL REFERENCES soma.views ( view ) DEFERRABLE,
     threshold_amount            integer         NOT NULL,
+    min_interval                integer         NOT NULL DEFAULT 1,
     CHECK ( capability_view != 'any' ),
     CHECK ( threshold_amount >= 0 ),
+    CHECK ( min_interval > 0 ),
     UNIQUE ( capability_monitoring, capability_metric, capability_view )
 );`
 	queries[idx] = "createTableMonitoringCapabilities"
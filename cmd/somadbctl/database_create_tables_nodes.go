@@ -20,6 +20,9 @@ create table if not exists soma.nodes (
     node_deleted                boolean         NOT NULL DEFAULT 'no',
     created_by                  uuid            NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' REFERENCES inventory.user ( id ) DEFERRABLE,
     created_at                  timestamptz(3)  NOT NULL DEFAULT NOW(),
+    lifecycle_state             varchar(64)     NULL REFERENCES soma.object_states ( object_state ) DEFERRABLE,
+    lifecycle_state_since       timestamptz(3)  NULL,
+    lifecycle_state_by          varchar(256)    NULL,
     UNIQUE ( node_id, organizational_team_id )
 );`
 	queries[idx] = "createTableNodes"
@@ -241,6 +241,15 @@ create table if not exists soma.authorizations_team (
     UNIQUE ( user_id, tool_id, team_id, category, permission_id, authorized_team_id )
 );`
 	queries[idx] = "createTableTeamAuthorizations"
+	idx++
+
+	queryMap[`createTableAPIKeyPermissions`] = `
+create table if not exists auth.api_key_permissions (
+    api_key_id                  uuid            NOT NULL REFERENCES auth.api_keys ( id ) ON DELETE CASCADE DEFERRABLE,
+    permission_id               uuid            NOT NULL REFERENCES soma.permission ( id ) ON DELETE CASCADE DEFERRABLE,
+    CONSTRAINT _api_key_permission_unique UNIQUE ( api_key_id, permission_id )
+);`
+	queries[idx] = `createTableAPIKeyPermissions`
 
 	performDatabaseTask(printOnly, verbose, queries, queryMap)
 }
@@ -94,6 +94,7 @@ create table if not exists soma.custom_properties (
     custom_property_id          uuid            PRIMARY KEY,
     repository_id               uuid            NOT NULL REFERENCES soma.repository (id) DEFERRABLE,
     custom_property             varchar(128)    NOT NULL,
+    value_schema                jsonb           NULL,
     UNIQUE( repository_id, custom_property ),
     UNIQUE( repository_id, custom_property_id )
 );`
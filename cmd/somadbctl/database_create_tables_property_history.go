@@ -0,0 +1,70 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package main
+
+func createTablesPropertyHistory(printOnly bool, verbose bool) {
+	idx := 0
+	// map for storing the SQL statements by name
+	queryMap := make(map[string]string)
+	// slice storing the required statement order so foreign keys can
+	// resolve successfully
+	queries := make([]string, 5)
+
+	queryMap[`createTablePropertyHistory`] = `
+create table if not exists soma.property_history (
+    id                          uuid            NOT NULL DEFAULT public.gen_random_uuid(),
+    instance_id                 uuid            NOT NULL,
+    repository_id               uuid            NOT NULL,
+    object_id                   uuid            NOT NULL,
+    object_type                 varchar(64)     NOT NULL,
+    property_type               varchar(64)     NOT NULL,
+    view                        varchar(64)     NOT NULL,
+    source_instance_id          uuid            NOT NULL,
+    source_object_type          varchar(64)     NOT NULL,
+    source_object_id            uuid            NOT NULL,
+    inheritance_enabled         boolean         NOT NULL,
+    children_only               boolean         NOT NULL,
+    custom_property_id          uuid            NULL,
+    system_property             varchar(128)    NULL,
+    service_id                  uuid            NULL,
+    oncall_duty_id              uuid            NULL,
+    value                       text            NULL,
+    created_by_job              uuid            NULL,
+    created_at                  timestamptz(3)  NOT NULL DEFAULT NOW()::timestamptz(3),
+    deleted_by_job              uuid            NULL,
+    deleted_at                  timestamptz(3)  NULL,
+    CONSTRAINT _property_history_primary_key    PRIMARY KEY (id),
+    CONSTRAINT _property_history_object_type    FOREIGN KEY ( object_type ) REFERENCES soma.object_types ( object_type ) DEFERRABLE,
+    CONSTRAINT _property_history_source_type    FOREIGN KEY ( source_object_type ) REFERENCES soma.object_types ( object_type ) DEFERRABLE,
+    CONSTRAINT _property_history_created_job    FOREIGN KEY ( created_by_job ) REFERENCES soma.job ( id ) DEFERRABLE,
+    CONSTRAINT _property_history_deleted_job    FOREIGN KEY ( deleted_by_job ) REFERENCES soma.job ( id ) DEFERRABLE,
+    CONSTRAINT _property_history_property_type  CHECK ( property_type IN ( 'custom', 'system', 'service', 'oncall' ) ),
+    CONSTRAINT _property_history_deletion       CHECK ( ( deleted_at IS NULL ) = ( deleted_by_job IS NULL ) ),
+    CONSTRAINT _property_history_timeline       CHECK ( deleted_at IS NULL OR deleted_at >= created_at )
+);`
+	queries[idx] = `createTablePropertyHistory`
+	idx++
+
+	queryMap[`createIndexPropertyHistoryObject`] = `
+create index _property_history_by_object
+    on soma.property_history ( object_id, created_at, deleted_at )
+;`
+	queries[idx] = `createIndexPropertyHistoryObject`
+	idx++
+
+	queryMap[`createIndexPropertyHistoryOpen`] = `
+create unique index _property_history_open_instance
+    on soma.property_history ( instance_id )
+    where deleted_at IS NULL
+;`
+	queries[idx] = `createIndexPropertyHistoryOpen`
+
+	performDatabaseTask(printOnly, verbose, queries, queryMap)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -182,7 +182,7 @@ INSERT INTO public.schema_versions (
             description )
 VALUES (
             'inventory',
-            201811150001,
+            202610190001,
             'Initial create - somadbctl %s'
 );`, version)
 	queryMap["insertInventorySchemaVersion"] = invString
@@ -196,7 +196,7 @@ INSERT INTO public.schema_versions (
             description
 ) VALUES (
             'auth',
-            201811150001,
+            202610190001,
             'Initial create - somadbctl %s'
 );`, version)
 	queryMap["insertAuthSchemaVersion"] = authString
@@ -210,7 +210,7 @@ INSERT INTO public.schema_versions (
             description
 ) VALUES (
             'soma',
-            201903130001,
+            202610190007,
             'Initial create - somadbctl %s'
 );`, version)
 	queryMap["insertSomaSchemaVersion"] = somaString
@@ -11,6 +11,9 @@ var (
 	cache         *db.DB
 	async         bool
 	jobSave       bool
+	noCache       bool
+	recordJobs    bool
+	recordedJobs  []string
 	postProcessor string
 )
 
@@ -34,4 +37,22 @@ func ConfigureJSONPostProcessor(p string) {
 	postProcessor = p
 }
 
+func DisableLookupCache(b bool) {
+	noCache = b
+}
+
+// RecordJobs enables recording the IDs of all jobs that are created
+// by requests
+func RecordJobs(b bool) {
+	recordJobs = b
+}
+
+// RecordedJobs returns the IDs of the jobs that were recorded since
+// the last call
+func RecordedJobs() []string {
+	jobs := recordedJobs
+	recordedJobs = nil
+	return jobs
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
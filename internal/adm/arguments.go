@@ -115,28 +115,71 @@ func ParseVariadicCheckArguments(
 	constraints *[]proto.CheckConfigConstraint,
 	thresholds *[]proto.CheckConfigThreshold,
 	args []string,
+) error {
+	return parseVariadicCheckArguments(
+		result,
+		constraints,
+		thresholds,
+		[]string{
+			`threshold`,
+			`constraint`},
+		[]string{
+			`in`,
+			`on`,
+			`with`,
+			`interval`,
+			`inheritance`,
+			`childrenonly`,
+			`extern`},
+		[]string{
+			`in`,
+			`on`,
+			`with`,
+			`interval`},
+		args,
+	)
+}
+
+// ParseVariadicCheckUpdateArguments is a version of
+// ParseVariadicCheckArguments for the keywords that can be changed
+// on an existing check. Keywords do not have to be passed in.
+func ParseVariadicCheckUpdateArguments(
+	result map[string][]string,
+	thresholds *[]proto.CheckConfigThreshold,
+	args []string,
+) error {
+	constraints := []proto.CheckConfigConstraint{}
+
+	return parseVariadicCheckArguments(
+		result,
+		&constraints,
+		thresholds,
+		[]string{
+			`threshold`},
+		[]string{
+			`in`,
+			`interval`,
+			`extern`,
+			`enabled`},
+		[]string{
+			`in`},
+		args,
+	)
+}
+
+// parseVariadicCheckArguments implements ParseVariadicCheckArguments
+// for the provided keywords
+func parseVariadicCheckArguments(
+	result map[string][]string,
+	constraints *[]proto.CheckConfigConstraint,
+	thresholds *[]proto.CheckConfigThreshold,
+	multiple, unique, required []string,
+	args []string,
 ) error {
 	// used to hold found errors, so if three keywords are missing they can
 	// all be mentioned in one call
 	errors := []string{}
 
-	multiple := []string{
-		`threshold`,
-		`constraint`}
-	unique := []string{
-		`in`,
-		`on`,
-		`with`,
-		`interval`,
-		`inheritance`,
-		`childrenonly`,
-		`extern`}
-	required := []string{
-		`in`,
-		`on`,
-		`with`,
-		`interval`}
-
 	// merge key slices
 	keys := append(multiple, unique...)
 
@@ -540,6 +583,10 @@ func argumentsForCommand(s string) (multipleAllowed, uniqueOptions, mandatoryOpt
 		return []string{}, []string{`to`}, []string{`to`}
 	case `node::unassign`:
 		return []string{}, []string{`from`}, []string{}
+	case `node::move`:
+		return []string{}, []string{`to`, `group`, `cluster`}, []string{`to`}
+	case `node::set-state`:
+		return []string{}, []string{`to`}, []string{`to`}
 	default:
 		return []string{}, []string{}, []string{}
 	}
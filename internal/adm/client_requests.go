@@ -113,10 +113,24 @@ func handleRequestOptions(resp *resty.Response, err error) (*resty.Response, err
 		return nil, err
 	}
 
+	switch resp.StatusCode() {
+	case 400, 403, 404:
+		// a cached lookup result may refer to an object that no
+		// longer exists, or that has been moved or repossessed
+		InvalidateUsedLookups()
+	}
+
 	if resp.StatusCode() >= 300 {
 		return resp, fmt.Errorf("Request error: %s, %s", resp.Status(), resp.String())
 	}
 
+	if recordJobs && resp.StatusCode() == 202 {
+		recorded := &proto.Result{}
+		if decodeResponse(resp, recorded) == nil && recorded.JobID != `` {
+			recordedJobs = append(recordedJobs, recorded.JobID)
+		}
+	}
+
 	if !(async || jobSave) {
 		return resp, nil
 	}
@@ -152,6 +166,53 @@ func asyncWait(result *proto.Result) {
 	}
 }
 
+// WaitForJobs blocks until all jobs have been processed. It returns an
+// error listing all jobs that were not processed in time or did not
+// finish successfully.
+func WaitForJobs(jobs []string) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	req := proto.NewJobWaitRequest()
+	req.JobWait.IDList = jobs
+	req.JobWait.Mode = proto.JobWaitAll
+	resp, err := PostReqBody(req, `/job/wait`)
+	if err != nil {
+		return fmt.Errorf("Wait error for jobs: %s", err.Error())
+	}
+	res := proto.Result{}
+	if err = DecodedResponse(resp, &res); err != nil {
+		return fmt.Errorf("Wait error for jobs: %s", err.Error())
+	}
+	if res.Jobs == nil {
+		return nil
+	}
+
+	timeout, failed := []string{}, []string{}
+	for _, job := range *res.Jobs {
+		switch {
+		case job.Status != `processed`:
+			timeout = append(timeout, job.ID)
+		case job.Result != `success`:
+			failed = append(failed, fmt.Sprintf("%s (%s: %s)",
+				job.ID, job.Result, job.Error))
+		}
+	}
+	m := []string{}
+	if len(timeout) > 0 {
+		m = append(m, fmt.Sprintf("Wait timeout for jobs: %s",
+			strings.Join(timeout, `, `)))
+	}
+	if len(failed) > 0 {
+		m = append(m, fmt.Sprintf("Failed jobs: %s",
+			strings.Join(failed, `, `)))
+	}
+	if len(m) > 0 {
+		return fmt.Errorf("%s", strings.Join(m, `; `))
+	}
+	return nil
+}
+
 func decodeResponse(resp *resty.Response, res *proto.Result) error {
 	decoder := json.NewDecoder(bytes.NewReader(resp.Body()))
 	return decoder.Decode(res)
@@ -26,7 +26,9 @@ func LookupOncallID(s string) (string, error) {
 	if IsUUID(s) {
 		return s, nil
 	}
-	return oncallIDByName(s)
+	return cachedLookup(`oncall`, s, func() (string, error) {
+		return oncallIDByName(s)
+	})
 }
 
 // LookupOncallDetails looks up the details for oncall duty s.
@@ -52,7 +54,9 @@ func LookupUserID(s string) (string, error) {
 	if IsUUID(s) {
 		return s, nil
 	}
-	return userIDByUserName(s)
+	return cachedLookup(`user`, s, func() (string, error) {
+		return userIDByUserName(s)
+	})
 }
 
 // LookupAdminID looks up the UUID for an admin account of a
@@ -72,6 +76,16 @@ func LookupAdminID(s string) (string, error) {
 	return lookupAdminIDByUserID(userID)
 }
 
+// LookupAPIKeyID looks up the UUID of the active API key with name s
+// of the user with userID. If s is already a UUID, then it is
+// returned immediately.
+func LookupAPIKeyID(userID, s string) (string, error) {
+	if IsUUID(s) {
+		return s, nil
+	}
+	return apiKeyIDByName(userID, s)
+}
+
 // LookupTeamID looks up the UUID for a team on the server
 // with teamname s. Error is set if no such team was found
 // or an error occurred.
@@ -82,7 +96,9 @@ func LookupTeamID(s string, r *string) error {
 		*r = s
 		return nil
 	}
-	return teamIDByName(s, r)
+	return cachedLookupRef(`team`, s, r, func(t *string) error {
+		return teamIDByName(s, t)
+	})
 }
 
 // LookupTeamByRepo looks up the UUID for the team that is the
@@ -102,6 +118,7 @@ func LookupTeamByRepo(s string, r *string) error {
 		bID = s
 	}
 
+	// ownership changes, it is not cached
 	return teamIDByRepoID(bID, r)
 }
 
@@ -122,6 +139,7 @@ func LookupTeamByBucket(s string) (string, error) {
 		bID = s
 	}
 
+	// ownership changes, it is not cached
 	return teamIDByBucketID(bID)
 }
 
@@ -142,6 +160,7 @@ func LookupTeamByNode(s string) (string, error) {
 		nID = s
 	}
 
+	// ownership changes, it is not cached
 	return teamIDByNodeID(nID)
 }
 
@@ -153,7 +172,9 @@ func LookupRepoID(s string) (string, error) {
 	if IsUUID(s) {
 		return s, nil
 	}
-	return repoIDByName(s)
+	return cachedLookup(`repository`, s, func() (string, error) {
+		return repoIDByName(s)
+	})
 }
 
 //  LookupRepoName looks up the name for a repository on the server
@@ -166,7 +187,9 @@ func LookupRepoName(id string, name *string) error {
 		*name = id
 		return nil
 	}
-	return repoNameByID(id, name)
+	return cachedLookupRef(`repository-name`, id, name, func(n *string) error {
+		return repoNameByID(id, n)
+	})
 }
 
 // LookupRepoByBucket looks up the UUI for a repository by either
@@ -185,7 +208,9 @@ func LookupRepoByBucket(s string) (string, error) {
 		bID = s
 	}
 
-	return repoIDByBucketID(bID)
+	return cachedLookup(`bucket-repository`, bID, func() (string, error) {
+		return repoIDByBucketID(bID)
+	})
 }
 
 // LookupBucketID looks up the UUID for a bucket on the server
@@ -196,7 +221,9 @@ func LookupBucketID(s string) (string, error) {
 	if IsUUID(s) {
 		return s, nil
 	}
-	return bucketIDByName(s)
+	return cachedLookup(`bucket`, s, func() (string, error) {
+		return bucketIDByName(s)
+	})
 }
 
 // LookupGroupID looks up the UUID for group group in bucket
@@ -218,7 +245,9 @@ func LookupGroupID(group, bucket string) (string, error) {
 		bID = bucket
 	}
 
-	return groupIDByName(group, bID)
+	return cachedLookup(`group`, bID+`/`+group, func() (string, error) {
+		return groupIDByName(group, bID)
+	})
 }
 
 // LookupClusterID looks up the UUID for cluster cluster in
@@ -240,7 +269,9 @@ func LookupClusterID(cluster, bucket string) (string, error) {
 		bID = bucket
 	}
 
-	return clusterIDByName(cluster, bID)
+	return cachedLookup(`cluster`, bID+`/`+cluster, func() (string, error) {
+		return clusterIDByName(cluster, bID)
+	})
 }
 
 // LookupServerID looks up the UUID for a server either in the
@@ -284,7 +315,9 @@ func LookupMonitoringID(s string) (string, error) {
 	if IsUUID(s) {
 		return s, nil
 	}
-	return monitoringIDByName(s)
+	return cachedLookup(`monitoring`, s, func() (string, error) {
+		return monitoringIDByName(s)
+	})
 }
 
 // LookupNodeID looks up the UUID of the repository the bucket
@@ -294,7 +327,9 @@ func LookupNodeID(s string) (string, error) {
 	if IsUUID(s) {
 		return s, nil
 	}
-	return nodeIDByName(s)
+	return cachedLookup(`node`, s, func() (string, error) {
+		return nodeIDByName(s)
+	})
 }
 
 // LookupCapabilityID looks up the UUID of the capability with the
@@ -303,7 +338,9 @@ func LookupCapabilityID(s string) (string, error) {
 	if IsUUID(s) {
 		return s, nil
 	}
-	return capabilityIDByName(s)
+	return cachedLookup(`capability`, s, func() (string, error) {
+		return capabilityIDByName(s)
+	})
 }
 
 // LookupSectionID looks up the UUID of the section with the name
@@ -312,7 +349,9 @@ func LookupSectionID(s string) (string, error) {
 	if IsUUID(s) {
 		return s, nil
 	}
-	return sectionIDByName(s)
+	return cachedLookup(`section`, s, func() (string, error) {
+		return sectionIDByName(s)
+	})
 }
 
 // LookupActionID looks up the UUID of the action with the name
@@ -326,7 +365,9 @@ func LookupActionID(a, s string) (string, error) {
 	if sID, err = LookupSectionID(s); err != nil {
 		return ``, err
 	}
-	return actionIDByName(a, sID)
+	return cachedLookup(`action`, sID+`/`+a, func() (string, error) {
+		return actionIDByName(a, sID)
+	})
 }
 
 // LookupCategoryBySection returns the category for section s
@@ -373,7 +414,15 @@ func LookupCheckConfigID(name, repo, instance string) (string, string, error) {
 			return ``, ``, err
 		}
 		repoID = r
-		return checkConfigIDByName(name, repoID)
+		checkID, err := cachedLookup(`checkconfig`, repoID+`/`+name,
+			func() (string, error) {
+				id, _, err := checkConfigIDByName(name, repoID)
+				return id, err
+			})
+		if err != nil {
+			return ``, ``, err
+		}
+		return checkID, repoID, nil
 	} else if instance != `` {
 		return checkConfigIDByInstance(instance)
 	}
@@ -392,7 +441,41 @@ func LookupCustomPropertyID(s, repo string) (string, error) {
 		return ``, err
 	}
 	rID = r
-	return propertyIDByName(`custom`, s, rID)
+	return cachedLookup(`property-custom`, rID+`/`+s, func() (string, error) {
+		return propertyIDByName(`custom`, s, rID)
+	})
+}
+
+// LookupCustomPropertySchema looks up the value schema of the custom
+// property with UUID id in repository repoID. Properties without
+// schema return nil.
+func LookupCustomPropertySchema(id, repoID string) (*proto.PropertySchema, error) {
+	res, err := fetchObjList(fmt.Sprintf(
+		"/repository/%s/property-mgmt/%s/%s",
+		url.QueryEscape(repoID),
+		url.QueryEscape(proto.PropertyTypeCustom),
+		url.QueryEscape(id),
+	))
+	if err != nil {
+		goto abort
+	}
+
+	if res.Properties == nil || len(*res.Properties) == 0 ||
+		(*res.Properties)[0].Custom == nil {
+		err = fmt.Errorf(`no object returned`)
+		goto abort
+	}
+
+	if id != (*res.Properties)[0].Custom.ID {
+		err = fmt.Errorf("PropertyId mismatch: %s vs %s",
+			id, (*res.Properties)[0].Custom.ID)
+		goto abort
+	}
+	return (*res.Properties)[0].Custom.Schema, nil
+
+abort:
+	return nil, fmt.Errorf("CustomPropertySchema lookup failed: %s",
+		err.Error())
 }
 
 // LookupServicePropertyID looks up the id of a service property s
@@ -405,11 +488,13 @@ func LookupServicePropertyID(s, team string) (string, error) {
 	if IsUUID(team) {
 		tID = team
 	} else {
-		if err := teamIDByName(team, &tID); err != nil {
+		if err := LookupTeamID(team, &tID); err != nil {
 			return ``, err
 		}
 	}
-	return propertyIDByName(`service`, s, tID)
+	return cachedLookup(`property-service`, tID+`/`+s, func() (string, error) {
+		return propertyIDByName(`service`, s, tID)
+	})
 }
 
 // LookupTemplatePropertyID looks up the id of a service template
@@ -418,7 +503,9 @@ func LookupTemplatePropertyID(s string) (string, error) {
 	if IsUUID(s) {
 		return s, nil
 	}
-	return propertyIDByName(proto.PropertyTypeTemplate, s, `none`)
+	return cachedLookup(`property-template`, s, func() (string, error) {
+		return propertyIDByName(proto.PropertyTypeTemplate, s, `none`)
+	})
 }
 
 // LookupLevelName looks up the long name of a level s, where s
@@ -657,7 +744,7 @@ abort:
 // teamIDByBucketID implements the actual serverside lookup of
 // a bucket's TeamID
 func teamIDByBucketID(bucketID string) (string, error) {
-	repoID, err := repoIDByBucketID(bucketID)
+	repoID, err := LookupRepoByBucket(bucketID)
 	if err != nil {
 		return ``, err
 	}
@@ -710,6 +797,28 @@ abort:
 		err.Error())
 }
 
+// apiKeyIDByName implements the actual serverside lookup of the
+// active API key's UUID
+func apiKeyIDByName(userID, name string) (string, error) {
+	res, err := fetchObjList(fmt.Sprintf("/user/%s/apikey/", userID))
+	if err != nil {
+		goto abort
+	}
+
+	if res.APIKeys != nil {
+		for _, key := range *res.APIKeys {
+			// revoked API keys may share the name
+			if key.Name == name && key.RevokedAt == `` {
+				return key.ID, nil
+			}
+		}
+	}
+	err = fmt.Errorf("no active API key named %s", name)
+
+abort:
+	return ``, fmt.Errorf("APIKeyID lookup failed: %s", err.Error())
+}
+
 // teamIDByNodeID implements the actual serverside lookup of a
 // node's TeamID
 func teamIDByNodeID(node string) (string, error) {
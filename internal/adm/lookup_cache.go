@@ -0,0 +1,66 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package adm
+
+// usedLookups records the cached lookup results that were used by
+// this invocation, as kind and key
+var usedLookups [][2]string
+
+// cachedLookup returns the cached result of the lookup of key for
+// objects of type kind. If there is no cached result, the lookup is
+// performed via fetch and its result is cached. Failed lookups
+// remove the cached result.
+func cachedLookup(kind, key string, fetch func() (string, error)) (string, error) {
+	if noCache || cache == nil {
+		return fetch()
+	}
+
+	if value, err := cache.Lookup(kind, key); err == nil {
+		usedLookups = append(usedLookups, [2]string{kind, key})
+		return value, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		cache.InvalidateLookup(kind, key)
+		return ``, err
+	}
+	cache.SaveLookup(kind, key, value)
+	return value, nil
+}
+
+// cachedLookupRef is cachedLookup for lookups that return their
+// result via reference
+func cachedLookupRef(kind, key string, result *string, fetch func(*string) error) error {
+	value, err := cachedLookup(kind, key, func() (string, error) {
+		var r string
+		err := fetch(&r)
+		return r, err
+	})
+	if err != nil {
+		return err
+	}
+	*result = value
+	return nil
+}
+
+// InvalidateUsedLookups removes all cached lookup results that were
+// used by this invocation. It is called if the server rejects a
+// request or a command fails, which may have been caused by an
+// outdated lookup result.
+func InvalidateUsedLookups() {
+	if cache == nil {
+		return
+	}
+	for _, used := range usedLookups {
+		cache.InvalidateLookup(used[0], used[1])
+	}
+	usedLookups = nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
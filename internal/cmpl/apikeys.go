@@ -0,0 +1,9 @@
+package cmpl
+
+import "github.com/codegangsta/cli"
+
+func APIKeyAdd(c *cli.Context) {
+	GenericMulti(c, []string{`to`, `expires`}, []string{`permission`})
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
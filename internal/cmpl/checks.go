@@ -252,4 +252,91 @@ func CheckConfigCreate(c *cli.Context) {
 	}
 }
 
+func CheckConfigUpdate(c *cli.Context) {
+	topArgs := []string{`in`, `interval`, `extern`, `enabled`, `threshold`}
+	thrArgs := []string{`predicate`, `level`, `value`}
+
+	if c.NArg() == 0 {
+		return
+	}
+
+	skipNext := 0
+	subTHRESHOLD := false
+	seen := map[string]bool{}
+
+	hasTHRPredicate := false
+	hasTHRLevel := false
+	hasTHRValue := false
+
+	for _, t := range c.Args().Tail() {
+		if skipNext > 0 {
+			skipNext--
+			continue
+		}
+		if subTHRESHOLD {
+			if hasTHRPredicate && hasTHRLevel && hasTHRValue {
+				subTHRESHOLD = false
+				hasTHRPredicate = false
+				hasTHRLevel = false
+				hasTHRValue = false
+			} else {
+				switch t {
+				case `predicate`:
+					skipNext = 1
+					hasTHRPredicate = true
+					continue
+				case `level`:
+					skipNext = 1
+					hasTHRLevel = true
+					continue
+				case `value`:
+					skipNext = 1
+					hasTHRValue = true
+					continue
+				}
+			}
+		}
+		switch t {
+		case `in`, `interval`, `extern`, `enabled`:
+			skipNext = 1
+			seen[t] = true
+			continue
+		case `threshold`:
+			subTHRESHOLD = true
+			continue
+		}
+	}
+	// skipNext not yet consumed
+	if skipNext > 0 {
+		return
+	}
+	// in subchain: THRESHOLD
+	if subTHRESHOLD && !(hasTHRPredicate && hasTHRLevel && hasTHRValue) {
+		for _, t := range thrArgs {
+			switch {
+			case t == `predicate` && hasTHRPredicate:
+			case t == `level` && hasTHRLevel:
+			case t == `value` && hasTHRValue:
+			default:
+				fmt.Println(t)
+			}
+		}
+		return
+	}
+	// not in any subchain
+	for _, t := range topArgs {
+		if !seen[t] {
+			fmt.Println(t)
+		}
+	}
+}
+
+func CheckConfigExport(c *cli.Context) {
+	GenericDirect(c, []string{`in`, `to`, `check`})
+}
+
+func CheckConfigImport(c *cli.Context) {
+	Generic(c, []string{`into`, `dry-run`})
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
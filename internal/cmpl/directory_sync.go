@@ -0,0 +1,9 @@
+package cmpl
+
+import "github.com/codegangsta/cli"
+
+func DirectorySync(c *cli.Context) {
+	GenericDirect(c, []string{`dry-run`})
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
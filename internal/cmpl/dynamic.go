@@ -0,0 +1,77 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package cmpl
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+)
+
+// Dynamic completes the first argument with the names returned by
+// data and the following arguments with keywords. The arguments of
+// keywords listed in values are completed with the names returned by
+// the corresponding function. The name functions are only called if
+// their names are required for the completion.
+func Dynamic(c *cli.Context, data func() []string, keywords []string,
+	values map[string]func() []string) {
+	if c.NArg() == 0 {
+		if data != nil {
+			printNames(data())
+		}
+		return
+	}
+	dynamicKeywords(c.Args().Tail(), keywords, values)
+}
+
+// DynamicDirect works like Dynamic for commands that start with a
+// keyword instead of a name
+func DynamicDirect(c *cli.Context, keywords []string,
+	values map[string]func() []string) {
+	dynamicKeywords(c.Args(), keywords, values)
+}
+
+// dynamicKeywords completes the next keyword or the argument of the
+// last keyword in args
+func dynamicKeywords(args, keywords []string,
+	values map[string]func() []string) {
+	var last string
+	skip := 0
+	match := make(map[string]bool)
+
+	for _, t := range args {
+		if skip > 0 {
+			skip--
+			continue
+		}
+		skip = 1
+		last = t
+		match[t] = true
+	}
+	// complete the argument of the last keyword
+	if skip > 0 {
+		if f, ok := values[last]; ok {
+			printNames(f())
+		}
+		return
+	}
+	for _, t := range keywords {
+		if !match[t] {
+			fmt.Println(t)
+		}
+	}
+}
+
+// printNames prints one name per line
+func printNames(names []string) {
+	for _, name := range names {
+		fmt.Println(name)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -62,6 +62,8 @@ func Augmented(c *cli.Context, dispatcher string, data []string) {
 		GenericDataFirst(c, data, []string{`from`})
 	case `in`:
 		GenericDataFirst(c, data, []string{`in`})
+	case `move`:
+		GenericDataFirst(c, data, []string{`to`, `group`, `cluster`})
 	}
 }
 
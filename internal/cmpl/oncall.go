@@ -10,4 +10,16 @@ func OncallUpdate(c *cli.Context) {
 	Generic(c, []string{`phone`, `name`})
 }
 
+func OncallCurrent(c *cli.Context) {
+	Generic(c, []string{`at`})
+}
+
+func OncallRotationSet(c *cli.Context) {
+	GenericMulti(c, []string{`period`, `handover`, `start`, `timezone`}, []string{`member`})
+}
+
+func OncallOverrideAdd(c *cli.Context) {
+	Generic(c, []string{`user`, `from`, `until`})
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
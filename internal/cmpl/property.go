@@ -14,4 +14,14 @@ func Repository(c *cli.Context) {
 	Generic(c, []string{`repository`})
 }
 
+func PropertyCustomAdd(c *cli.Context) {
+	GenericMulti(c, []string{`to`, `type`, `regex`, `min`, `max`},
+		[]string{`enum`})
+}
+
+func PropertyCustomSetSchema(c *cli.Context) {
+	GenericMulti(c, []string{`in`, `type`, `regex`, `min`, `max`},
+		[]string{`enum`})
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -35,6 +35,8 @@ type Config struct {
 	NoPoke        bool       `json:"no.poke,string"`
 	PrintChannels bool       `json:"startup.print.channel.errors,string"`
 	ShutdownDelay uint64     `json:"shutdown.delay.seconds,string"`
+	JobAttempts   uint64     `json:"job.recovery.attempts,string"`
+	JobBackoff    uint64     `json:"job.recovery.backoff.seconds,string"`
 	InstanceName  string     `json:"instance.name"`
 	LogLevel      string     `json:"log.level"`
 	LogPath       string     `json:"log.path"`
@@ -44,6 +46,8 @@ type Config struct {
 	Daemon        Daemon     `json:"daemon"`
 	Auth          AuthConfig `json:"authentication"`
 	Ldap          LdapConfig `json:"ldap"`
+	Inventory     Inventory  `json:"inventory"`
+	CheckBudget   Budget     `json:"check.budget"`
 }
 
 // DbConfig provides the database credentials for SOMA
@@ -72,10 +76,14 @@ type AuthConfig struct {
 	KexExpirySeconds     uint64 `json:"kex.expiry,string"`
 	TokenExpirySeconds   uint64 `json:"token.expiry,string"`
 	CredentialExpiryDays uint64 `json:"credential.expiry,string"`
+	APIKeyExpiryDays     uint64 `json:"apikey.expiry,string"`
 	Activation           string `json:"activation.mode"`
 	// dd if=/dev/random bs=1M count=1 2>/dev/null | sha512
 	TokenSeed string `json:"token.seed"`
 	TokenKey  string `json:"token.key"`
+	// key for signing exported check configuration bundles, must be
+	// the same on all instances that exchange bundles
+	BundleKey string `json:"bundle.key"`
 }
 
 // LdapConfig stores the information required to access LDAP
@@ -88,6 +96,46 @@ type LdapConfig struct {
 	TLS        bool   `json:"tls,string"`
 	Cert       string `json:"cert.file"`
 	SkipVerify bool   `json:"insecure,string"`
+	// directory synchronization of users and teams
+	Sync            bool   `json:"sync.enabled,string"`
+	SyncInterval    uint64 `json:"sync.interval.minutes,string"`
+	SyncAccount     string `json:"sync.account"`
+	BindDN          string `json:"bind.dn"`
+	BindPassword    string `json:"bind.password"`
+	UserFilter      string `json:"user.filter"`
+	TeamDN          string `json:"team.dn"`
+	TeamFilter      string `json:"team.filter"`
+	TeamAttribute   string `json:"team.attribute"`
+	TeamIDAttribute string `json:"team.id.attribute"`
+	MemberAttribute string `json:"member.attribute"`
+	// maximum number of users a single sync deactivates
+	SyncMaxDeactivate uint64 `json:"sync.max.deactivate,string"`
+}
+
+// Inventory configures the reconciliation of servers and nodes
+// against an external inventory source
+type Inventory struct {
+	Enabled bool `json:"enabled,string"`
+	// file or http
+	Source  string `json:"source.type"`
+	Path    string `json:"source.file"`
+	URL     string `json:"source.url"`
+	Timeout uint64 `json:"source.timeout.seconds,string"`
+	// without apply, differences are only proposed
+	Apply    bool   `json:"apply,string"`
+	Interval uint64 `json:"interval.minutes,string"`
+	Account  string `json:"account"`
+	// maximum number of servers a single reconciliation removes
+	MaxRemove uint64 `json:"max.remove,string"`
+}
+
+// Budget limits the number of check instances. Jobs that create new
+// check instances fail if they exceed any limit, 0 is unlimited.
+type Budget struct {
+	Repository uint64 `json:"instances.per.repository,string"`
+	Team       uint64 `json:"instances.per.team,string"`
+	Monitoring uint64 `json:"instances.per.monitoring,string"`
+	Job        uint64 `json:"new.instances.per.job,string"`
 }
 
 // ReadConfigFile assembles soma.Config from a file
@@ -165,15 +213,38 @@ func (c *Config) ReadConfigFile(fname string) error {
 			c.PokePath)
 	}
 
+	if c.Auth.APIKeyExpiryDays == 0 {
+		log.Println(`Setting default value for authentication.apikey.expiry: 365`)
+		c.Auth.APIKeyExpiryDays = 365
+	}
+
 	if c.Auth.Activation == `ldap` && !c.Ldap.TLS {
 		log.Println(`Account activation via LDAP configured, but LDAP/TLS disabled!`)
 	}
 
+	if c.Ldap.Sync {
+		c.Ldap.setSyncDefaults()
+	}
+
+	if c.Inventory.Enabled {
+		c.Inventory.setDefaults()
+	}
+
 	if c.ShutdownDelay == 0 {
 		log.Println(`Setting default value for shutdown.delay.seconds: 5`)
 		c.ShutdownDelay = 5
 	}
 
+	if c.JobAttempts == 0 {
+		log.Println(`Setting default value for job.recovery.attempts: 3`)
+		c.JobAttempts = 3
+	}
+
+	if c.JobBackoff == 0 {
+		log.Println(`Setting default value for job.recovery.backoff.seconds: 5`)
+		c.JobBackoff = 5
+	}
+
 	switch c.LogLevel {
 	case `debug`, `info`, `warn`, `error`, `fatal`, `panic`:
 	default:
@@ -184,6 +255,75 @@ func (c *Config) ReadConfigFile(fname string) error {
 	return nil
 }
 
+// setSyncDefaults sets the default values for the LDAP directory
+// synchronization
+func (l *LdapConfig) setSyncDefaults() {
+	if l.SyncAccount == `` {
+		log.Println(`Setting default value for ldap.sync.account: root`)
+		l.SyncAccount = `root`
+	}
+	if l.SyncMaxDeactivate == 0 {
+		log.Println(`Setting default value for ldap.sync.max.deactivate: 10`)
+		l.SyncMaxDeactivate = 10
+	}
+	if l.UserFilter == `` {
+		log.Println(`Setting default value for ldap.user.filter: (objectClass=inetOrgPerson)`)
+		l.UserFilter = `(objectClass=inetOrgPerson)`
+	}
+	if l.TeamFilter == `` {
+		log.Println(`Setting default value for ldap.team.filter: (objectClass=posixGroup)`)
+		l.TeamFilter = `(objectClass=posixGroup)`
+	}
+	if l.TeamAttribute == `` {
+		log.Println(`Setting default value for ldap.team.attribute: cn`)
+		l.TeamAttribute = `cn`
+	}
+	if l.TeamIDAttribute == `` {
+		log.Println(`Setting default value for ldap.team.id.attribute: gidNumber`)
+		l.TeamIDAttribute = `gidNumber`
+	}
+	if l.MemberAttribute == `` {
+		log.Println(`Setting default value for ldap.member.attribute: memberUid`)
+		l.MemberAttribute = `memberUid`
+	}
+	if l.SyncInterval == 0 {
+		log.Println(`LDAP directory sync has no sync.interval.minutes, only running on demand`)
+	}
+}
+
+// setDefaults sets the default values for the inventory
+// reconciliation
+func (i *Inventory) setDefaults() {
+	switch i.Source {
+	case `file`:
+		if i.Path == `` {
+			log.Fatal(`Inventory source type file requires inventory.source.file`)
+		}
+	case `http`:
+		if i.URL == `` {
+			log.Fatal(`Inventory source type http requires inventory.source.url`)
+		}
+	default:
+		log.Fatal(`Invalid inventory.source.type specified: `, i.Source,
+			`. Valid types are: file, http`)
+	}
+	if i.Timeout == 0 {
+		log.Println(`Setting default value for inventory.source.timeout.seconds: 30`)
+		i.Timeout = 30
+	}
+	if i.Account == `` {
+		log.Println(`Setting default value for inventory.account: root`)
+		i.Account = `root`
+	}
+	if i.Interval == 0 {
+		log.Println(`Inventory reconciliation has no interval.minutes, only running on demand`)
+	}
+	if i.MaxRemove == 0 {
+		log.Println(`Setting default value for inventory.max.remove: 10`)
+		i.MaxRemove = 10
+	}
+}
+
 func (c *Config) verifyPathWritable(path string) error {
 	return unix.Access(path, unix.W_OK)
 }
@@ -0,0 +1,77 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// completionEntry is the cached list of object names of one kind
+type completionEntry struct {
+	Names  []string `json:"names"`
+	Expire string   `json:"expire"`
+}
+
+// SaveCompletion caches the object names of kind for shell
+// completion. The cached names expire after ttl.
+func (d *DB) SaveCompletion(kind string, names []string, ttl time.Duration) error {
+	if err := d.Open(); err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(`completion`))
+		if err != nil {
+			return err
+		}
+		data, _ := json.Marshal(&completionEntry{
+			Names:  names,
+			Expire: time.Now().UTC().Add(ttl).Format(time.RFC3339),
+		})
+		return b.Put([]byte(kind), data)
+	})
+}
+
+// Completion returns the cached object names of kind. If no
+// unexpired names are cached, bolt.ErrBucketNotFound is returned.
+func (d *DB) Completion(kind string) ([]string, error) {
+	if err := d.Open(); err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	entry := completionEntry{}
+	if err := d.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(`completion`))
+		if b == nil {
+			return bolt.ErrBucketNotFound
+		}
+
+		data := b.Get([]byte(kind))
+		if data == nil {
+			return bolt.ErrBucketNotFound
+		}
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return err
+		}
+		expire, _ := time.Parse(time.RFC3339, entry.Expire)
+		if time.Now().UTC().After(expire.UTC()) {
+			return bolt.ErrBucketNotFound
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return entry.Names, nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
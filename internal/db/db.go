@@ -25,6 +25,10 @@ type DB struct {
 	Path       string
 	Mode       os.FileMode
 	Options    *bolt.Options
+	// lookupAPI and lookupUser limit cached lookups to one API
+	// and user
+	lookupAPI  string
+	lookupUser string
 }
 
 func (d *DB) Configure(p string, m os.FileMode, o *bolt.Options) {
@@ -67,7 +71,7 @@ func (d *DB) EnsureBuckets() error {
 	}
 	defer d.Close()
 
-	for _, buck := range []string{"jobs", "tokens", "idcache"} {
+	for _, buck := range []string{"jobs", "tokens", "idcache", "completion"} {
 		err := d.db.Update(func(tx *bolt.Tx) error {
 			if _, err := tx.CreateBucketIfNotExists([]byte(buck)); err != nil {
 				return fmt.Errorf("Failed to create DB bucket: %s", err)
@@ -95,6 +99,9 @@ func (d *DB) EnsureBuckets() error {
 				if _, err := b.CreateBucketIfNotExists([]byte(`serverasset`)); err != nil {
 					return fmt.Errorf("Failed to create DB bucket: %s", err)
 				}
+				if _, err := b.CreateBucketIfNotExists([]byte(`lookup`)); err != nil {
+					return fmt.Errorf("Failed to create DB bucket: %s", err)
+				}
 			}
 			return nil
 		})
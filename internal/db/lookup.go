@@ -0,0 +1,191 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// LookupEntry is a cached result of a name to UUID lookup
+type LookupEntry struct {
+	API    string `json:"api"`
+	User   string `json:"user"`
+	Kind   string `json:"kind"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Expire string `json:"expire"`
+}
+
+// ScopeLookups limits all following lookups to the results cached
+// for user on the SOMA API at api. The same names can refer to
+// different objects on different SOMA instances, and users can see
+// different objects. Without a scope, lookups are not cached.
+func (d *DB) ScopeLookups(api, user string) {
+	d.lookupAPI = api
+	d.lookupUser = user
+}
+
+// isScoped reports if lookups are limited to an API and user
+func (d *DB) isScoped() bool {
+	return d.lookupAPI != `` && d.lookupUser != ``
+}
+
+// lookupKey returns the key of a lookup entry within the bucket,
+// which includes the scope of the lookup
+func (d *DB) lookupKey(kind, key string) []byte {
+	return []byte(d.lookupAPI + `|` + d.lookupUser + `|` + kind + `|` +
+		key)
+}
+
+// SaveLookup caches the result value of the lookup of key for
+// objects of type kind
+func (d *DB) SaveLookup(kind, key, value string) error {
+	if !d.isScoped() {
+		return nil
+	}
+	if err := d.Open(); err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket([]byte(`idcache`)).CreateBucketIfNotExists(
+			[]byte(`lookup`))
+		if err != nil {
+			return err
+		}
+		data, _ := json.Marshal(&LookupEntry{
+			API:    d.lookupAPI,
+			User:   d.lookupUser,
+			Kind:   kind,
+			Key:    key,
+			Value:  value,
+			Expire: time.Now().UTC().Add(time.Hour * 48).Format(time.RFC3339),
+		})
+		return b.Put(d.lookupKey(kind, key), data)
+	})
+}
+
+// Lookup returns the cached result of the lookup of key for objects
+// of type kind. If no unexpired result is cached,
+// bolt.ErrBucketNotFound is returned.
+func (d *DB) Lookup(kind, key string) (string, error) {
+	if !d.isScoped() {
+		return ``, bolt.ErrBucketNotFound
+	}
+	if err := d.Open(); err != nil {
+		return ``, err
+	}
+	defer d.Close()
+
+	entry := LookupEntry{}
+	if err := d.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(`idcache`)).Bucket([]byte(`lookup`))
+		if b == nil {
+			return bolt.ErrBucketNotFound
+		}
+
+		data := b.Get(d.lookupKey(kind, key))
+		if data == nil {
+			return bolt.ErrBucketNotFound
+		}
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return err
+		}
+		expire, _ := time.Parse(time.RFC3339, entry.Expire)
+		if time.Now().UTC().After(expire.UTC()) {
+			return bolt.ErrBucketNotFound
+		}
+		return nil
+	}); err != nil {
+		return ``, err
+	}
+	return entry.Value, nil
+}
+
+// InvalidateLookup removes the cached result of the lookup of key
+// for objects of type kind
+func (d *DB) InvalidateLookup(kind, key string) error {
+	if err := d.Open(); err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(`idcache`)).Bucket([]byte(`lookup`))
+		if b == nil {
+			return nil
+		}
+		return b.Delete(d.lookupKey(kind, key))
+	})
+}
+
+// Lookups returns all cached lookup results of all scopes, including
+// expired ones
+func (d *DB) Lookups() ([]LookupEntry, error) {
+	if err := d.Open(); err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	entries := []LookupEntry{}
+	if err := d.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(`idcache`)).Bucket([]byte(`lookup`))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			entry := LookupEntry{}
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ClearCache removes all cached lookup results, cached servers and
+// cached shell completion names
+func (d *DB) ClearCache() error {
+	if err := d.Open(); err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(`idcache`))
+		for _, name := range []string{`lookup`, `servername`, `serverasset`} {
+			if b.Bucket([]byte(name)) == nil {
+				continue
+			}
+			if err := b.DeleteBucket([]byte(name)); err != nil {
+				return err
+			}
+			if _, err := b.CreateBucket([]byte(name)); err != nil {
+				return err
+			}
+		}
+		if tx.Bucket([]byte(`completion`)) == nil {
+			return nil
+		}
+		if err := tx.DeleteBucket([]byte(`completion`)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket([]byte(`completion`))
+		return err
+	})
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -36,6 +36,7 @@ const (
 	SectionEnvironment      = `environment`
 	SectionHostDeployment   = `hostdeployment`
 	SectionInstanceMgmt     = `instance-mgmt`
+	SectionInventory        = `inventory`
 	SectionJobMgmt          = `job-mgmt`
 	SectionJobResultMgmt    = `job-result-mgmt`
 	SectionJobStatusMgmt    = `job-status-mgmt`
@@ -64,10 +65,12 @@ const (
 // Sections in category Identity are special global sections for actions
 // related to identity management
 const (
-	CategoryIdentity = `identity`
-	SectionAdminMgmt = `admin-mgmt`
-	SectionTeamMgmt  = `team-mgmt`
-	SectionUserMgmt  = `user-mgmt`
+	CategoryIdentity     = `identity`
+	SectionAPIKeyMgmt    = `apikey-mgmt`
+	SectionAdminMgmt     = `admin-mgmt`
+	SectionDirectorySync = `directory-sync`
+	SectionTeamMgmt      = `team-mgmt`
+	SectionUserMgmt      = `user-mgmt`
 )
 
 // Sections in category self are for actions with a per-user
@@ -103,6 +106,7 @@ const (
 const (
 	CategoryRepository      = `repository`
 	SectionBucket           = `bucket`
+	SectionChangeSet        = `changeset`
 	SectionCheckConfig      = `check-config`
 	SectionCluster          = `cluster`
 	SectionGroup            = `group`
@@ -134,29 +138,44 @@ const (
 const (
 	ActionAdd             = `add`
 	ActionAll             = `all`
+	ActionApply           = `apply`
 	ActionAssemble        = `assemble`
 	ActionAssign          = `assign`
 	ActionAudit           = `audit`
+	ActionClone           = `clone`
 	ActionCreate          = `create`
+	ActionCurrent         = `current`
 	ActionDeclare         = `declare`
 	ActionDelete          = `delete`
 	ActionDestroy         = `destroy`
+	ActionDrift           = `drift`
+	ActionEvaluate        = `evaluate`
+	ActionExport          = `export`
 	ActionFailed          = `failed`
 	ActionFilter          = `filter`
+	ActionFsck            = `fsck`
 	ActionGet             = `get`
 	ActionGrant           = `grant`
+	ActionImport          = `import`
 	ActionInsertNullID    = `insert-null`
+	ActionLifecycle       = `lifecycle`
 	ActionList            = `list`
 	ActionMap             = `map`
 	ActionMemberAssign    = `member-assign`
 	ActionMemberList      = `member-list`
 	ActionMemberUnassign  = `member-unassign`
+	ActionMove            = `move`
+	ActionOverrideAdd     = `override-add`
+	ActionOverrideBudget  = `override-budget`
+	ActionOverrideRemove  = `override-remove`
 	ActionPending         = `pending`
 	ActionPropertyCreate  = `property-create`
 	ActionPropertyDestroy = `property-destroy`
+	ActionPropertyHistory = `property-history`
 	ActionPropertyUpdate  = `property-update`
 	ActionPurge           = `purge`
 	ActionRemove          = `remove`
+	ActionReconcile       = `reconcile`
 	ActionRename          = `rename`
 	ActionRepoRebuild     = `rebuild-repository`
 	ActionRepoRestart     = `restart-repository`
@@ -164,6 +183,8 @@ const (
 	ActionRepossess       = `repossess`
 	ActionRetry           = `retry`
 	ActionRevoke          = `revoke`
+	ActionRotationClear   = `rotation-clear`
+	ActionRotationSet     = `rotation-set`
 	ActionSearch          = `search`
 	ActionSearchAll       = `search/all`
 	ActionSearchByList    = `search/list`
@@ -23,6 +23,7 @@ type Request struct {
 	TargetEntity  string
 	RemoteAddr    string
 	AuthUser      string
+	AuthAPIKey    string
 	RequestURI    string
 	Reply         chan Result `json:"-"`
 	JobID         uuid.UUID
@@ -31,13 +32,19 @@ type Request struct {
 	Flag          Flags
 	DeploymentIDs []string
 
-	Super *Supervisor
-	Cache *Request
+	Super     *Supervisor
+	Cache     *Request
+	ChangeSet []Request
+	// Warnings are raised while validating the request, they are
+	// recorded with the job
+	Warnings []string
 
+	APIKey      proto.APIKey
 	ActionObj   proto.Action
 	Admin       proto.Admin
 	Attribute   proto.Attribute
 	Bucket      proto.Bucket
+	Bundle      proto.Bundle
 	Capability  proto.Capability
 	Category    proto.Category
 	CheckConfig proto.CheckConfig
@@ -53,6 +60,7 @@ type Request struct {
 	JobResult   proto.JobResult
 	JobStatus   proto.JobStatus
 	JobType     proto.JobType
+	JobWait     proto.JobWait
 	Level       proto.Level
 	Metric      proto.Metric
 	Mode        proto.Mode
@@ -86,36 +94,39 @@ func New(r *http.Request, params httprouter.Params) Request {
 		RequestURI: requestURI(params),
 		RemoteAddr: remoteAddr(r),
 		AuthUser:   authUser(params),
+		AuthAPIKey: authAPIKey(params),
 		Reply:      returnChannel,
 	}
 }
 
 type Filter struct {
-	IsDetailed bool
-	ActionObj  proto.Action
-	Bucket     proto.BucketFilter
-	Cluster    proto.Cluster
-	Grant      proto.Grant
-	Group      proto.Group
-	Job        proto.JobFilter
-	JobResult  proto.JobResult
-	JobStatus  proto.JobStatus
-	JobType    proto.JobType
-	Level      proto.Level
-	Monitoring proto.Monitoring
-	Node       proto.Node
-	Oncall     proto.Oncall
-	Permission proto.Permission
-	Property   proto.Property
-	Repository proto.RepositoryFilter
-	SectionObj proto.Section
-	Server     proto.Server
-	Team       proto.Team
-	User       proto.User
+	IsDetailed  bool
+	ActionObj   proto.Action
+	Bucket      proto.BucketFilter
+	CheckConfig proto.CheckConfigFilter
+	Cluster     proto.Cluster
+	Grant       proto.Grant
+	Group       proto.Group
+	Job         proto.JobFilter
+	JobResult   proto.JobResult
+	JobStatus   proto.JobStatus
+	JobType     proto.JobType
+	Level       proto.Level
+	Monitoring  proto.Monitoring
+	Node        proto.Node
+	Oncall      proto.Oncall
+	Permission  proto.Permission
+	Property    proto.Property
+	Repository  proto.RepositoryFilter
+	SectionObj  proto.Section
+	Server      proto.Server
+	Team        proto.Team
+	User        proto.User
 }
 
 type UpdateData struct {
 	Bucket      proto.Bucket
+	CheckConfig proto.CheckConfig
 	Cluster     proto.Cluster
 	Datacenter  proto.Datacenter
 	Entity      proto.Entity
@@ -135,6 +146,34 @@ type Flags struct {
 	Unscoped     bool
 	Rebuild      bool
 	RebuildLevel string
+	DryRun       bool
+	// PendingBucket is set on change set steps whose bucket is
+	// created by an earlier step of the same change set
+	PendingBucket bool
+	// Enable and Disable set the enabled state of a check
+	// configuration that is updated
+	Enable  bool
+	Disable bool
+	// OverrideBudget allows the job to exceed the check instance
+	// budgets
+	OverrideBudget bool
+}
+
+// BudgetOverrideFromRequest returns the request that must be
+// authorized for rq to exceed the check instance budgets. The
+// permission is part of the check-config section and scoped to the
+// bucket or repository rq is issued for.
+func BudgetOverrideFromRequest(rq *Request) *Request {
+	override := *rq
+	override.Section = SectionCheckConfig
+	override.Action = ActionOverrideBudget
+	if override.Repository.ID == `` {
+		override.Repository.ID = rq.CheckConfig.RepositoryID
+	}
+	if override.Bucket.ID == `` {
+		override.Bucket.ID = rq.CheckConfig.BucketID
+	}
+	return &override
 }
 
 func CacheUpdateFromRequest(rq *Request) Request {
@@ -26,49 +26,58 @@ type Result struct {
 
 	Super Supervisor
 
-	ActionObj      []proto.Action
-	Admin          []proto.Admin
-	Attribute      []proto.Attribute
-	Bucket         []proto.Bucket
-	Capability     []proto.Capability
-	Category       []proto.Category
-	CheckConfig    []proto.CheckConfig
-	Cluster        []proto.Cluster
-	Datacenter     []proto.Datacenter
-	Deployment     []proto.Deployment
-	Entity         []proto.Entity
-	Environment    []proto.Environment
-	Grant          []proto.Grant
-	Group          []proto.Group
-	HostDeployment []proto.HostDeployment
-	Instance       []proto.Instance
-	Job            []proto.Job
-	JobResult      []proto.JobResult
-	JobStatus      []proto.JobStatus
-	JobType        []proto.JobType
-	Level          []proto.Level
-	Metric         []proto.Metric
-	Mode           []proto.Mode
-	Monitoring     []proto.Monitoring
-	Node           []proto.Node
-	Oncall         []proto.Oncall
-	Permission     []proto.Permission
-	Predicate      []proto.Predicate
-	Property       []proto.Property
-	Provider       []proto.Provider
-	Repository     []proto.Repository
-	SectionObj     []proto.Section
-	Server         []proto.Server
-	State          []proto.State
-	Status         []proto.Status
-	System         []proto.System
-	Team           []proto.Team
-	Tree           proto.Tree
-	Unit           []proto.Unit
-	User           []proto.User
-	Validity       []proto.Validity
-	View           []proto.View
-	Workflow       []proto.Workflow
+	APIKey          []proto.APIKey
+	ActionObj       []proto.Action
+	Admin           []proto.Admin
+	Attribute       []proto.Attribute
+	Bucket          []proto.Bucket
+	Bundle          []proto.Bundle
+	Capability      []proto.Capability
+	Category        []proto.Category
+	CheckConfig     []proto.CheckConfig
+	Cluster         []proto.Cluster
+	Datacenter      []proto.Datacenter
+	Deployment      []proto.Deployment
+	DirectorySync   []proto.DirectorySync
+	Entity          []proto.Entity
+	Environment     []proto.Environment
+	Evaluation      []proto.CheckEvaluation
+	Fsck            []proto.Fsck
+	Grant           []proto.Grant
+	Graph           *proto.Graph
+	Group           []proto.Group
+	HostDeployment  []proto.HostDeployment
+	Import          []proto.BundleImport
+	Instance        []proto.Instance
+	InventoryDrift  []proto.InventoryDrift
+	Job             []proto.Job
+	JobResult       []proto.JobResult
+	JobStatus       []proto.JobStatus
+	JobType         []proto.JobType
+	Level           []proto.Level
+	Metric          []proto.Metric
+	Mode            []proto.Mode
+	Monitoring      []proto.Monitoring
+	Node            []proto.Node
+	Oncall          []proto.Oncall
+	Permission      []proto.Permission
+	Predicate       []proto.Predicate
+	Property        []proto.Property
+	PropertyHistory []proto.PropertyHistory
+	Provider        []proto.Provider
+	Repository      []proto.Repository
+	SectionObj      []proto.Section
+	Server          []proto.Server
+	State           []proto.State
+	Status          []proto.Status
+	System          []proto.System
+	Team            []proto.Team
+	Tree            proto.Tree
+	Unit            []proto.Unit
+	User            []proto.User
+	Validity        []proto.Validity
+	View            []proto.View
+	Workflow        []proto.Workflow
 }
 
 func FromRequest(rq *Request) Result {
@@ -147,6 +156,8 @@ func (r *Result) Clear(s string) {
 		r.ActionObj = []proto.Action{}
 	case `admin`:
 		r.Admin = []proto.Admin{}
+	case SectionAPIKeyMgmt:
+		r.APIKey = []proto.APIKey{}
 	case `attribute`:
 		r.Attribute = []proto.Attribute{}
 	case `bucket`:
@@ -163,6 +174,8 @@ func (r *Result) Clear(s string) {
 		r.Datacenter = []proto.Datacenter{}
 	case `deployment`:
 		r.Deployment = []proto.Deployment{}
+	case SectionDirectorySync:
+		r.DirectorySync = []proto.DirectorySync{}
 	case `entity`:
 		r.Entity = []proto.Entity{}
 	case `environment`:
@@ -176,6 +189,8 @@ func (r *Result) Clear(s string) {
 		r.Deployment = []proto.Deployment{}
 	case `instance`, `instance-mgmt`:
 		r.Instance = []proto.Instance{}
+	case SectionInventory:
+		r.InventoryDrift = []proto.InventoryDrift{}
 	case `job`:
 		r.Job = []proto.Job{}
 	case `level`:
@@ -32,6 +32,10 @@ type Supervisor struct {
 	}
 	// The active token to be invalidated
 	AuthToken string
+	// ID of the API key the request was authenticated with and the
+	// IDs of the permissions the API key is restricted to
+	APIKeyID string
+	Scope    []string
 	// Request to be authorized
 	Authorize *Request
 	// AuditLog Entry for this supervisor task
@@ -61,6 +65,8 @@ func (s *Supervisor) Clear() {
 		Token string
 	}{}
 	s.Authorize = nil
+	s.APIKeyID = ``
+	s.Scope = nil
 	s.Object = ``
 	s.User = proto.User{}
 	s.Team = proto.Team{}
@@ -28,7 +28,11 @@ func (c *Cache) isAuthorized(q *msg.Request) msg.Result {
 	var user *proto.User
 	var subjType, category, actionID, sectionID string
 	var sectionPermIDs, actionPermIDs, mergedPermIDs []string
-	var any bool
+	var any, scoped, unrestricted bool
+
+	// requests authenticated with an API key are restricted to the
+	// permissions of the API key
+	scoped = q.Super.APIKeyID != ``
 
 	// determine type of the request subject
 	switch {
@@ -55,7 +59,8 @@ func (c *Cache) isAuthorized(q *msg.Request) msg.Result {
 	}
 
 	// check if the subject has omnipotence
-	if c.checkOmnipotence(subjType, user.ID, &result) {
+	if (!scoped || inScope(q.Super.Scope, omnipotenceID)) &&
+		c.checkOmnipotence(subjType, user.ID, &result) {
 		result.Super.Audit = result.Super.Audit.
 			WithField(`permCache::status`, `evaluated`).
 			WithField(`permCache::result`, `omnipotent`)
@@ -76,6 +81,11 @@ func (c *Cache) isAuthorized(q *msg.Request) msg.Result {
 	// XXX BUG nilptr crash if section is not found
 	category = c.section.getByName(q.Super.Authorize.Section).Category
 
+	// API keys that contain omnipotence or the system permission of
+	// the category are not restricted within the category
+	unrestricted = !scoped || inScope(q.Super.Scope, omnipotenceID) ||
+		inScope(q.Super.Scope, c.pmap.getIDByName(`system`, category))
+
 	// lookup sectionID and actionID of the Request, abort for
 	// unknown actions
 	if action := c.action.getByName(
@@ -100,7 +110,7 @@ func (c *Cache) isAuthorized(q *msg.Request) msg.Result {
 			WithField(`permCache::result`, `InternalError`).
 			WithField(`permCache::error`, `InvalidMissingSystemPermission`)
 		goto dispatch
-	} else if ok {
+	} else if ok && unrestricted {
 		result.Super.Audit = result.Super.Audit.
 			WithField(`permCache::status`, `evaluated`).
 			WithField(`permCache::result`, `systempermission`)
@@ -128,6 +138,17 @@ func (c *Cache) isAuthorized(q *msg.Request) msg.Result {
 	actionPermIDs = c.pmap.getActionPermissionID(sectionID, actionID)
 	mergedPermIDs = append(sectionPermIDs, actionPermIDs...)
 
+	// restrict the permissions to the scope of the API key
+	if !unrestricted {
+		if mergedPermIDs = restrictToScope(q.Super.Scope,
+			mergedPermIDs); len(mergedPermIDs) == 0 {
+			result.Super.Audit = result.Super.Audit.
+				WithField(`permCache::status`, `exhausted`).
+				WithField(`permCache::error`, `OutOfScope`)
+			goto dispatch
+		}
+	}
+
 	// check if we care about the specific object
 	switch q.Super.Authorize.Action {
 	case `list`, `search`:
@@ -172,17 +193,43 @@ dispatch:
 	return result
 }
 
+// omnipotenceID is the ID of the omnipotence permission
+const omnipotenceID = `00000000-0000-0000-0000-000000000000`
+
 // checkOmnipotence returns true if the subject is omnipotent
 func (c *Cache) checkOmnipotence(subjectType, subjectID string, result *msg.Result) bool {
 	return c.grantGlobal.assess(
 		subjectType,
 		subjectID,
 		`omnipotence`,
-		`00000000-0000-0000-0000-000000000000`,
+		omnipotenceID,
 		result,
 	)
 }
 
+// inScope returns true if the API key scope contains permission
+// permID
+func inScope(scope []string, permID string) bool {
+	for _, id := range scope {
+		if id == permID {
+			return true
+		}
+	}
+	return false
+}
+
+// restrictToScope returns the permIDs that are part of the API key
+// scope
+func restrictToScope(scope, permIDs []string) []string {
+	restricted := []string{}
+	for _, permID := range permIDs {
+		if inScope(scope, permID) {
+			restricted = append(restricted, permID)
+		}
+	}
+	return restricted
+}
+
 // checkSystem returns true,false if the subject has the system
 // permission for the category. If no system permission exists it
 // returns false,true
@@ -228,8 +275,8 @@ permloop:
 			case msg.SectionRepository:
 				objID = q.Repository.TeamID
 			// per-repository scope
-			case msg.SectionInstance, msg.SectionNodeConfig, msg.SectionPropertyCustom,
-				msg.SectionRepositoryConfig:
+			case msg.SectionChangeSet, msg.SectionInstance, msg.SectionNodeConfig,
+				msg.SectionPropertyCustom, msg.SectionRepositoryConfig:
 				objID = q.Repository.ID
 			case msg.SectionBucket, msg.SectionCluster, msg.SectionCheckConfig,
 				msg.SectionGroup:
@@ -249,9 +296,10 @@ permloop:
 				category, objID, permID, any, result) {
 				return true
 			}
-		case msg.SectionBucket, msg.SectionCheckConfig, msg.SectionCluster,
-			msg.SectionGroup, msg.SectionInstance, msg.SectionNodeConfig,
-			msg.SectionPropertyCustom, msg.SectionRepositoryConfig:
+		case msg.SectionBucket, msg.SectionChangeSet, msg.SectionCheckConfig,
+			msg.SectionCluster, msg.SectionGroup, msg.SectionInstance,
+			msg.SectionNodeConfig, msg.SectionPropertyCustom,
+			msg.SectionRepositoryConfig:
 			// per-repository sections
 			if c.grantRepository.assess(subjectType, subjectID,
 				category, objID, permID, any, result) {
@@ -262,6 +310,16 @@ permloop:
 				msg.SectionGroup:
 				// permission could be on the repository
 				objID = c.object.repoForBucket(q.Bucket.ID)
+				switch {
+				case objID == `` && q.Flag.PendingBucket:
+					// the bucket is created by an earlier step of
+					// the same change set
+					objID = q.Repository.ID
+				case objID == `` && q.Action == msg.ActionOverrideBudget:
+					// budget overrides for jobs that are not bound
+					// to a bucket are scoped to the repository
+					objID = q.Repository.ID
+				}
 				if objID == `` {
 					continue permloop
 				}
@@ -139,6 +139,17 @@ func (c *Cache) performNodeUnassign(q *msg.Request) {
 	c.lock.Unlock()
 }
 
+// performNodeMove updates the bucket of a node in the object cache
+func (c *Cache) performNodeMove(q *msg.Request) {
+	c.lock.Lock()
+	c.object.rmNode(q.Node.ID)
+	c.object.addNode(
+		q.Update.Node.Config.BucketID,
+		q.Node.ID,
+	)
+	c.lock.Unlock()
+}
+
 // performPermissionAdd registers a permission
 func (c *Cache) performPermissionAdd(q *msg.Request) {
 	c.lock.Lock()
@@ -69,6 +69,8 @@ func (c *Cache) performNode(q *msg.Request) {
 		c.performNodeAssign(q)
 	case msg.ActionUnassign:
 		c.performNodeUnassign(q)
+	case msg.ActionMove:
+		c.performNodeMove(q)
 	}
 }
 
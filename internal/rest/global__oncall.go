@@ -10,6 +10,7 @@
 package rest // import "github.com/mjolnir42/soma/internal/rest"
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -232,4 +233,138 @@ func (x *Rest) OncallMemberList(w http.ResponseWriter, r *http.Request,
 	x.send(&w, &result)
 }
 
+// OncallCurrent function
+func (x *Rest) OncallCurrent(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionOncall
+	request.Action = msg.ActionCurrent
+	request.Oncall.ID = params.ByName(`oncallID`)
+	request.Oncall.Duty = &proto.OncallDuty{
+		At: r.URL.Query().Get(`at`),
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// OncallRotationSet function
+func (x *Rest) OncallRotationSet(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionOncall
+	request.Action = msg.ActionRotationSet
+
+	cReq := proto.NewOncallRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	if cReq.Oncall.Rotation == nil || len(cReq.Oncall.Rotation.Members) == 0 {
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			`OncallRotationSet request without rotation members`))
+		return
+	}
+	request.Oncall.ID = params.ByName(`oncallID`)
+	request.Oncall.Rotation = cReq.Oncall.Rotation.Clone()
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// OncallRotationClear function
+func (x *Rest) OncallRotationClear(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionOncall
+	request.Action = msg.ActionRotationClear
+	request.Oncall.ID = params.ByName(`oncallID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// OncallOverrideAdd function
+func (x *Rest) OncallOverrideAdd(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionOncall
+	request.Action = msg.ActionOverrideAdd
+
+	cReq := proto.NewOncallRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	if cReq.Oncall.Overrides == nil || len(*cReq.Oncall.Overrides) != 1 {
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			`OncallOverrideAdd request requires exactly one override`))
+		return
+	}
+	request.Oncall.ID = params.ByName(`oncallID`)
+	request.Oncall.Overrides = &[]proto.OncallOverride{
+		(*cReq.Oncall.Overrides)[0],
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// OncallOverrideRemove function
+func (x *Rest) OncallOverrideRemove(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionOncall
+	request.Action = msg.ActionOverrideRemove
+	request.Oncall.ID = params.ByName(`oncallID`)
+	request.Oncall.Overrides = &[]proto.OncallOverride{
+		proto.OncallOverride{ID: params.ByName(`overrideID`)},
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -70,6 +70,7 @@ func (x *Rest) PredicateAdd(w http.ResponseWriter, r *http.Request,
 		return
 	}
 	request.Predicate.Symbol = cReq.Predicate.Symbol
+	request.Predicate.Escalation = cReq.Predicate.Escalation
 
 	if !x.isAuthorized(&request) {
 		x.replyForbidden(&w, &request)
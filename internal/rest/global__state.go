@@ -9,6 +9,7 @@
 package rest // import "github.com/mjolnir42/soma/internal/rest"
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -129,4 +130,57 @@ func (x *Rest) StateRename(w http.ResponseWriter, r *http.Request,
 	x.send(&w, &result)
 }
 
+// StateUpdate function
+func (x *Rest) StateUpdate(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionState
+	request.Action = msg.ActionUpdate
+
+	cReq := proto.NewStateRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	switch cReq.State.CheckHandling {
+	case proto.StateChecksActive, proto.StateChecksSuspend,
+		proto.StateChecksDeprovision:
+		if len(cReq.State.Views) != 0 {
+			x.replyBadRequest(&w, &request, fmt.Errorf(
+				"Views are only valid for check handling %s",
+				proto.StateChecksRestrict))
+			return
+		}
+	case proto.StateChecksRestrict:
+		if len(cReq.State.Views) == 0 {
+			x.replyBadRequest(&w, &request, fmt.Errorf(
+				"Check handling %s requires at least one view",
+				proto.StateChecksRestrict))
+			return
+		}
+	default:
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			"Invalid check handling: %s", cReq.State.CheckHandling))
+		return
+	}
+
+	request.State = cReq.State.Clone()
+	request.State.Name = params.ByName(`state`)
+	if request.State.Views == nil {
+		request.State.Views = []string{}
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
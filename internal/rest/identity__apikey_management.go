@@ -0,0 +1,95 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// APIKeyMgmtAdd function
+func (x *Rest) APIKeyMgmtAdd(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionAPIKeyMgmt
+	request.Action = msg.ActionAdd
+
+	cReq := proto.NewAPIKeyRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	if cReq.APIKey.UserID != `` &&
+		cReq.APIKey.UserID != params.ByName(`userID`) {
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			`Mismatched userID in URL and request body`))
+		return
+	}
+	request.APIKey.UserID = params.ByName(`userID`)
+	request.APIKey.Name = cReq.APIKey.Name
+	request.APIKey.ExpiresAt = cReq.APIKey.ExpiresAt
+	request.APIKey.Permissions = cReq.APIKey.Permissions
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// APIKeyMgmtList function
+func (x *Rest) APIKeyMgmtList(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionAPIKeyMgmt
+	request.Action = msg.ActionList
+	request.APIKey.UserID = params.ByName(`userID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// APIKeyMgmtRevoke function
+func (x *Rest) APIKeyMgmtRevoke(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionAPIKeyMgmt
+	request.Action = msg.ActionRevoke
+	request.APIKey.UserID = params.ByName(`userID`)
+	request.APIKey.ID = params.ByName(`keyID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
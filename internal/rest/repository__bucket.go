@@ -108,8 +108,10 @@ func (x *Rest) BucketTree(w http.ResponseWriter, r *http.Request,
 	request.Section = msg.SectionBucket
 	request.Action = msg.ActionTree
 	request.Tree = proto.Tree{
-		ID:   params.ByName(`bucketID`),
-		Type: msg.EntityBucket,
+		ID:     params.ByName(`bucketID`),
+		Type:   msg.EntityBucket,
+		At:     r.URL.Query().Get(`at`),
+		Format: r.URL.Query().Get(`format`),
 	}
 
 	if !x.isAuthorized(&request) {
@@ -122,6 +124,27 @@ func (x *Rest) BucketTree(w http.ResponseWriter, r *http.Request,
 	x.send(&w, &result)
 }
 
+// BucketPropertyHistory function
+func (x *Rest) BucketPropertyHistory(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionBucket
+	request.Action = msg.ActionPropertyHistory
+	request.Repository.ID = params.ByName(`repositoryID`)
+	request.Bucket.ID = params.ByName(`bucketID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // BucketCreate function
 func (x *Rest) BucketCreate(w http.ResponseWriter, r *http.Request,
 	params httprouter.Params) {
@@ -271,6 +294,13 @@ func (x *Rest) BucketPropertyCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
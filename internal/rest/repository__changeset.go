@@ -0,0 +1,315 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// ChangeSetApply function
+func (x *Rest) ChangeSetApply(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionChangeSet
+	request.Action = msg.ActionApply
+	request.Repository.ID = params.ByName(`repositoryID`)
+
+	cReq := proto.NewChangeSetRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	switch {
+	case cReq.ChangeSet.RepositoryID != `` &&
+		cReq.ChangeSet.RepositoryID != request.Repository.ID:
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			"Mismatched repository ids: %s, %s",
+			request.Repository.ID,
+			cReq.ChangeSet.RepositoryID))
+		return
+	case len(cReq.ChangeSet.Steps) == 0:
+		x.replyBadRequest(&w, &request,
+			fmt.Errorf(`Empty change set`))
+		return
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	// every step requires the permission for its individual action
+	pending := map[string]bool{}
+	for i := range cReq.ChangeSet.Steps {
+		step, err := changeSetStep(&request, &cReq.ChangeSet.Steps[i])
+		if err != nil {
+			x.replyBadRequest(&w, &request, fmt.Errorf(
+				"Step %d (%s::%s): %s", i+1,
+				cReq.ChangeSet.Steps[i].Section,
+				cReq.ChangeSet.Steps[i].Action,
+				err))
+			return
+		}
+		step.Flag.PendingBucket = pending[step.Bucket.ID]
+
+		switch {
+		case step.Section == msg.SectionNodeConfig &&
+			step.Action != msg.ActionPropertyCreate:
+			// check if the user is allowed to (un)assign nodes from
+			// this team
+			nodeStep := step
+			nodeStep.Section = msg.SectionNode
+			if !x.isAuthorized(&nodeStep) {
+				x.replyForbidden(&w, &request)
+				return
+			}
+		case step.Section == msg.SectionBucket &&
+			step.Action == msg.ActionCreate:
+			if step.Bucket.ID != `` {
+				pending[step.Bucket.ID] = true
+			}
+		}
+
+		if !x.isAuthorized(&step) {
+			x.replyForbidden(&w, &request)
+			return
+		}
+		request.ChangeSet = append(request.ChangeSet, step)
+	}
+
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// changeSetStep converts a change set step into the request for its
+// individual action
+func changeSetStep(q *msg.Request, s *proto.ChangeSetStep) (msg.Request, error) {
+	step := msg.Request{
+		ID:         q.ID,
+		Section:    s.Section,
+		Action:     s.Action,
+		RemoteAddr: q.RemoteAddr,
+		AuthUser:   q.AuthUser,
+		AuthAPIKey: q.AuthAPIKey,
+		RequestURI: q.RequestURI,
+	}
+	step.Repository.ID = q.Repository.ID
+	c := s.Request
+
+	switch s.Section {
+	case msg.SectionRepositoryConfig:
+		switch s.Action {
+		case msg.ActionPropertyCreate:
+			if c.Repository == nil {
+				return step, fmt.Errorf(`Missing repository`)
+			}
+			if c.Repository.ID != q.Repository.ID {
+				return step, fmt.Errorf("Mismatched repository ids: %s, %s",
+					q.Repository.ID, c.Repository.ID)
+			}
+			if err := changeSetProperty(c.Repository.Properties); err != nil {
+				return step, err
+			}
+			step.Repository = c.Repository.Clone()
+			step.TargetEntity = msg.EntityRepository
+			step.Property.Type = (*c.Repository.Properties)[0].Type
+			return step, nil
+		}
+
+	case msg.SectionBucket:
+		if c.Bucket == nil {
+			return step, fmt.Errorf(`Missing bucket`)
+		}
+		switch s.Action {
+		case msg.ActionCreate:
+			if c.Bucket.Name == `` || c.Bucket.Environment == `` ||
+				c.Bucket.TeamID == `` {
+				return step, fmt.Errorf(`Incomplete Bucket.Create request`)
+			}
+			nameLen := utf8.RuneCountInString(c.Bucket.Name)
+			if nameLen < 4 || nameLen > 512 {
+				return step, fmt.Errorf(
+					`Illegal bucket name length (4 < x <= 512)`)
+			}
+			if c.Bucket.ID != `` {
+				if err := checkStringIsUUID(c.Bucket.ID); err != nil {
+					return step, err
+				}
+			}
+			step.Bucket = c.Bucket.Clone()
+			step.Bucket.RepositoryID = q.Repository.ID
+			return step, nil
+		case msg.ActionPropertyCreate:
+			if err := changeSetProperty(c.Bucket.Properties); err != nil {
+				return step, err
+			}
+			step.TargetEntity = msg.EntityBucket
+			step.Bucket = c.Bucket.Clone()
+			step.Bucket.RepositoryID = q.Repository.ID
+			step.Property.Type = (*c.Bucket.Properties)[0].Type
+			return step, nil
+		}
+
+	case msg.SectionGroup:
+		if c.Group == nil {
+			return step, fmt.Errorf(`Missing group`)
+		}
+		if c.Group.BucketID == `` {
+			return step, fmt.Errorf(`Missing bucket id`)
+		}
+		step.Bucket.ID = c.Group.BucketID
+		step.Group = c.Group.Clone()
+		step.Group.RepositoryID = q.Repository.ID
+
+		switch s.Action {
+		case msg.ActionCreate:
+			nameLen := utf8.RuneCountInString(c.Group.Name)
+			if nameLen < 2 || nameLen > 256 {
+				return step, fmt.Errorf(
+					`Illegal group name length (2 <= x <= 256)`)
+			}
+			if c.Group.ID != `` {
+				if err := checkStringIsUUID(c.Group.ID); err != nil {
+					return step, err
+				}
+			}
+			return step, nil
+		case msg.ActionDestroy:
+			return step, nil
+		case msg.ActionMemberAssign, msg.ActionMemberUnassign:
+			switch {
+			case c.Group.MemberGroups != nil &&
+				len(*c.Group.MemberGroups) == 1 &&
+				c.Group.MemberClusters == nil &&
+				c.Group.MemberNodes == nil:
+				step.TargetEntity = msg.EntityGroup
+			case c.Group.MemberClusters != nil &&
+				len(*c.Group.MemberClusters) == 1 &&
+				c.Group.MemberGroups == nil &&
+				c.Group.MemberNodes == nil:
+				step.TargetEntity = msg.EntityCluster
+			case c.Group.MemberNodes != nil &&
+				len(*c.Group.MemberNodes) == 1 &&
+				c.Group.MemberGroups == nil &&
+				c.Group.MemberClusters == nil:
+				step.TargetEntity = msg.EntityNode
+			default:
+				return step, fmt.Errorf(`Expected exactly one member`)
+			}
+			return step, nil
+		case msg.ActionPropertyCreate:
+			if err := changeSetProperty(c.Group.Properties); err != nil {
+				return step, err
+			}
+			step.TargetEntity = msg.EntityGroup
+			step.Property.Type = (*c.Group.Properties)[0].Type
+			return step, nil
+		}
+
+	case msg.SectionCluster:
+		if c.Cluster == nil {
+			return step, fmt.Errorf(`Missing cluster`)
+		}
+		if c.Cluster.BucketID == `` {
+			return step, fmt.Errorf(`Missing bucket id`)
+		}
+		step.Bucket.ID = c.Cluster.BucketID
+		step.Cluster = c.Cluster.Clone()
+		step.Cluster.RepositoryID = q.Repository.ID
+
+		switch s.Action {
+		case msg.ActionCreate:
+			nameLen := utf8.RuneCountInString(c.Cluster.Name)
+			if nameLen < 2 || nameLen > 256 {
+				return step, fmt.Errorf(
+					`Illegal cluster name length (2 <= x <= 256)`)
+			}
+			if c.Cluster.ID != `` {
+				if err := checkStringIsUUID(c.Cluster.ID); err != nil {
+					return step, err
+				}
+			}
+			return step, nil
+		case msg.ActionDestroy:
+			return step, nil
+		case msg.ActionMemberAssign, msg.ActionMemberUnassign:
+			if c.Cluster.Members == nil || len(*c.Cluster.Members) != 1 {
+				return step, fmt.Errorf(`Expected exactly one member`)
+			}
+			step.TargetEntity = msg.EntityNode
+			return step, nil
+		case msg.ActionPropertyCreate:
+			if err := changeSetProperty(c.Cluster.Properties); err != nil {
+				return step, err
+			}
+			step.TargetEntity = msg.EntityCluster
+			step.Property.Type = (*c.Cluster.Properties)[0].Type
+			return step, nil
+		}
+
+	case msg.SectionNodeConfig:
+		if c.Node == nil || c.Node.Config == nil {
+			return step, fmt.Errorf(`Missing node configuration`)
+		}
+		if c.Node.ID == `` || c.Node.Config.BucketID == `` {
+			return step, fmt.Errorf(`Missing node or bucket id`)
+		}
+		step.Bucket.ID = c.Node.Config.BucketID
+		step.Node.ID = c.Node.ID
+		step.Node.Config = &proto.NodeConfig{
+			RepositoryID: q.Repository.ID,
+			BucketID:     c.Node.Config.BucketID,
+		}
+
+		switch s.Action {
+		case msg.ActionAssign, msg.ActionUnassign:
+			return step, nil
+		case msg.ActionPropertyCreate:
+			if err := changeSetProperty(c.Node.Properties); err != nil {
+				return step, err
+			}
+			step.TargetEntity = msg.EntityNode
+			step.Node = c.Node.Clone()
+			step.Node.Config.RepositoryID = q.Repository.ID
+			step.Property.Type = (*c.Node.Properties)[0].Type
+			return step, nil
+		}
+	}
+	return step, fmt.Errorf(`Action is not supported in change sets`)
+}
+
+// changeSetProperty validates the property of a property-create step
+func changeSetProperty(props *[]proto.Property) error {
+	switch {
+	case props == nil || len(*props) != 1:
+		return fmt.Errorf(`Expected property count 1`)
+	case (*props)[0].Type == `service` &&
+		((*props)[0].Service == nil || (*props)[0].Service.Name == ``):
+		return fmt.Errorf(`Empty service name is invalid`)
+	}
+	return nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
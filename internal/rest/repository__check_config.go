@@ -103,6 +103,88 @@ func (x *Rest) CheckConfigShow(w http.ResponseWriter, r *http.Request,
 	x.send(&w, &result)
 }
 
+// CheckConfigEvaluate function
+func (x *Rest) CheckConfigEvaluate(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionCheckConfig
+	request.Action = msg.ActionEvaluate
+	request.Repository.ID = params.ByName(`repositoryID`)
+	request.CheckConfig = proto.CheckConfig{
+		ID:           params.ByName(`checkID`),
+		RepositoryID: params.ByName(`repositoryID`),
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// CheckConfigExport function
+func (x *Rest) CheckConfigExport(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionCheckConfig
+	request.Action = msg.ActionExport
+
+	cReq := proto.NewCheckConfigFilter()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.CheckConfig = proto.CheckConfig{
+		RepositoryID: params.ByName(`repositoryID`),
+	}
+	request.Search.CheckConfig.Names = cReq.Filter.CheckConfig.Names
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// CheckConfigImport function
+func (x *Rest) CheckConfigImport(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionCheckConfig
+	request.Action = msg.ActionImport
+
+	cReq := proto.NewBundleRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.CheckConfig = proto.CheckConfig{
+		RepositoryID: params.ByName(`repositoryID`),
+	}
+	request.Bundle = *cReq.Bundle
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // CheckConfigCreate function
 func (x *Rest) CheckConfigCreate(w http.ResponseWriter, r *http.Request,
 	params httprouter.Params) {
@@ -132,6 +214,13 @@ func (x *Rest) CheckConfigCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -168,4 +257,56 @@ func (x *Rest) CheckConfigDestroy(w http.ResponseWriter, r *http.Request,
 	x.send(&w, &result)
 }
 
+// CheckConfigUpdate function
+func (x *Rest) CheckConfigUpdate(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionMonitoring
+	request.Action = msg.ActionUse
+
+	cReq := proto.NewCheckConfigRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	// invalid: enable+disable at the same time
+	if cReq.Flags.Enable && cReq.Flags.Disable {
+		x.replyBadRequest(&w, &request, fmt.Errorf(`Ambiguous instruction`))
+		return
+	}
+	request.Flag.Enable = cReq.Flags.Enable
+	request.Flag.Disable = cReq.Flags.Disable
+	request.Update.CheckConfig = cReq.CheckConfig.Clone()
+	request.CheckConfig = proto.CheckConfig{
+		ID:           params.ByName(`checkID`),
+		RepositoryID: params.ByName(`repositoryID`),
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	request.Section = msg.SectionCheckConfig
+	request.Action = msg.ActionUpdate
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
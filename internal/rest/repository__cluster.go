@@ -116,6 +116,30 @@ func (x *Rest) ClusterTree(w http.ResponseWriter, r *http.Request,
 	request.Cluster.ID = params.ByName(`clusterID`)
 	request.Tree.ID = params.ByName(`clusterID`)
 	request.Tree.Type = msg.EntityCluster
+	request.Tree.At = r.URL.Query().Get(`at`)
+	request.Tree.Format = r.URL.Query().Get(`format`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// ClusterPropertyHistory function
+func (x *Rest) ClusterPropertyHistory(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionCluster
+	request.Action = msg.ActionPropertyHistory
+	request.Repository.ID = params.ByName(`repositoryID`)
+	request.Bucket.ID = params.ByName(`bucketID`)
+	request.Cluster.ID = params.ByName(`clusterID`)
 
 	if !x.isAuthorized(&request) {
 		x.replyForbidden(&w, &request)
@@ -233,6 +257,13 @@ func (x *Rest) ClusterMemberAssign(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -318,6 +349,13 @@ func (x *Rest) ClusterPropertyCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
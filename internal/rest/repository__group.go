@@ -120,6 +120,30 @@ func (x *Rest) GroupTree(w http.ResponseWriter, r *http.Request,
 	request.Group.ID = params.ByName(`groupID`)
 	request.Tree.ID = params.ByName(`groupID`)
 	request.Tree.Type = msg.EntityGroup
+	request.Tree.At = r.URL.Query().Get(`at`)
+	request.Tree.Format = r.URL.Query().Get(`format`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// GroupPropertyHistory function
+func (x *Rest) GroupPropertyHistory(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionGroup
+	request.Action = msg.ActionPropertyHistory
+	request.Repository.ID = params.ByName(`repositoryID`)
+	request.Bucket.ID = params.ByName(`bucketID`)
+	request.Group.ID = params.ByName(`groupID`)
 
 	if !x.isAuthorized(&request) {
 		x.replyForbidden(&w, &request)
@@ -262,6 +286,13 @@ func (x *Rest) GroupMemberAssign(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -351,6 +382,13 @@ func (x *Rest) GroupPropertyCreate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -53,6 +53,13 @@ func (x *Rest) NodeConfigAssign(w http.ResponseWriter,
 		return
 	}
 
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -94,6 +101,126 @@ func (x *Rest) NodeConfigUnassign(w http.ResponseWriter,
 	x.send(&w, &result)
 }
 
+// NodeConfigMove function
+func (x *Rest) NodeConfigMove(w http.ResponseWriter,
+	r *http.Request, params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionNodeConfig
+	request.Action = msg.ActionMove
+
+	cReq := proto.NewNodeRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	switch {
+	case params.ByName(`nodeID`) != cReq.Node.ID:
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			"Mismatched node ids: %s, %s",
+			params.ByName(`nodeID`),
+			cReq.Node.ID))
+		return
+	case cReq.Node.Config == nil || cReq.Node.Config.BucketID == ``:
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			"Missing target bucket for node move"))
+		return
+	case cReq.Group != nil && cReq.Cluster != nil:
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			"Node can not be moved into a group and a cluster"))
+		return
+	}
+
+	request.Repository.ID = params.ByName(`repositoryID`)
+	request.Bucket.ID = params.ByName(`bucketID`)
+	request.Node.ID = params.ByName(`nodeID`)
+	request.Node.Config = &proto.NodeConfig{
+		RepositoryID: params.ByName(`repositoryID`),
+		BucketID:     params.ByName(`bucketID`),
+	}
+	request.Update.Node.ID = params.ByName(`nodeID`)
+	request.Update.Node.Config = &proto.NodeConfig{
+		RepositoryID: params.ByName(`repositoryID`),
+		BucketID:     cReq.Node.Config.BucketID,
+	}
+	request.TargetEntity = msg.EntityBucket
+	switch {
+	case cReq.Group != nil:
+		request.TargetEntity = msg.EntityGroup
+		request.Group.ID = cReq.Group.ID
+	case cReq.Cluster != nil:
+		request.TargetEntity = msg.EntityCluster
+		request.Cluster.ID = cReq.Cluster.ID
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// NodeConfigLifecycle function
+func (x *Rest) NodeConfigLifecycle(w http.ResponseWriter,
+	r *http.Request, params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionNodeConfig
+	request.Action = msg.ActionLifecycle
+
+	cReq := proto.NewNodeRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	if params.ByName(`nodeID`) != cReq.Node.ID {
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			"Mismatched node ids: %s, %s",
+			params.ByName(`nodeID`),
+			cReq.Node.ID))
+		return
+	}
+
+	request.Repository.ID = params.ByName(`repositoryID`)
+	request.Bucket.ID = params.ByName(`bucketID`)
+	request.Node.ID = params.ByName(`nodeID`)
+	request.Node.Lifecycle = cReq.Node.Lifecycle
+	request.Node.Config = &proto.NodeConfig{
+		RepositoryID: params.ByName(`repositoryID`),
+		BucketID:     params.ByName(`bucketID`),
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // NodeConfigPropertyCreate function
 func (x *Rest) NodeConfigPropertyCreate(w http.ResponseWriter,
 	r *http.Request, params httprouter.Params) {
@@ -140,6 +267,13 @@ func (x *Rest) NodeConfigPropertyCreate(w http.ResponseWriter,
 		return
 	}
 
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
@@ -222,10 +356,34 @@ func (x *Rest) NodeConfigTree(w http.ResponseWriter, r *http.Request,
 	request.Section = msg.SectionNodeConfig
 	request.Action = msg.ActionTree
 	request.Tree = proto.Tree{
-		ID:   params.ByName(`nodeID`),
-		Type: msg.EntityNode,
+		ID:     params.ByName(`nodeID`),
+		Type:   msg.EntityNode,
+		At:     r.URL.Query().Get(`at`),
+		Format: r.URL.Query().Get(`format`),
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
 	}
 
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// NodeConfigPropertyHistory function
+func (x *Rest) NodeConfigPropertyHistory(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionNodeConfig
+	request.Action = msg.ActionPropertyHistory
+	request.Repository.ID = params.ByName(`repositoryID`)
+	request.Bucket.ID = params.ByName(`bucketID`)
+	request.Node.ID = params.ByName(`nodeID`)
+
 	if !x.isAuthorized(&request) {
 		x.replyForbidden(&w, &request)
 		return
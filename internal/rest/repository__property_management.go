@@ -47,6 +47,62 @@ func (x *Rest) PropertyMgmtCustomAdd(w http.ResponseWriter, r *http.Request,
 		x.replyBadRequest(&w, &request, fmt.Errorf(`Invalid empty custom property name`))
 		return
 	}
+	if cReq.Property.Custom.Schema != nil {
+		if err := cReq.Property.Custom.Schema.Verify(); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
+	request.Property = cReq.Property.Clone()
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// PropertyMgmtCustomUpdate function
+func (x *Rest) PropertyMgmtCustomUpdate(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionPropertyCustom
+	request.Action = msg.ActionUpdate
+
+	cReq := proto.NewPropertyRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	switch {
+	case params.ByName(`propertyType`) != msg.PropertyCustom:
+		x.replyBadRequest(&w, &request, fmt.Errorf("Invalid property type: %s", params.ByName(`propertyType`)))
+		return
+	case cReq.Property.Type != msg.PropertyCustom || cReq.Property.Custom == nil:
+		x.replyBadRequest(&w, &request, fmt.Errorf("Invalid property type: %s", cReq.Property.Type))
+		return
+	case cReq.Property.Custom.ID != params.ByName(`propertyID`):
+		x.replyBadRequest(&w, &request, fmt.Errorf("Mismatching property IDs: %s vs %s",
+			cReq.Property.Custom.ID, params.ByName(`propertyID`)))
+		return
+	case cReq.Property.Custom.RepositoryID != params.ByName(`repositoryID`):
+		x.replyBadRequest(&w, &request, fmt.Errorf("Mismatching repository IDs: %s vs %s",
+			cReq.Property.Custom.RepositoryID, params.ByName(`repositoryID`)))
+		return
+	}
+	// a missing schema removes the schema of the property
+	if cReq.Property.Custom.Schema != nil {
+		if err := cReq.Property.Custom.Schema.Verify(); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
 	request.Property = cReq.Property.Clone()
 
 	if !x.isAuthorized(&request) {
@@ -108,8 +108,10 @@ func (x *Rest) RepositoryConfigTree(w http.ResponseWriter, r *http.Request,
 	request.Section = msg.SectionRepositoryConfig
 	request.Action = msg.ActionTree
 	request.Tree = proto.Tree{
-		ID:   params.ByName(`repositoryID`),
-		Type: msg.EntityRepository,
+		ID:     params.ByName(`repositoryID`),
+		Type:   msg.EntityRepository,
+		At:     r.URL.Query().Get(`at`),
+		Format: r.URL.Query().Get(`format`),
 	}
 
 	if !x.isAuthorized(&request) {
@@ -122,6 +124,46 @@ func (x *Rest) RepositoryConfigTree(w http.ResponseWriter, r *http.Request,
 	x.send(&w, &result)
 }
 
+// RepositoryConfigPropertyHistory function
+func (x *Rest) RepositoryConfigPropertyHistory(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionRepositoryConfig
+	request.Action = msg.ActionPropertyHistory
+	request.Repository.ID = params.ByName(`repositoryID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
+// RepositoryConfigFsck function
+func (x *Rest) RepositoryConfigFsck(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionRepositoryConfig
+	request.Action = msg.ActionFsck
+	request.Repository.ID = params.ByName(`repositoryID`)
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // RepositoryConfigPropertyCreate function
 func (x *Rest) RepositoryConfigPropertyCreate(w http.ResponseWriter, r *http.Request,
 	params httprouter.Params) {
@@ -163,6 +205,13 @@ func (x *Rest) RepositoryConfigPropertyCreate(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// exceeding the check instance budgets requires an additional
+	// permission
+	if !x.overrideBudget(&request, cReq.Flags) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
 	x.handlerMap.MustLookup(&request).Intake() <- request
 	result := <-request.Reply
 	x.send(&w, &result)
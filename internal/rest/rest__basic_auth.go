@@ -110,11 +110,21 @@ func (x *Rest) basicAuth(h httprouter.Handle) httprouter.Handle {
 							Key:   `AuthenticatedUser`,
 							Value: string(pair[0]),
 						})
-						// record the used token for supervisor:token/invalidate
-						ps = append(ps, httprouter.Param{
-							Key:   `AuthenticatedToken`,
-							Value: string(pair[1]),
-						})
+						switch {
+						case result.Super.APIKeyID != ``:
+							// record the used API key, requests are
+							// restricted to its permissions
+							ps = append(ps, httprouter.Param{
+								Key:   `AuthenticatedAPIKey`,
+								Value: result.Super.APIKeyID,
+							})
+						default:
+							// record the used token for supervisor:token/invalidate
+							ps = append(ps, httprouter.Param{
+								Key:   `AuthenticatedToken`,
+								Value: string(pair[1]),
+							})
+						}
 
 						// log successful basic auth requests only at debug level
 						// since they will also be logged by rest.send()
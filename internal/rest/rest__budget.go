@@ -0,0 +1,26 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package rest // import "github.com/mjolnir42/soma/internal/rest"
+
+import (
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// overrideBudget copies the check instance budget override of the
+// client request into request. It returns false if the user is not
+// permitted to exceed the check instance budgets.
+func (x *Rest) overrideBudget(request *msg.Request, flags *proto.Flags) bool {
+	if flags == nil || !flags.OverrideBudget {
+		return true
+	}
+	request.Flag.OverrideBudget = true
+	return x.isAuthorized(msg.BudgetOverrideFromRequest(request))
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
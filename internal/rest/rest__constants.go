@@ -10,6 +10,8 @@ package rest // import "github.com/mjolnir42/soma/internal/rest"
 const (
 	rtRepository                 = `/repository/`
 	rtRepositoryID               = `/repository/:repositoryID`
+	rtRepositoryChangeSet        = `/repository/:repositoryID/changeset`
+	rtRepositoryFsck             = `/repository/:repositoryID/fsck`
 	rtRepositoryInstance         = `/repository/:repositoryID/instance/`
 	rtRepositoryInstanceID       = `/repository/:repositoryID/instance/:instanceID`
 	rtRepositoryInstanceVersions = `/repository/:repositoryID/instance/:instanceID/versions`
@@ -17,6 +19,7 @@ const (
 	rtRepositoryMemberID         = `/repository/:repositoryID/member/:memberType/:memberID`
 	rtRepositoryProperty         = `/repository/:repositoryID/property/`
 	rtRepositoryPropertyID       = `/repository/:repositoryID/property/:propertyType/:sourceID`
+	rtRepositoryPropertyHistory  = `/repository/:repositoryID/property/history`
 	rtRepositoryPropertyMgmt     = `/repository/:repositoryID/property-mgmt/:propertyType/`
 	rtRepositoryPropertyMgmtID   = `/repository/:repositoryID/property-mgmt/:propertyType/:propertyID`
 	rtRepositoryTree             = `/repository/:repositoryID/tree`
@@ -30,6 +33,7 @@ const (
 	rtBucketMemberID             = `/repository/:repositoryID/bucket/:bucketID/member/:memberType/:memberID`
 	rtBucketProperty             = `/repository/:repositoryID/bucket/:bucketID/property/`
 	rtBucketPropertyID           = `/repository/:repositoryID/bucket/:bucketID/property/:propertyType/:sourceID`
+	rtBucketPropertyHistory      = `/repository/:repositoryID/bucket/:bucketID/property/history`
 	rtBucketTree                 = `/repository/:repositoryID/bucket/:bucketID/tree`
 	rtCluster                    = `/repository/:repositoryID/bucket/:bucketID/cluster/`
 	rtClusterID                  = `/repository/:repositoryID/bucket/:bucketID/cluster/:clusterID`
@@ -40,6 +44,7 @@ const (
 	rtClusterMemberID            = `/repository/:repositoryID/bucket/:bucketID/cluster/:clusterID/member/:memberType/:memberID`
 	rtClusterProperty            = `/repository/:repositoryID/bucket/:bucketID/cluster/:clusterID/property/`
 	rtClusterPropertyID          = `/repository/:repositoryID/bucket/:bucketID/cluster/:clusterID/property/:propertyType/:sourceID`
+	rtClusterPropertyHistory     = `/repository/:repositoryID/bucket/:bucketID/cluster/:clusterID/property/history`
 	rtClusterTree                = `/repository/:repositoryID/bucket/:bucketID/cluster/:clusterID/tree`
 	rtGroup                      = `/repository/:repositoryID/bucket/:bucketID/group/`
 	rtGroupID                    = `/repository/:repositoryID/bucket/:bucketID/group/:groupID`
@@ -51,16 +56,20 @@ const (
 	rtGroupMemberID              = `/repository/:repositoryID/bucket/:bucketID/group/:groupID/member/:memberType/:memberID`
 	rtGroupProperty              = `/repository/:repositoryID/bucket/:bucketID/group/:groupID/property/`
 	rtGroupPropertyID            = `/repository/:repositoryID/bucket/:bucketID/group/:groupID/property/:propertyType/:sourceID`
+	rtGroupPropertyHistory       = `/repository/:repositoryID/bucket/:bucketID/group/:groupID/property/history`
 	rtGroupTree                  = `/repository/:repositoryID/bucket/:bucketID/group/:groupID/tree`
 	rtNode                       = `/node/`
 	rtNodeID                     = `/node/:nodeID`
 	rtNodeConfig                 = `/node/:nodeID/config`
 	rtNodeUnassign               = `/repository/:repositoryID/bucket/:bucketID/node/:nodeID/config`
+	rtNodeMove                   = `/repository/:repositoryID/bucket/:bucketID/node/:nodeID/config/move`
+	rtNodeLifecycle              = `/repository/:repositoryID/bucket/:bucketID/node/:nodeID/config/lifecycle`
 	rtNodeInstance               = `/repository/:repositoryID/bucket/:bucketID/node/:nodeID/instance/`
 	rtNodeInstanceID             = `/repository/:repositoryID/bucket/:bucketID/node/:nodeID/instance/:instanceID`
 	rtNodeInstanceVersions       = `/repository/:repositoryID/bucket/:bucketID/node/:nodeID/instance/:instanceID/versions`
 	rtNodeProperty               = `/repository/:repositoryID/bucket/:bucketID/node/:nodeID/property/`
 	rtNodePropertyID             = `/repository/:repositoryID/bucket/:bucketID/node/:nodeID/property/:propertyType/:sourceID`
+	rtNodePropertyHistory        = `/repository/:repositoryID/bucket/:bucketID/node/:nodeID/property/history`
 	rtNodeTree                   = `/repository/:repositoryID/bucket/:bucketID/node/:nodeID/tree`
 	rtPermission                 = `/category/:category/permission/`
 	rtPermissionID               = `/category/:category/permission/:permissionID`
@@ -71,6 +80,7 @@ const (
 	rtTeamMember                 = `/team/:teamID/member/`
 	rtTeamRepositoryID           = `/team/:teamID/repository/:repositoryID`
 	rtTeamRepositoryIDAudit      = `/team/:teamID/repository/:repositoryID/audit`
+	rtTeamRepositoryIDClone      = `/team/:teamID/repository/:repositoryID/clone`
 	rtTeamRepositoryIDName       = `/team/:teamID/repository/:repositoryID/name`
 	rtTeamRepositoryIDOwner      = `/team/:teamID/repository/:repositoryID/owner`
 	rtTeamPropertyMgmt           = `/team/:teamID/property-mgmt/:propertyType/`
@@ -101,10 +111,15 @@ const (
 	rtCompatDeploymentIDAction   = `/deployments/id/:deploymentID/:action`
 	rtOncallMember               = `/oncall/:oncallID/member/`
 	rtOncallMemberID             = `/oncall/:oncallID/member/:userID`
+	rtOncallCurrent              = `/oncall/:oncallID/current`
+	rtOncallRotation             = `/oncall/:oncallID/rotation`
+	rtOncallOverride             = `/oncall/:oncallID/override/`
+	rtOncallOverrideID           = `/oncall/:oncallID/override/:overrideID`
 	rtJob                        = `/job/`
 	rtJobEntry                   = `/job/byID/`
 	rtJobEntryID                 = `/job/byID/:jobID`
 	rtJobEntryWaitID             = `/job/byID/:jobID/_processed`
+	rtJobWait                    = `/job/wait`
 	rtJobTypeMgmt                = `/job/type-mgmt/`
 	rtJobTypeMgmtID              = `/job/type-mgmt/:typeID`
 	rtJobStatusMgmt              = `/job/status-mgmt/`
@@ -28,6 +28,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 	router.GET(`/category/:category/section/`, x.Authenticated(x.SectionList))
 	router.GET(`/category/:category`, x.Authenticated(x.CategoryShow))
 	router.GET(`/category/`, x.Authenticated(x.CategoryList))
+	router.GET(`/checkconfig/:repositoryID/:checkID/evaluation`, x.Authenticated(x.CheckConfigEvaluate))
 	router.GET(`/checkconfig/:repositoryID/:checkID`, x.Authenticated(x.CheckConfigShow))
 	router.GET(`/checkconfig/:repositoryID/`, x.Authenticated(x.CheckConfigList))
 	router.GET(`/datacenter/:datacenter`, x.Authenticated(x.DatacenterShow))
@@ -70,6 +71,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 	router.GET(`/unit/`, x.Authenticated(x.UnitList))
 	router.GET(`/user/:userID`, x.Authenticated(x.ScopeSelectUserShow))
 	router.GET(`/user/:userID/admin`, x.Authenticated(x.AdminMgmtShow))
+	router.GET(`/user/:userID/apikey/`, x.Authenticated(x.APIKeyMgmtList))
 	router.GET(`/user/`, x.Authenticated(x.UserMgmtList))
 	router.GET(`/validity/:property`, x.Authenticated(x.ValidityShow))
 	router.GET(`/validity/`, x.Authenticated(x.ValidityList))
@@ -83,6 +85,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 	router.GET(rtBucketInstanceID, x.Authenticated(x.InstanceShow))
 	router.GET(rtBucketInstanceVersions, x.Authenticated(x.InstanceVersions))
 	router.GET(rtBucketMember, x.Authenticated(x.BucketMemberList))
+	router.GET(rtBucketPropertyHistory, x.Authenticated(x.BucketPropertyHistory))
 	router.GET(rtBucketTree, x.Authenticated(x.BucketTree))
 	router.GET(rtCluster, x.Authenticated(x.ClusterList))
 	router.GET(rtClusterID, x.Authenticated(x.ClusterShow))
@@ -90,6 +93,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 	router.GET(rtClusterInstanceID, x.Authenticated(x.InstanceShow))
 	router.GET(rtClusterInstanceVersions, x.Authenticated(x.InstanceVersions))
 	router.GET(rtClusterMember, x.Authenticated(x.ClusterMemberList))
+	router.GET(rtClusterPropertyHistory, x.Authenticated(x.ClusterPropertyHistory))
 	router.GET(rtClusterTree, x.Authenticated(x.ClusterTree))
 	router.GET(rtGroup, x.Authenticated(x.GroupList))
 	router.GET(rtGroupID, x.Authenticated(x.GroupShow))
@@ -97,6 +101,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 	router.GET(rtGroupInstanceID, x.Authenticated(x.InstanceShow))
 	router.GET(rtGroupInstanceVersions, x.Authenticated(x.InstanceVersions))
 	router.GET(rtGroupMember, x.Authenticated(x.GroupMemberList))
+	router.GET(rtGroupPropertyHistory, x.Authenticated(x.GroupPropertyHistory))
 	router.GET(rtGroupTree, x.Authenticated(x.GroupTree))
 	router.GET(rtJob, x.Authenticated(x.ScopeSelectJobList))
 	router.GET(rtJobEntry, x.Authenticated(x.ScopeSelectJobList))
@@ -113,17 +118,21 @@ func (x *Rest) setupRouter() *httprouter.Router {
 	router.GET(rtNodeInstance, x.Authenticated(x.InstanceList))
 	router.GET(rtNodeInstanceID, x.Authenticated(x.InstanceShow))
 	router.GET(rtNodeInstanceVersions, x.Authenticated(x.InstanceVersions))
+	router.GET(rtNodePropertyHistory, x.Authenticated(x.NodeConfigPropertyHistory))
 	router.GET(rtNodeTree, x.Authenticated(x.NodeConfigTree))
 	router.GET(rtOncallMember, x.Authenticated(x.OncallMemberList))
+	router.GET(rtOncallCurrent, x.Authenticated(x.OncallCurrent))
 	router.GET(rtPermission, x.Authenticated(x.PermissionList))
 	router.GET(rtPermissionID, x.Authenticated(x.PermissionShow))
 	router.GET(rtPropertyMgmt, x.Authenticated(x.PropertyMgmtList))
 	router.GET(rtPropertyMgmtID, x.Authenticated(x.PropertyMgmtShow))
 	router.GET(rtRepository, x.Authenticated(x.RepositoryConfigList))
+	router.GET(rtRepositoryFsck, x.Authenticated(x.RepositoryConfigFsck))
 	router.GET(rtTeamRepositoryID, x.Authenticated(x.ScopeSelectRepositoryShow))
 	router.GET(rtRepositoryInstance, x.Authenticated(x.InstanceList))
 	router.GET(rtRepositoryInstanceID, x.Authenticated(x.InstanceShow))
 	router.GET(rtRepositoryInstanceVersions, x.Authenticated(x.InstanceVersions))
+	router.GET(rtRepositoryPropertyHistory, x.Authenticated(x.RepositoryConfigPropertyHistory))
 	router.GET(rtRepositoryPropertyMgmt, x.Authenticated(x.PropertyMgmtList))
 	router.GET(rtRepositoryPropertyMgmtID, x.Authenticated(x.PropertyMgmtShow))
 	router.GET(rtRepositoryTree, x.Authenticated(x.RepositoryConfigTree))
@@ -138,6 +147,8 @@ func (x *Rest) setupRouter() *httprouter.Router {
 	router.POST(`/search/action/`, x.Authenticated(x.ActionSearch))
 	router.POST(`/search/capability/`, x.Authenticated(x.CapabilitySearch))
 	router.POST(`/search/checkconfig/:repositoryID/`, x.Authenticated(x.CheckConfigSearch))
+	router.POST(`/export/checkconfig/:repositoryID/`, x.Authenticated(x.CheckConfigExport))
+	router.POST(`/import/checkconfig/:repositoryID/`, x.Authenticated(x.CheckConfigImport))
 	router.POST(`/search/level/`, x.Authenticated(x.LevelSearch))
 	router.POST(`/search/monitoringsystem/`, x.Authenticated(x.ScopeSelectMonitoringSearch))
 	router.POST(`/search/oncall/`, x.Authenticated(x.OncallSearch))
@@ -189,6 +200,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.DELETE(`/tokens/self/all`, x.Authenticated(x.SupervisorTokenInvalidateSelf))
 			router.DELETE(`/unit/:unit`, x.Authenticated(x.UnitRemove))
 			router.DELETE(`/user/:userID/admin/:adminID`, x.Authenticated(x.AdminMgmtRemove))
+			router.DELETE(`/user/:userID/apikey/:keyID`, x.Authenticated(x.APIKeyMgmtRevoke))
 			router.DELETE(`/user/:userID`, x.Authenticated(x.UserMgmtRemove))
 			router.DELETE(`/validity/:property`, x.Authenticated(x.ValidityRemove))
 			router.DELETE(`/view/:view`, x.Authenticated(x.ViewRemove))
@@ -209,6 +221,8 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.DELETE(rtNodePropertyID, x.Authenticated(x.NodeConfigPropertyDestroy))
 			router.DELETE(rtNodeUnassign, x.Authenticated(x.NodeConfigUnassign))
 			router.DELETE(rtOncallMemberID, x.Authenticated(x.OncallMemberUnassign))
+			router.DELETE(rtOncallOverrideID, x.Authenticated(x.OncallOverrideRemove))
+			router.DELETE(rtOncallRotation, x.Authenticated(x.OncallRotationClear))
 			router.DELETE(rtPermissionID, x.Authenticated(x.PermissionRemove))
 			router.DELETE(rtPropertyMgmtID, x.Authenticated(x.PropertyMgmtRemove))
 			router.DELETE(rtRepositoryPropertyID, x.Authenticated(x.RepositoryConfigPropertyDestroy))
@@ -216,6 +230,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.DELETE(rtRightID, x.Authenticated(x.RightRevoke))
 			router.DELETE(rtTeamPropertyMgmtID, x.Authenticated(x.PropertyMgmtServiceRemove))
 			router.DELETE(rtTeamRepositoryID, x.Authenticated(x.RepositoryDestroy))
+			router.GET(`/inventory/drift`, x.Authenticated(x.InventoryDrift))
 			router.GET(rtAliasDeploymentID, x.Unauthenticated(x.DeploymentShow))
 			router.GET(rtCompatDeploymentID, x.Unauthenticated(x.DeploymentShow))
 			router.GET(rtDeployment, x.Unauthenticated(x.DeploymentList))
@@ -225,7 +240,9 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.GET(rtJobEntryWaitID, x.Authenticated(x.ScopeSelectJobWait))
 			router.GET(rtTeamRepositoryIDAudit, x.Authenticated(x.RepositoryAudit))
 			router.PATCH(`/accounts/password/:kexID`, x.Unauthenticated(x.SupervisorPasswordChange))
+			router.PATCH(`/checkconfig/:repositoryID/:checkID`, x.Authenticated(x.CheckConfigUpdate))
 			router.PATCH(`/oncall/:oncallID`, x.Authenticated(x.OncallUpdate))
+			router.PATCH(`/state/:state`, x.Authenticated(x.StateUpdate))
 			router.PATCH(`/workflow/retry`, x.Authenticated(x.WorkflowRetry))
 			router.PATCH(`/workflow/set/:instanceconfigID`, x.Authenticated(x.WorkflowSet))
 			router.PATCH(rtAliasDeploymentIDAction, x.Unauthenticated(x.DeploymentUpdate))
@@ -234,6 +251,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.PATCH(rtDeploymentIDAction, x.Unauthenticated(x.DeploymentUpdate))
 			router.PATCH(rtOncallMember, x.Authenticated(x.OncallMemberAssign))
 			router.PATCH(rtPermissionID, x.Authenticated(x.PermissionEdit))
+			router.PATCH(rtRepositoryPropertyMgmtID, x.Authenticated(x.PropertyMgmtCustomUpdate))
 			router.PATCH(rtTeamRepositoryIDName, x.Authenticated(x.RepositoryRename))
 			router.PATCH(rtTeamRepositoryIDOwner, x.Authenticated(x.RepositoryRepossess))
 			router.POST(`/attribute/`, x.Authenticated(x.AttributeAdd))
@@ -245,6 +263,7 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.POST(`/datacenter/`, x.Authenticated(x.DatacenterAdd))
 			router.POST(`/entity/`, x.Authenticated(x.EntityAdd))
 			router.POST(`/environment/`, x.Authenticated(x.EnvironmentAdd))
+			router.POST(`/inventory/reconcile`, x.Authenticated(x.InventoryReconcile))
 			router.POST(`/kex/`, x.Unauthenticated(x.SupervisorKex))
 			router.POST(`/level/`, x.Authenticated(x.LevelAdd))
 			router.POST(`/metric/`, x.Authenticated(x.MetricAdd))
@@ -258,8 +277,10 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.POST(`/state/`, x.Authenticated(x.StateAdd))
 			router.POST(`/status/`, x.Authenticated(x.StatusAdd))
 			router.POST(`/system/`, x.Authenticated(x.SystemOperation))
+			router.POST(`/sync/directory/`, x.Authenticated(x.DirectorySync))
 			router.POST(`/team/`, x.Authenticated(x.TeamMgmtAdd))
 			router.POST(`/unit/`, x.Authenticated(x.UnitAdd))
+			router.POST(`/user/:userID/apikey/`, x.Authenticated(x.APIKeyMgmtAdd))
 			router.POST(`/user/`, x.Authenticated(x.UserMgmtAdd))
 			router.POST(`/validity/`, x.Authenticated(x.ValidityAdd))
 			router.POST(`/view/`, x.Authenticated(x.ViewAdd))
@@ -275,15 +296,20 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.POST(rtJobResultMgmt, x.Authenticated(x.JobResultMgmtAdd))
 			router.POST(rtJobStatusMgmt, x.Authenticated(x.JobStatusMgmtAdd))
 			router.POST(rtJobTypeMgmt, x.Authenticated(x.JobTypeMgmtAdd))
+			router.POST(rtJobWait, x.Authenticated(x.JobWaitList))
 			router.POST(rtNode, x.Authenticated(x.NodeMgmtAdd))
+			router.POST(rtNodeMove, x.Authenticated(x.NodeConfigMove))
 			router.POST(rtNodeProperty, x.Authenticated(x.NodeConfigPropertyCreate))
+			router.POST(rtOncallOverride, x.Authenticated(x.OncallOverrideAdd))
 			router.POST(rtPermission, x.Authenticated(x.PermissionAdd))
 			router.POST(rtPropertyMgmt, x.Authenticated(x.PropertyMgmtAdd))
 			router.POST(rtRepository, x.Authenticated(x.RepositoryMgmtCreate))
+			router.POST(rtRepositoryChangeSet, x.Authenticated(x.ChangeSetApply))
 			router.POST(rtRepositoryProperty, x.Authenticated(x.RepositoryConfigPropertyCreate))
 			router.POST(rtRepositoryPropertyMgmt, x.Authenticated(x.PropertyMgmtCustomAdd))
 			router.POST(rtRight, x.Authenticated(x.RightGrant))
 			router.POST(rtTeamPropertyMgmt, x.Authenticated(x.PropertyMgmtServiceAdd))
+			router.POST(rtTeamRepositoryIDClone, x.Authenticated(x.RepositoryClone))
 			router.PUT(`/accounts/activate/root/:kexID`, x.Unauthenticated(x.SupervisorActivateRoot))
 			router.PUT(`/accounts/activate/user/:kexID`, x.Unauthenticated(x.SupervisorActivateUser))
 			router.PUT(`/accounts/activate/admin/:kexID`, x.Unauthenticated(x.SupervisorActivateAdmin))
@@ -302,8 +328,10 @@ func (x *Rest) setupRouter() *httprouter.Router {
 			router.PUT(rtClusterPropertyID, x.Authenticated(x.ClusterPropertyUpdate))
 			router.PUT(rtGroupPropertyID, x.Authenticated(x.GroupPropertyUpdate))
 			router.PUT(rtNodeConfig, x.Authenticated(x.NodeConfigAssign))
+			router.PUT(rtNodeLifecycle, x.Authenticated(x.NodeConfigLifecycle))
 			router.PUT(rtNodeID, x.Authenticated(x.NodeMgmtUpdate))
 			router.PUT(rtNodePropertyID, x.Authenticated(x.NodeConfigPropertyUpdate))
+			router.PUT(rtOncallRotation, x.Authenticated(x.OncallRotationSet))
 			router.PUT(rtRepositoryPropertyID, x.Authenticated(x.RepositoryConfigPropertyUpdate))
 		}
 	}
@@ -24,10 +24,11 @@ import (
 // fail input validation and got processes by the application.
 func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 	var (
-		bjson  []byte
-		err    error
-		k      auth.Kex
-		result proto.Result
+		bjson       []byte
+		contentType string
+		err         error
+		k           auth.Kex
+		result      proto.Result
 	)
 
 	// build RequestLog entry
@@ -69,14 +70,32 @@ func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 		result = proto.NewCategoryResult()
 		*result.Categories = append(*result.Categories, r.Category...)
 	case msg.SectionCheckConfig:
-		result = proto.NewCheckConfigResult()
-		*result.CheckConfigs = append(*result.CheckConfigs, r.CheckConfig...)
+		switch r.Action {
+		case msg.ActionEvaluate:
+			result = proto.NewCheckEvaluationResult()
+			*result.CheckEvaluation = append(*result.CheckEvaluation, r.Evaluation...)
+		case msg.ActionExport:
+			result = proto.NewBundleResult()
+			*result.Bundles = append(*result.Bundles, r.Bundle...)
+		case msg.ActionImport:
+			result = proto.NewBundleImportResult()
+			*result.Imports = append(*result.Imports, r.Import...)
+		default:
+			result = proto.NewCheckConfigResult()
+			*result.CheckConfigs = append(*result.CheckConfigs, r.CheckConfig...)
+		}
 	case msg.SectionDatacenter:
 		result = proto.NewDatacenterResult()
 		*result.Datacenters = append(*result.Datacenters, r.Datacenter...)
 	case msg.SectionDeployment:
 		result = proto.NewDeploymentResult()
 		*result.Deployments = append(*result.Deployments, r.Deployment...)
+	case msg.SectionDirectorySync:
+		result = proto.NewDirectorySyncResult()
+		*result.DirectorySync = append(*result.DirectorySync, r.DirectorySync...)
+	case msg.SectionInventory:
+		result = proto.NewInventoryDriftResult()
+		*result.InventoryDrift = append(*result.InventoryDrift, r.InventoryDrift...)
 	case msg.SectionEntity:
 		result = proto.NewEntityResult()
 		*result.Entities = append(*result.Entities, r.Entity...)
@@ -195,6 +214,9 @@ func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 	case msg.SectionAdminMgmt:
 		result = proto.NewAdminResult()
 		*result.Admins = append(*result.Admins, r.Admin...)
+	case msg.SectionAPIKeyMgmt:
+		result = proto.NewAPIKeyResult()
+		*result.APIKeys = append(*result.APIKeys, r.APIKey...)
 
 	// tree configuration results have different result data based on
 	// the action and may have multiple scopes
@@ -206,17 +228,37 @@ func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 	case msg.SectionRepositoryMgmt:
 		switch r.Action {
 		case msg.ActionTree:
+			if r.Graph != nil && r.Code == 200 {
+				goto dispatchGraph
+			}
 			result = proto.NewTreeResult()
 			*result.Tree = r.Tree
+		case msg.ActionPropertyHistory:
+			result = proto.NewPropertyHistoryResult()
+			*result.PropertyHistory = append(*result.PropertyHistory, r.PropertyHistory...)
+		case msg.ActionFsck:
+			result = proto.NewFsckResult()
+			*result.Fsck = append(*result.Fsck, r.Fsck...)
 		default:
 			result = proto.NewRepositoryResult()
 			*result.Repositories = append(*result.Repositories, r.Repository...)
 		}
+	case msg.SectionChangeSet:
+		result = proto.NewChangeSetResult()
+		*result.Buckets = append(*result.Buckets, r.Bucket...)
+		*result.Groups = append(*result.Groups, r.Group...)
+		*result.Clusters = append(*result.Clusters, r.Cluster...)
 	case msg.SectionBucket:
 		switch r.Action {
 		case msg.ActionTree:
+			if r.Graph != nil && r.Code == 200 {
+				goto dispatchGraph
+			}
 			result = proto.NewTreeResult()
 			*result.Tree = r.Tree
+		case msg.ActionPropertyHistory:
+			result = proto.NewPropertyHistoryResult()
+			*result.PropertyHistory = append(*result.PropertyHistory, r.PropertyHistory...)
 		default:
 			result = proto.NewBucketResult()
 			*result.Buckets = append(*result.Buckets, r.Bucket...)
@@ -224,8 +266,14 @@ func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 	case msg.SectionGroup:
 		switch r.Action {
 		case msg.ActionTree:
+			if r.Graph != nil && r.Code == 200 {
+				goto dispatchGraph
+			}
 			result = proto.NewTreeResult()
 			*result.Tree = r.Tree
+		case msg.ActionPropertyHistory:
+			result = proto.NewPropertyHistoryResult()
+			*result.PropertyHistory = append(*result.PropertyHistory, r.PropertyHistory...)
 		default:
 			result = proto.NewGroupResult()
 			*result.Groups = append(*result.Groups, r.Group...)
@@ -233,8 +281,14 @@ func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 	case msg.SectionCluster:
 		switch r.Action {
 		case msg.ActionTree:
+			if r.Graph != nil && r.Code == 200 {
+				goto dispatchGraph
+			}
 			result = proto.NewTreeResult()
 			*result.Tree = r.Tree
+		case msg.ActionPropertyHistory:
+			result = proto.NewPropertyHistoryResult()
+			*result.PropertyHistory = append(*result.PropertyHistory, r.PropertyHistory...)
 		default:
 			result = proto.NewClusterResult()
 			*result.Clusters = append(*result.Clusters, r.Cluster...)
@@ -246,8 +300,14 @@ func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 	case msg.SectionNodeMgmt:
 		switch r.Action {
 		case msg.ActionTree:
+			if r.Graph != nil && r.Code == 200 {
+				goto dispatchGraph
+			}
 			result = proto.NewTreeResult()
 			*result.Tree = r.Tree
+		case msg.ActionPropertyHistory:
+			result = proto.NewPropertyHistoryResult()
+			*result.PropertyHistory = append(*result.PropertyHistory, r.PropertyHistory...)
 		default:
 			result = proto.NewNodeResult()
 			*result.Nodes = append(*result.Nodes, r.Node...)
@@ -507,6 +567,19 @@ func (x *Rest) send(w *http.ResponseWriter, r *msg.Result) {
 	}
 	goto buildJSON
 
+dispatchGraph:
+	if bjson, contentType, err = r.Graph.Render(r.Tree.Format); err != nil {
+		x.errLog.WithField(`RequestID`, r.ID.String()).
+			WithField(`Phase`, `graph`).
+			Error(err)
+		x.hardServerError(w)
+		return
+	}
+	logEntry.WithField(`Code`, r.Code).
+		WithField(`Format`, r.Tree.Format).Info(`OK`)
+	x.writeReply(w, contentType, &bjson)
+	return
+
 dispatchOCTET:
 	x.writeReplyOctetStream(w, &r.Super.Encrypted.Data)
 	return
@@ -541,4 +614,12 @@ func (x *Rest) writeReplyJSON(w *http.ResponseWriter, b *[]byte) {
 	(*w).Write(*b)
 }
 
+// writeReply writes out b as the reply with content-type set to
+// contentType
+func (x *Rest) writeReply(w *http.ResponseWriter, contentType string, b *[]byte) {
+	(*w).Header().Set(`Content-Type`, contentType)
+	(*w).WriteHeader(http.StatusOK)
+	(*w).Write(*b)
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -9,7 +9,9 @@
 package rest // import "github.com/mjolnir42/soma/internal/rest"
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mjolnir42/soma/internal/msg"
@@ -104,4 +106,105 @@ func (x *Rest) JobWait(w http.ResponseWriter, r *http.Request,
 	x.replyNoContent(&w)
 }
 
+// JobWaitList function
+func (x *Rest) JobWaitList(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionJob
+	request.Action = msg.ActionWait
+
+	cReq := proto.NewJobWaitRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+	request.JobWait = *cReq.JobWait
+
+	if len(request.JobWait.IDList) == 0 {
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			"Empty list of jobs to wait on"))
+		return
+	}
+	for _, jobID := range request.JobWait.IDList {
+		if err := checkStringIsUUID(jobID); err != nil {
+			x.replyBadRequest(&w, &request, err)
+			return
+		}
+	}
+	switch request.JobWait.Mode {
+	case ``:
+		request.JobWait.Mode = proto.JobWaitAll
+	case proto.JobWaitAll, proto.JobWaitAny:
+	default:
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			"Invalid wait mode: %s", request.JobWait.Mode))
+		return
+	}
+	if request.JobWait.Timeout == 0 {
+		request.JobWait.Timeout = 300
+	}
+	if request.JobWait.Timeout > 3600 {
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			"Timeout exceeds 3600 seconds: %d",
+			request.JobWait.Timeout))
+		return
+	}
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	// check which jobs have already been processed before blocking
+	// on the remaining jobs
+	result := x.jobWaitStatus(r, params, request.JobWait.IDList)
+	if !result.IsOK() {
+		x.send(&w, &result)
+		return
+	}
+	if len(result.Job) != len(request.JobWait.IDList) {
+		result.NotFound(fmt.Errorf("Unknown jobs in wait list"),
+			result.Section)
+		x.send(&w, &result)
+		return
+	}
+	pending := []string{}
+	for _, job := range result.Job {
+		if job.Status != `processed` {
+			pending = append(pending, job.ID)
+		}
+	}
+	if len(pending) == 0 || (request.JobWait.Mode == proto.JobWaitAny &&
+		len(pending) < len(result.Job)) {
+		x.send(&w, &result)
+		return
+	}
+
+	// block on the job notifications from the TreeKeepers
+	idList := request.JobWait.IDList
+	request.JobWait.IDList = pending
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	select {
+	case <-request.Reply:
+	case <-time.After(time.Duration(request.JobWait.Timeout) * time.Second):
+	}
+
+	result = x.jobWaitStatus(r, params, idList)
+	x.send(&w, &result)
+}
+
+// jobWaitStatus returns the current state of the jobs in idList
+func (x *Rest) jobWaitStatus(r *http.Request, params httprouter.Params,
+	idList []string) msg.Result {
+	request := msg.New(r, params)
+	request.Section = msg.SectionJob
+	request.Action = msg.ActionSearchByList
+	request.Search.Job.IDList = idList
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	return <-request.Reply
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
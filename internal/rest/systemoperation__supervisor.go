@@ -8,6 +8,7 @@
 package rest // import "github.com/mjolnir42/soma/internal/rest"
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 
@@ -65,6 +66,13 @@ func (x *Rest) SupervisorTokenInvalidate(w http.ResponseWriter, r *http.Request,
 		AuthToken: params.ByName(`AuthenticatedToken`),
 	}
 
+	// API keys are revoked via apikey-mgmt, not invalidated
+	if params.ByName(`AuthenticatedAPIKey`) != `` {
+		x.replyBadRequest(&w, &request, fmt.Errorf(
+			`API keys can not be invalidated as token`))
+		return
+	}
+
 	// authorization to invalidate the token is implicit from being
 	// able to use it for BasicAuth authentication
 
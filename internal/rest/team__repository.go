@@ -59,6 +59,42 @@ func (x *Rest) RepositoryAudit(w http.ResponseWriter, r *http.Request,
 	x.send(&w, &result)
 }
 
+// RepositoryClone function
+func (x *Rest) RepositoryClone(w http.ResponseWriter, r *http.Request,
+	params httprouter.Params) {
+	defer panicCatcher(w)
+
+	request := msg.New(r, params)
+	request.Section = msg.SectionRepository
+	request.Action = msg.ActionClone
+
+	cReq := proto.NewRepositoryRequest()
+	if err := decodeJSONBody(r, &cReq); err != nil {
+		x.replyBadRequest(&w, &request, err)
+		return
+	}
+
+	nameLen := utf8.RuneCountInString(cReq.Repository.Name)
+	if nameLen < 4 || nameLen > 128 {
+		x.replyBadRequest(&w, &request, fmt.Errorf(`Illegal new repository name length (4 < x <= 128)`))
+		return
+	}
+	request.Repository.ID = params.ByName(`repositoryID`)
+	request.Repository.TeamID = params.ByName(`teamID`)
+	request.Update.Repository.Name = cReq.Repository.Name
+	request.Update.Repository.TeamID = params.ByName(`teamID`)
+	request.Update.Repository.IsActive = true
+
+	if !x.isAuthorized(&request) {
+		x.replyForbidden(&w, &request)
+		return
+	}
+
+	x.handlerMap.MustLookup(&request).Intake() <- request
+	result := <-request.Reply
+	x.send(&w, &result)
+}
+
 // RepositoryRename function
 func (x *Rest) RepositoryRename(w http.ResponseWriter, r *http.Request,
 	params httprouter.Params) {
@@ -162,7 +162,7 @@ func (r *CapabilityRead) list(q *msg.Request, mr *msg.Result) {
 func (r *CapabilityRead) show(q *msg.Request, mr *msg.Result) {
 	var (
 		id, monitoring, metric, view, monName string
-		thresholds                            int
+		thresholds, minInterval               int
 		err                                   error
 	)
 
@@ -174,6 +174,7 @@ func (r *CapabilityRead) show(q *msg.Request, mr *msg.Result) {
 		&metric,
 		&view,
 		&thresholds,
+		&minInterval,
 		&monName,
 	); err == sql.ErrNoRows {
 		mr.NotFound(err, q.Section)
@@ -189,6 +190,7 @@ func (r *CapabilityRead) show(q *msg.Request, mr *msg.Result) {
 		Metric:       metric,
 		View:         view,
 		Thresholds:   uint64(thresholds),
+		MinInterval:  uint64(minInterval),
 		Name:         fmt.Sprintf("%s.%s.%s", monName, view, metric),
 	})
 	mr.OK()
@@ -175,6 +175,12 @@ func (w *CapabilityWrite) declare(q *msg.Request, mr *msg.Result) {
 		return
 	}
 
+	// capabilities declared without a minimum check interval
+	// support every interval
+	if q.Capability.MinInterval == 0 {
+		q.Capability.MinInterval = 1
+	}
+
 	q.Capability.ID = uuid.Must(uuid.NewV4()).String()
 	if res, err = w.stmtAdd.Exec(
 		q.Capability.ID,
@@ -182,6 +188,7 @@ func (w *CapabilityWrite) declare(q *msg.Request, mr *msg.Result) {
 		q.Capability.Metric,
 		q.Capability.View,
 		q.Capability.Thresholds,
+		q.Capability.MinInterval,
 	); err != nil {
 		mr.ServerError(err, q.Section)
 		return
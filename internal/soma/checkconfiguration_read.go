@@ -27,6 +27,7 @@ type CheckConfigurationRead struct {
 	Shutdown                    chan struct{}
 	handlerName                 string
 	conn                        *sql.DB
+	soma                        *Soma
 	stmtList                    *sql.Stmt
 	stmtShow                    *sql.Stmt
 	stmtShowThreshold           *sql.Stmt
@@ -37,6 +38,14 @@ type CheckConfigurationRead struct {
 	stmtShowConstraintAttribute *sql.Stmt
 	stmtShowConstraintOncall    *sql.Stmt
 	stmtShowInstanceInfo        *sql.Stmt
+	stmtRepoName                *sql.Stmt
+	stmtCapability              *sql.Stmt
+	stmtCapabilityID            *sql.Stmt
+	stmtObjectName              *sql.Stmt
+	stmtObjectID                *sql.Stmt
+	stmtCustomID                *sql.Stmt
+	stmtOncallID                *sql.Stmt
+	stmtServiceID               *sql.Stmt
 	appLog                      *logrus.Logger
 	reqLog                      *logrus.Logger
 	errLog                      *logrus.Logger
@@ -45,8 +54,9 @@ type CheckConfigurationRead struct {
 // newCheckConfigurationRead returns a new
 // CheckConfigurationRead handler with input
 // buffer of length
-func newCheckConfigurationRead(length int) (string, *CheckConfigurationRead) {
+func newCheckConfigurationRead(length int, s *Soma) (string, *CheckConfigurationRead) {
 	r := &CheckConfigurationRead{}
+	r.soma = s
 	r.handlerName = generateHandlerName() + `_r`
 	r.Input = make(chan msg.Request, length)
 	r.Shutdown = make(chan struct{})
@@ -68,6 +78,8 @@ func (r *CheckConfigurationRead) RegisterRequests(hmap *handler.Map) {
 		msg.ActionList,
 		msg.ActionShow,
 		msg.ActionSearch,
+		msg.ActionExport,
+		msg.ActionImport,
 	} {
 		hmap.Request(msg.SectionCheckConfig, action, r.handlerName)
 	}
@@ -98,6 +110,14 @@ func (r *CheckConfigurationRead) Run() {
 		stmt.CheckConfigShowConstrAttribute: &r.stmtShowConstraintAttribute,
 		stmt.CheckConfigShowConstrOncall:    &r.stmtShowConstraintOncall,
 		stmt.CheckConfigInstanceInfo:        &r.stmtShowInstanceInfo,
+		stmt.RepoNameByID:                   &r.stmtRepoName,
+		stmt.ShowCapability:                 &r.stmtCapability,
+		stmt.CheckConfigBundleCapabilityID:  &r.stmtCapabilityID,
+		stmt.CheckConfigBundleObjectName:    &r.stmtObjectName,
+		stmt.CheckConfigBundleObjectID:      &r.stmtObjectID,
+		stmt.CheckConfigBundleCustomID:      &r.stmtCustomID,
+		stmt.CheckConfigBundleOncallID:      &r.stmtOncallID,
+		stmt.CheckConfigBundleServiceID:     &r.stmtServiceID,
 	} {
 		if *prepStmt, err = r.conn.Prepare(statement); err != nil {
 			r.errLog.Fatal(`checkconfig`, err, stmt.Name(statement))
@@ -131,6 +151,10 @@ func (r *CheckConfigurationRead) process(q *msg.Request) {
 	case msg.ActionSearch:
 		// XXX BUG x.search(q, &result)
 		r.list(q, &result)
+	case msg.ActionExport:
+		r.bundleExport(q, &result)
+	case msg.ActionImport:
+		r.bundleImport(q, &result)
 	default:
 		result.UnknownRequest(q)
 	}
@@ -189,7 +213,27 @@ func (r *CheckConfigurationRead) list(q *msg.Request, mr *msg.Result) {
 // show returns details for a check configuration
 func (r *CheckConfigurationRead) show(q *msg.Request, mr *msg.Result) {
 	var (
-		configID, repoID, configName   string
+		err         error
+		checkConfig proto.CheckConfig
+	)
+
+	if checkConfig, err = r.load(q.CheckConfig.ID); err == sql.ErrNoRows {
+		mr.NotFound(err, q.Section)
+		return
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	mr.CheckConfig = append(mr.CheckConfig, checkConfig)
+	mr.OK()
+}
+
+// load returns the check configuration configID including its
+// thresholds, constraints and instances
+func (r *CheckConfigurationRead) load(configID string) (proto.CheckConfig, error) {
+	var (
+		repoID, configName             string
 		bucketID, objectID, objectType string
 		capabilityID, externalID       string
 		bucketNULL                     sql.NullString
@@ -201,7 +245,7 @@ func (r *CheckConfigurationRead) show(q *msg.Request, mr *msg.Result) {
 	)
 
 	if err = r.stmtShow.QueryRow(
-		q.CheckConfig.ID,
+		configID,
 	).Scan(
 		&configID,
 		&repoID,
@@ -216,11 +260,8 @@ func (r *CheckConfigurationRead) show(q *msg.Request, mr *msg.Result) {
 		&interval,
 		&isEnabled,
 		&externalID,
-	); err == sql.ErrNoRows {
-		mr.NotFound(err, q.Section)
-		return
-	} else if err != nil {
-		goto fail
+	); err != nil {
+		return checkConfig, err
 	}
 
 	if bucketNULL.Valid {
@@ -245,23 +286,17 @@ func (r *CheckConfigurationRead) show(q *msg.Request, mr *msg.Result) {
 
 	// retrieve check configuration thresholds
 	if err = r.thresholds(&checkConfig); err != nil {
-		goto fail
+		return checkConfig, err
 	}
 
 	if err = r.constraints(&checkConfig); err != nil {
-		goto fail
+		return checkConfig, err
 	}
 
 	if err = r.instances(&checkConfig); err != nil {
-		goto fail
+		return checkConfig, err
 	}
-
-	mr.CheckConfig = append(mr.CheckConfig, checkConfig)
-	mr.OK()
-	return
-
-fail:
-	mr.ServerError(err, q.Section)
+	return checkConfig, nil
 }
 
 // thresholds add thresholds to a check configuration
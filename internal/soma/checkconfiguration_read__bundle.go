@@ -0,0 +1,518 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// bundleExport returns the check configurations of a repository as
+// a signed bundle, in which all references are made by name
+func (r *CheckConfigurationRead) bundleExport(q *msg.Request, mr *msg.Result) {
+	var (
+		err        error
+		repoName   string
+		configs    map[string]string
+		names      []string
+		cnf        proto.CheckConfig
+		entry      proto.BundleEntry
+		bundle     proto.Bundle
+		selected   map[string]bool
+		bundleKey  = r.soma.conf.Auth.BundleKey
+		repository = q.CheckConfig.RepositoryID
+	)
+
+	if bundleKey == `` {
+		mr.ServerError(fmt.Errorf(`No bundle.key configured,`+
+			` can not sign bundle`), q.Section)
+		return
+	}
+
+	if err = r.stmtRepoName.QueryRow(
+		repository,
+	).Scan(
+		&repoName,
+	); err == sql.ErrNoRows {
+		mr.NotFound(fmt.Errorf("Repository %s not found", repository),
+			q.Section)
+		return
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	if configs, err = r.configNames(repository); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	selected = map[string]bool{}
+	for _, name := range q.Search.CheckConfig.Names {
+		if _, ok := configs[name]; !ok {
+			mr.NotFound(fmt.Errorf("Check configuration %s not found"+
+				" in repository %s", name, repoName), q.Section)
+			return
+		}
+		selected[name] = true
+	}
+
+	names = make([]string, 0, len(configs))
+	for name := range configs {
+		if len(selected) > 0 && !selected[name] {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bundle = proto.Bundle{
+		Environment:    r.soma.conf.Environment,
+		InstanceName:   r.soma.conf.InstanceName,
+		RepositoryName: repoName,
+		ExportedAt:     time.Now().UTC().Format(msg.RFC3339Milli),
+		Entries:        []proto.BundleEntry{},
+	}
+	for _, name := range names {
+		if cnf, err = r.load(configs[name]); err != nil {
+			mr.ServerError(err, q.Section)
+			return
+		}
+		if entry, err = r.bundleEntry(cnf); err != nil {
+			mr.ServerError(err, q.Section)
+			return
+		}
+		bundle.Entries = append(bundle.Entries, entry)
+	}
+
+	if err = signBundle(&bundle, bundleKey); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	mr.Bundle = append(mr.Bundle, bundle)
+	mr.OK()
+}
+
+// bundleEntry converts cnf into a bundle entry, replacing all IDs
+// with names
+func (r *CheckConfigurationRead) bundleEntry(cnf proto.CheckConfig) (proto.BundleEntry, error) {
+	var (
+		err                          error
+		capabilityID, monitoringID   string
+		monitoringName, metric, view string
+		thresholdAmount              int
+		objectName, bucketName       string
+		entry                        proto.BundleEntry
+	)
+
+	if err = r.stmtCapability.QueryRow(
+		cnf.CapabilityID,
+	).Scan(
+		&capabilityID,
+		&monitoringID,
+		&metric,
+		&view,
+		&thresholdAmount,
+		&monitoringName,
+	); err != nil {
+		return entry, err
+	}
+
+	if err = r.stmtObjectName.QueryRow(
+		cnf.ObjectID,
+		cnf.ObjectType,
+	).Scan(
+		&objectName,
+	); err != nil {
+		return entry, err
+	}
+
+	if cnf.BucketID != `` {
+		if err = r.stmtObjectName.QueryRow(
+			cnf.BucketID,
+			`bucket`,
+		).Scan(
+			&bucketName,
+		); err != nil {
+			return entry, err
+		}
+	}
+
+	cnf.ID = ``
+	cnf.RepositoryID = ``
+	cnf.BucketID = ``
+	cnf.CapabilityID = ``
+	cnf.ObjectID = ``
+	cnf.Details = nil
+	for i := range cnf.Constraints {
+		switch cnf.Constraints[i].ConstraintType {
+		case msg.ConstraintCustom:
+			cnf.Constraints[i].Custom.ID = ``
+			cnf.Constraints[i].Custom.RepositoryID = ``
+		case msg.ConstraintOncall:
+			cnf.Constraints[i].Oncall.ID = ``
+		case msg.ConstraintService:
+			cnf.Constraints[i].Service.ID = ``
+			cnf.Constraints[i].Service.TeamID = ``
+		}
+	}
+
+	entry = proto.BundleEntry{
+		CheckConfig: cnf,
+		Monitoring:  monitoringName,
+		Metric:      metric,
+		View:        view,
+		BucketName:  bucketName,
+		ObjectName:  objectName,
+	}
+	return entry, nil
+}
+
+// bundleImport verifies a signed bundle and reports how its check
+// configurations map onto the repository. The repository is not
+// modified, the check configurations in the report are created or
+// updated in place by the client through the regular check
+// configuration requests.
+func (r *CheckConfigurationRead) bundleImport(q *msg.Request, mr *msg.Result) {
+	var (
+		err        error
+		configs    map[string]string
+		entry      proto.BundleImportEntry
+		bundleKey  = r.soma.conf.Auth.BundleKey
+		repository = q.CheckConfig.RepositoryID
+	)
+
+	if bundleKey == `` {
+		mr.ServerError(fmt.Errorf(`No bundle.key configured,`+
+			` can not verify bundle`), q.Section)
+		return
+	}
+
+	if err = verifyBundle(&q.Bundle, bundleKey); err != nil {
+		mr.BadRequest(err, q.Section)
+		return
+	}
+
+	if configs, err = r.configNames(repository); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	report := proto.BundleImport{
+		RepositoryID:      repository,
+		SourceEnvironment: q.Bundle.Environment,
+		TargetEnvironment: r.soma.conf.Environment,
+		Entries:           []proto.BundleImportEntry{},
+	}
+	for _, bundleEntry := range q.Bundle.Entries {
+		if entry, err = r.bundleImportEntry(repository, bundleEntry,
+			configs); err != nil {
+			mr.ServerError(err, q.Section)
+			return
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+
+	mr.Import = append(mr.Import, report)
+	mr.OK()
+}
+
+// bundleImportEntry maps all references of a bundle entry to the
+// objects of the repository and compares the result with the
+// existing check configuration of the same name. Unresolvable
+// references are reported, an error is only returned if the lookup
+// itself failed.
+func (r *CheckConfigurationRead) bundleImportEntry(repository string,
+	bundleEntry proto.BundleEntry, configs map[string]string) (
+	proto.BundleImportEntry, error) {
+	var (
+		err        error
+		objectID   string
+		bucketNULL sql.NullString
+		have       proto.CheckConfig
+	)
+	cnf := bundleEntry.CheckConfig
+	entry := proto.BundleImportEntry{
+		Name:    cnf.Name,
+		Changes: []string{},
+		Errors:  []string{},
+	}
+
+	cnf.RepositoryID = repository
+	if err = r.stmtCapabilityID.QueryRow(
+		bundleEntry.Monitoring,
+		bundleEntry.Metric,
+		bundleEntry.View,
+	).Scan(
+		&cnf.CapabilityID,
+	); err == sql.ErrNoRows {
+		entry.Errors = append(entry.Errors, fmt.Sprintf(
+			"Capability %s.%s.%s not found", bundleEntry.Monitoring,
+			bundleEntry.View, bundleEntry.Metric))
+	} else if err != nil {
+		return entry, err
+	}
+
+	if err = r.stmtObjectID.QueryRow(
+		repository,
+		cnf.ObjectType,
+		bundleEntry.BucketName,
+		bundleEntry.ObjectName,
+	).Scan(
+		&objectID,
+		&bucketNULL,
+	); err == sql.ErrNoRows {
+		entry.Errors = append(entry.Errors, fmt.Sprintf(
+			"%s %s not found", cnf.ObjectType, bundleEntry.ObjectName))
+	} else if err != nil {
+		return entry, err
+	}
+	cnf.ObjectID = objectID
+	if bucketNULL.Valid {
+		cnf.BucketID = bucketNULL.String
+	}
+
+	for i := range cnf.Constraints {
+		constr := &cnf.Constraints[i]
+		switch constr.ConstraintType {
+		case msg.ConstraintCustom:
+			constr.Custom.RepositoryID = repository
+			err = r.stmtCustomID.QueryRow(
+				repository,
+				constr.Custom.Name,
+			).Scan(
+				&constr.Custom.ID,
+			)
+		case msg.ConstraintOncall:
+			err = r.stmtOncallID.QueryRow(
+				constr.Oncall.Name,
+			).Scan(
+				&constr.Oncall.ID,
+				&constr.Oncall.Number,
+			)
+		case msg.ConstraintService:
+			err = r.stmtServiceID.QueryRow(
+				repository,
+				constr.Service.Name,
+			).Scan(
+				&constr.Service.ID,
+				&constr.Service.TeamID,
+			)
+		default:
+			continue
+		}
+		if err == sql.ErrNoRows {
+			entry.Errors = append(entry.Errors, fmt.Sprintf(
+				"%s property %s not found", constr.ConstraintType,
+				bundleConstraintName(*constr)))
+		} else if err != nil {
+			return entry, err
+		}
+	}
+
+	switch {
+	case len(entry.Errors) > 0:
+		entry.Action = `error`
+		return entry, nil
+	case configs[cnf.Name] == ``:
+		entry.Action = `create`
+	default:
+		entry.ExistingID = configs[cnf.Name]
+		if have, err = r.load(entry.ExistingID); err != nil {
+			return entry, err
+		}
+		var fixed []string
+		entry.Changes, fixed = bundleChanges(have, cnf)
+		switch {
+		case len(entry.Changes) == 0:
+			entry.Action = `unchanged`
+		case len(fixed) > 0:
+			// replacing the check configuration would replace all
+			// its check instances
+			entry.Action = `conflict`
+			entry.Errors = append(entry.Errors, fmt.Sprintf(
+				"Can not be updated in place: %s",
+				strings.Join(fixed, `, `)))
+		default:
+			entry.Action = `change`
+		}
+	}
+	entry.CheckConfig = &cnf
+	return entry, nil
+}
+
+// configNames returns the IDs of all check configurations in the
+// repository, keyed by name
+func (r *CheckConfigurationRead) configNames(repository string) (map[string]string, error) {
+	var (
+		configID, repoID, configName string
+		bucketNULL                   sql.NullString
+		rows                         *sql.Rows
+		err                          error
+	)
+	configs := map[string]string{}
+
+	if rows, err = r.stmtList.Query(
+		repository,
+	); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&configID,
+			&repoID,
+			&bucketNULL,
+			&configName,
+		); err != nil {
+			return nil, err
+		}
+		configs[configName] = configID
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// bundleChanges returns a description of all differences between the
+// check configurations have and want, as well as the names of all
+// changed fields that can not be updated in place
+func bundleChanges(have, want proto.CheckConfig) ([]string, []string) {
+	changes := []string{}
+	fixed := []string{}
+	for _, field := range []struct {
+		name       string
+		have, want string
+		mutable    bool
+	}{
+		{`interval`, fmt.Sprint(have.Interval), fmt.Sprint(want.Interval),
+			true},
+		{`capability`, have.CapabilityID, want.CapabilityID, false},
+		{`object`, have.ObjectType + `/` + have.ObjectID,
+			want.ObjectType + `/` + want.ObjectID, false},
+		{`inheritance`, fmt.Sprint(have.Inheritance),
+			fmt.Sprint(want.Inheritance), false},
+		{`childrenonly`, fmt.Sprint(have.ChildrenOnly),
+			fmt.Sprint(want.ChildrenOnly), false},
+		// an update can not remove the external ID
+		{`extern`, have.ExternalID, want.ExternalID,
+			want.ExternalID != ``},
+		{`thresholds`, bundleThresholds(have.Thresholds),
+			bundleThresholds(want.Thresholds), true},
+		{`constraints`, bundleConstraints(have.Constraints),
+			bundleConstraints(want.Constraints), false},
+	} {
+		if field.have != field.want {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s",
+				field.name, field.have, field.want))
+			if !field.mutable {
+				fixed = append(fixed, field.name)
+			}
+		}
+	}
+	return changes, fixed
+}
+
+// bundleThresholds returns a sorted string representation of
+// thresholds
+func bundleThresholds(thresholds []proto.CheckConfigThreshold) string {
+	s := make([]string, 0, len(thresholds))
+	for _, thr := range thresholds {
+		s = append(s, fmt.Sprintf("%s%s%d", thr.Level.Name,
+			thr.Predicate.Symbol, thr.Value))
+	}
+	sort.Strings(s)
+	return `[` + strings.Join(s, `, `) + `]`
+}
+
+// bundleConstraints returns a sorted string representation of
+// constraints that only uses names
+func bundleConstraints(constraints []proto.CheckConfigConstraint) string {
+	s := make([]string, 0, len(constraints))
+	for _, constr := range constraints {
+		s = append(s, constr.ConstraintType+`:`+
+			bundleConstraintName(constr))
+	}
+	sort.Strings(s)
+	return `[` + strings.Join(s, `, `) + `]`
+}
+
+// bundleConstraintName returns the name and the value of the property
+// a constraint references
+func bundleConstraintName(constr proto.CheckConfigConstraint) string {
+	switch constr.ConstraintType {
+	case msg.ConstraintNative:
+		return constr.Native.Name + `=` + constr.Native.Value
+	case msg.ConstraintSystem:
+		return constr.System.Name + `=` + constr.System.Value
+	case msg.ConstraintCustom:
+		return constr.Custom.Name + `=` + constr.Custom.Value
+	case msg.ConstraintAttribute:
+		return constr.Attribute.Name + `=` + constr.Attribute.Value
+	case msg.ConstraintOncall:
+		return constr.Oncall.Name
+	case msg.ConstraintService:
+		return constr.Service.Name
+	}
+	return ``
+}
+
+// signBundle sets the signature of b, which is the hex encoded
+// HMAC-SHA256 of the JSON encoded bundle without signature
+func signBundle(b *proto.Bundle, key string) error {
+	b.Signature = ``
+	mac, err := bundleMAC(b, key)
+	if err != nil {
+		return err
+	}
+	b.Signature = hex.EncodeToString(mac)
+	return nil
+}
+
+// verifyBundle returns an error if the signature of b is invalid
+func verifyBundle(b *proto.Bundle, key string) error {
+	signature, err := hex.DecodeString(b.Signature)
+	if err != nil || len(signature) == 0 {
+		return fmt.Errorf(`Bundle has no valid signature`)
+	}
+
+	unsigned := *b
+	unsigned.Signature = ``
+	mac, err := bundleMAC(&unsigned, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(mac, signature) {
+		return fmt.Errorf(`Bundle signature verification failed`)
+	}
+	return nil
+}
+
+func bundleMAC(b *proto.Bundle, key string) ([]byte, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
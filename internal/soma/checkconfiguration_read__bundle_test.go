@@ -0,0 +1,51 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func TestBundleChanges(t *testing.T) {
+	have := proto.CheckConfig{
+		Interval:     60,
+		CapabilityID: `cap-1`,
+		ObjectType:   `bucket`,
+		ObjectID:     `bucket-1`,
+		ExternalID:   `ext-1`,
+	}
+
+	want := have
+	changes, fixed := bundleChanges(have, want)
+	if len(changes) != 0 || len(fixed) != 0 {
+		t.Errorf("Identical configurations differ: %v %v", changes, fixed)
+	}
+
+	want.Interval = 300
+	want.ExternalID = `ext-2`
+	changes, fixed = bundleChanges(have, want)
+	if len(changes) != 2 || len(fixed) != 0 {
+		t.Errorf("Mutable changes reported as fixed: %v %v",
+			changes, fixed)
+	}
+
+	want.CapabilityID = `cap-2`
+	want.Inheritance = true
+	want.ExternalID = ``
+	_, fixed = bundleChanges(have, want)
+	if !reflect.DeepEqual(fixed, []string{
+		`capability`, `inheritance`, `extern`,
+	}) {
+		t.Errorf("Unexpected fixed fields: %v", fixed)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -36,6 +36,7 @@ type DeploymentWrite struct {
 	stmtClearFlag            *sql.Stmt
 	stmtDeprovision          *sql.Stmt
 	stmtDeprovisionForUpdate *sql.Stmt
+	duty                     oncallDuty
 	appLog                   *logrus.Logger
 	reqLog                   *logrus.Logger
 	errLog                   *logrus.Logger
@@ -97,6 +98,9 @@ func (w *DeploymentWrite) Run() {
 		stmt.DeploymentClearFlag:        &w.stmtClearFlag,
 		stmt.DeploymentDeprovision:      &w.stmtDeprovision,
 		stmt.DeploymentDeprovisionStyle: &w.stmtDeprovisionForUpdate,
+		stmt.OncallOverrideActive:       &w.duty.stmtOverride,
+		stmt.OncallRotationMembers:      &w.duty.stmtMembers,
+		stmt.OncallRotationShow:         &w.duty.stmtRotation,
 	} {
 		if *prepStmt, err = w.conn.Prepare(statement); err != nil {
 			w.errLog.Fatal(`deployment`, err, stmt.Name(statement))
@@ -231,6 +235,10 @@ func (w *DeploymentWrite) show(q *msg.Request, mr *msg.Result) {
 		return
 	}
 
+	// who is on duty is not part of the stored deployment, since it
+	// changes over time
+	w.duty.setDuty(&depl, w.errLog)
+
 	if statusUpdateRequired {
 		if res, err = w.stmtSetStatusUpdate.Exec(
 			newCurrentStatus,
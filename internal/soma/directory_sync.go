@@ -0,0 +1,173 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/config"
+	"github.com/mjolnir42/soma/internal/handler"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	uuid "github.com/satori/go.uuid"
+)
+
+// DirectorySync synchronizes users and teams from the LDAP directory
+type DirectorySync struct {
+	Input          chan msg.Request
+	Shutdown       chan struct{}
+	conn           *sql.DB
+	conf           *config.LdapConfig
+	stmtTeamLoad   *sql.Stmt
+	stmtTeamAdd    *sql.Stmt
+	stmtTeamUpdate *sql.Stmt
+	stmtUserLoad   *sql.Stmt
+	stmtUserAdd    *sql.Stmt
+	stmtUserUpdate *sql.Stmt
+	stmtUserRemove *sql.Stmt
+	appLog         *logrus.Logger
+	reqLog         *logrus.Logger
+	errLog         *logrus.Logger
+	soma           *Soma
+}
+
+// newDirectorySync returns a new DirectorySync handler with input
+// buffer of length
+func newDirectorySync(length int, s *Soma) (d *DirectorySync) {
+	d = &DirectorySync{}
+	d.Input = make(chan msg.Request, length)
+	d.Shutdown = make(chan struct{})
+	d.conf = &s.conf.Ldap
+	d.soma = s
+	return
+}
+
+// Register initializes resources provided by the Soma app
+func (d *DirectorySync) Register(c *sql.DB, l ...*logrus.Logger) {
+	d.conn = c
+	d.appLog = l[0]
+	d.reqLog = l[1]
+	d.errLog = l[2]
+}
+
+// RegisterRequests links the handler inside the handlermap to the requests
+// it processes
+func (d *DirectorySync) RegisterRequests(hmap *handler.Map) {
+	hmap.Request(msg.SectionDirectorySync, msg.ActionSync, `directory_sync`)
+}
+
+// Intake exposes the Input channel as part of the handler interface
+func (d *DirectorySync) Intake() chan msg.Request {
+	return d.Input
+}
+
+// PriorityIntake aliases Intake as part of the handler interface
+func (d *DirectorySync) PriorityIntake() chan msg.Request {
+	return d.Intake()
+}
+
+// Run is the event loop for DirectorySync
+func (d *DirectorySync) Run() {
+	var err error
+	var tick <-chan time.Time
+
+	for statement, prepStmt := range map[string]**sql.Stmt{
+		stmt.TeamLoad:   &d.stmtTeamLoad,
+		stmt.TeamAdd:    &d.stmtTeamAdd,
+		stmt.TeamUpdate: &d.stmtTeamUpdate,
+		stmt.UserLoad:   &d.stmtUserLoad,
+		stmt.UserAdd:    &d.stmtUserAdd,
+		stmt.UserUpdate: &d.stmtUserUpdate,
+		stmt.UserRemove: &d.stmtUserRemove,
+	} {
+		if *prepStmt, err = d.conn.Prepare(statement); err != nil {
+			d.errLog.Fatal(`directory_sync`, err, stmt.Name(statement))
+		}
+		defer (*prepStmt).Close()
+	}
+
+	// scheduled synchronization is optional, a nil channel blocks
+	// forever
+	if d.conf.Sync && d.conf.SyncInterval > 0 {
+		ticker := time.NewTicker(
+			time.Duration(d.conf.SyncInterval) * time.Minute,
+		)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+runloop:
+	for {
+		select {
+		case <-d.Shutdown:
+			break runloop
+		case <-tick:
+			d.scheduled()
+		case req := <-d.Input:
+			d.process(&req)
+		}
+	}
+}
+
+// process is the request dispatcher
+func (d *DirectorySync) process(q *msg.Request) {
+	result := msg.FromRequest(q)
+	logRequest(d.reqLog, q)
+
+	switch q.Action {
+	case msg.ActionSync:
+		d.sync(q, &result)
+	default:
+		result.UnknownRequest(q)
+	}
+	q.Reply <- result
+}
+
+// scheduled runs a synchronization triggered by the sync interval
+// timer
+func (d *DirectorySync) scheduled() {
+	q := msg.Request{
+		ID:       uuid.Must(uuid.NewV4()),
+		Section:  msg.SectionDirectorySync,
+		Action:   msg.ActionSync,
+		AuthUser: d.conf.SyncAccount,
+	}
+	result := msg.FromRequest(&q)
+	logRequest(d.reqLog, &q)
+
+	d.sync(&q, &result)
+	if result.Error != nil {
+		d.errLog.WithField(`RequestID`, q.ID.String()).
+			Errorln(`Scheduled directory sync failed:`, result.Error)
+		return
+	}
+	for _, change := range result.DirectorySync {
+		entry := d.appLog.WithField(`RequestID`, q.ID.String()).
+			WithField(`ObjectType`, change.ObjectType).
+			WithField(`Action`, change.Action).
+			WithField(`Name`, change.Name)
+		if change.Error != `` {
+			entry.Warnln(`Directory sync:`, change.Error)
+			continue
+		}
+		entry.Infoln(`Directory sync:`, change.Changes)
+	}
+	d.appLog.WithField(`RequestID`, q.ID.String()).
+		Infoln(fmt.Sprintf("Scheduled directory sync finished with"+
+			" %d changes", len(result.DirectorySync)))
+}
+
+// ShutdownNow signals the handler to shut down
+func (d *DirectorySync) ShutdownNow() {
+	close(d.Shutdown)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
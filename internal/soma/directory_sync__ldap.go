@@ -0,0 +1,198 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/ldap.v2"
+)
+
+// directoryTeam is a team read from the LDAP directory
+type directoryTeam struct {
+	name   string
+	ldapID string
+}
+
+// directoryUser is a user read from the LDAP directory
+type directoryUser struct {
+	uid            string
+	firstName      string
+	lastName       string
+	mail           string
+	employeeNumber string
+	// ldapID of the team the user is a member of
+	team string
+}
+
+// dial opens a connection to the configured LDAP directory and binds
+// with the configured credentials
+func (d *DirectorySync) dial() (*ldap.Conn, error) {
+	var (
+		conn *ldap.Conn
+		err  error
+		pem  []byte
+	)
+
+	addr := fmt.Sprintf("%s:%d", d.conf.Address, d.conf.Port)
+	if d.conf.TLS {
+		conf := &tls.Config{
+			InsecureSkipVerify: d.conf.SkipVerify,
+			ServerName:         d.conf.Address,
+			MinVersion:         tls.VersionTLS12,
+			MaxVersion:         tls.VersionTLS12,
+			CipherSuites: []uint16{
+				tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+			},
+		}
+		if d.conf.Cert != `` {
+			if pem, err = ioutil.ReadFile(d.conf.Cert); err != nil {
+				return nil, err
+			}
+			conf.RootCAs = x509.NewCertPool()
+			conf.RootCAs.AppendCertsFromPEM(pem)
+		}
+		conn, err = ldap.DialTLS(`tcp`, addr, conf)
+	} else {
+		conn, err = ldap.Dial(`tcp`, addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// without bind.dn the directory is searched anonymously
+	if d.conf.BindDN != `` {
+		if err = conn.Bind(d.conf.BindDN, d.conf.BindPassword); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// fetchDirectory reads all teams and their members from the LDAP
+// directory. Only users that are member of a team are returned,
+// users in multiple teams are assigned to the team with the lowest
+// name.
+func (d *DirectorySync) fetchDirectory() (map[string]directoryTeam,
+	map[string]directoryUser, error) {
+	var (
+		conn        *ldap.Conn
+		err         error
+		teamResult  *ldap.SearchResult
+		userResult  *ldap.SearchResult
+		memberships map[string][]directoryTeam
+	)
+	teams := map[string]directoryTeam{}
+	users := map[string]directoryUser{}
+	memberships = map[string][]directoryTeam{}
+
+	if conn, err = d.dial(); err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+
+	if teamResult, err = conn.Search(ldap.NewSearchRequest(
+		joinDN(d.conf.TeamDN, d.conf.BaseDN),
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		d.conf.TeamFilter,
+		[]string{
+			d.conf.TeamAttribute,
+			d.conf.TeamIDAttribute,
+			d.conf.MemberAttribute,
+		},
+		nil,
+	)); err != nil {
+		return nil, nil, fmt.Errorf("LDAP team search: %s", err)
+	}
+
+	for _, entry := range teamResult.Entries {
+		team := directoryTeam{
+			name:   entry.GetAttributeValue(d.conf.TeamAttribute),
+			ldapID: entry.GetAttributeValue(d.conf.TeamIDAttribute),
+		}
+		if team.name == `` || team.ldapID == `` {
+			return nil, nil, fmt.Errorf(
+				"LDAP team %s lacks attribute %s or %s", entry.DN,
+				d.conf.TeamAttribute, d.conf.TeamIDAttribute)
+		}
+		teams[team.ldapID] = team
+
+		for _, member := range entry.GetAttributeValues(
+			d.conf.MemberAttribute,
+		) {
+			uid := memberUID(member)
+			memberships[uid] = append(memberships[uid], team)
+		}
+	}
+
+	if userResult, err = conn.Search(ldap.NewSearchRequest(
+		joinDN(d.conf.UserDN, d.conf.BaseDN),
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		d.conf.UserFilter,
+		[]string{
+			d.conf.Attribute,
+			`givenName`,
+			`sn`,
+			`mail`,
+			`employeeNumber`,
+		},
+		nil,
+	)); err != nil {
+		return nil, nil, fmt.Errorf("LDAP user search: %s", err)
+	}
+
+	for _, entry := range userResult.Entries {
+		uid := entry.GetAttributeValue(d.conf.Attribute)
+		if len(memberships[uid]) == 0 {
+			continue
+		}
+		sort.Slice(memberships[uid], func(i, j int) bool {
+			return memberships[uid][i].name < memberships[uid][j].name
+		})
+		users[uid] = directoryUser{
+			uid:            uid,
+			firstName:      entry.GetAttributeValue(`givenName`),
+			lastName:       entry.GetAttributeValue(`sn`),
+			mail:           entry.GetAttributeValue(`mail`),
+			employeeNumber: entry.GetAttributeValue(`employeeNumber`),
+			team:           memberships[uid][0].ldapID,
+		}
+	}
+	return teams, users, nil
+}
+
+// memberUID returns the uid of a team member. Depending on the
+// member attribute this is either the uid itself or the DN of the
+// user, from which the value of the first RDN is used.
+func memberUID(member string) string {
+	if !strings.Contains(member, `=`) {
+		return member
+	}
+	dn, err := ldap.ParseDN(member)
+	if err != nil || len(dn.RDNs) == 0 ||
+		len(dn.RDNs[0].Attributes) == 0 {
+		return member
+	}
+	return dn.RDNs[0].Attributes[0].Value
+}
+
+// joinDN prefixes base with the optional relative DN rdn
+func joinDN(rdn, base string) string {
+	if rdn == `` {
+		return base
+	}
+	return strings.Join([]string{rdn, base}, `,`)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,519 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
+)
+
+// somaTeam is a team as stored in the database
+type somaTeam struct {
+	id       string
+	name     string
+	ldapID   string
+	isSystem bool
+}
+
+// somaUser is a user as stored in the database
+type somaUser struct {
+	id             string
+	uid            string
+	firstName      string
+	lastName       string
+	employeeNumber string
+	mail           string
+	isActive       bool
+	isSystem       bool
+	isDeleted      bool
+	teamID         string
+}
+
+// sync compares the LDAP directory with the users and teams in the
+// database and resolves all differences, unless the request is a
+// dry-run
+func (d *DirectorySync) sync(q *msg.Request, mr *msg.Result) {
+	var (
+		err                error
+		dirTeams           map[string]directoryTeam
+		dirUsers           map[string]directoryUser
+		somaTeams          []somaTeam
+		somaUsers          map[string]somaUser
+		teamByLdap         map[string]somaTeam
+		teamByName         map[string]somaTeam
+		teamIDs            map[string]string
+		ldapIDs, userNames []string
+	)
+
+	if !d.conf.Sync {
+		mr.BadRequest(fmt.Errorf(`LDAP directory sync is not enabled`),
+			q.Section)
+		return
+	}
+
+	if dirTeams, dirUsers, err = d.fetchDirectory(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	// an empty search result is far more likely a misconfigured
+	// search than an empty directory, and would deactivate every
+	// user
+	if len(dirTeams) == 0 || len(dirUsers) == 0 {
+		mr.ServerError(fmt.Errorf("LDAP directory returned %d teams"+
+			" and %d users, refusing to synchronize",
+			len(dirTeams), len(dirUsers)), q.Section)
+		return
+	}
+	if somaTeams, somaUsers, err = d.load(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	teamByLdap = map[string]somaTeam{}
+	teamByName = map[string]somaTeam{}
+	// ldapID of directory team -> ID of SOMA team
+	teamIDs = map[string]string{}
+	for _, team := range somaTeams {
+		teamByName[team.name] = team
+		if !team.isSystem {
+			teamByLdap[team.ldapID] = team
+		}
+	}
+
+	// teams are synchronized first, so users can be assigned to
+	// newly created teams
+	for ldapID := range dirTeams {
+		ldapIDs = append(ldapIDs, ldapID)
+	}
+	sort.Strings(ldapIDs)
+	for _, ldapID := range ldapIDs {
+		dir := dirTeams[ldapID]
+		change := proto.DirectorySync{
+			ObjectType: `team`,
+			Name:       dir.name,
+		}
+
+		if team, ok := teamByLdap[ldapID]; ok {
+			teamIDs[ldapID] = team.id
+			if team.name == dir.name {
+				continue
+			}
+			change.Action = `update`
+			change.ID = team.id
+			change.Changes = []string{fmt.Sprintf(
+				"name: %s -> %s", team.name, dir.name)}
+		} else if team, ok := teamByName[dir.name]; ok {
+			change.ID = team.id
+			if team.isSystem {
+				change.Action = `conflict`
+				change.Error = `Name is used by a system team`
+				mr.DirectorySync = append(mr.DirectorySync, change)
+				continue
+			}
+			teamIDs[ldapID] = team.id
+			change.Action = `update`
+			change.Changes = []string{fmt.Sprintf(
+				"ldapId: %s -> %s", team.ldapID, ldapID)}
+		} else {
+			change.Action = `create`
+			change.ID = uuid.Must(uuid.NewV4()).String()
+			change.Changes = []string{fmt.Sprintf("ldapId: %s", ldapID)}
+		}
+
+		if !q.Flag.DryRun {
+			if err = d.applyTeam(q, change.Action, proto.Team{
+				ID:     change.ID,
+				Name:   dir.name,
+				LdapID: ldapID,
+			}); err != nil {
+				change.Error = err.Error()
+				mr.DirectorySync = append(mr.DirectorySync, change)
+				continue
+			}
+		}
+		teamIDs[ldapID] = change.ID
+		mr.DirectorySync = append(mr.DirectorySync, change)
+	}
+
+	// teams that are no longer in the directory are reported, but
+	// not removed since they still own repositories and nodes
+	for _, team := range somaTeams {
+		if team.isSystem {
+			continue
+		}
+		if _, ok := dirTeams[team.ldapID]; !ok {
+			mr.DirectorySync = append(mr.DirectorySync,
+				proto.DirectorySync{
+					ObjectType: `team`,
+					Action:     `orphaned`,
+					Name:       team.name,
+					ID:         team.id,
+				})
+		}
+	}
+
+	for uid := range dirUsers {
+		userNames = append(userNames, uid)
+	}
+	sort.Strings(userNames)
+	for _, uid := range userNames {
+		dir := dirUsers[uid]
+		change := proto.DirectorySync{
+			ObjectType: `user`,
+			Name:       uid,
+		}
+		user := proto.User{
+			UserName:       uid,
+			FirstName:      dir.firstName,
+			LastName:       dir.lastName,
+			EmployeeNumber: dir.employeeNumber,
+			MailAddress:    dir.mail,
+			TeamID:         teamIDs[dir.team],
+		}
+
+		current, exists := somaUsers[uid]
+		switch {
+		case exists && current.isSystem:
+			change.Action = `conflict`
+			change.ID = current.id
+			change.Error = `Name is used by a system user`
+		case exists && current.isDeleted:
+			change.Action = `conflict`
+			change.ID = current.id
+			change.Error = `User is deleted in SOMA`
+		case exists:
+			change.Action = `update`
+			change.ID = current.id
+			change.Changes = userChanges(current, user)
+			if len(change.Changes) == 0 {
+				continue
+			}
+		default:
+			change.Action = `create`
+			change.ID = uuid.Must(uuid.NewV4()).String()
+		}
+		user.ID = change.ID
+
+		switch {
+		case change.Error != ``:
+		case user.TeamID == ``:
+			change.Error = `Team of the user could not be synchronized`
+		case !isEmployeeNumber(user.EmployeeNumber):
+			change.Error = fmt.Sprintf(
+				"Invalid employeeNumber: %s", user.EmployeeNumber)
+		case !q.Flag.DryRun:
+			if err = d.applyUser(q, change.Action, user); err != nil {
+				change.Error = err.Error()
+			}
+		}
+		mr.DirectorySync = append(mr.DirectorySync, change)
+	}
+
+	// users that are no longer in the directory lose access to SOMA
+	userNames = deactivationCandidates(somaUsers, dirUsers, teamIDs)
+	for _, uid := range userNames {
+		current := somaUsers[uid]
+		change := proto.DirectorySync{
+			ObjectType: `user`,
+			Action:     `deactivate`,
+			Name:       uid,
+			ID:         current.id,
+		}
+		switch {
+		case uint64(len(userNames)) > d.conf.SyncMaxDeactivate:
+			change.Error = fmt.Sprintf("Not deactivated, %d users"+
+				" exceed sync.max.deactivate limit of %d",
+				len(userNames), d.conf.SyncMaxDeactivate)
+		case !q.Flag.DryRun:
+			if err = d.deactivateUser(q, current); err != nil {
+				change.Error = err.Error()
+			}
+		}
+		mr.DirectorySync = append(mr.DirectorySync, change)
+	}
+
+	mr.OK()
+}
+
+// deactivationCandidates returns the sorted names of all users that
+// are missing in the directory. Only users of teams that are
+// synchronized from the directory are managed by the sync, users of
+// system teams or teams missing in the directory are never
+// deactivated.
+func deactivationCandidates(somaUsers map[string]somaUser,
+	dirUsers map[string]directoryUser,
+	teamIDs map[string]string) []string {
+	managed := map[string]bool{}
+	for _, teamID := range teamIDs {
+		managed[teamID] = true
+	}
+
+	userNames := []string{}
+	for uid, current := range somaUsers {
+		if _, ok := dirUsers[uid]; ok || current.isSystem ||
+			current.isDeleted || !managed[current.teamID] {
+			continue
+		}
+		userNames = append(userNames, uid)
+	}
+	sort.Strings(userNames)
+	return userNames
+}
+
+// load reads all teams and users from the database
+func (d *DirectorySync) load() ([]somaTeam, map[string]somaUser, error) {
+	var (
+		err   error
+		rows  *sql.Rows
+		teams []somaTeam
+	)
+	users := map[string]somaUser{}
+
+	if rows, err = d.stmtTeamLoad.Query(); err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		team := somaTeam{}
+		if err = rows.Scan(
+			&team.id,
+			&team.name,
+			&team.ldapID,
+			&team.isSystem,
+		); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		teams = append(teams, team)
+	}
+	if err = rows.Err(); err != nil {
+		rows.Close()
+		return nil, nil, err
+	}
+	rows.Close()
+
+	if rows, err = d.stmtUserLoad.Query(); err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		user := somaUser{}
+		if err = rows.Scan(
+			&user.id,
+			&user.uid,
+			&user.firstName,
+			&user.lastName,
+			&user.employeeNumber,
+			&user.mail,
+			&user.isActive,
+			&user.isSystem,
+			&user.isDeleted,
+			&user.teamID,
+		); err != nil {
+			return nil, nil, err
+		}
+		users[user.uid] = user
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return teams, users, nil
+}
+
+// applyTeam creates or updates a team and notifies the supervisor
+func (d *DirectorySync) applyTeam(q *msg.Request, action string,
+	team proto.Team) error {
+	var (
+		err error
+		res sql.Result
+	)
+
+	update := msg.Request{
+		ID:       q.ID,
+		Section:  msg.SectionTeamMgmt,
+		AuthUser: q.AuthUser,
+		Team:     team,
+	}
+	switch action {
+	case `create`:
+		update.Action = msg.ActionAdd
+		res, err = d.stmtTeamAdd.Exec(
+			team.ID,
+			team.Name,
+			team.LdapID,
+			false,
+			q.AuthUser,
+		)
+	case `update`:
+		update.Action = msg.ActionUpdate
+		res, err = d.stmtTeamUpdate.Exec(
+			team.Name,
+			team.LdapID,
+			false,
+			team.ID,
+		)
+	}
+	if err = checkSingleRow(res, err); err != nil {
+		return err
+	}
+
+	d.soma.getSupervisor().Update <- msg.CacheUpdateFromRequest(&update)
+	return nil
+}
+
+// applyUser creates or updates a user and notifies the supervisor
+func (d *DirectorySync) applyUser(q *msg.Request, action string,
+	user proto.User) error {
+	var (
+		err error
+		res sql.Result
+	)
+
+	update := msg.Request{
+		ID:       q.ID,
+		Section:  msg.SectionUserMgmt,
+		AuthUser: q.AuthUser,
+		User:     user,
+	}
+	switch action {
+	case `create`:
+		// users are created inactive and must activate their
+		// account
+		update.Action = msg.ActionAdd
+		res, err = d.stmtUserAdd.Exec(
+			user.ID,
+			user.UserName,
+			user.FirstName,
+			user.LastName,
+			user.EmployeeNumber,
+			user.MailAddress,
+			false,
+			false,
+			false,
+			user.TeamID,
+			q.AuthUser,
+		)
+	case `update`:
+		update.Action = msg.ActionUpdate
+		update.Update.User = user
+		res, err = d.stmtUserUpdate.Exec(
+			user.UserName,
+			user.FirstName,
+			user.LastName,
+			user.EmployeeNumber,
+			user.MailAddress,
+			false,
+			user.TeamID,
+			user.ID,
+		)
+	}
+	if err = checkSingleRow(res, err); err != nil {
+		return err
+	}
+
+	d.soma.getSupervisor().Update <- msg.CacheUpdateFromRequest(&update)
+	return nil
+}
+
+// deactivateUser revokes all tokens of a user and marks the user as
+// deleted
+func (d *DirectorySync) deactivateUser(q *msg.Request,
+	user somaUser) error {
+	var (
+		err error
+		res sql.Result
+	)
+
+	// only active users can have tokens
+	if user.isActive {
+		returnChannel := make(chan msg.Result)
+		d.soma.getSupervisor().Input <- msg.Request{
+			ID:       q.ID,
+			Section:  msg.SectionSupervisor,
+			Action:   msg.ActionToken,
+			AuthUser: q.AuthUser,
+			Reply:    returnChannel,
+			Super: &msg.Supervisor{
+				Task:        msg.TaskInvalidateAccount,
+				RevokeForID: user.id,
+			},
+		}
+		if result := <-returnChannel; result.Error != nil {
+			return fmt.Errorf("Token revocation failed: %s",
+				result.Error.Error())
+		}
+	}
+
+	res, err = d.stmtUserRemove.Exec(user.id)
+	if err = checkSingleRow(res, err); err != nil {
+		return err
+	}
+
+	update := msg.Request{
+		ID:       q.ID,
+		Section:  msg.SectionUserMgmt,
+		Action:   msg.ActionRemove,
+		AuthUser: q.AuthUser,
+		User: proto.User{
+			ID:       user.id,
+			UserName: user.uid,
+		},
+	}
+	d.soma.getSupervisor().Update <- msg.CacheUpdateFromRequest(&update)
+	return nil
+}
+
+// userChanges returns the differences between the stored user and
+// the user read from the directory
+func userChanges(current somaUser, user proto.User) []string {
+	changes := []string{}
+	for _, field := range []struct {
+		name, old, new string
+	}{
+		{`firstName`, current.firstName, user.FirstName},
+		{`lastName`, current.lastName, user.LastName},
+		{`employeeNumber`, current.employeeNumber, user.EmployeeNumber},
+		{`mailAddress`, current.mail, user.MailAddress},
+		{`teamId`, current.teamID, user.TeamID},
+	} {
+		if field.old != field.new {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s",
+				field.name, field.old, field.new))
+		}
+	}
+	return changes
+}
+
+// isEmployeeNumber returns true if s is a valid employee number
+func isEmployeeNumber(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// checkSingleRow returns an error if the statement failed or did not
+// affect exactly one row
+func checkSingleRow(res sql.Result, err error) error {
+	var cnt int64
+	if err != nil {
+		return err
+	}
+	if cnt, err = res.RowsAffected(); err != nil {
+		return err
+	}
+	if cnt != 1 {
+		return fmt.Errorf("Statement affected %d rows, expected 1", cnt)
+	}
+	return nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
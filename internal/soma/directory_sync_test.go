@@ -0,0 +1,46 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeactivationCandidates(t *testing.T) {
+	somaUsers := map[string]somaUser{
+		`alice`:   {uid: `alice`, teamID: `t-1`},
+		`bob`:     {uid: `bob`, teamID: `t-1`},
+		`carol`:   {uid: `carol`, teamID: `t-2`},
+		`dave`:    {uid: `dave`, teamID: `t-1`, isDeleted: true},
+		`root`:    {uid: `root`, teamID: `t-1`, isSystem: true},
+		`manual`:  {uid: `manual`, teamID: `t-3`},
+		`mallory`: {uid: `mallory`, teamID: `t-2`},
+	}
+	dirUsers := map[string]directoryUser{
+		`alice`: {uid: `alice`, team: `1000`},
+	}
+	// t-3 is not synchronized from the directory
+	teamIDs := map[string]string{
+		`1000`: `t-1`,
+		`1001`: `t-2`,
+	}
+
+	res := deactivationCandidates(somaUsers, dirUsers, teamIDs)
+	if !reflect.DeepEqual(res, []string{`bob`, `carol`, `mallory`}) {
+		t.Errorf("Unexpected deactivation candidates: %v", res)
+	}
+
+	// without synchronized teams, no user is managed by the sync
+	res = deactivationCandidates(somaUsers, dirUsers, map[string]string{})
+	if len(res) != 0 {
+		t.Errorf("Unmanaged users selected for deactivation: %v", res)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -61,6 +61,8 @@ func (f *ForestCustodian) Register(c *sql.DB, l ...*logrus.Logger) {
 // it processes
 func (f *ForestCustodian) RegisterRequests(hmap *handler.Map) {
 	hmap.Request(msg.SectionRepositoryMgmt, msg.ActionCreate, `forest_custodian`)
+	hmap.Request(msg.SectionRepositoryConfig, msg.ActionFsck, `forest_custodian`)
+	hmap.Request(msg.SectionCheckConfig, msg.ActionEvaluate, `forest_custodian`)
 	hmap.Request(msg.SectionSystem, msg.ActionRepoRebuild, `forest_custodian`)
 	hmap.Request(msg.SectionSystem, msg.ActionRepoRestart, `forest_custodian`)
 	hmap.Request(msg.SectionSystem, msg.ActionRepoStop, `forest_custodian`)
@@ -130,12 +132,61 @@ func (f *ForestCustodian) process(q *msg.Request) {
 	switch q.Action {
 	case msg.ActionCreate:
 		f.create(q, &result)
+	case msg.ActionFsck, msg.ActionEvaluate:
+		if f.forward(q, &result) {
+			// TreeKeeper replies directly
+			return
+		}
 	default:
 		result.UnknownRequest(q)
 	}
 	q.Reply <- result
 }
 
+// forward passes a read-only request to the TreeKeeper of the
+// repository. It returns true if the request was forwarded, in which
+// case the TreeKeeper sends the reply.
+func (f *ForestCustodian) forward(q *msg.Request, mr *msg.Result) bool {
+	var (
+		repoName, teamID, keeper string
+		err                      error
+	)
+
+	if err = f.stmtRepoName.QueryRow(
+		q.Repository.ID,
+	).Scan(
+		&repoName,
+		&teamID,
+	); err == sql.ErrNoRows {
+		mr.NotFound(err, q.Section)
+		return false
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return false
+	}
+
+	keeper = fmt.Sprintf("repository_%s", repoName)
+	if !f.soma.handlerMap.Exists(keeper) {
+		mr.NotFound(fmt.Errorf("Handler %s not found", keeper), q.Section)
+		return false
+	}
+
+	handler := f.soma.handlerMap.Get(keeper).(*TreeKeeper)
+	switch {
+	case q.Action == msg.ActionFsck && handler.isBroken() &&
+		!handler.isStopped():
+		// broken TreeKeepers still answer fsck requests
+	case !handler.isReady() || handler.isStopped():
+		mr.Unavailable(fmt.Errorf("TreeKeeper %s is not ready", keeper))
+		return false
+	}
+
+	q.Repository.Name = repoName
+	q.Repository.TeamID = teamID
+	handler.System <- *q
+	return true
+}
+
 // create spawns a new repository tree
 func (f *ForestCustodian) create(q *msg.Request, mr *msg.Result) {
 	var (
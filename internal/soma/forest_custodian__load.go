@@ -115,7 +115,7 @@ func (f *ForestCustodian) loadSomaTree(q *msg.Request) error {
 			q.Repository.Name,
 			e.String(),
 		)
-		err = fmt.Errorf(e.String())
+		err = fmt.Errorf("%s", e.String())
 	}
 	for i := len(actionChan); i > 0; i-- {
 		// discard actions on initial load
@@ -173,6 +173,7 @@ func (f *ForestCustodian) spawnTreeKeeper(q *msg.Request, s *tree.Tree,
 	}
 	tK := new(TreeKeeper)
 	tK.Input = make(chan msg.Request, 1024)
+	tK.System = make(chan msg.Request, 32)
 	tK.Shutdown = make(chan struct{})
 	tK.Stop = make(chan struct{})
 	tK.conn = db
@@ -23,6 +23,8 @@ func (f *ForestCustodian) sysProcess(q *msg.Request) {
 	logRequest(f.reqLog, q)
 
 	switch q.Action {
+	case msg.ActionClone:
+		f.loadClone(q, &result)
 	case msg.ActionRepoRebuild:
 		f.rebuild(q, &result)
 	case msg.ActionRepoRestart:
@@ -118,6 +120,22 @@ func (f *ForestCustodian) restart(q *msg.Request, mr *msg.Result) {
 	mr.OK()
 }
 
+// loadClone launches the TreeKeeper for a repository that was
+// created by cloning an existing repository
+func (f *ForestCustodian) loadClone(q *msg.Request, mr *msg.Result) {
+	loadRequest := &msg.Request{
+		Section:    msg.SectionRepositoryMgmt,
+		Action:     msg.ActionCreate,
+		Repository: q.Repository.Clone(),
+	}
+	if err := f.loadSomaTree(loadRequest); err != nil {
+		f.errLog.Printf("fc.loadClone(), error: %s", err.Error())
+		mr.ServerError(err)
+		return
+	}
+	mr.OK()
+}
+
 // rebuild recreates checks/instances for a repository
 func (f *ForestCustodian) rebuild(q *msg.Request, mr *msg.Result) {
 	var err error
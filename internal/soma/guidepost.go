@@ -36,9 +36,18 @@ type GuidePost struct {
 	stmtServiceAttributes     *sql.Stmt
 	stmtCapabilityThresholds  *sql.Stmt
 	stmtCheckDetailsForDelete *sql.Stmt
+	stmtCheckDetailsForUpdate *sql.Stmt
+	stmtCheckThresholds       *sql.Stmt
 	stmtBucketForNodeID       *sql.Stmt
 	stmtBucketForClusterID    *sql.Stmt
 	stmtBucketForGroupID      *sql.Stmt
+	stmtCapabilityMinInterval *sql.Stmt
+	stmtLevelShow             *sql.Stmt
+	stmtPredicateShow         *sql.Stmt
+	stmtLintConstraint        *sql.Stmt
+	stmtLintDuplicate         *sql.Stmt
+	stmtStateShow             *sql.Stmt
+	stmtCustomSchema          *sql.Stmt
 	appLog                    *logrus.Logger
 	reqLog                    *logrus.Logger
 	errLog                    *logrus.Logger
@@ -89,6 +98,7 @@ func (g *GuidePost) RegisterRequests(hmap *handler.Map) {
 		Action  string
 	}{
 		{Section: msg.SectionSystem, Action: msg.ActionRepoStop},
+		{Section: msg.SectionRepository, Action: msg.ActionClone},
 		{Section: msg.SectionRepository, Action: msg.ActionDestroy},
 		{Section: msg.SectionRepository, Action: msg.ActionRename},
 		{Section: msg.SectionRepository, Action: msg.ActionRepossess},
@@ -97,6 +107,8 @@ func (g *GuidePost) RegisterRequests(hmap *handler.Map) {
 		{Section: msg.SectionRepositoryConfig, Action: msg.ActionPropertyUpdate},
 		{Section: msg.SectionNodeConfig, Action: msg.ActionAssign},
 		{Section: msg.SectionNodeConfig, Action: msg.ActionUnassign},
+		{Section: msg.SectionNodeConfig, Action: msg.ActionMove},
+		{Section: msg.SectionNodeConfig, Action: msg.ActionLifecycle},
 		{Section: msg.SectionNodeConfig, Action: msg.ActionPropertyCreate},
 		{Section: msg.SectionNodeConfig, Action: msg.ActionPropertyDestroy},
 		{Section: msg.SectionNodeConfig, Action: msg.ActionPropertyUpdate},
@@ -106,6 +118,8 @@ func (g *GuidePost) RegisterRequests(hmap *handler.Map) {
 		{Section: msg.SectionCluster, Action: msg.ActionMemberUnassign},
 		{Section: msg.SectionCheckConfig, Action: msg.ActionCreate},
 		{Section: msg.SectionCheckConfig, Action: msg.ActionDestroy},
+		{Section: msg.SectionCheckConfig, Action: msg.ActionUpdate},
+		{Section: msg.SectionChangeSet, Action: msg.ActionApply},
 	} {
 		hmap.Request(request.Section, request.Action, `guidepost`)
 	}
@@ -127,17 +141,26 @@ func (g *GuidePost) Run() {
 	var err error
 
 	for statement, prepStmt := range map[string]**sql.Stmt{
-		stmt.JobSave:               &g.stmtJobSave,
-		stmt.RepoByBucketID:        &g.stmtRepoForBucketID,
-		stmt.NodeDetails:           &g.stmtNodeDetails,
-		stmt.RepoNameByID:          &g.stmtRepoNameByID,
-		stmt.ServiceLookup:         &g.stmtServiceLookup,
-		stmt.ServiceAttributes:     &g.stmtServiceAttributes,
-		stmt.CapabilityThresholds:  &g.stmtCapabilityThresholds,
-		stmt.CheckDetailsForDelete: &g.stmtCheckDetailsForDelete,
-		stmt.NodeBucketID:          &g.stmtBucketForNodeID,
-		stmt.ClusterBucketID:       &g.stmtBucketForClusterID,
-		stmt.GroupBucketID:         &g.stmtBucketForGroupID,
+		stmt.JobSave:                   &g.stmtJobSave,
+		stmt.RepoByBucketID:            &g.stmtRepoForBucketID,
+		stmt.NodeDetails:               &g.stmtNodeDetails,
+		stmt.RepoNameByID:              &g.stmtRepoNameByID,
+		stmt.ServiceLookup:             &g.stmtServiceLookup,
+		stmt.ServiceAttributes:         &g.stmtServiceAttributes,
+		stmt.CapabilityThresholds:      &g.stmtCapabilityThresholds,
+		stmt.CheckDetailsForDelete:     &g.stmtCheckDetailsForDelete,
+		stmt.CheckDetailsForUpdate:     &g.stmtCheckDetailsForUpdate,
+		stmt.CheckConfigShowThreshold:  &g.stmtCheckThresholds,
+		stmt.NodeBucketID:              &g.stmtBucketForNodeID,
+		stmt.ClusterBucketID:           &g.stmtBucketForClusterID,
+		stmt.GroupBucketID:             &g.stmtBucketForGroupID,
+		stmt.CapabilityMinInterval:     &g.stmtCapabilityMinInterval,
+		stmt.LevelShow:                 &g.stmtLevelShow,
+		stmt.PredicateShow:             &g.stmtPredicateShow,
+		stmt.CheckConfigLintConstraint: &g.stmtLintConstraint,
+		stmt.CheckConfigLintDuplicate:  &g.stmtLintDuplicate,
+		stmt.ObjectStateShow:           &g.stmtStateShow,
+		stmt.PropertyCustomSchemaLoad:  &g.stmtCustomSchema,
 	} {
 		if *prepStmt, err = g.conn.Prepare(statement); err != nil {
 			g.errLog.Fatal(`guidepost`, err, stmt.Name(statement))
@@ -183,6 +206,7 @@ func (g *GuidePost) process(q *msg.Request) {
 		nf                       bool
 		handler                  *TreeKeeper
 		rowCnt                   int64
+		warnings                 []byte
 	)
 	result := msg.FromRequest(q)
 	logRequest(g.reqLog, q)
@@ -220,6 +244,12 @@ func (g *GuidePost) process(q *msg.Request) {
 	if j, err = json.Marshal(q); err != nil {
 		goto bailout
 	}
+	if q.Warnings == nil {
+		q.Warnings = []string{}
+	}
+	if warnings, err = json.Marshal(q.Warnings); err != nil {
+		goto bailout
+	}
 	if res, err = g.stmtJobSave.Exec(
 		q.JobID.String(),
 		`queued`,
@@ -228,6 +258,7 @@ func (g *GuidePost) process(q *msg.Request) {
 		repoID,
 		q.AuthUser,
 		string(j),
+		string(warnings),
 	); err != nil {
 		goto bailout
 	}
@@ -246,6 +277,12 @@ func (g *GuidePost) process(q *msg.Request) {
 
 	switch q.Section {
 	case msg.SectionRepository:
+		if q.Action == msg.ActionClone {
+			// report the repository that will be created
+			result.Repository = append(result.Repository,
+				q.Update.Repository)
+			break
+		}
 		fallthrough
 	case msg.SectionRepositoryConfig:
 		fallthrough
@@ -267,6 +304,24 @@ func (g *GuidePost) process(q *msg.Request) {
 	case msg.SectionCheckConfig:
 		result.CheckConfig = append(result.CheckConfig,
 			q.CheckConfig)
+	case msg.SectionChangeSet:
+		// report the objects that will be created
+		for i := range q.ChangeSet {
+			if q.ChangeSet[i].Action != msg.ActionCreate {
+				continue
+			}
+			switch q.ChangeSet[i].Section {
+			case msg.SectionBucket:
+				result.Bucket = append(result.Bucket,
+					q.ChangeSet[i].Bucket)
+			case msg.SectionGroup:
+				result.Group = append(result.Group,
+					q.ChangeSet[i].Group)
+			case msg.SectionCluster:
+				result.Cluster = append(result.Cluster,
+					q.ChangeSet[i].Cluster)
+			}
+		}
 	}
 	result.Accepted()
 
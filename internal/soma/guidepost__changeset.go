@@ -0,0 +1,179 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"fmt"
+
+	"github.com/mjolnir42/soma/internal/msg"
+)
+
+// validateChangeSet verifies that every step of a change set targets
+// the repository of the change set and can be processed. Steps that
+// reference objects created, destroyed or moved by an earlier step
+// can not be validated against the database, they are checked by
+// the tree when the change set is applied.
+func (g *GuidePost) validateChangeSet(q *msg.Request) (bool, error) {
+	changed := map[string]bool{}
+
+	for i := range q.ChangeSet {
+		step := &q.ChangeSet[i]
+
+		if step.Section == msg.SectionChangeSet {
+			return false, changeSetError(i, step,
+				fmt.Errorf(`Change sets can not be nested`))
+		}
+
+		pending := false
+		for _, id := range changeSetReferences(step) {
+			pending = pending || changed[id]
+		}
+
+		switch {
+		case !pending:
+			repoID, _, nf, err := g.extractRouting(step)
+			if err != nil {
+				return nf, changeSetError(i, step, err)
+			}
+			if repoID != q.Repository.ID {
+				return false, changeSetError(i, step, fmt.Errorf(
+					"Step targets repository %s", repoID))
+			}
+			if nf, err = g.validateRequest(step); err != nil {
+				return nf, changeSetError(i, step, err)
+			}
+		case step.Section == msg.SectionNodeConfig &&
+			step.Action == msg.ActionAssign && !changed[step.Node.ID]:
+			// the node is assigned to a bucket created by the
+			// change set, it must not be assigned elsewhere
+			if nf, err := g.validateNodeUnassigned(step); err != nil {
+				return nf, changeSetError(i, step, err)
+			}
+		}
+
+		// custom property values are also checked on objects the
+		// change set creates
+		if pending && step.Action == msg.ActionPropertyCreate {
+			if nf, err := g.validatePropertyCustom(step,
+				q.Repository.ID); err != nil {
+				return nf, changeSetError(i, step, err)
+			}
+		}
+
+		for _, id := range changeSetChanges(step) {
+			if id != `` {
+				changed[id] = true
+			}
+		}
+	}
+	return false, nil
+}
+
+// fillChangeSet fills in the required data for every step of a
+// change set. IDs chosen by the client for created objects are kept,
+// since later steps may reference them.
+func (g *GuidePost) fillChangeSet(q *msg.Request) (bool, error) {
+	for i := range q.ChangeSet {
+		step := &q.ChangeSet[i]
+
+		var id *string
+		switch {
+		case step.Section == msg.SectionBucket && step.Action == msg.ActionCreate:
+			id = &step.Bucket.ID
+		case step.Section == msg.SectionGroup && step.Action == msg.ActionCreate:
+			id = &step.Group.ID
+		case step.Section == msg.SectionCluster && step.Action == msg.ActionCreate:
+			id = &step.Cluster.ID
+		}
+		clientID := ``
+		if id != nil {
+			clientID = *id
+		}
+
+		if nf, err := g.fillReqData(step); err != nil {
+			return nf, changeSetError(i, step, err)
+		}
+		if clientID != `` {
+			*id = clientID
+		}
+	}
+	return false, nil
+}
+
+// changeSetError reports the failed step of a change set
+func changeSetError(i int, step *msg.Request, err error) error {
+	return fmt.Errorf("Step %d (%s::%s) failed: %s",
+		i+1, step.Section, step.Action, err.Error())
+}
+
+// changeSetReferences returns the IDs of all tree objects a change
+// set step references
+func changeSetReferences(q *msg.Request) []string {
+	ids := []string{
+		q.Bucket.ID,
+		q.Group.ID,
+		q.Group.BucketID,
+		q.Cluster.ID,
+		q.Cluster.BucketID,
+		q.Node.ID,
+	}
+	if q.Node.Config != nil {
+		ids = append(ids, q.Node.Config.BucketID)
+	}
+	return append(ids, changeSetMembers(q)...)
+}
+
+// changeSetChanges returns the IDs of the tree objects that are
+// created, destroyed or moved by a change set step
+func changeSetChanges(q *msg.Request) []string {
+	switch q.Action {
+	case msg.ActionCreate, msg.ActionDestroy:
+		switch q.Section {
+		case msg.SectionBucket:
+			return []string{q.Bucket.ID}
+		case msg.SectionGroup:
+			return []string{q.Group.ID}
+		case msg.SectionCluster:
+			return []string{q.Cluster.ID}
+		}
+	case msg.ActionAssign, msg.ActionUnassign:
+		return []string{q.Node.ID}
+	case msg.ActionMemberAssign, msg.ActionMemberUnassign:
+		return changeSetMembers(q)
+	}
+	return []string{}
+}
+
+// changeSetMembers returns the IDs of the members a change set step
+// assigns or unassigns
+func changeSetMembers(q *msg.Request) []string {
+	ids := []string{}
+	if q.Group.MemberGroups != nil {
+		for _, m := range *q.Group.MemberGroups {
+			ids = append(ids, m.ID)
+		}
+	}
+	if q.Group.MemberClusters != nil {
+		for _, m := range *q.Group.MemberClusters {
+			ids = append(ids, m.ID)
+		}
+	}
+	if q.Group.MemberNodes != nil {
+		for _, m := range *q.Group.MemberNodes {
+			ids = append(ids, m.ID)
+		}
+	}
+	if q.Cluster.Members != nil {
+		for _, m := range *q.Cluster.Members {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
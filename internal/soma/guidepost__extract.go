@@ -72,6 +72,8 @@ func (g *GuidePost) extractID(q *msg.Request) (string, string) {
 		case msg.ActionAssign:
 		case msg.ActionUnassign:
 			return q.Repository.ID, q.Bucket.ID
+		case msg.ActionMove:
+		case msg.ActionLifecycle:
 		case msg.ActionPropertyCreate:
 		case msg.ActionPropertyDestroy:
 		default:
@@ -82,6 +84,7 @@ func (g *GuidePost) extractID(q *msg.Request) (string, string) {
 		switch q.Action {
 		case msg.ActionCreate:
 		case msg.ActionDestroy:
+		case msg.ActionUpdate:
 		default:
 			return ``, ``
 		}
@@ -134,8 +137,11 @@ func (g *GuidePost) extractID(q *msg.Request) (string, string) {
 			return ``, ``
 		}
 		return q.Repository.ID, ``
+	case msg.SectionChangeSet:
+		return q.Repository.ID, ``
 	case msg.SectionRepository:
 		switch q.Action {
+		case msg.ActionClone:
 		case msg.ActionDestroy:
 		case msg.ActionRename:
 		case msg.ActionRepossess:
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/lib/pq"
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/stmt"
 	"github.com/mjolnir42/soma/lib/proto"
@@ -25,6 +26,8 @@ func (g *GuidePost) fillReqData(q *msg.Request) (bool, error) {
 		return g.fillServiceAttributes(q)
 	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionAssign:
 		return g.fillNode(q)
+	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionLifecycle:
+		return g.fillNodeLifecycle(q)
 	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionDestroy:
 		return g.fillCheckDeleteInfo(q)
 	case q.Section == msg.SectionBucket && q.Action == msg.ActionCreate:
@@ -37,6 +40,10 @@ func (g *GuidePost) fillReqData(q *msg.Request) (bool, error) {
 		return g.fillPropertyDeleteInfo(q)
 	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionCreate:
 		return g.fillCheckConfigID(q)
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionClone:
+		return g.fillRepositoryCloneID(q)
+	case q.Section == msg.SectionChangeSet:
+		return g.fillChangeSet(q)
 	default:
 		return false, nil
 	}
@@ -45,6 +52,8 @@ func (g *GuidePost) fillReqData(q *msg.Request) (bool, error) {
 // generate CheckConfigId
 func (g *GuidePost) fillCheckConfigID(q *msg.Request) (bool, error) {
 	q.CheckConfig.ID = uuid.Must(uuid.NewV4()).String()
+	// new check configurations are always created enabled
+	q.CheckConfig.IsEnabled = true
 	return false, nil
 }
 
@@ -66,6 +75,12 @@ func (g *GuidePost) fillClusterID(q *msg.Request) (bool, error) {
 	return false, nil
 }
 
+// generate RepositoryID for the cloned repository
+func (g *GuidePost) fillRepositoryCloneID(q *msg.Request) (bool, error) {
+	q.Update.Repository.ID = uuid.Must(uuid.NewV4()).String()
+	return false, nil
+}
+
 // Populate the node structure with data, overwriting the client
 // submitted values.
 func (g *GuidePost) fillNode(q *msg.Request) (bool, error) {
@@ -97,6 +112,41 @@ func (g *GuidePost) fillNode(q *msg.Request) (bool, error) {
 	return false, nil
 }
 
+// load the check handling of the lifecycle state the node is
+// moved into. An empty lifecycle state returns the node to normal
+// operation.
+func (g *GuidePost) fillNodeLifecycle(q *msg.Request) (bool, error) {
+	var (
+		err             error
+		state, handling string
+		views           pq.StringArray
+		unassignAfter   int64
+	)
+	if q.Node.Lifecycle == `` {
+		q.State = proto.State{}
+		return false, nil
+	}
+	if err = g.stmtStateShow.QueryRow(q.Node.Lifecycle).Scan(
+		&state,
+		&handling,
+		&views,
+		&unassignAfter,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return true, fmt.Errorf("State not found: %s",
+				q.Node.Lifecycle)
+		}
+		return false, err
+	}
+	q.State = proto.State{
+		Name:          state,
+		CheckHandling: handling,
+		Views:         []string(views),
+		UnassignAfter: uint64(unassignAfter),
+	}
+	return false, nil
+}
+
 // load authoritative copy of the service attributes from the
 // database. Replaces whatever the client sent in.
 func (g *GuidePost) fillServiceAttributes(q *msg.Request) (bool, error) {
@@ -0,0 +1,340 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// lintCheckConfig verifies that a check configuration makes sense
+// beyond its structure. Problems that render the check unusable are
+// returned as error, questionable but working configurations are
+// recorded as warnings on the request and reported with the job.
+func (g *GuidePost) lintCheckConfig(q *msg.Request) (bool, error) {
+	for _, lint := range []func(*msg.Request) (bool, error){
+		g.lintCheckInterval,
+		g.lintCheckThresholds,
+		g.lintCheckConstraints,
+		g.lintCheckDuplicate,
+	} {
+		if nf, err := lint(q); err != nil {
+			return nf, err
+		}
+	}
+	return false, nil
+}
+
+// lintCheckInterval verifies that the check interval is supported
+// by the capability
+func (g *GuidePost) lintCheckInterval(q *msg.Request) (bool, error) {
+	var minInterval int64
+
+	if err := g.stmtCapabilityMinInterval.QueryRow(
+		q.CheckConfig.CapabilityID,
+	).Scan(
+		&minInterval,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return true, fmt.Errorf(
+				"Capability %s not found",
+				q.CheckConfig.CapabilityID)
+		}
+		return false, err
+	}
+
+	if q.CheckConfig.Interval < uint64(minInterval) {
+		return false, fmt.Errorf(
+			"Check interval %ds is below the minimum interval of"+
+				" %ds supported by the capability",
+			q.CheckConfig.Interval,
+			minInterval)
+	}
+	return false, nil
+}
+
+// lintCheckThresholds resolves the notification levels of all
+// thresholds and verifies that the threshold values escalate with
+// the severity of the levels
+func (g *GuidePost) lintCheckThresholds(q *msg.Request) (bool, error) {
+	var (
+		name, shortName, escalation string
+		numeric                     int64
+		warnings                    []string
+		err                         error
+	)
+	levels := map[string]bool{}
+
+	for i := range q.CheckConfig.Thresholds {
+		thr := &q.CheckConfig.Thresholds[i]
+
+		if thr.Predicate.Symbol !=
+			q.CheckConfig.Thresholds[0].Predicate.Symbol {
+			return false, fmt.Errorf(
+				"Thresholds use multiple predicates: %s, %s",
+				q.CheckConfig.Thresholds[0].Predicate.Symbol,
+				thr.Predicate.Symbol)
+		}
+		if levels[thr.Level.Name] {
+			return false, fmt.Errorf(
+				"Multiple thresholds for level %s",
+				thr.Level.Name)
+		}
+		levels[thr.Level.Name] = true
+
+		if err = g.stmtLevelShow.QueryRow(
+			thr.Level.Name,
+		).Scan(
+			&name,
+			&shortName,
+			&numeric,
+		); err != nil {
+			if err == sql.ErrNoRows {
+				return true, fmt.Errorf(
+					"Notification level %s not found",
+					thr.Level.Name)
+			}
+			return false, err
+		}
+		thr.Level.ShortName = shortName
+		thr.Level.Numeric = uint16(numeric)
+	}
+
+	if len(q.CheckConfig.Thresholds) == 0 {
+		return false, nil
+	}
+	if err = g.stmtPredicateShow.QueryRow(
+		q.CheckConfig.Thresholds[0].Predicate.Symbol,
+	).Scan(
+		&name,
+		&escalation,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return true, fmt.Errorf(
+				"Predicate %s not found",
+				q.CheckConfig.Thresholds[0].Predicate.Symbol)
+		}
+		return false, err
+	}
+	for i := range q.CheckConfig.Thresholds {
+		q.CheckConfig.Thresholds[i].Predicate.Escalation = escalation
+	}
+
+	if warnings, err = lintThresholdOrder(
+		escalation,
+		q.CheckConfig.Thresholds,
+	); err != nil {
+		return false, err
+	}
+	q.Warnings = append(q.Warnings, warnings...)
+	return false, nil
+}
+
+// lintCheckConstraints verifies that all constraints reference
+// properties or attributes that exist
+func (g *GuidePost) lintCheckConstraints(q *msg.Request) (bool, error) {
+	var name string
+
+	for _, constr := range q.CheckConfig.Constraints {
+		key, err := lintConstraintKey(&constr)
+		if err != nil {
+			return false, err
+		}
+
+		if err = g.stmtLintConstraint.QueryRow(
+			constr.ConstraintType,
+			key,
+			q.CheckConfig.RepositoryID,
+		).Scan(
+			&name,
+		); err != nil {
+			if err == sql.ErrNoRows {
+				return true, fmt.Errorf(
+					"Constraint references unknown %s %s",
+					constr.ConstraintType,
+					key)
+			}
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// lintCheckDuplicate warns if the object already has a check
+// configuration with the same capability and constraints
+func (g *GuidePost) lintCheckDuplicate(q *msg.Request) (bool, error) {
+	var (
+		rows                           *sql.Rows
+		err                            error
+		configID, configName           string
+		constrType, constrKey, constrV string
+	)
+	names := map[string]string{}
+	existing := map[string]map[string]bool{}
+
+	if rows, err = g.stmtLintDuplicate.Query(
+		q.CheckConfig.RepositoryID,
+		q.CheckConfig.ObjectID,
+		q.CheckConfig.CapabilityID,
+	); err != nil {
+		return false, err
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&configID,
+			&configName,
+			&constrType,
+			&constrKey,
+			&constrV,
+		); err != nil {
+			rows.Close()
+			return false, err
+		}
+		if _, ok := existing[configID]; !ok {
+			existing[configID] = map[string]bool{}
+			names[configID] = configName
+		}
+		if constrType != `` {
+			existing[configID][lintConstraintSignature(
+				constrType, constrKey, constrV)] = true
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return false, err
+	}
+
+	requested := map[string]bool{}
+	for _, constr := range q.CheckConfig.Constraints {
+		requested[lintRequestSignature(&constr)] = true
+	}
+
+	duplicates := []string{}
+	for id := range existing {
+		if len(existing[id]) != len(requested) {
+			continue
+		}
+		match := true
+		for sig := range requested {
+			match = match && existing[id][sig]
+		}
+		if match {
+			duplicates = append(duplicates, id)
+		}
+	}
+	sort.Strings(duplicates)
+
+	for _, id := range duplicates {
+		q.Warnings = append(q.Warnings, fmt.Sprintf(
+			"Check configuration duplicates check configuration"+
+				" %s (%s) with identical capability and constraints"+
+				" on the same object",
+			names[id], id))
+	}
+	return false, nil
+}
+
+// lintThresholdOrder verifies that thresholds for more severe levels
+// are reached later than those for less severe levels, in the
+// escalation direction of the predicate
+func lintThresholdOrder(escalation string,
+	thresholds []proto.CheckConfigThreshold) ([]string, error) {
+	warnings := []string{}
+	if len(thresholds) < 2 {
+		return warnings, nil
+	}
+
+	var rising bool
+	switch escalation {
+	case proto.EscalationRising:
+		rising = true
+	case proto.EscalationFalling:
+		rising = false
+	default:
+		return warnings, nil
+	}
+
+	sorted := proto.SortThresholds(thresholds)
+	for i := 1; i < len(sorted); i++ {
+		lower, higher := sorted[i-1], sorted[i]
+		switch {
+		case lower.Value == higher.Value:
+			warnings = append(warnings, fmt.Sprintf(
+				"Thresholds for levels %s and %s are identical,"+
+					" level %s is never raised",
+				lower.Level.Name, higher.Level.Name,
+				lower.Level.Name))
+		case rising == (higher.Value < lower.Value):
+			return nil, fmt.Errorf(
+				"Threshold %s %d for level %s is reached before"+
+					" threshold %s %d for less severe level %s",
+				higher.Predicate.Symbol, higher.Value,
+				higher.Level.Name, lower.Predicate.Symbol,
+				lower.Value, lower.Level.Name)
+		}
+	}
+	return warnings, nil
+}
+
+// lintConstraintKey returns the key by which the property or
+// attribute referenced by constraint c is looked up
+func lintConstraintKey(c *proto.CheckConfigConstraint) (string, error) {
+	switch {
+	case c.ConstraintType == msg.ConstraintNative && c.Native != nil:
+		return c.Native.Name, nil
+	case c.ConstraintType == msg.ConstraintSystem && c.System != nil:
+		return c.System.Name, nil
+	case c.ConstraintType == msg.ConstraintCustom && c.Custom != nil:
+		return c.Custom.ID, nil
+	case c.ConstraintType == msg.ConstraintService && c.Service != nil:
+		return c.Service.Name, nil
+	case c.ConstraintType == msg.ConstraintAttribute && c.Attribute != nil:
+		return c.Attribute.Name, nil
+	case c.ConstraintType == msg.ConstraintOncall && c.Oncall != nil:
+		return c.Oncall.ID, nil
+	}
+	return ``, fmt.Errorf("Invalid %s constraint specification",
+		c.ConstraintType)
+}
+
+// lintRequestSignature returns the signature of constraint c, which
+// matches the signature of the same constraint stored in the database
+func lintRequestSignature(c *proto.CheckConfigConstraint) string {
+	switch c.ConstraintType {
+	case msg.ConstraintNative:
+		return lintConstraintSignature(c.ConstraintType,
+			c.Native.Name, c.Native.Value)
+	case msg.ConstraintSystem:
+		return lintConstraintSignature(c.ConstraintType,
+			c.System.Name, c.System.Value)
+	case msg.ConstraintCustom:
+		return lintConstraintSignature(c.ConstraintType,
+			c.Custom.ID, c.Custom.Value)
+	case msg.ConstraintService:
+		return lintConstraintSignature(c.ConstraintType,
+			c.Service.Name, c.Service.TeamID)
+	case msg.ConstraintAttribute:
+		return lintConstraintSignature(c.ConstraintType,
+			c.Attribute.Name, c.Attribute.Value)
+	case msg.ConstraintOncall:
+		return lintConstraintSignature(c.ConstraintType,
+			c.Oncall.ID, ``)
+	}
+	return ``
+}
+
+// lintConstraintSignature builds a comparable constraint signature
+func lintConstraintSignature(typ, key, value string) string {
+	return fmt.Sprintf("%s::%s=%s", typ, key, value)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,108 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"testing"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// testThreshold returns a threshold for the level with severity num
+func testThreshold(pred, level string, num uint16, value int64) proto.CheckConfigThreshold {
+	return proto.CheckConfigThreshold{
+		Predicate: proto.Predicate{Symbol: pred},
+		Level:     proto.Level{Name: level, Numeric: num},
+		Value:     value,
+	}
+}
+
+func TestLintThresholdOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		escalation string
+		thresholds []proto.CheckConfigThreshold
+		fail       bool
+		warnings   int
+	}{
+		{
+			name:       `rising escalation`,
+			escalation: proto.EscalationRising,
+			thresholds: []proto.CheckConfigThreshold{
+				testThreshold(`>=`, `critical`, 5, 90),
+				testThreshold(`>=`, `warning`, 2, 80),
+			},
+		},
+		{
+			name:       `inverted rising escalation`,
+			escalation: proto.EscalationRising,
+			thresholds: []proto.CheckConfigThreshold{
+				testThreshold(`>`, `warning`, 2, 90),
+				testThreshold(`>`, `critical`, 5, 80),
+			},
+			fail: true,
+		},
+		{
+			name:       `falling escalation`,
+			escalation: proto.EscalationFalling,
+			thresholds: []proto.CheckConfigThreshold{
+				testThreshold(`<`, `warning`, 2, 20),
+				testThreshold(`<`, `critical`, 5, 10),
+			},
+		},
+		{
+			name:       `inverted falling escalation`,
+			escalation: proto.EscalationFalling,
+			thresholds: []proto.CheckConfigThreshold{
+				testThreshold(`<=`, `warning`, 2, 10),
+				testThreshold(`<=`, `critical`, 5, 20),
+			},
+			fail: true,
+		},
+		{
+			name:       `identical values`,
+			escalation: proto.EscalationRising,
+			thresholds: []proto.CheckConfigThreshold{
+				testThreshold(`>`, `warning`, 2, 80),
+				testThreshold(`>`, `critical`, 5, 80),
+			},
+			warnings: 1,
+		},
+		{
+			name:       `equality predicate`,
+			escalation: proto.EscalationNone,
+			thresholds: []proto.CheckConfigThreshold{
+				testThreshold(`==`, `warning`, 2, 1),
+				testThreshold(`==`, `critical`, 5, 0),
+			},
+		},
+		{
+			name:       `single threshold`,
+			escalation: proto.EscalationRising,
+			thresholds: []proto.CheckConfigThreshold{
+				testThreshold(`>`, `critical`, 5, 80),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		warnings, err := lintThresholdOrder(test.escalation,
+			test.thresholds)
+		switch {
+		case test.fail && err == nil:
+			t.Errorf("%s: expected error, got none", test.name)
+		case !test.fail && err != nil:
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		case !test.fail && len(warnings) != test.warnings:
+			t.Errorf("%s: expected %d warnings, got %d: %v",
+				test.name, test.warnings, len(warnings), warnings)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
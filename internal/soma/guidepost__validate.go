@@ -10,9 +10,11 @@ package soma
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
 )
 
 func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
@@ -23,6 +25,10 @@ func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
 			if nf, err := g.validateCheckObjectInBucket(q); err != nil {
 				return nf, err
 			}
+		case msg.ActionUpdate:
+			if nf, err := g.validateCheckUpdate(q); err != nil {
+				return nf, err
+			}
 		}
 	case msg.SectionNodeConfig:
 		if nf, err := g.validateNodeConfig(q); err != nil {
@@ -43,6 +49,8 @@ func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
 		); err != nil {
 			return nf, err
 		}
+	case msg.SectionChangeSet:
+		return g.validateChangeSet(q)
 	case msg.SectionRepositoryConfig, msg.SectionRepository:
 		// since repository ids are the routing information,
 		// it is unnecessary to check that the object is where the
@@ -62,7 +70,23 @@ func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
 	case msg.SectionCheckConfig:
 		switch q.Action {
 		case msg.ActionCreate:
-			return g.validateCheckThresholds(q)
+			if nf, err := g.validateCheckThresholds(q); err != nil {
+				return nf, err
+			}
+			return g.lintCheckConfig(q)
+		case msg.ActionUpdate:
+			if nf, err := g.validateCheckThresholds(q); err != nil {
+				return nf, err
+			}
+			if nf, err := g.lintCheckInterval(q); err != nil {
+				return nf, err
+			}
+			return g.lintCheckThresholds(q)
+		}
+	case msg.SectionNodeConfig:
+		switch q.Action {
+		case msg.ActionMove:
+			return g.validateNodeMove(q)
 		}
 	case msg.SectionBucket:
 		switch q.Action {
@@ -83,9 +107,16 @@ func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
 			msg.SectionGroup,
 			msg.SectionCluster,
 			msg.SectionNodeConfig:
+			if q.Action == msg.ActionPropertyCreate {
+				repoID, _, nf, err := g.extractRouting(q)
+				if err != nil {
+					return nf, err
+				}
+				return g.validatePropertyCustom(q, repoID)
+			}
 			return false, nil
 		}
-	case msg.ActionAssign, msg.ActionUnassign:
+	case msg.ActionAssign, msg.ActionUnassign, msg.ActionLifecycle:
 		switch q.Section {
 		case msg.SectionNodeConfig:
 			return false, nil
@@ -104,6 +135,11 @@ func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
 			msg.SectionCluster:
 			return false, nil
 		}
+	case msg.ActionClone:
+		switch q.Section {
+		case msg.SectionRepository:
+			return false, nil
+		}
 	case msg.ActionRename:
 		switch q.Section {
 		case msg.SectionRepository:
@@ -118,6 +154,62 @@ func (g *GuidePost) validateRequest(q *msg.Request) (bool, error) {
 	return false, fmt.Errorf("Unimplemented guidepost/%s::%s", q.Section, q.Action)
 }
 
+// validatePropertyCustom verifies that the value of a custom property
+// is accepted by the schema of the property definition
+func (g *GuidePost) validatePropertyCustom(q *msg.Request,
+	repoID string) (bool, error) {
+	var (
+		err       error
+		props     *[]proto.Property
+		rawSchema sql.NullString
+		schema    *proto.PropertySchema
+	)
+
+	switch q.Section {
+	case msg.SectionRepositoryConfig:
+		props = q.Repository.Properties
+	case msg.SectionBucket:
+		props = q.Bucket.Properties
+	case msg.SectionGroup:
+		props = q.Group.Properties
+	case msg.SectionCluster:
+		props = q.Cluster.Properties
+	case msg.SectionNodeConfig:
+		props = q.Node.Properties
+	}
+	if props == nil || len(*props) == 0 {
+		return false, nil
+	}
+	prop := (*props)[0]
+	if prop.Type != msg.PropertyCustom || prop.Custom == nil {
+		return false, nil
+	}
+
+	if err = g.stmtCustomSchema.QueryRow(
+		prop.Custom.ID,
+		repoID,
+	).Scan(
+		&rawSchema,
+	); err == sql.ErrNoRows {
+		return true, fmt.Errorf(
+			"Custom property %s does not exist in repository %s",
+			prop.Custom.Name, repoID)
+	} else if err != nil {
+		return false, err
+	}
+	if schema, err = decodePropertySchema(rawSchema); err != nil {
+		return false, err
+	}
+	if schema == nil {
+		return false, nil
+	}
+	if err = schema.Validate(prop.Custom.Value); err != nil {
+		return false, fmt.Errorf("Invalid value for custom property"+
+			" %s: %s", prop.Custom.Name, err.Error())
+	}
+	return false, nil
+}
+
 func (g *GuidePost) validateObjectMatch(q *msg.Request) (bool, error) {
 	var (
 		nodeID, clusterID, groupID, childGroupID              string
@@ -335,6 +427,54 @@ func (g *GuidePost) validateNodeConfig(q *msg.Request) (bool, error) {
 	)
 }
 
+// Verify that the target of a node move is inside the same
+// repository as the node
+func (g *GuidePost) validateNodeMove(q *msg.Request) (bool, error) {
+	if q.Update.Node.Config == nil {
+		return false, fmt.Errorf("NodeConfig subobject for move target missing")
+	}
+	if nf, err := g.validateBucketInRepository(
+		q.Node.Config.RepositoryID,
+		q.Update.Node.Config.BucketID,
+	); err != nil {
+		return nf, err
+	}
+
+	var bid string
+	var err error
+	switch q.TargetEntity {
+	case msg.EntityBucket:
+		return false, nil
+	case msg.EntityGroup:
+		err = g.stmtBucketForGroupID.QueryRow(
+			q.Group.ID,
+		).Scan(
+			&bid,
+		)
+	case msg.EntityCluster:
+		err = g.stmtBucketForClusterID.QueryRow(
+			q.Cluster.ID,
+		).Scan(
+			&bid,
+		)
+	default:
+		return false, fmt.Errorf("Invalid move target type: %s",
+			q.TargetEntity)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return true, fmt.Errorf("%s is not assigned to any bucket",
+				q.TargetEntity)
+		}
+		return false, err
+	}
+	if bid != q.Update.Node.Config.BucketID {
+		return false, fmt.Errorf("Move target %s is in different bucket %s",
+			q.TargetEntity, bid)
+	}
+	return false, nil
+}
+
 // Verify that the ObjectId->BucketId->RepositoryId chain is part of
 // the same tree.
 func (g *GuidePost) validateCheckObjectInBucket(q *msg.Request) (bool, error) {
@@ -441,6 +581,120 @@ func (g *GuidePost) validateCheckThresholds(q *msg.Request) (bool, error) {
 	return false, nil
 }
 
+// validateCheckUpdate merges the requested changes of a check
+// configuration update into the stored check configuration, which
+// is then validated like a new check configuration
+func (g *GuidePost) validateCheckUpdate(q *msg.Request) (bool, error) {
+	var (
+		bucketID                                sql.NullString
+		interval, lvlNumeric, value             int64
+		predicate, threshold, lvlName, lvlShort string
+		configID                                string
+		rows                                    *sql.Rows
+		err                                     error
+	)
+	upd := &q.Update.CheckConfig
+
+	if upd.Interval == 0 && upd.ExternalID == `` &&
+		len(upd.Thresholds) == 0 && !q.Flag.Enable && !q.Flag.Disable {
+		return false, fmt.Errorf(
+			"No changes for check configuration %s specified",
+			q.CheckConfig.ID)
+	}
+
+	if err = g.stmtCheckDetailsForUpdate.QueryRow(
+		q.CheckConfig.ID,
+		q.CheckConfig.RepositoryID,
+	).Scan(
+		&q.CheckConfig.Name,
+		&bucketID,
+		&q.CheckConfig.CapabilityID,
+		&q.CheckConfig.ObjectID,
+		&q.CheckConfig.ObjectType,
+		&q.CheckConfig.IsActive,
+		&q.CheckConfig.Inheritance,
+		&q.CheckConfig.ChildrenOnly,
+		&interval,
+		&q.CheckConfig.IsEnabled,
+		&q.CheckConfig.ExternalID,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return true, fmt.Errorf(
+				"Check configuration %s not found",
+				q.CheckConfig.ID)
+		}
+		return false, err
+	}
+	if bucketID.Valid {
+		q.CheckConfig.BucketID = bucketID.String
+	}
+	q.CheckConfig.Interval = uint64(interval)
+
+	if upd.Interval != 0 {
+		q.CheckConfig.Interval = upd.Interval
+	}
+	if upd.ExternalID != `` {
+		q.CheckConfig.ExternalID = upd.ExternalID
+	}
+	switch {
+	case q.Flag.Enable:
+		q.CheckConfig.IsEnabled = true
+	case q.Flag.Disable:
+		q.CheckConfig.IsEnabled = false
+	}
+
+	q.CheckConfig.Thresholds = []proto.CheckConfigThreshold{}
+	if len(upd.Thresholds) > 0 {
+		for i := range upd.Thresholds {
+			q.CheckConfig.Thresholds = append(q.CheckConfig.Thresholds,
+				upd.Thresholds[i].Clone())
+		}
+		return false, nil
+	}
+
+	// thresholds are not updated, keep the stored ones
+	if rows, err = g.stmtCheckThresholds.Query(
+		q.CheckConfig.ID,
+	); err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&configID,
+			&predicate,
+			&threshold,
+			&lvlName,
+			&lvlShort,
+			&lvlNumeric,
+		); err != nil {
+			return false, err
+		}
+		if value, err = strconv.ParseInt(
+			threshold, 10, 64,
+		); err != nil {
+			return false, err
+		}
+		q.CheckConfig.Thresholds = append(q.CheckConfig.Thresholds,
+			proto.CheckConfigThreshold{
+				Predicate: proto.Predicate{
+					Symbol: predicate,
+				},
+				Level: proto.Level{
+					Name:      lvlName,
+					ShortName: lvlShort,
+					Numeric:   uint16(lvlNumeric),
+				},
+				Value: value,
+			})
+	}
+	if err = rows.Err(); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
 // check the naming schema for the bucket (global unique object)
 func (g *GuidePost) validateBucketName(q *msg.Request) (bool, error) {
 	_, repoName, _, _ := g.extractRouting(q)
@@ -28,6 +28,7 @@ type HostDeploymentRead struct {
 	conn                    *sql.DB
 	stmtInstancesForNode    *sql.Stmt
 	stmtLastInstanceVersion *sql.Stmt
+	duty                    oncallDuty
 	appLog                  *logrus.Logger
 	reqLog                  *logrus.Logger
 	errLog                  *logrus.Logger
@@ -79,6 +80,9 @@ func (r *HostDeploymentRead) Run() {
 	for statement, prepStmt := range map[string]**sql.Stmt{
 		stmt.DeploymentInstancesForNode:    &r.stmtInstancesForNode,
 		stmt.DeploymentLastInstanceVersion: &r.stmtLastInstanceVersion,
+		stmt.OncallOverrideActive:          &r.duty.stmtOverride,
+		stmt.OncallRotationMembers:         &r.duty.stmtMembers,
+		stmt.OncallRotationShow:            &r.duty.stmtRotation,
 	} {
 		if *prepStmt, err = r.conn.Prepare(statement); err != nil {
 			r.errLog.Fatal(`hostdeployment`, err, stmt.Name(statement))
@@ -190,6 +194,7 @@ func (r *HostDeploymentRead) get(q *msg.Request, mr *msg.Result) {
 			}
 		}
 
+		r.duty.setDuty(&depl, r.errLog)
 		mr.Deployment = append(mr.Deployment, depl)
 	}
 	if err = idList.Err(); err != nil {
@@ -279,6 +284,7 @@ assembleloop:
 			}
 		}
 
+		r.duty.setDuty(&depl, r.errLog)
 		mr.Deployment = append(mr.Deployment, depl)
 	}
 	if err = idList.Err(); err != nil {
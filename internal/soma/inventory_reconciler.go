@@ -0,0 +1,191 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/config"
+	"github.com/mjolnir42/soma/internal/handler"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	uuid "github.com/satori/go.uuid"
+)
+
+// InventoryReconciler reconciles servers and nodes against an external
+// inventory source
+type InventoryReconciler struct {
+	Input             chan msg.Request
+	Shutdown          chan struct{}
+	conn              *sql.DB
+	conf              *config.Inventory
+	source            inventorySource
+	stmtServerLoad    *sql.Stmt
+	stmtServerAdd     *sql.Stmt
+	stmtServerUpdate  *sql.Stmt
+	stmtServerRemove  *sql.Stmt
+	stmtServerRestore *sql.Stmt
+	stmtNodeLoad      *sql.Stmt
+	stmtNodeAdd       *sql.Stmt
+	stmtNodeUpdate    *sql.Stmt
+	stmtTeamLoad      *sql.Stmt
+	appLog            *logrus.Logger
+	reqLog            *logrus.Logger
+	errLog            *logrus.Logger
+}
+
+// newInventoryReconciler returns a new InventoryReconciler handler
+// with input buffer of length
+func newInventoryReconciler(length int, s *Soma) (i *InventoryReconciler) {
+	i = &InventoryReconciler{}
+	i.Input = make(chan msg.Request, length)
+	i.Shutdown = make(chan struct{})
+	i.conf = &s.conf.Inventory
+	return
+}
+
+// Register initializes resources provided by the Soma app
+func (i *InventoryReconciler) Register(c *sql.DB, l ...*logrus.Logger) {
+	i.conn = c
+	i.appLog = l[0]
+	i.reqLog = l[1]
+	i.errLog = l[2]
+}
+
+// RegisterRequests links the handler inside the handlermap to the requests
+// it processes
+func (i *InventoryReconciler) RegisterRequests(hmap *handler.Map) {
+	for _, action := range []string{
+		msg.ActionDrift,
+		msg.ActionReconcile,
+	} {
+		hmap.Request(msg.SectionInventory, action, `inventory_reconciler`)
+	}
+}
+
+// Intake exposes the Input channel as part of the handler interface
+func (i *InventoryReconciler) Intake() chan msg.Request {
+	return i.Input
+}
+
+// PriorityIntake aliases Intake as part of the handler interface
+func (i *InventoryReconciler) PriorityIntake() chan msg.Request {
+	return i.Intake()
+}
+
+// Run is the event loop for InventoryReconciler
+func (i *InventoryReconciler) Run() {
+	var err error
+	var tick <-chan time.Time
+
+	for statement, prepStmt := range map[string]**sql.Stmt{
+		stmt.SyncServers:    &i.stmtServerLoad,
+		stmt.AddServers:     &i.stmtServerAdd,
+		stmt.UpdateServers:  &i.stmtServerUpdate,
+		stmt.DeleteServers:  &i.stmtServerRemove,
+		stmt.RestoreServers: &i.stmtServerRestore,
+		stmt.NodeSync:       &i.stmtNodeLoad,
+		stmt.NodeAdd:        &i.stmtNodeAdd,
+		stmt.NodeUpdate:     &i.stmtNodeUpdate,
+		stmt.TeamLoad:       &i.stmtTeamLoad,
+	} {
+		if *prepStmt, err = i.conn.Prepare(statement); err != nil {
+			i.errLog.Fatal(`inventory_reconciler`, err, stmt.Name(statement))
+		}
+		defer (*prepStmt).Close()
+	}
+
+	if i.conf.Enabled {
+		i.source = newInventorySource(i.conf)
+
+		// scheduled reconciliation is optional, a nil channel
+		// blocks forever
+		if i.conf.Interval > 0 {
+			ticker := time.NewTicker(
+				time.Duration(i.conf.Interval) * time.Minute,
+			)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+	}
+
+runloop:
+	for {
+		select {
+		case <-i.Shutdown:
+			break runloop
+		case <-tick:
+			i.scheduled()
+		case req := <-i.Input:
+			i.process(&req)
+		}
+	}
+}
+
+// process is the request dispatcher
+func (i *InventoryReconciler) process(q *msg.Request) {
+	result := msg.FromRequest(q)
+	logRequest(i.reqLog, q)
+
+	switch q.Action {
+	case msg.ActionDrift:
+		i.reconcile(q, &result, false)
+	case msg.ActionReconcile:
+		i.reconcile(q, &result, !q.Flag.DryRun)
+	default:
+		result.UnknownRequest(q)
+	}
+	q.Reply <- result
+}
+
+// scheduled runs a reconciliation triggered by the interval timer.
+// Differences are only applied if the configuration enables it.
+func (i *InventoryReconciler) scheduled() {
+	q := msg.Request{
+		ID:       uuid.Must(uuid.NewV4()),
+		Section:  msg.SectionInventory,
+		Action:   msg.ActionReconcile,
+		AuthUser: i.conf.Account,
+	}
+	result := msg.FromRequest(&q)
+	logRequest(i.reqLog, &q)
+
+	i.reconcile(&q, &result, i.conf.Apply)
+	if result.Error != nil {
+		i.errLog.WithField(`RequestID`, q.ID.String()).
+			Errorln(`Scheduled inventory reconciliation failed:`,
+				result.Error)
+		return
+	}
+	for _, drift := range result.InventoryDrift {
+		entry := i.appLog.WithField(`RequestID`, q.ID.String()).
+			WithField(`ObjectType`, drift.ObjectType).
+			WithField(`Action`, drift.Action).
+			WithField(`Name`, drift.Name).
+			WithField(`AssetID`, drift.AssetID).
+			WithField(`Applied`, drift.Applied)
+		if drift.Error != `` {
+			entry.Warnln(`Inventory drift:`, drift.Error)
+			continue
+		}
+		entry.Infoln(`Inventory drift:`, drift.Changes)
+	}
+	i.appLog.WithField(`RequestID`, q.ID.String()).
+		Infoln(fmt.Sprintf("Scheduled inventory reconciliation"+
+			" finished with %d differences", len(result.InventoryDrift)))
+}
+
+// ShutdownNow signals the handler to shut down
+func (i *InventoryReconciler) ShutdownNow() {
+	close(i.Shutdown)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,566 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
+)
+
+// nullServerID is the server of nodes that run on no known server
+const nullServerID = `00000000-0000-0000-0000-000000000000`
+
+// inventoryServer is a server as stored in the database
+type inventoryServer struct {
+	id         string
+	assetID    uint64
+	datacenter string
+	location   string
+	name       string
+	isOnline   bool
+	isDeleted  bool
+}
+
+// inventoryNode is a node as stored in the database
+type inventoryNode struct {
+	id        string
+	assetID   uint64
+	name      string
+	teamID    string
+	serverID  string
+	isOnline  bool
+	isDeleted bool
+}
+
+// inventoryChange is a difference between the inventory source and
+// the database, together with the state that resolves it
+type inventoryChange struct {
+	drift  proto.InventoryDrift
+	server inventoryServer
+	node   inventoryNode
+}
+
+// reconcile compares the inventory source with the servers and nodes
+// in the database and reports all differences. If apply is set, the
+// differences that can be resolved automatically are written to the
+// database.
+func (i *InventoryReconciler) reconcile(q *msg.Request, mr *msg.Result,
+	apply bool) {
+	var (
+		err     error
+		src     *proto.InventorySource
+		servers []inventoryServer
+		nodes   []inventoryNode
+		teams   map[string]string
+	)
+
+	if !i.conf.Enabled {
+		mr.BadRequest(fmt.Errorf(`Inventory reconciliation is not enabled`),
+			q.Section)
+		return
+	}
+
+	if src, err = i.source.fetch(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if servers, nodes, teams, err = i.load(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	changes := inventoryDiff(src, servers, nodes, teams)
+	if reason := inventoryRefusal(src, servers, changes,
+		i.conf.MaxRemove); apply && reason != `` {
+		// a broken inventory source must not remove servers in bulk,
+		// nothing is applied
+		apply = false
+		for n := range changes {
+			if changes[n].drift.Error == `` {
+				changes[n].drift.Error = reason
+			}
+		}
+	}
+
+	for _, change := range changes {
+		if apply && change.drift.Error == `` {
+			switch change.drift.ObjectType {
+			case `server`:
+				err = i.applyServer(change.drift.Action, change.server)
+			case `node`:
+				err = i.applyNode(q, change.drift.Action, change.node)
+			}
+			if err != nil {
+				change.drift.Error = err.Error()
+			} else if change.drift.Action != `orphaned` &&
+				change.drift.Action != `vanished` {
+				change.drift.Applied = true
+			}
+		}
+		mr.InventoryDrift = append(mr.InventoryDrift, change.drift)
+	}
+	mr.OK()
+}
+
+// inventoryDiff computes the differences between the inventory source
+// and the servers and nodes from the database. teams maps team names
+// to their IDs. Nodes are only reconciled if the source lists nodes,
+// but nodes whose server vanished from the inventory are always
+// reported.
+func inventoryDiff(src *proto.InventorySource, servers []inventoryServer,
+	nodes []inventoryNode, teams map[string]string) []inventoryChange {
+	changes := []inventoryChange{}
+
+	serverByAsset := map[uint64]inventoryServer{}
+	for _, server := range servers {
+		serverByAsset[server.assetID] = server
+	}
+	// assetID of the source server -> ID of the SOMA server
+	serverIDs := map[uint64]string{}
+	// servers that were deleted or will be removed, by ID
+	vanished := map[string]inventoryServer{}
+
+	srcServers := append([]proto.InventoryServer{}, src.Servers...)
+	sort.Slice(srcServers, func(a, b int) bool {
+		return srcServers[a].AssetID < srcServers[b].AssetID
+	})
+	for _, s := range srcServers {
+		change := inventoryChange{
+			drift: proto.InventoryDrift{
+				ObjectType: `server`,
+				Name:       s.Name,
+				AssetID:    s.AssetID,
+			},
+			server: inventoryServer{
+				assetID:    s.AssetID,
+				datacenter: s.Datacenter,
+				location:   s.Location,
+				name:       s.Name,
+				isOnline:   s.IsOnline,
+			},
+		}
+
+		current, exists := serverByAsset[s.AssetID]
+		switch {
+		case !exists:
+			change.drift.Action = `create`
+			change.server.id = uuid.Must(uuid.NewV4()).String()
+		case current.isDeleted:
+			// the server returned to the inventory
+			change.drift.Action = `restore`
+			change.server.id = current.id
+			change.drift.Changes = append(
+				[]string{`isDeleted: true -> false`},
+				serverChanges(current, change.server)...)
+		default:
+			change.drift.Action = `update`
+			change.server.id = current.id
+			change.drift.Changes = serverChanges(current, change.server)
+		}
+		change.drift.ID = change.server.id
+		serverIDs[s.AssetID] = change.server.id
+		if len(change.drift.Changes) == 0 && change.drift.Action == `update` {
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	sortedServers := append([]inventoryServer{}, servers...)
+	sort.Slice(sortedServers, func(a, b int) bool {
+		return sortedServers[a].assetID < sortedServers[b].assetID
+	})
+	for _, server := range sortedServers {
+		if serverIDs[server.assetID] == server.id {
+			continue
+		}
+		if server.isDeleted {
+			vanished[server.id] = server
+			continue
+		}
+		vanished[server.id] = server
+		changes = append(changes, inventoryChange{
+			drift: proto.InventoryDrift{
+				ObjectType: `server`,
+				Action:     `remove`,
+				Name:       server.name,
+				AssetID:    server.assetID,
+				ID:         server.id,
+			},
+			server: server,
+		})
+	}
+
+	nodeByAsset := map[uint64]inventoryNode{}
+	for _, node := range nodes {
+		nodeByAsset[node.assetID] = node
+	}
+	// nodes whose server is resolved by this reconciliation, by ID
+	resolved := map[string]bool{}
+
+	if src.Nodes != nil {
+		listed := map[uint64]bool{}
+		srcNodes := append([]proto.InventoryNode{}, src.Nodes...)
+		sort.Slice(srcNodes, func(a, b int) bool {
+			return srcNodes[a].AssetID < srcNodes[b].AssetID
+		})
+		for _, n := range srcNodes {
+			listed[n.AssetID] = true
+			change := inventoryChange{
+				drift: proto.InventoryDrift{
+					ObjectType: `node`,
+					Name:       n.Name,
+					AssetID:    n.AssetID,
+				},
+				node: inventoryNode{
+					assetID:  n.AssetID,
+					name:     n.Name,
+					teamID:   teams[n.Team],
+					serverID: nullServerID,
+					isOnline: n.IsOnline,
+				},
+			}
+			if n.ServerAssetID != 0 {
+				change.node.serverID = serverIDs[n.ServerAssetID]
+			}
+
+			current, exists := nodeByAsset[n.AssetID]
+			if exists {
+				change.drift.ID = current.id
+			}
+			switch {
+			case exists && current.isDeleted:
+				change.drift.Action = `conflict`
+				change.drift.Error = `Node is deleted in SOMA`
+			case change.node.teamID == ``:
+				change.drift.Action = `conflict`
+				change.drift.Error = fmt.Sprintf(
+					"Unknown team: %s", n.Team)
+			case change.node.serverID == ``:
+				change.drift.Action = `conflict`
+				change.drift.Error = fmt.Sprintf(
+					"Server with assetId %d is not available",
+					n.ServerAssetID)
+			case !exists:
+				change.drift.Action = `create`
+				change.node.id = uuid.Must(uuid.NewV4()).String()
+				change.drift.ID = change.node.id
+			default:
+				resolved[current.id] = true
+				change.drift.Action = `update`
+				change.node.id = current.id
+				change.drift.Changes = nodeChanges(current, change.node)
+				if len(change.drift.Changes) == 0 {
+					continue
+				}
+			}
+			changes = append(changes, change)
+		}
+
+		// nodes that are no longer in the inventory are reported,
+		// but not removed since they may still carry configuration
+		for _, node := range sortedNodes(nodes) {
+			if node.isDeleted || listed[node.assetID] {
+				continue
+			}
+			changes = append(changes, inventoryChange{
+				drift: proto.InventoryDrift{
+					ObjectType: `node`,
+					Action:     `orphaned`,
+					Name:       node.name,
+					AssetID:    node.assetID,
+					ID:         node.id,
+				},
+			})
+		}
+	}
+
+	for _, node := range sortedNodes(nodes) {
+		if node.isDeleted || resolved[node.id] {
+			continue
+		}
+		server, ok := vanished[node.serverID]
+		if !ok {
+			continue
+		}
+		changes = append(changes, inventoryChange{
+			drift: proto.InventoryDrift{
+				ObjectType: `node`,
+				Action:     `vanished`,
+				Name:       node.name,
+				AssetID:    node.assetID,
+				ID:         node.id,
+				Changes: []string{fmt.Sprintf(
+					"server: %s (assetId %d)",
+					server.name, server.assetID)},
+			},
+		})
+	}
+	return changes
+}
+
+// inventoryRefusal returns why the changes computed from src must not
+// be applied, or an empty string if they can be applied. A source
+// that lists no servers or would remove more than maxRemove servers
+// is most likely broken.
+func inventoryRefusal(src *proto.InventorySource,
+	servers []inventoryServer, changes []inventoryChange,
+	maxRemove uint64) string {
+	var active, removed uint64
+
+	for _, server := range servers {
+		if !server.isDeleted && server.id != nullServerID {
+			active++
+		}
+	}
+	for _, change := range changes {
+		if change.drift.ObjectType == `server` &&
+			change.drift.Action == `remove` {
+			removed++
+		}
+	}
+
+	switch {
+	case len(src.Servers) == 0 && active > 0:
+		return `Not applied, the inventory source lists no servers`
+	case removed > maxRemove:
+		return fmt.Sprintf("Not applied, %d servers would be removed,"+
+			" which exceeds the inventory.max.remove limit of %d",
+			removed, maxRemove)
+	}
+	return ``
+}
+
+// load reads all servers, nodes and teams from the database
+func (i *InventoryReconciler) load() ([]inventoryServer,
+	[]inventoryNode, map[string]string, error) {
+	var (
+		err                  error
+		rows                 *sql.Rows
+		servers              []inventoryServer
+		nodes                []inventoryNode
+		assetID              int
+		teamID, name, ldapID string
+		isSystem             bool
+	)
+	teams := map[string]string{}
+
+	if rows, err = i.stmtServerLoad.Query(); err != nil {
+		return nil, nil, nil, err
+	}
+	for rows.Next() {
+		server := inventoryServer{}
+		if err = rows.Scan(
+			&server.id,
+			&assetID,
+			&server.datacenter,
+			&server.location,
+			&server.name,
+			&server.isOnline,
+			&server.isDeleted,
+		); err != nil {
+			rows.Close()
+			return nil, nil, nil, err
+		}
+		server.assetID = uint64(assetID)
+		servers = append(servers, server)
+	}
+	if err = rows.Err(); err != nil {
+		rows.Close()
+		return nil, nil, nil, err
+	}
+	rows.Close()
+
+	if rows, err = i.stmtNodeLoad.Query(); err != nil {
+		return nil, nil, nil, err
+	}
+	for rows.Next() {
+		node := inventoryNode{}
+		if err = rows.Scan(
+			&node.id,
+			&assetID,
+			&node.name,
+			&node.teamID,
+			&node.serverID,
+			&node.isOnline,
+			&node.isDeleted,
+		); err != nil {
+			rows.Close()
+			return nil, nil, nil, err
+		}
+		node.assetID = uint64(assetID)
+		nodes = append(nodes, node)
+	}
+	if err = rows.Err(); err != nil {
+		rows.Close()
+		return nil, nil, nil, err
+	}
+	rows.Close()
+
+	if rows, err = i.stmtTeamLoad.Query(); err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err = rows.Scan(
+			&teamID,
+			&name,
+			&ldapID,
+			&isSystem,
+		); err != nil {
+			return nil, nil, nil, err
+		}
+		teams[name] = teamID
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	return servers, nodes, teams, nil
+}
+
+// applyServer creates, updates, restores or removes a server
+func (i *InventoryReconciler) applyServer(action string,
+	server inventoryServer) error {
+	var (
+		err error
+		res sql.Result
+	)
+
+	switch action {
+	case `create`:
+		res, err = i.stmtServerAdd.Exec(
+			server.id,
+			server.assetID,
+			server.datacenter,
+			server.location,
+			server.name,
+			server.isOnline,
+			false,
+		)
+	case `update`:
+		res, err = i.stmtServerUpdate.Exec(
+			server.id,
+			server.assetID,
+			server.datacenter,
+			server.location,
+			server.name,
+			server.isOnline,
+			false,
+		)
+	case `remove`:
+		res, err = i.stmtServerRemove.Exec(
+			server.id,
+		)
+	case `restore`:
+		res, err = i.stmtServerRestore.Exec(
+			server.id,
+			server.assetID,
+			server.datacenter,
+			server.location,
+			server.name,
+			server.isOnline,
+		)
+	default:
+		return nil
+	}
+	return checkSingleRow(res, err)
+}
+
+// applyNode creates or updates a node
+func (i *InventoryReconciler) applyNode(q *msg.Request, action string,
+	node inventoryNode) error {
+	var (
+		err error
+		res sql.Result
+	)
+
+	switch action {
+	case `create`:
+		res, err = i.stmtNodeAdd.Exec(
+			node.id,
+			node.assetID,
+			node.name,
+			node.teamID,
+			node.serverID,
+			`unassigned`,
+			node.isOnline,
+			false,
+			q.AuthUser,
+		)
+	case `update`:
+		res, err = i.stmtNodeUpdate.Exec(
+			node.assetID,
+			node.name,
+			node.teamID,
+			node.serverID,
+			node.isOnline,
+			false,
+			node.id,
+		)
+	default:
+		return nil
+	}
+	return checkSingleRow(res, err)
+}
+
+// serverChanges returns the differences between the stored server and
+// the server read from the inventory
+func serverChanges(current, server inventoryServer) []string {
+	changes := []string{}
+	for _, field := range []struct {
+		name, old, new string
+	}{
+		{`name`, current.name, server.name},
+		{`datacenter`, current.datacenter, server.datacenter},
+		{`location`, current.location, server.location},
+		{`isOnline`, fmt.Sprintf("%t", current.isOnline),
+			fmt.Sprintf("%t", server.isOnline)},
+	} {
+		if field.old != field.new {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s",
+				field.name, field.old, field.new))
+		}
+	}
+	return changes
+}
+
+// nodeChanges returns the differences between the stored node and the
+// node read from the inventory
+func nodeChanges(current, node inventoryNode) []string {
+	changes := []string{}
+	for _, field := range []struct {
+		name, old, new string
+	}{
+		{`name`, current.name, node.name},
+		{`teamId`, current.teamID, node.teamID},
+		{`serverId`, current.serverID, node.serverID},
+		{`isOnline`, fmt.Sprintf("%t", current.isOnline),
+			fmt.Sprintf("%t", node.isOnline)},
+	} {
+		if field.old != field.new {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s",
+				field.name, field.old, field.new))
+		}
+	}
+	return changes
+}
+
+// sortedNodes returns a copy of nodes, sorted by asset ID
+func sortedNodes(nodes []inventoryNode) []inventoryNode {
+	sorted := append([]inventoryNode{}, nodes...)
+	sort.Slice(sorted, func(a, b int) bool {
+		return sorted[a].assetID < sorted[b].assetID
+	})
+	return sorted
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,120 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mjolnir42/soma/internal/config"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// inventorySource is implemented by the adapters that read the
+// external inventory
+type inventorySource interface {
+	fetch() (*proto.InventorySource, error)
+}
+
+// newInventorySource returns the source adapter selected by the
+// configuration
+func newInventorySource(conf *config.Inventory) inventorySource {
+	switch conf.Source {
+	case `http`:
+		return &inventoryHTTPSource{
+			url: conf.URL,
+			client: &http.Client{
+				Timeout: time.Duration(conf.Timeout) * time.Second,
+			},
+		}
+	default:
+		return &inventoryFileSource{path: conf.Path}
+	}
+}
+
+// inventoryFileSource reads the inventory from a local JSON file
+type inventoryFileSource struct {
+	path string
+}
+
+// fetch implements inventorySource
+func (f *inventoryFileSource) fetch() (*proto.InventorySource, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return decodeInventory(file)
+}
+
+// inventoryHTTPSource reads the inventory from a JSON document served
+// via HTTP
+type inventoryHTTPSource struct {
+	url    string
+	client *http.Client
+}
+
+// fetch implements inventorySource
+func (h *inventoryHTTPSource) fetch() (*proto.InventorySource, error) {
+	resp, err := h.client.Get(h.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Inventory source %s returned %s",
+			h.url, resp.Status)
+	}
+	return decodeInventory(resp.Body)
+}
+
+// decodeInventory parses an inventory document and rejects entries
+// that can not be reconciled
+func decodeInventory(r io.Reader) (*proto.InventorySource, error) {
+	inv := &proto.InventorySource{}
+	if err := json.NewDecoder(r).Decode(inv); err != nil {
+		return nil, fmt.Errorf("Invalid inventory document: %s",
+			err.Error())
+	}
+
+	servers := map[uint64]bool{}
+	for _, server := range inv.Servers {
+		switch {
+		case server.AssetID == 0:
+			return nil, fmt.Errorf(
+				"Inventory server %s has no assetId", server.Name)
+		case servers[server.AssetID]:
+			return nil, fmt.Errorf(
+				"Inventory contains server assetId %d twice",
+				server.AssetID)
+		}
+		servers[server.AssetID] = true
+	}
+
+	nodes := map[uint64]bool{}
+	for _, node := range inv.Nodes {
+		switch {
+		case node.AssetID == 0:
+			return nil, fmt.Errorf(
+				"Inventory node %s has no assetId", node.Name)
+		case nodes[node.AssetID]:
+			return nil, fmt.Errorf(
+				"Inventory contains node assetId %d twice",
+				node.AssetID)
+		}
+		nodes[node.AssetID] = true
+	}
+	return inv, nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,310 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjolnir42/soma/internal/config"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+const testInventoryDocument = `{
+  "servers": [
+    { "assetId": 1001, "name": "srv1001", "datacenter": "dc1",
+      "location": "rack 7", "isOnline": true },
+    { "assetId": 1002, "name": "srv1002", "datacenter": "dc1",
+      "location": "rack 8", "isOnline": true }
+  ],
+  "nodes": [
+    { "assetId": 5001, "name": "node5001", "team": "ops",
+      "serverAssetId": 1001, "isOnline": true }
+  ]
+}`
+
+func TestInventoryFileSource(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `soma-inventory`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, `inventory.json`)
+	if err = ioutil.WriteFile(path, []byte(testInventoryDocument),
+		0600); err != nil {
+		t.Fatal(err)
+	}
+
+	src := newInventorySource(&config.Inventory{
+		Source: `file`,
+		Path:   path,
+	})
+	inv, err := src.fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Servers) != 2 || len(inv.Nodes) != 1 {
+		t.Errorf("Expected 2 servers and 1 node, got %d and %d",
+			len(inv.Servers), len(inv.Nodes))
+	}
+	if inv.Nodes[0].ServerAssetID != 1001 {
+		t.Errorf("Expected node on server 1001, got %d",
+			inv.Nodes[0].ServerAssetID)
+	}
+}
+
+func TestInventoryHTTPSource(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != `/inventory` {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set(`Content-Type`, `application/json`)
+			w.Write([]byte(testInventoryDocument))
+		}))
+	defer ts.Close()
+
+	src := newInventorySource(&config.Inventory{
+		Source:  `http`,
+		URL:     ts.URL + `/inventory`,
+		Timeout: 5,
+	})
+	inv, err := src.fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Servers) != 2 {
+		t.Errorf("Expected 2 servers, got %d", len(inv.Servers))
+	}
+
+	src = newInventorySource(&config.Inventory{
+		Source:  `http`,
+		URL:     ts.URL + `/missing`,
+		Timeout: 5,
+	})
+	if _, err = src.fetch(); err == nil {
+		t.Errorf("Expected error for HTTP 404 response")
+	}
+}
+
+func TestInventoryDecodeDuplicate(t *testing.T) {
+	if _, err := decodeInventory(strings.NewReader(`{"servers": [
+		{"assetId": 7, "name": "a"}, {"assetId": 7, "name": "b"}]}`,
+	)); err == nil {
+		t.Errorf("Expected error for duplicate server assetId")
+	}
+}
+
+func TestInventoryDiff(t *testing.T) {
+	servers := []inventoryServer{
+		// unchanged
+		{id: `s1`, assetID: 1001, datacenter: `dc1`, location: `rack 7`,
+			name: `srv1001`, isOnline: true},
+		// moved to another rack
+		{id: `s2`, assetID: 1002, datacenter: `dc1`, location: `rack 1`,
+			name: `srv1002`, isOnline: true},
+		// vanished from the inventory
+		{id: `s3`, assetID: 1003, datacenter: `dc1`, location: `rack 9`,
+			name: `srv1003`, isOnline: true},
+	}
+	nodes := []inventoryNode{
+		{id: `n1`, assetID: 5001, name: `node5001`, teamID: `t1`,
+			serverID: `s1`, isOnline: true},
+		// runs on the vanished server, not in the inventory
+		{id: `n2`, assetID: 5002, name: `node5002`, teamID: `t1`,
+			serverID: `s3`, isOnline: true},
+	}
+	teams := map[string]string{`ops`: `t1`}
+
+	src := &proto.InventorySource{
+		Servers: []proto.InventoryServer{
+			{AssetID: 1001, Name: `srv1001`, Datacenter: `dc1`,
+				Location: `rack 7`, IsOnline: true},
+			{AssetID: 1002, Name: `srv1002`, Datacenter: `dc1`,
+				Location: `rack 8`, IsOnline: true},
+			{AssetID: 1004, Name: `srv1004`, Datacenter: `dc1`,
+				Location: `rack 2`, IsOnline: true},
+		},
+		Nodes: []proto.InventoryNode{
+			{AssetID: 5001, Name: `node5001`, Team: `ops`,
+				ServerAssetID: 1001, IsOnline: true},
+			{AssetID: 5003, Name: `node5003`, Team: `ops`,
+				ServerAssetID: 1004, IsOnline: true},
+			{AssetID: 5004, Name: `node5004`, Team: `unknown`,
+				ServerAssetID: 1001, IsOnline: true},
+		},
+	}
+
+	expected := []struct {
+		objectType, action string
+		assetID            uint64
+	}{
+		{`server`, `update`, 1002},
+		{`server`, `create`, 1004},
+		{`server`, `remove`, 1003},
+		{`node`, `create`, 5003},
+		{`node`, `conflict`, 5004},
+		{`node`, `orphaned`, 5002},
+		{`node`, `vanished`, 5002},
+	}
+
+	changes := inventoryDiff(src, servers, nodes, teams)
+	if len(changes) != len(expected) {
+		for _, c := range changes {
+			t.Logf("%s %s %d", c.drift.ObjectType, c.drift.Action,
+				c.drift.AssetID)
+		}
+		t.Fatalf("Expected %d changes, got %d",
+			len(expected), len(changes))
+	}
+	for i, exp := range expected {
+		drift := changes[i].drift
+		if drift.ObjectType != exp.objectType ||
+			drift.Action != exp.action || drift.AssetID != exp.assetID {
+			t.Errorf("Change %d: expected %s %s %d, got %s %s %d", i,
+				exp.objectType, exp.action, exp.assetID,
+				drift.ObjectType, drift.Action, drift.AssetID)
+		}
+	}
+
+	// the new node is placed on the newly created server
+	if changes[3].node.serverID != changes[1].server.id {
+		t.Errorf("New node not assigned to new server: %s != %s",
+			changes[3].node.serverID, changes[1].server.id)
+	}
+	if changes[0].drift.Changes[0] != `location: rack 1 -> rack 8` {
+		t.Errorf("Unexpected server update: %v",
+			changes[0].drift.Changes)
+	}
+}
+
+func TestInventoryDiffServersOnly(t *testing.T) {
+	servers := []inventoryServer{
+		{id: `s1`, assetID: 1001, name: `srv1001`, isOnline: true},
+	}
+	nodes := []inventoryNode{
+		{id: `n1`, assetID: 5001, name: `node5001`, teamID: `t1`,
+			serverID: `s1`, isOnline: true},
+	}
+
+	// without nodes in the source, nodes are not reconciled but
+	// still flagged if their server vanished
+	changes := inventoryDiff(&proto.InventorySource{}, servers, nodes,
+		map[string]string{})
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %d", len(changes))
+	}
+	if changes[0].drift.Action != `remove` ||
+		changes[1].drift.Action != `vanished` {
+		t.Errorf("Expected remove and vanished, got %s and %s",
+			changes[0].drift.Action, changes[1].drift.Action)
+	}
+}
+
+func TestInventoryDiffRestore(t *testing.T) {
+	servers := []inventoryServer{
+		// deleted in SOMA, back in the inventory
+		{id: `s1`, assetID: 1001, datacenter: `dc1`, location: `rack 7`,
+			name: `srv1001`, isDeleted: true},
+	}
+	nodes := []inventoryNode{
+		{id: `n1`, assetID: 5001, name: `node5001`, teamID: `t1`,
+			serverID: `s1`, isOnline: true},
+	}
+	src := &proto.InventorySource{
+		Servers: []proto.InventoryServer{
+			{AssetID: 1001, Name: `srv1001`, Datacenter: `dc1`,
+				Location: `rack 7`, IsOnline: true},
+		},
+		Nodes: []proto.InventoryNode{
+			{AssetID: 5001, Name: `node5001`, Team: `ops`,
+				ServerAssetID: 1001, IsOnline: true},
+		},
+	}
+
+	// the server is restored under its ID and the node on it is
+	// neither in conflict nor vanished
+	changes := inventoryDiff(src, servers, nodes,
+		map[string]string{`ops`: `t1`})
+	if len(changes) != 1 {
+		for _, c := range changes {
+			t.Logf("%s %s %d", c.drift.ObjectType, c.drift.Action,
+				c.drift.AssetID)
+		}
+		t.Fatalf("Expected 1 change, got %d", len(changes))
+	}
+	drift := changes[0].drift
+	if drift.Action != `restore` || drift.ID != `s1` ||
+		changes[0].server.id != `s1` || drift.Error != `` {
+		t.Errorf("Unexpected restore: %+v", drift)
+	}
+	if len(drift.Changes) != 2 ||
+		drift.Changes[0] != `isDeleted: true -> false` ||
+		drift.Changes[1] != `isOnline: false -> true` {
+		t.Errorf("Unexpected restore changes: %v", drift.Changes)
+	}
+}
+
+func TestInventoryRefusal(t *testing.T) {
+	servers := []inventoryServer{
+		{id: nullServerID, assetID: 0, name: `soma-null-server`},
+		{id: `s1`, assetID: 1001, name: `srv1001`},
+		{id: `s2`, assetID: 1002, name: `srv1002`},
+		{id: `s3`, assetID: 1003, name: `srv1003`},
+		{id: `s4`, assetID: 1004, name: `srv1004`, isDeleted: true},
+	}
+	full := &proto.InventorySource{
+		Servers: []proto.InventoryServer{
+			{AssetID: 0, Name: `soma-null-server`},
+			{AssetID: 1001, Name: `srv1001`},
+			{AssetID: 1002, Name: `srv1002`},
+			{AssetID: 1003, Name: `srv1003`},
+		},
+	}
+	partial := &proto.InventorySource{
+		Servers: []proto.InventoryServer{
+			{AssetID: 0, Name: `soma-null-server`},
+			{AssetID: 1001, Name: `srv1001`},
+		},
+	}
+	empty := &proto.InventorySource{}
+
+	tests := []struct {
+		name      string
+		src       *proto.InventorySource
+		servers   []inventoryServer
+		maxRemove uint64
+		refused   bool
+	}{
+		{`complete source`, full, servers, 1, false},
+		{`within limit`, partial, servers, 2, false},
+		{`above limit`, partial, servers, 1, true},
+		{`empty source`, empty, servers, 10, true},
+		// nothing to lose on a fresh installation
+		{`empty source and database`, empty, servers[:1], 10, false},
+	}
+
+	for _, test := range tests {
+		changes := inventoryDiff(test.src, test.servers, nil,
+			map[string]string{})
+		reason := inventoryRefusal(test.src, test.servers, changes,
+			test.maxRemove)
+		if (reason != ``) != test.refused {
+			t.Errorf("%s: unexpected refusal: %q", test.name, reason)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -15,6 +15,7 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/mjolnir42/soma/internal/handler"
 	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
 )
 
 // XXX BUG self|job|wait permission can currently block on every known job UUID
@@ -25,18 +26,30 @@ type JobBlock struct {
 	Input     chan msg.Request
 	Shutdown  chan struct{}
 	Notify    chan string
-	blockList map[string][]blockSpec
+	blockList map[string][]*blockSpec
 	jobDone   map[string]time.Time
 	appLog    *logrus.Logger
 	reqLog    *logrus.Logger
 	errLog    *logrus.Logger
 }
 
-// blockSpec identifies a job that a client would like to block on
+// blockSpec identifies the jobs that a client would like to block on
 type blockSpec struct {
-	JobID string
-	RecvT time.Time
-	Reply chan msg.Result
+	Pending  map[string]struct{}
+	Mode     string
+	RecvT    time.Time
+	Deadline time.Time
+	Reply    chan msg.Result
+	released bool
+}
+
+// release unblocks the client waiting on bs
+func (bs *blockSpec) release() {
+	if bs.released {
+		return
+	}
+	bs.released = true
+	close(bs.Reply)
 }
 
 // newJobBlock returns a new JobBlock handler with input and notify
@@ -46,7 +59,7 @@ func newJobBlock(length int) (j *JobBlock) {
 	j.Input = make(chan msg.Request, length)
 	j.Notify = make(chan string, length)
 	j.Shutdown = make(chan struct{})
-	j.blockList = make(map[string][]blockSpec)
+	j.blockList = make(map[string][]*blockSpec)
 	j.jobDone = make(map[string]time.Time)
 	return
 }
@@ -86,7 +99,7 @@ func (j *JobBlock) PriorityIntake() chan msg.Request {
 func (j *JobBlock) Run() {
 	tock := time.Tick(1 * time.Minute)
 	j.jobDone = make(map[string]time.Time)
-	j.blockList = make(map[string][]blockSpec)
+	j.blockList = make(map[string][]*blockSpec)
 
 runloop:
 	for {
@@ -102,8 +115,8 @@ runloop:
 			// clean all block specifications
 			for jID := range j.blockList {
 				// disconnect all clients waiting on that job
-				for i := range j.blockList[jID] {
-					close(j.blockList[jID][i].Reply)
+				for _, bs := range j.blockList[jID] {
+					bs.release()
 				}
 				delete(j.blockList, jID)
 			}
@@ -112,29 +125,20 @@ runloop:
 			// a job completion notification was received
 
 			j.jobDone[jID] = time.Now().UTC()
-			// unblock all clients blocking on this Job
+			// unblock all clients whose wait condition is now met.
+			// Clients waiting on any job remain listed for their
+			// other jobs until those finish or the periodic cleanup
+			// removes them.
 			for _, bs := range j.blockList[jID] {
-				close(bs.Reply)
+				delete(bs.Pending, jID)
+				if bs.Mode == proto.JobWaitAny || len(bs.Pending) == 0 {
+					bs.release()
+				}
 			}
 			delete(j.blockList, jID)
 		case rq := <-j.Input:
 			// a new block request was received
-			bs := blockSpec{
-				JobID: rq.Job.ID,
-				Reply: rq.Reply,
-			}
-
-			// unblock immediate if the Job has already finished
-			if _, ok := j.jobDone[bs.JobID]; ok {
-				close(bs.Reply)
-			} else {
-				// register request to wait for this Job
-				bs.RecvT = time.Now().UTC()
-				if _, ok := j.blockList[bs.JobID]; !ok {
-					j.blockList[bs.JobID] = []blockSpec{}
-				}
-				j.blockList[bs.JobID] = append(j.blockList[bs.JobID], bs)
-			}
+			j.block(&rq)
 		case <-tock:
 			// time for a periodic cleanup
 
@@ -145,15 +149,18 @@ runloop:
 					delete(j.jobDone, jID)
 				}
 			}
-			// disconnect active blocks that have waited for more than 5
-			// minutes
+			// disconnect active blocks that have exceeded their
+			// deadline and drop blocks that were already released
+			now := time.Now().UTC()
 			for jID := range j.blockList {
-				newList := []blockSpec{}
-				for i := range j.blockList[jID] {
-					if time.Since(j.blockList[jID][i].RecvT) < (5 * time.Minute) {
-						newList = append(newList, j.blockList[jID][i])
-					} else {
-						close(j.blockList[jID][i].Reply)
+				newList := []*blockSpec{}
+				for _, bs := range j.blockList[jID] {
+					switch {
+					case bs.released:
+					case now.After(bs.Deadline):
+						bs.release()
+					default:
+						newList = append(newList, bs)
 					}
 				}
 				if len(newList) > 0 {
@@ -166,6 +173,51 @@ runloop:
 	}
 }
 
+// block registers the wait request rq. Requests for a single job
+// specify it via rq.Job.ID, requests for multiple jobs use
+// rq.JobWait.
+func (j *JobBlock) block(rq *msg.Request) {
+	bs := &blockSpec{
+		Pending: make(map[string]struct{}),
+		Mode:    rq.JobWait.Mode,
+		RecvT:   time.Now().UTC(),
+		Reply:   rq.Reply,
+	}
+	if bs.Mode == `` {
+		bs.Mode = proto.JobWaitAll
+	}
+	bs.Deadline = bs.RecvT.Add(5 * time.Minute)
+	if rq.JobWait.Timeout > 0 {
+		bs.Deadline = bs.RecvT.Add(
+			time.Duration(rq.JobWait.Timeout) * time.Second)
+	}
+
+	idList := rq.JobWait.IDList
+	if len(idList) == 0 {
+		idList = []string{rq.Job.ID}
+	}
+
+	done := false
+	for _, jID := range idList {
+		if _, ok := j.jobDone[jID]; ok {
+			done = true
+			continue
+		}
+		bs.Pending[jID] = struct{}{}
+	}
+
+	// unblock immediate if the wait condition is already met
+	if len(bs.Pending) == 0 || (bs.Mode == proto.JobWaitAny && done) {
+		bs.release()
+		return
+	}
+
+	// register request to wait for the pending jobs
+	for jID := range bs.Pending {
+		j.blockList[jID] = append(j.blockList[jID], bs)
+	}
+}
+
 // ShutdownNow signals the handler to shutdown
 func (j *JobBlock) ShutdownNow() {
 	close(j.Shutdown)
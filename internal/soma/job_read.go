@@ -10,6 +10,7 @@ package soma
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -224,6 +225,7 @@ func (r *JobRead) show(q *msg.Request, mr *msg.Result) {
 		err                                                error
 		jobID, jobType, jobStatus, jobResult, repositoryID string
 		jobError, jobSpec, teamID, userID                  string
+		jobWarnings                                        []byte
 		jobSerial                                          int
 		jobQueued                                          time.Time
 		jobStarted, jobFinished                            pq.NullTime
@@ -244,6 +246,7 @@ func (r *JobRead) show(q *msg.Request, mr *msg.Result) {
 		&jobStarted,
 		&jobFinished,
 		&jobError,
+		&jobWarnings,
 		&jobSpec,
 	); err == sql.ErrNoRows {
 		mr.NotFound(err, q.Section)
@@ -263,6 +266,10 @@ func (r *JobRead) show(q *msg.Request, mr *msg.Result) {
 		TeamID:       teamID,
 		Error:        jobError,
 	}
+	if err = json.Unmarshal(jobWarnings, &job.Warnings); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
 	job.TsQueued = jobQueued.Format(msg.RFC3339Milli)
 	if jobStarted.Valid {
 		job.TsStarted = jobStarted.Time.Format(msg.RFC3339Milli)
@@ -286,6 +293,7 @@ func (r *JobRead) search(q *msg.Request, mr *msg.Result) {
 		err                                                error
 		jobID, jobType, jobStatus, jobResult, repositoryID string
 		userID, teamID, jobError, jobSpec, idList          string
+		jobWarnings                                        []byte
 		jobSerial                                          int
 		jobQueued                                          time.Time
 		jobStarted, jobFinished                            pq.NullTime
@@ -313,6 +321,7 @@ func (r *JobRead) search(q *msg.Request, mr *msg.Result) {
 			&jobStarted,
 			&jobFinished,
 			&jobError,
+			&jobWarnings,
 			&jobSpec,
 		); err != nil {
 			rows.Close()
@@ -330,6 +339,11 @@ func (r *JobRead) search(q *msg.Request, mr *msg.Result) {
 			TeamID:       teamID,
 			Error:        jobError,
 		}
+		if err = json.Unmarshal(jobWarnings, &job.Warnings); err != nil {
+			rows.Close()
+			mr.ServerError(err, q.Section)
+			return
+		}
 		job.TsQueued = jobQueued.Format(msg.RFC3339Milli)
 		if jobStarted.Valid {
 			job.TsStarted = jobStarted.Time.Format(msg.RFC3339Milli)
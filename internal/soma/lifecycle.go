@@ -18,6 +18,7 @@ import (
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/stmt"
 	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
 	"gopkg.in/resty.v0"
 )
 
@@ -34,10 +35,12 @@ type LifeCycle struct {
 	stmtDeadlock      *sql.Stmt
 	stmtReschedule    *sql.Stmt
 	stmtSetNotify     *sql.Stmt
+	stmtUnassignDue   *sql.Stmt
 	appLog            *logrus.Logger
 	reqLog            *logrus.Logger
 	errLog            *logrus.Logger
 	pokers            map[string]chan string
+	unassigned        map[string]time.Time
 	soma              *Soma
 }
 
@@ -79,6 +82,7 @@ func (lc *LifeCycle) RegisterRequests(hmap *handler.Map) {
 func (lc *LifeCycle) Run() {
 	var err error
 	lc.pokers = make(map[string]chan string)
+	lc.unassigned = make(map[string]time.Time)
 
 	lc.tick = time.NewTicker(
 		time.Duration(lc.soma.conf.LifeCycleTick) * time.Second,
@@ -93,6 +97,7 @@ func (lc *LifeCycle) Run() {
 		stmt.LifecycleDeadLockResolver:                 &lc.stmtDeadlock,
 		stmt.LifecycleRescheduleDeployments:            &lc.stmtReschedule,
 		stmt.LifecycleSetNotified:                      &lc.stmtSetNotify,
+		stmt.LifecycleUnassignDue:                      &lc.stmtUnassignDue,
 	} {
 		if *prepStmt, err = lc.conn.Prepare(statement); err != nil {
 			lc.errLog.Fatal(`lifecycle`, err, stmt.Name(statement))
@@ -120,6 +125,7 @@ runloop:
 			}
 			lc.deadlockResolver()
 			lc.handleDelete()
+			lc.unassignDue()
 			if !lc.soma.conf.NoPoke {
 				lc.poke()
 			}
@@ -340,6 +346,82 @@ func (lc *LifeCycle) deadlockResolver() {
 	}
 }
 
+// unassignDue unassigns nodes whose lifecycle state schedules their
+// unassignment and whose grace period has passed. The unassignment
+// is submitted as job on behalf of the user that moved the node into
+// the lifecycle state.
+func (lc *LifeCycle) unassignDue() {
+	var (
+		rows                                    *sql.Rows
+		nodeID, bucketID, repositoryID, account string
+		err                                     error
+	)
+
+	if rows, err = lc.stmtUnassignDue.Query(); err != nil {
+		lc.errLog.Println(`LifeCycle.unassignDue()`, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&nodeID,
+			&bucketID,
+			&repositoryID,
+			&account,
+		); err != nil {
+			lc.errLog.Println(`LifeCycle.unassignDue()`, err)
+			return
+		}
+
+		// the node remains assigned until the job has been
+		// processed, do not submit it again while it is queued
+		if submitted, ok := lc.unassigned[nodeID]; ok &&
+			time.Since(submitted) < 10*time.Minute {
+			continue
+		}
+		lc.unassigned[nodeID] = time.Now().UTC()
+
+		request := msg.Request{
+			ID:         uuid.Must(uuid.NewV4()),
+			Section:    msg.SectionNodeConfig,
+			Action:     msg.ActionUnassign,
+			RemoteAddr: `lifecycle`,
+			AuthUser:   account,
+			Reply:      make(chan msg.Result, 1),
+			Repository: proto.Repository{ID: repositoryID},
+			Bucket:     proto.Bucket{ID: bucketID},
+			Node: proto.Node{
+				ID: nodeID,
+				Config: &proto.NodeConfig{
+					RepositoryID: repositoryID,
+					BucketID:     bucketID,
+				},
+			},
+		}
+		lc.appLog.Printf("LifeCycle: unassigning node %s from bucket %s",
+			nodeID, bucketID)
+
+		go func(q msg.Request) {
+			lc.soma.handlerMap.MustLookup(&q).Intake() <- q
+			if result := <-q.Reply; result.Error != nil {
+				lc.errLog.Printf("LifeCycle: failed to unassign node %s: %s",
+					q.Node.ID, result.Error.Error())
+			}
+		}(request)
+	}
+	if err = rows.Err(); err != nil {
+		lc.errLog.Println(`LifeCycle.unassignDue()`, err)
+	}
+
+	// forget nodes submitted long ago
+	for nodeID, submitted := range lc.unassigned {
+		if time.Since(submitted) >= 10*time.Minute {
+			delete(lc.unassigned, nodeID)
+		}
+	}
+}
+
 // handleDelete checks for check instance configurations that are
 // currently provisioned whose check instance has been deleted and
 // triggers their deprovisioning
@@ -223,6 +223,7 @@ func (r *NodeRead) show(q *msg.Request, mr *msg.Result) {
 		repositoryID, bucketID, nodeState      string
 		nodeOnline, nodeDeleted                bool
 		nodeAsset                              int
+		nodeLifecycle                          sql.NullString
 		node                                   proto.Node
 		tx                                     *sql.Tx
 		checkConfigs                           *[]proto.CheckConfig
@@ -239,6 +240,7 @@ func (r *NodeRead) show(q *msg.Request, mr *msg.Result) {
 		&nodeState,
 		&nodeOnline,
 		&nodeDeleted,
+		&nodeLifecycle,
 	); err == sql.ErrNoRows {
 		mr.NotFound(err, q.Section)
 		return
@@ -252,6 +254,7 @@ func (r *NodeRead) show(q *msg.Request, mr *msg.Result) {
 		TeamID:    nodeTeam,
 		ServerID:  nodeServer,
 		State:     nodeState,
+		Lifecycle: nodeLifecycle.String,
 		IsOnline:  nodeOnline,
 		IsDeleted: nodeDeleted,
 	}
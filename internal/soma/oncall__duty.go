@@ -0,0 +1,213 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// oncallDuty holds the prepared statements required to calculate
+// who is on duty for an oncall duty team. Handlers embed it and
+// prepare the statements alongside their own.
+type oncallDuty struct {
+	stmtRotation *sql.Stmt
+	stmtMembers  *sql.Stmt
+	stmtOverride *sql.Stmt
+}
+
+// onDuty returns who is on duty for oncallID at time at. It returns
+// nil if nobody is on duty, ie. the team has neither a rotation
+// schedule nor an active override.
+func (o *oncallDuty) onDuty(oncallID string, at time.Time) (*proto.OncallDuty, error) {
+	var (
+		err             error
+		startsAt, endAt time.Time
+		rotation        *proto.OncallRotation
+	)
+	duty := &proto.OncallDuty{
+		At: at.UTC().Format(msg.RFC3339Milli),
+	}
+
+	// overrides take precedence over the rotation schedule
+	if err = o.stmtOverride.QueryRow(
+		oncallID,
+		at.UTC(),
+	).Scan(
+		&duty.OverrideID,
+		&duty.UserID,
+		&duty.UserName,
+		&startsAt,
+		&endAt,
+	); err == nil {
+		duty.From = startsAt.UTC().Format(msg.RFC3339Milli)
+		duty.Until = endAt.UTC().Format(msg.RFC3339Milli)
+		return duty, nil
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if rotation, err = o.rotation(oncallID); err != nil {
+		return nil, err
+	} else if rotation == nil {
+		return nil, nil
+	}
+
+	member, from, until, ok, err := rotationShift(rotation, at)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+	duty.UserID = member.UserID
+	duty.UserName = member.UserName
+	duty.From = from.UTC().Format(msg.RFC3339Milli)
+	duty.Until = until.UTC().Format(msg.RFC3339Milli)
+	return duty, nil
+}
+
+// setDuty adds who is currently on duty to the oncall block of a
+// deployment. Errors are only logged, the deployment is still valid
+// without this information.
+func (o *oncallDuty) setDuty(depl *proto.Deployment, errLog *logrus.Logger) {
+	if depl.Oncall == nil || depl.Oncall.ID == `` {
+		return
+	}
+
+	duty, err := o.onDuty(depl.Oncall.ID, time.Now().UTC())
+	if err != nil {
+		errLog.Printf("Failed to calculate oncall duty for %s: %s",
+			depl.Oncall.ID, err.Error())
+		return
+	}
+	depl.Oncall.Duty = duty
+}
+
+// rotation loads the rotation schedule of oncallID, which is nil if
+// the team has none
+func (o *oncallDuty) rotation(oncallID string) (*proto.OncallRotation, error) {
+	var (
+		err       error
+		rows      *sql.Rows
+		startDate time.Time
+	)
+	rotation := &proto.OncallRotation{
+		Members: []proto.OncallMember{},
+	}
+
+	if err = o.stmtRotation.QueryRow(
+		oncallID,
+	).Scan(
+		&rotation.Period,
+		&rotation.Handover,
+		&startDate,
+		&rotation.TimeZone,
+	); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	rotation.Start = startDate.Format(`2006-01-02`)
+
+	if rows, err = o.stmtMembers.Query(
+		oncallID,
+	); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		member := proto.OncallMember{}
+		if err = rows.Scan(
+			&member.UserID,
+			&member.UserName,
+		); err != nil {
+			return nil, err
+		}
+		rotation.Members = append(rotation.Members, member)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return rotation, nil
+}
+
+// rotationShift calculates the shift of rotation that contains time
+// at. Shifts start at the handover time in the rotation's time zone,
+// so they follow daylight saving time changes. ok is false if at is
+// before the first shift or the rotation has no members.
+func rotationShift(rotation *proto.OncallRotation, at time.Time) (
+	member proto.OncallMember, from, until time.Time, ok bool,
+	err error) {
+	var (
+		loc      *time.Location
+		start    time.Time
+		handover time.Time
+		length   int
+	)
+
+	switch rotation.Period {
+	case `daily`:
+		length = 1
+	case `weekly`:
+		length = 7
+	default:
+		err = fmt.Errorf("Invalid rotation period: %s", rotation.Period)
+		return
+	}
+	if loc, err = time.LoadLocation(rotation.TimeZone); err != nil {
+		return
+	}
+	if start, err = time.Parse(`2006-01-02`, rotation.Start); err != nil {
+		return
+	}
+	if handover, err = time.Parse(`15:04`, rotation.Handover); err != nil {
+		return
+	}
+	if len(rotation.Members) == 0 {
+		return
+	}
+
+	// shiftStart returns the begin of shift number n
+	shiftStart := func(n int) time.Time {
+		return time.Date(
+			start.Year(), start.Month(), start.Day()+n*length,
+			handover.Hour(), handover.Minute(), 0, 0, loc,
+		)
+	}
+	if at.Before(shiftStart(0)) {
+		return
+	}
+
+	// count calendar days in the rotation's time zone, shifts that
+	// did not hand over yet today belong to the previous day
+	local := at.In(loc)
+	days := int(time.Date(
+		local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC,
+	).Sub(start).Hours() / 24)
+	if local.Before(time.Date(
+		local.Year(), local.Month(), local.Day(),
+		handover.Hour(), handover.Minute(), 0, 0, loc,
+	)) {
+		days--
+	}
+
+	shift := days / length
+	member = rotation.Members[shift%len(rotation.Members)]
+	from = shiftStart(shift)
+	until = shiftStart(shift + 1)
+	ok = true
+	return
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,198 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func TestRotationShift(t *testing.T) {
+	loc, err := time.LoadLocation(`Europe/Berlin`)
+	if err != nil {
+		t.Skip(err)
+	}
+	members := []proto.OncallMember{
+		{UserID: `1`, UserName: `alice`},
+		{UserID: `2`, UserName: `bob`},
+		{UserID: `3`, UserName: `carol`},
+	}
+	berlin := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2019, month, day, hour, min, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name   string
+		period string
+		start  string
+		at     time.Time
+		ok     bool
+		member string
+		from   time.Time
+		until  time.Time
+	}{
+		{
+			name:   `daily, before the rotation start date`,
+			period: `daily`, start: `2019-03-25`,
+			at: berlin(time.March, 24, 12, 0),
+		},
+		{
+			name:   `daily, before the first handover`,
+			period: `daily`, start: `2019-03-25`,
+			at: berlin(time.March, 25, 8, 59),
+		},
+		{
+			name:   `daily, first handover`,
+			period: `daily`, start: `2019-03-25`,
+			at: berlin(time.March, 25, 9, 0), ok: true, member: `alice`,
+			from:  berlin(time.March, 25, 9, 0),
+			until: berlin(time.March, 26, 9, 0),
+		},
+		{
+			name:   `daily, last minute before handover`,
+			period: `daily`, start: `2019-03-25`,
+			at: berlin(time.March, 26, 8, 59), ok: true, member: `alice`,
+			from:  berlin(time.March, 25, 9, 0),
+			until: berlin(time.March, 26, 9, 0),
+		},
+		{
+			name:   `daily, second handover`,
+			period: `daily`, start: `2019-03-25`,
+			at: berlin(time.March, 26, 9, 0), ok: true, member: `bob`,
+			from:  berlin(time.March, 26, 9, 0),
+			until: berlin(time.March, 27, 9, 0),
+		},
+		{
+			name:   `daily, members wrap around`,
+			period: `daily`, start: `2019-03-25`,
+			at: berlin(time.March, 28, 23, 0), ok: true, member: `alice`,
+			from:  berlin(time.March, 28, 9, 0),
+			until: berlin(time.March, 29, 9, 0),
+		},
+		{
+			name:   `daily, shift across the start of DST is 23 hours`,
+			period: `daily`, start: `2019-03-30`,
+			at: berlin(time.March, 31, 4, 0), ok: true, member: `alice`,
+			from:  berlin(time.March, 30, 9, 0),
+			until: berlin(time.March, 31, 9, 0),
+		},
+		{
+			name:   `daily, handover after the start of DST`,
+			period: `daily`, start: `2019-03-30`,
+			at: berlin(time.March, 31, 9, 0), ok: true, member: `bob`,
+			from:  berlin(time.March, 31, 9, 0),
+			until: berlin(time.April, 1, 9, 0),
+		},
+		{
+			name:   `daily, shift across the end of DST is 25 hours`,
+			period: `daily`, start: `2019-10-26`,
+			at: berlin(time.October, 27, 8, 59), ok: true, member: `alice`,
+			from:  berlin(time.October, 26, 9, 0),
+			until: berlin(time.October, 27, 9, 0),
+		},
+		{
+			name:   `weekly, before the first handover`,
+			period: `weekly`, start: `2019-03-25`,
+			at: berlin(time.March, 25, 8, 59),
+		},
+		{
+			name:   `weekly, within the first week`,
+			period: `weekly`, start: `2019-03-25`,
+			at: berlin(time.March, 29, 12, 0), ok: true, member: `alice`,
+			from:  berlin(time.March, 25, 9, 0),
+			until: berlin(time.April, 1, 9, 0),
+		},
+		{
+			name:   `weekly, last minute before handover`,
+			period: `weekly`, start: `2019-03-25`,
+			at: berlin(time.April, 1, 8, 59), ok: true, member: `alice`,
+			from:  berlin(time.March, 25, 9, 0),
+			until: berlin(time.April, 1, 9, 0),
+		},
+		{
+			name:   `weekly, second handover`,
+			period: `weekly`, start: `2019-03-25`,
+			at: berlin(time.April, 1, 9, 0), ok: true, member: `bob`,
+			from:  berlin(time.April, 1, 9, 0),
+			until: berlin(time.April, 8, 9, 0),
+		},
+		{
+			name:   `weekly, members wrap around`,
+			period: `weekly`, start: `2019-03-25`,
+			at: berlin(time.April, 15, 10, 0), ok: true, member: `alice`,
+			from:  berlin(time.April, 15, 9, 0),
+			until: berlin(time.April, 22, 9, 0),
+		},
+	}
+
+	for _, test := range tests {
+		rotation := &proto.OncallRotation{
+			Period:   test.period,
+			Handover: `09:00`,
+			Start:    test.start,
+			TimeZone: `Europe/Berlin`,
+			Members:  members,
+		}
+		// the shift must not depend on the time zone of at
+		member, from, until, ok, err := rotationShift(rotation,
+			test.at.UTC())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err.Error())
+			continue
+		}
+		if ok != test.ok {
+			t.Errorf("%s: expected ok %t, got %t", test.name, test.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if member.UserName != test.member || !from.Equal(test.from) ||
+			!until.Equal(test.until) {
+			t.Errorf("%s: expected %s from %s until %s, got %s from %s"+
+				" until %s", test.name, test.member, test.from,
+				test.until, member.UserName, from, until)
+		}
+	}
+}
+
+func TestRotationShiftInvalid(t *testing.T) {
+	at := time.Date(2019, time.April, 1, 12, 0, 0, 0, time.UTC)
+	rotation := &proto.OncallRotation{
+		Period:   `daily`,
+		Handover: `09:00`,
+		Start:    `2019-03-25`,
+		TimeZone: `UTC`,
+	}
+
+	// a rotation without members has nobody on duty
+	if _, _, _, ok, err := rotationShift(rotation, at); ok || err != nil {
+		t.Errorf("Expected nobody on duty, got ok %t, error %v", ok, err)
+	}
+
+	rotation.Members = []proto.OncallMember{{UserName: `alice`}}
+	for _, broken := range []proto.OncallRotation{
+		{Period: `monthly`, Handover: `09:00`, Start: `2019-03-25`,
+			TimeZone: `UTC`},
+		{Period: `daily`, Handover: `9am`, Start: `2019-03-25`,
+			TimeZone: `UTC`},
+		{Period: `daily`, Handover: `09:00`, Start: `25.03.2019`,
+			TimeZone: `UTC`},
+		{Period: `daily`, Handover: `09:00`, Start: `2019-03-25`,
+			TimeZone: `Mars/Olympus_Mons`},
+	} {
+		broken.Members = rotation.Members
+		if _, _, _, _, err := rotationShift(&broken, at); err == nil {
+			t.Errorf("Expected error for rotation %+v", broken)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
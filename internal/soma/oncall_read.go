@@ -11,6 +11,7 @@ package soma // import "github.com/mjolnir42/soma/internal/soma"
 
 import (
 	"database/sql"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -23,17 +24,19 @@ import (
 
 // OncallRead handles read requests for oncall
 type OncallRead struct {
-	Input       chan msg.Request
-	Shutdown    chan struct{}
-	handlerName string
-	conn        *sql.DB
-	stmtList    *sql.Stmt
-	stmtMembers *sql.Stmt
-	stmtSearch  *sql.Stmt
-	stmtShow    *sql.Stmt
-	appLog      *logrus.Logger
-	reqLog      *logrus.Logger
-	errLog      *logrus.Logger
+	Input         chan msg.Request
+	Shutdown      chan struct{}
+	handlerName   string
+	conn          *sql.DB
+	stmtList      *sql.Stmt
+	stmtMembers   *sql.Stmt
+	stmtOverrides *sql.Stmt
+	stmtSearch    *sql.Stmt
+	stmtShow      *sql.Stmt
+	duty          oncallDuty
+	appLog        *logrus.Logger
+	reqLog        *logrus.Logger
+	errLog        *logrus.Logger
 }
 
 // newOncallRead return a new OncallRead handler with input buffer of length
@@ -57,6 +60,7 @@ func (r *OncallRead) Register(c *sql.DB, l ...*logrus.Logger) {
 // it processes
 func (r *OncallRead) RegisterRequests(hmap *handler.Map) {
 	for _, action := range []string{
+		msg.ActionCurrent,
 		msg.ActionList,
 		msg.ActionMemberList,
 		msg.ActionSearch,
@@ -81,10 +85,14 @@ func (r *OncallRead) Run() {
 	var err error
 
 	for statement, prepStmt := range map[string]**sql.Stmt{
-		stmt.OncallList:       &r.stmtList,
-		stmt.OncallMemberList: &r.stmtMembers,
-		stmt.OncallSearch:     &r.stmtSearch,
-		stmt.OncallShow:       &r.stmtShow,
+		stmt.OncallList:            &r.stmtList,
+		stmt.OncallMemberList:      &r.stmtMembers,
+		stmt.OncallOverrideActive:  &r.duty.stmtOverride,
+		stmt.OncallOverrideList:    &r.stmtOverrides,
+		stmt.OncallRotationMembers: &r.duty.stmtMembers,
+		stmt.OncallRotationShow:    &r.duty.stmtRotation,
+		stmt.OncallSearch:          &r.stmtSearch,
+		stmt.OncallShow:            &r.stmtShow,
 	} {
 		if *prepStmt, err = r.conn.Prepare(statement); err != nil {
 			r.errLog.Fatal(`oncall`, err, stmt.Name(statement))
@@ -111,6 +119,8 @@ func (r *OncallRead) process(q *msg.Request) {
 	logRequest(r.reqLog, q)
 
 	switch q.Action {
+	case msg.ActionCurrent:
+		r.current(q, &result)
 	case msg.ActionList:
 		r.list(q, &result)
 	case msg.ActionSearch:
@@ -196,6 +206,8 @@ func (r *OncallRead) show(q *msg.Request, mr *msg.Result) {
 		dictID, dictName, createdBy string
 		createdAt                   time.Time
 		oncallNumber                int
+		rotation                    *proto.OncallRotation
+		overrides                   *[]proto.OncallOverride
 		err                         error
 	)
 
@@ -216,10 +228,22 @@ func (r *OncallRead) show(q *msg.Request, mr *msg.Result) {
 		mr.ServerError(err, q.Section)
 		return
 	}
+
+	if rotation, err = r.duty.rotation(oncallID); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if overrides, err = r.overrides(oncallID); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
 	mr.Oncall = append(mr.Oncall, proto.Oncall{
-		ID:     oncallID,
-		Name:   oncallName,
-		Number: strconv.Itoa(oncallNumber),
+		ID:        oncallID,
+		Name:      oncallName,
+		Number:    strconv.Itoa(oncallNumber),
+		Rotation:  rotation,
+		Overrides: overrides,
 		Details: &proto.OncallDetails{
 			Creation: &proto.DetailsCreation{
 				CreatedAt: createdAt.Format(msg.RFC3339Milli),
@@ -273,6 +297,84 @@ func (r *OncallRead) members(q *msg.Request, mr *msg.Result) {
 	mr.OK()
 }
 
+// current returns who is on duty for an oncall duty at the requested
+// time
+func (r *OncallRead) current(q *msg.Request, mr *msg.Result) {
+	var (
+		at   time.Time
+		duty *proto.OncallDuty
+		err  error
+	)
+
+	at = time.Now().UTC()
+	if q.Oncall.Duty != nil && q.Oncall.Duty.At != `` {
+		if at, err = time.Parse(time.RFC3339, q.Oncall.Duty.At); err != nil {
+			mr.BadRequest(err, q.Section)
+			return
+		}
+	}
+
+	if duty, err = r.duty.onDuty(q.Oncall.ID, at); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if duty == nil {
+		mr.NotFound(fmt.Errorf(
+			"Nobody is on duty for oncall %s at %s",
+			q.Oncall.ID, at.UTC().Format(time.RFC3339),
+		), q.Section)
+		return
+	}
+
+	mr.Oncall = append(mr.Oncall, proto.Oncall{
+		ID:   q.Oncall.ID,
+		Duty: duty,
+	})
+	mr.OK()
+}
+
+// overrides returns all current and future overrides of an oncall
+// duty
+func (r *OncallRead) overrides(oncallID string) (*[]proto.OncallOverride, error) {
+	var (
+		startsAt, endsAt time.Time
+		rows             *sql.Rows
+		err              error
+	)
+	overrides := []proto.OncallOverride{}
+
+	if rows, err = r.stmtOverrides.Query(
+		oncallID,
+		time.Now().UTC(),
+	); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		override := proto.OncallOverride{}
+		if err = rows.Scan(
+			&override.ID,
+			&override.UserID,
+			&override.UserName,
+			&startsAt,
+			&endsAt,
+		); err != nil {
+			return nil, err
+		}
+		override.Start = startsAt.UTC().Format(msg.RFC3339Milli)
+		override.End = endsAt.UTC().Format(msg.RFC3339Milli)
+		overrides = append(overrides, override)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(overrides) == 0 {
+		return nil, nil
+	}
+	return &overrides, nil
+}
+
 // ShutdownNow signals the handler to shut down
 func (r *OncallRead) ShutdownNow() {
 	close(r.Shutdown)
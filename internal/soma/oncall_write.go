@@ -11,7 +11,9 @@ package soma // import "github.com/mjolnir42/soma/internal/soma"
 
 import (
 	"database/sql"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/mjolnir42/soma/internal/handler"
@@ -22,18 +24,20 @@ import (
 
 // OncallWrite handles write requests for oncall
 type OncallWrite struct {
-	Input        chan msg.Request
-	Shutdown     chan struct{}
-	handlerName  string
-	conn         *sql.DB
-	stmtAdd      *sql.Stmt
-	stmtAssign   *sql.Stmt
-	stmtRemove   *sql.Stmt
-	stmtUnassign *sql.Stmt
-	stmtUpdate   *sql.Stmt
-	appLog       *logrus.Logger
-	reqLog       *logrus.Logger
-	errLog       *logrus.Logger
+	Input              chan msg.Request
+	Shutdown           chan struct{}
+	handlerName        string
+	conn               *sql.DB
+	stmtAdd            *sql.Stmt
+	stmtAssign         *sql.Stmt
+	stmtOverrideAdd    *sql.Stmt
+	stmtOverrideRemove *sql.Stmt
+	stmtRemove         *sql.Stmt
+	stmtUnassign       *sql.Stmt
+	stmtUpdate         *sql.Stmt
+	appLog             *logrus.Logger
+	reqLog             *logrus.Logger
+	errLog             *logrus.Logger
 }
 
 // newOncallWrite return a new OncallWrite handler with input buffer of
@@ -61,7 +65,11 @@ func (w *OncallWrite) RegisterRequests(hmap *handler.Map) {
 		msg.ActionAdd,
 		msg.ActionMemberAssign,
 		msg.ActionMemberUnassign,
+		msg.ActionOverrideAdd,
+		msg.ActionOverrideRemove,
 		msg.ActionRemove,
+		msg.ActionRotationClear,
+		msg.ActionRotationSet,
 		msg.ActionUpdate,
 	} {
 		hmap.Request(msg.SectionOncall, action, w.handlerName)
@@ -86,6 +94,8 @@ func (w *OncallWrite) Run() {
 		stmt.OncallAdd:            &w.stmtAdd,
 		stmt.OncallMemberAssign:   &w.stmtAssign,
 		stmt.OncallMemberUnassign: &w.stmtUnassign,
+		stmt.OncallOverrideAdd:    &w.stmtOverrideAdd,
+		stmt.OncallOverrideRemove: &w.stmtOverrideRemove,
 		stmt.OncallRemove:         &w.stmtRemove,
 		stmt.OncallUpdate:         &w.stmtUpdate,
 	} {
@@ -118,8 +128,16 @@ func (w *OncallWrite) process(q *msg.Request) {
 		w.assign(q, &result)
 	case msg.ActionMemberUnassign:
 		w.unassign(q, &result)
+	case msg.ActionOverrideAdd:
+		w.overrideAdd(q, &result)
+	case msg.ActionOverrideRemove:
+		w.overrideRemove(q, &result)
 	case msg.ActionRemove:
 		w.remove(q, &result)
+	case msg.ActionRotationClear:
+		w.rotationClear(q, &result)
+	case msg.ActionRotationSet:
+		w.rotationSet(q, &result)
 	case msg.ActionUpdate:
 		w.update(q, &result)
 	default:
@@ -243,6 +261,152 @@ func (w *OncallWrite) update(q *msg.Request, mr *msg.Result) {
 	}
 }
 
+// rotationSet replaces the rotation schedule of an oncall duty team
+func (w *OncallWrite) rotationSet(q *msg.Request, mr *msg.Result) {
+	var (
+		tx  *sql.Tx
+		res sql.Result
+		err error
+	)
+
+	// validate the schedule before storing it
+	if _, _, _, _, err = rotationShift(
+		q.Oncall.Rotation,
+		time.Now().UTC(),
+	); err != nil {
+		mr.BadRequest(err, q.Section)
+		return
+	}
+
+	if tx, err = w.conn.Begin(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	if _, err = tx.Exec(
+		stmt.OncallRotationRemove,
+		q.Oncall.ID,
+	); err != nil {
+		goto abort
+	}
+
+	if res, err = tx.Exec(
+		stmt.OncallRotationAdd,
+		q.Oncall.ID,
+		q.Oncall.Rotation.Period,
+		q.Oncall.Rotation.Handover,
+		q.Oncall.Rotation.Start,
+		q.Oncall.Rotation.TimeZone,
+		q.AuthUser,
+	); err != nil {
+		goto abort
+	}
+	if !mr.RowCnt(res.RowsAffected()) {
+		tx.Rollback()
+		return
+	}
+
+	for i, member := range q.Oncall.Rotation.Members {
+		if _, err = tx.Exec(
+			stmt.OncallRotationMemberAdd,
+			q.Oncall.ID,
+			member.UserID,
+			i,
+		); err != nil {
+			goto abort
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		goto abort
+	}
+	mr.Oncall = append(mr.Oncall, q.Oncall)
+	return
+
+abort:
+	tx.Rollback()
+	mr.ServerError(err, q.Section)
+}
+
+// rotationClear removes the rotation schedule of an oncall duty team
+func (w *OncallWrite) rotationClear(q *msg.Request, mr *msg.Result) {
+	var (
+		res sql.Result
+		err error
+	)
+
+	if res, err = w.conn.Exec(
+		stmt.OncallRotationRemove,
+		q.Oncall.ID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.Oncall = append(mr.Oncall, q.Oncall)
+	}
+}
+
+// overrideAdd puts a member of an oncall duty team on duty for a
+// period of time, regardless of the rotation schedule
+func (w *OncallWrite) overrideAdd(q *msg.Request, mr *msg.Result) {
+	var (
+		res        sql.Result
+		start, end time.Time
+		err        error
+	)
+	override := &(*q.Oncall.Overrides)[0]
+
+	if start, err = time.Parse(time.RFC3339, override.Start); err != nil {
+		mr.BadRequest(err, q.Section)
+		return
+	}
+	if end, err = time.Parse(time.RFC3339, override.End); err != nil {
+		mr.BadRequest(err, q.Section)
+		return
+	}
+	if !start.Before(end) {
+		mr.BadRequest(fmt.Errorf(
+			`Override must end after it starts`), q.Section)
+		return
+	}
+
+	override.ID = uuid.Must(uuid.NewV4()).String()
+	if res, err = w.stmtOverrideAdd.Exec(
+		override.ID,
+		q.Oncall.ID,
+		override.UserID,
+		start.UTC(),
+		end.UTC(),
+		q.AuthUser,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.Oncall = append(mr.Oncall, q.Oncall)
+	}
+}
+
+// overrideRemove deletes an override
+func (w *OncallWrite) overrideRemove(q *msg.Request, mr *msg.Result) {
+	var (
+		res sql.Result
+		err error
+	)
+
+	if res, err = w.stmtOverrideRemove.Exec(
+		(*q.Oncall.Overrides)[0].ID,
+		q.Oncall.ID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.Oncall = append(mr.Oncall, q.Oncall)
+	}
+}
+
 // ShutdownNow signals the handler to shut down
 func (w *OncallWrite) ShutdownNow() {
 	close(w.Shutdown)
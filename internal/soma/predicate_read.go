@@ -145,14 +145,15 @@ func (r *PredicateRead) list(q *msg.Request, mr *msg.Result) {
 // show returns the details of a specific predicate
 func (r *PredicateRead) show(q *msg.Request, mr *msg.Result) {
 	var (
-		predicate string
-		err       error
+		predicate, escalation string
+		err                   error
 	)
 
 	if err = r.stmtShow.QueryRow(
 		q.Predicate.Symbol,
 	).Scan(
 		&predicate,
+		&escalation,
 	); err == sql.ErrNoRows {
 		mr.NotFound(err, q.Section)
 		return
@@ -161,7 +162,8 @@ func (r *PredicateRead) show(q *msg.Request, mr *msg.Result) {
 		return
 	}
 	mr.Predicate = append(mr.Predicate, proto.Predicate{
-		Symbol: predicate,
+		Symbol:     predicate,
+		Escalation: escalation,
 	})
 	mr.OK()
 }
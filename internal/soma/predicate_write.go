@@ -10,11 +10,13 @@ package soma // import "github.com/mjolnir42/soma/internal/soma"
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/mjolnir42/soma/internal/handler"
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
 )
 
 // PredicateWrite handles write requests for predicates
@@ -117,8 +119,21 @@ func (w *PredicateWrite) add(q *msg.Request, mr *msg.Result) {
 		res sql.Result
 	)
 
+	switch q.Predicate.Escalation {
+	case ``:
+		q.Predicate.Escalation = q.Predicate.DefaultEscalation()
+	case proto.EscalationRising,
+		proto.EscalationFalling,
+		proto.EscalationNone:
+	default:
+		mr.BadRequest(fmt.Errorf("Invalid escalation: %s",
+			q.Predicate.Escalation), q.Section)
+		return
+	}
+
 	if res, err = w.stmtAdd.Exec(
 		q.Predicate.Symbol,
+		q.Predicate.Escalation,
 	); err != nil {
 		mr.ServerError(err, q.Section)
 		return
@@ -164,6 +164,8 @@ func (r *PropertyRead) list(q *msg.Request, mr *msg.Result) {
 func (r *PropertyRead) listCustom(q *msg.Request, mr *msg.Result) {
 	var (
 		property, repository, id string
+		rawSchema                sql.NullString
+		schema                   *proto.PropertySchema
 		rows                     *sql.Rows
 		err                      error
 	)
@@ -176,7 +178,17 @@ func (r *PropertyRead) listCustom(q *msg.Request, mr *msg.Result) {
 	}
 
 	for rows.Next() {
-		if err = rows.Scan(&id, &repository, &property); err != nil {
+		if err = rows.Scan(
+			&id,
+			&repository,
+			&property,
+			&rawSchema,
+		); err != nil {
+			rows.Close()
+			mr.ServerError(err, q.Section)
+			return
+		}
+		if schema, err = decodePropertySchema(rawSchema); err != nil {
 			rows.Close()
 			mr.ServerError(err, q.Section)
 			return
@@ -187,6 +199,7 @@ func (r *PropertyRead) listCustom(q *msg.Request, mr *msg.Result) {
 				ID:           id,
 				RepositoryID: repository,
 				Name:         property,
+				Schema:       schema,
 			},
 		})
 	}
@@ -356,6 +369,8 @@ func (r *PropertyRead) show(q *msg.Request, mr *msg.Result) {
 func (r *PropertyRead) showCustom(q *msg.Request, mr *msg.Result) {
 	var (
 		property, repository, id string
+		rawSchema                sql.NullString
+		schema                   *proto.PropertySchema
 		err                      error
 	)
 
@@ -366,6 +381,7 @@ func (r *PropertyRead) showCustom(q *msg.Request, mr *msg.Result) {
 		&id,
 		&repository,
 		&property,
+		&rawSchema,
 	); err == sql.ErrNoRows {
 		mr.NotFound(err, q.Section)
 		return
@@ -373,12 +389,17 @@ func (r *PropertyRead) showCustom(q *msg.Request, mr *msg.Result) {
 		mr.ServerError(err, q.Section)
 		return
 	}
+	if schema, err = decodePropertySchema(rawSchema); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
 	mr.Property = append(mr.Property, proto.Property{
 		Type: q.Property.Type,
 		Custom: &proto.PropertyCustom{
 			ID:           id,
 			RepositoryID: repository,
 			Name:         property,
+			Schema:       schema,
 		},
 	})
 	mr.OK()
@@ -562,6 +583,8 @@ func (r *PropertyRead) search(q *msg.Request, mr *msg.Result) {
 func (r *PropertyRead) searchCustom(q *msg.Request, mr *msg.Result) {
 	var (
 		property, repository, id string
+		rawSchema                sql.NullString
+		schema                   *proto.PropertySchema
 		rows                     *sql.Rows
 		err                      error
 	)
@@ -574,7 +597,17 @@ func (r *PropertyRead) searchCustom(q *msg.Request, mr *msg.Result) {
 	}
 
 	for rows.Next() {
-		if err = rows.Scan(&id, &repository, &property); err != nil {
+		if err = rows.Scan(
+			&id,
+			&repository,
+			&property,
+			&rawSchema,
+		); err != nil {
+			rows.Close()
+			mr.ServerError(err, q.Section)
+			return
+		}
+		if schema, err = decodePropertySchema(rawSchema); err != nil {
 			rows.Close()
 			mr.ServerError(err, q.Section)
 			return
@@ -585,6 +618,7 @@ func (r *PropertyRead) searchCustom(q *msg.Request, mr *msg.Result) {
 				ID:           id,
 				RepositoryID: repository,
 				Name:         property,
+				Schema:       schema,
 			},
 		})
 	}
@@ -0,0 +1,44 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma // import "github.com/mjolnir42/soma/internal/soma"
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// decodePropertySchema parses the value schema of a custom property
+// as stored in the database. Custom properties without schema return
+// nil.
+func decodePropertySchema(raw sql.NullString) (*proto.PropertySchema, error) {
+	if !raw.Valid {
+		return nil, nil
+	}
+	schema := &proto.PropertySchema{}
+	if err := json.Unmarshal([]byte(raw.String), schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// encodePropertySchema returns the value schema of a custom property
+// for storage in the database, or NULL if there is none
+func encodePropertySchema(schema *proto.PropertySchema) (interface{}, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
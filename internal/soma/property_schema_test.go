@@ -0,0 +1,101 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+func TestPropertySchemaRoundtrip(t *testing.T) {
+	if schema, err := decodePropertySchema(sql.NullString{}); err != nil || schema != nil {
+		t.Fatalf("NULL schema decoded to %v, %v", schema, err)
+	}
+	if raw, err := encodePropertySchema(nil); err != nil || raw != nil {
+		t.Fatalf("nil schema encoded to %v, %v", raw, err)
+	}
+
+	max := float64(65535)
+	raw, err := encodePropertySchema(&proto.PropertySchema{
+		Type:    proto.SchemaTypeInteger,
+		Maximum: &max,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := decodePropertySchema(sql.NullString{String: raw.(string), Valid: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Type != proto.SchemaTypeInteger || schema.Minimum != nil ||
+		schema.Maximum == nil || *schema.Maximum != max {
+		t.Errorf("Roundtrip mismatch: %+v", schema)
+	}
+
+	if _, err := decodePropertySchema(sql.NullString{String: `{`, Valid: true}); err == nil {
+		t.Errorf("Decoding invalid JSON did not fail")
+	}
+}
+
+func TestPropertySchemaValidate(t *testing.T) {
+	min, max := float64(1), float64(10)
+	tests := []struct {
+		name   string
+		schema proto.PropertySchema
+		value  string
+		fail   bool
+	}{
+		{`untyped`, proto.PropertySchema{}, `anything`, false},
+		{`integer`, proto.PropertySchema{Type: proto.SchemaTypeInteger}, `42`, false},
+		{`integer garbage`, proto.PropertySchema{Type: proto.SchemaTypeInteger}, `4.2`, true},
+		{`number`, proto.PropertySchema{Type: proto.SchemaTypeNumber}, `4.2`, false},
+		{`boolean`, proto.PropertySchema{Type: proto.SchemaTypeBoolean}, `maybe`, true},
+		{`in range`, proto.PropertySchema{Type: proto.SchemaTypeInteger, Minimum: &min, Maximum: &max}, `10`, false},
+		{`below range`, proto.PropertySchema{Type: proto.SchemaTypeInteger, Minimum: &min}, `0`, true},
+		{`above range`, proto.PropertySchema{Type: proto.SchemaTypeNumber, Maximum: &max}, `10.5`, true},
+		{`regex`, proto.PropertySchema{Regex: `[a-z]+`}, `abc`, false},
+		{`regex anchored`, proto.PropertySchema{Regex: `[a-z]+`}, `abc1`, true},
+		{`enum`, proto.PropertySchema{Enum: []string{`dev`, `prod`}}, `prod`, false},
+		{`not in enum`, proto.PropertySchema{Enum: []string{`dev`, `prod`}}, `qa`, true},
+	}
+
+	for _, test := range tests {
+		if err := test.schema.Validate(test.value); (err != nil) != test.fail {
+			t.Errorf("%s: Validate(%s) returned %v, expected failure: %t",
+				test.name, test.value, err, test.fail)
+		}
+	}
+}
+
+func TestPropertySchemaVerify(t *testing.T) {
+	min, max := float64(10), float64(1)
+	tests := []struct {
+		name   string
+		schema proto.PropertySchema
+		fail   bool
+	}{
+		{`empty`, proto.PropertySchema{}, false},
+		{`unknown type`, proto.PropertySchema{Type: `date`}, true},
+		{`range on string`, proto.PropertySchema{Minimum: &max}, true},
+		{`inverted range`, proto.PropertySchema{Type: proto.SchemaTypeNumber, Minimum: &min, Maximum: &max}, true},
+		{`broken regex`, proto.PropertySchema{Regex: `(`}, true},
+		{`enum of wrong type`, proto.PropertySchema{Type: proto.SchemaTypeInteger, Enum: []string{`1`, `x`}}, true},
+		{`enum outside range`, proto.PropertySchema{Type: proto.SchemaTypeInteger, Maximum: &min, Enum: []string{`11`}}, true},
+	}
+
+	for _, test := range tests {
+		if err := test.schema.Verify(); (err != nil) != test.fail {
+			t.Errorf("%s: Verify() returned %v, expected failure: %t",
+				test.name, err, test.fail)
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
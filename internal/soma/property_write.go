@@ -40,6 +40,8 @@ type PropertyWrite struct {
 	stmtRemoveSystem       *sql.Stmt
 	stmtRemoveTemplate     *sql.Stmt
 	stmtRemoveTemplateAttr *sql.Stmt
+	stmtUpdateCustom       *sql.Stmt
+	stmtValuesCustom       *sql.Stmt
 	appLog                 *logrus.Logger
 	reqLog                 *logrus.Logger
 	errLog                 *logrus.Logger
@@ -81,6 +83,8 @@ func (w *PropertyWrite) RegisterRequests(hmap *handler.Map) {
 			hmap.Request(section, action, w.handlerName)
 		}
 	}
+	// only custom properties have a schema that can be updated
+	hmap.Request(msg.SectionPropertyCustom, msg.ActionUpdate, w.handlerName)
 }
 
 // Intake exposes the Input channel as part of the handler interface
@@ -100,6 +104,8 @@ func (w *PropertyWrite) Run() {
 	for statement, prepStmt := range map[string]**sql.Stmt{
 		stmt.PropertyCustomAdd:            &w.stmtAddCustom,
 		stmt.PropertyCustomDel:            &w.stmtRemoveCustom,
+		stmt.PropertyCustomSchemaUpdate:   &w.stmtUpdateCustom,
+		stmt.PropertyCustomValues:         &w.stmtValuesCustom,
 		stmt.PropertyNativeAdd:            &w.stmtAddNative,
 		stmt.PropertyNativeDel:            &w.stmtRemoveNative,
 		stmt.PropertyServiceAdd:           &w.stmtAddService,
@@ -140,6 +146,8 @@ func (w *PropertyWrite) process(q *msg.Request) {
 		w.add(q, &result)
 	case msg.ActionRemove:
 		w.remove(q, &result)
+	case msg.ActionUpdate:
+		w.updateCustom(q, &result)
 	default:
 		result.UnknownRequest(q)
 	}
@@ -202,15 +210,30 @@ func (w *PropertyWrite) addNative(q *msg.Request, mr *msg.Result) {
 // addCustom inserts custom repository properties
 func (w *PropertyWrite) addCustom(q *msg.Request, mr *msg.Result) {
 	var (
-		res sql.Result
-		err error
+		res    sql.Result
+		err    error
+		schema interface{}
 	)
 
+	if q.Property.Custom.Schema != nil {
+		if err = q.Property.Custom.Schema.Verify(); err != nil {
+			mr.BadRequest(err, q.Section)
+			return
+		}
+	}
+	if schema, err = encodePropertySchema(
+		q.Property.Custom.Schema,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
 	q.Property.Custom.ID = uuid.Must(uuid.NewV4()).String()
 	if res, err = w.stmtAddCustom.Exec(
 		q.Property.Custom.ID,
 		q.Property.Custom.RepositoryID,
 		q.Property.Custom.Name,
+		schema,
 	); err != nil {
 		mr.ServerError(err, q.Section)
 		return
@@ -367,6 +390,71 @@ func (w *PropertyWrite) removeCustom(q *msg.Request, mr *msg.Result) {
 	}
 }
 
+// updateCustom replaces the value schema of a custom repository
+// property. The new schema must accept all values the property is
+// currently set to.
+func (w *PropertyWrite) updateCustom(q *msg.Request, mr *msg.Result) {
+	var (
+		res    sql.Result
+		err    error
+		rows   *sql.Rows
+		value  string
+		schema interface{}
+	)
+
+	if q.Property.Custom.Schema != nil {
+		if err = q.Property.Custom.Schema.Verify(); err != nil {
+			mr.BadRequest(err, q.Section)
+			return
+		}
+
+		if rows, err = w.stmtValuesCustom.Query(
+			q.Property.Custom.ID,
+		); err != nil {
+			mr.ServerError(err, q.Section)
+			return
+		}
+		for rows.Next() {
+			if err = rows.Scan(&value); err != nil {
+				rows.Close()
+				mr.ServerError(err, q.Section)
+				return
+			}
+			if err = q.Property.Custom.Schema.Validate(value); err != nil {
+				rows.Close()
+				mr.BadRequest(fmt.Errorf(
+					"Schema rejects existing property value: %s",
+					err.Error()), q.Section)
+				return
+			}
+		}
+		if err = rows.Err(); err != nil {
+			rows.Close()
+			mr.ServerError(err, q.Section)
+			return
+		}
+		rows.Close()
+	}
+
+	if schema, err = encodePropertySchema(
+		q.Property.Custom.Schema,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if res, err = w.stmtUpdateCustom.Exec(
+		q.Property.Custom.RepositoryID,
+		q.Property.Custom.ID,
+		schema,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if mr.RowCnt(res.RowsAffected()) {
+		mr.Property = append(mr.Property, q.Property)
+	}
+}
+
 // removeService deletes a team service or service template
 func (w *PropertyWrite) removeService(q *msg.Request, mr *msg.Result) {
 	var (
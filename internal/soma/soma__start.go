@@ -27,7 +27,7 @@ func (s *Soma) Start() {
 	s.handlerMap.Add(newAttributeRead(s.conf.QueueLen))
 	s.handlerMap.Add(newBucketRead(s.conf.QueueLen))
 	s.handlerMap.Add(newCapabilityRead(s.conf.QueueLen))
-	s.handlerMap.Add(newCheckConfigurationRead(s.conf.QueueLen))
+	s.handlerMap.Add(newCheckConfigurationRead(s.conf.QueueLen, s))
 	s.handlerMap.Add(newClusterRead(s.conf.QueueLen))
 	s.handlerMap.Add(newDatacenterRead(s.conf.QueueLen))
 	s.handlerMap.Add(newEntityRead(s.conf.QueueLen))
@@ -71,8 +71,10 @@ func (s *Soma) Start() {
 			s.handlerMap.Add(newCapabilityWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newDatacenterWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newDeploymentWrite(s.conf.QueueLen))
+			s.handlerMap.Add(`directory_sync`, newDirectorySync(s.conf.QueueLen, s))
 			s.handlerMap.Add(newEntityWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newEnvironmentWrite(s.conf.QueueLen))
+			s.handlerMap.Add(`inventory_reconciler`, newInventoryReconciler(s.conf.QueueLen, s))
 			s.handlerMap.Add(`job_block`, newJobBlock(s.conf.QueueLen))
 			s.handlerMap.Add(newJobResultWrite(s.conf.QueueLen))
 			s.handlerMap.Add(newJobStatusWrite(s.conf.QueueLen))
@@ -12,6 +12,7 @@ import (
 	"database/sql"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/lib/pq"
 	"github.com/mjolnir42/soma/internal/handler"
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/stmt"
@@ -146,12 +147,21 @@ func (r *StateRead) list(q *msg.Request, mr *msg.Result) {
 
 // show returns details of a specific state
 func (r *StateRead) show(q *msg.Request, mr *msg.Result) {
-	var state string
-	var err error
+	var (
+		state, handling string
+		views           pq.StringArray
+		unassignAfter   int64
+		err             error
+	)
 
 	if err = r.stmtShow.QueryRow(
 		q.State.Name,
-	).Scan(&state); err == sql.ErrNoRows {
+	).Scan(
+		&state,
+		&handling,
+		&views,
+		&unassignAfter,
+	); err == sql.ErrNoRows {
 		mr.NotFound(err, q.Section)
 		return
 	} else if err != nil {
@@ -159,7 +169,10 @@ func (r *StateRead) show(q *msg.Request, mr *msg.Result) {
 		return
 	}
 	mr.State = append(mr.State, proto.State{
-		Name: state,
+		Name:          state,
+		CheckHandling: handling,
+		Views:         []string(views),
+		UnassignAfter: uint64(unassignAfter),
 	})
 	mr.OK()
 }
@@ -10,8 +10,10 @@ package soma // import "github.com/mjolnir42/soma/internal/soma"
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/lib/pq"
 	"github.com/mjolnir42/soma/internal/handler"
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/stmt"
@@ -25,7 +27,10 @@ type StateWrite struct {
 	conn        *sql.DB
 	stmtCreate  *sql.Stmt
 	stmtDelete  *sql.Stmt
+	stmtLock    *sql.Stmt
 	stmtRename  *sql.Stmt
+	stmtUpdate  *sql.Stmt
+	stmtUsage   *sql.Stmt
 	appLog      *logrus.Logger
 	reqLog      *logrus.Logger
 	errLog      *logrus.Logger
@@ -56,6 +61,7 @@ func (w *StateWrite) RegisterRequests(hmap *handler.Map) {
 		msg.ActionAdd,
 		msg.ActionRemove,
 		msg.ActionRename,
+		msg.ActionUpdate,
 	} {
 		hmap.Request(msg.SectionState, action, w.handlerName)
 	}
@@ -77,8 +83,11 @@ func (w *StateWrite) Run() {
 
 	for statement, prepStmt := range map[string]**sql.Stmt{
 		stmt.ObjectStateAdd:    &w.stmtCreate,
+		stmt.ObjectStateLock:   &w.stmtLock,
 		stmt.ObjectStateRemove: &w.stmtDelete,
 		stmt.ObjectStateRename: &w.stmtRename,
+		stmt.ObjectStateUpdate: &w.stmtUpdate,
+		stmt.ObjectStateUsage:  &w.stmtUsage,
 	} {
 		if *prepStmt, err = w.conn.Prepare(statement); err != nil {
 			w.errLog.Fatal(`state`, err, stmt.Name(statement))
@@ -109,6 +118,8 @@ func (w *StateWrite) process(q *msg.Request) {
 		w.remove(q, &result)
 	case msg.ActionRename:
 		w.rename(q, &result)
+	case msg.ActionUpdate:
+		w.update(q, &result)
 	default:
 		result.UnknownRequest(q)
 	}
@@ -167,6 +178,96 @@ func (w *StateWrite) rename(q *msg.Request, mr *msg.Result) {
 	}
 }
 
+// update changes the check handling of a state. Nodes receive the
+// check handling of a state when they are moved into it, it can
+// therefore not be changed while nodes are in the state or waiting
+// to be moved into it.
+func (w *StateWrite) update(q *msg.Request, mr *msg.Result) {
+	var (
+		err      error
+		res      sql.Result
+		tx       *sql.Tx
+		handling string
+		views    pq.StringArray
+		inUse    int64
+		rowCnt   int64
+	)
+
+	if tx, err = w.conn.Begin(); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	defer tx.Rollback()
+
+	// lock the state against nodes being moved into it
+	if err = tx.Stmt(w.stmtLock).QueryRow(
+		q.State.Name,
+	).Scan(
+		&handling,
+		&views,
+	); err == sql.ErrNoRows {
+		mr.NotFound(fmt.Errorf("State not found: %s", q.State.Name),
+			q.Section)
+		return
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	if handling != q.State.CheckHandling ||
+		!sameViews(views, q.State.Views) {
+		if err = tx.Stmt(w.stmtUsage).QueryRow(
+			q.State.Name,
+		).Scan(
+			&inUse,
+		); err != nil {
+			mr.ServerError(err, q.Section)
+			return
+		}
+		if inUse > 0 {
+			mr.Conflict(fmt.Errorf(
+				"State %s is used by %d nodes or queued lifecycle"+
+					" jobs, its check handling can not be changed",
+				q.State.Name, inUse), q.Section)
+			return
+		}
+	}
+
+	if res, err = tx.Stmt(w.stmtUpdate).Exec(
+		q.State.CheckHandling,
+		pq.StringArray(q.State.Views),
+		int64(q.State.UnassignAfter),
+		q.State.Name,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	if rowCnt, err = res.RowsAffected(); err == nil {
+		err = tx.Commit()
+	}
+	if mr.RowCnt(rowCnt, err) {
+		mr.State = append(mr.State, q.State)
+	}
+}
+
+// sameViews returns true if a and b contain the same views
+func sameViews(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := map[string]int{}
+	for i := range a {
+		count[a[i]]++
+		count[b[i]]--
+	}
+	for _, c := range count {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // ShutdownNow signals the handler to shut down
 func (w *StateWrite) ShutdownNow() {
 	close(w.Shutdown)
@@ -0,0 +1,159 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+// testStateWrite returns a StateWrite handler that updates states
+// in db
+func testStateWrite(t *testing.T, db *sql.DB,
+	mock sqlmock.Sqlmock) *StateWrite {
+	_, w := newStateWrite(1)
+	w.conn = db
+
+	for _, prepStmt := range []struct {
+		statement string
+		stmt      **sql.Stmt
+	}{
+		{stmt.ObjectStateLock, &w.stmtLock},
+		{stmt.ObjectStateUsage, &w.stmtUsage},
+		{stmt.ObjectStateUpdate, &w.stmtUpdate},
+	} {
+		mock.ExpectPrepare(`soma.object_states|soma.nodes`)
+		var err error
+		if *prepStmt.stmt, err = db.Prepare(prepStmt.statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w
+}
+
+func TestStateWriteUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		handling string
+		views    string
+		update   proto.State
+		changed  bool
+		inUse    int64
+		code     uint16
+	}{
+		{
+			name:     `check handling of unused state`,
+			handling: `active`, views: `{}`,
+			update: proto.State{Name: `maintenance`,
+				CheckHandling: `suspend`},
+			changed: true,
+			code:    200,
+		},
+		{
+			name:     `check handling of state in use`,
+			handling: `active`, views: `{}`,
+			update: proto.State{Name: `maintenance`,
+				CheckHandling: `suspend`},
+			changed: true,
+			inUse:   3,
+			code:    406,
+		},
+		{
+			name:     `views of state in use`,
+			handling: `restrict`, views: `{local}`,
+			update: proto.State{Name: `standby`,
+				CheckHandling: `restrict`,
+				Views:         []string{`local`, `internal`}},
+			changed: true,
+			inUse:   1,
+			code:    406,
+		},
+		{
+			name:     `reordered views of state in use`,
+			handling: `restrict`, views: `{local,internal}`,
+			update: proto.State{Name: `standby`,
+				CheckHandling: `restrict`,
+				Views:         []string{`internal`, `local`}},
+			code: 200,
+		},
+		{
+			name:     `unassign-after of state in use`,
+			handling: `deprovision`, views: `{}`,
+			update: proto.State{Name: `decommissioning`,
+				CheckHandling: `deprovision`, UnassignAfter: 604800},
+			code: 200,
+		},
+		{
+			name:   `unknown state`,
+			update: proto.State{Name: `unknown`, CheckHandling: `active`},
+			code:   404,
+		},
+	}
+
+	for _, test := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := testStateWrite(t, db, mock)
+
+		mock.ExpectBegin()
+		lock := mock.ExpectQuery(`FOR\s+UPDATE`).
+			WithArgs(test.update.Name)
+		if test.code == 404 {
+			lock.WillReturnError(sql.ErrNoRows)
+		} else {
+			lock.WillReturnRows(sqlmock.NewRows(
+				[]string{`check_handling`, `restrict_views`},
+			).AddRow(test.handling, test.views))
+		}
+		if test.changed {
+			mock.ExpectQuery(`lifecycle_state`).
+				WithArgs(test.update.Name).
+				WillReturnRows(sqlmock.NewRows([]string{`count`}).
+					AddRow(test.inUse))
+		}
+		if test.code == 200 {
+			mock.ExpectExec(`UPDATE soma.object_states`).
+				WithArgs(test.update.CheckHandling, sqlmock.AnyArg(),
+					int64(test.update.UnassignAfter), test.update.Name).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		} else {
+			// the state is neither updated nor left locked
+			mock.ExpectRollback()
+		}
+
+		q := &msg.Request{
+			Section: msg.SectionState,
+			Action:  msg.ActionUpdate,
+			State:   test.update,
+		}
+		result := msg.FromRequest(q)
+		w.update(q, &result)
+
+		if result.Code != test.code {
+			t.Errorf("%s: expected code %d, got %d: %v", test.name,
+				test.code, result.Code, result.Error)
+		}
+		if test.code == 200 && len(result.State) != 1 {
+			t.Errorf("%s: updated state not returned", test.name)
+		}
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		}
+		db.Close()
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -59,9 +59,13 @@ type TreeRead struct {
 	stmtListGroupMemberClusters     *sql.Stmt
 	stmtListGroupMemberNodes        *sql.Stmt
 	stmtListClusterMemberNodes      *sql.Stmt
-	appLog                          *logrus.Logger
-	reqLog                          *logrus.Logger
-	errLog                          *logrus.Logger
+	// property history
+	stmtPropertyHistory *sql.Stmt
+	// graph export
+	stmtCheckCount *sql.Stmt
+	appLog         *logrus.Logger
+	reqLog         *logrus.Logger
+	errLog         *logrus.Logger
 }
 
 // newTreeRead return a new TreeRead handler with input buffer of
@@ -93,6 +97,7 @@ func (r *TreeRead) RegisterRequests(hmap *handler.Map) {
 		msg.SectionNodeConfig,
 	} {
 		hmap.Request(section, msg.ActionTree, r.handlerName)
+		hmap.Request(section, msg.ActionPropertyHistory, r.handlerName)
 	}
 }
 
@@ -125,6 +130,8 @@ func (r *TreeRead) Run() {
 		stmt.TreeClustersInGroup:     &r.stmtListGroupMemberClusters,
 		stmt.TreeNodesInGroup:        &r.stmtListGroupMemberNodes,
 		stmt.TreeNodesInCluster:      &r.stmtListClusterMemberNodes,
+		stmt.PropertyHistoryList:     &r.stmtPropertyHistory,
+		stmt.TreeCheckCount:          &r.stmtCheckCount,
 	} {
 		if *prepStmt, err = r.conn.Prepare(statement); err != nil {
 			r.errLog.Fatal(`tree_r`, err, stmt.Name(statement))
@@ -153,6 +160,8 @@ func (r *TreeRead) process(q *msg.Request) {
 	switch q.Action {
 	case msg.ActionTree:
 		r.tree(q, &result)
+	case msg.ActionPropertyHistory:
+		r.propertyHistory(q, &result)
 	default:
 		result.UnknownRequest(q)
 	}
@@ -161,10 +170,32 @@ func (r *TreeRead) process(q *msg.Request) {
 
 // tree returns the requested tree
 func (r *TreeRead) tree(q *msg.Request, mr *msg.Result) {
-	var err error
+	var (
+		err error
+		at  time.Time
+	)
 	tree := proto.Tree{
-		ID:   q.Tree.ID,
-		Type: q.Tree.Type,
+		ID:     q.Tree.ID,
+		Type:   q.Tree.Type,
+		At:     q.Tree.At,
+		Format: q.Tree.Format,
+	}
+
+	if tree.Format != `` && !proto.ValidGraphFormat(tree.Format) {
+		mr.BadRequest(
+			fmt.Errorf("Unknown graph format: %s", tree.Format),
+			q.Section,
+		)
+		return
+	}
+
+	// the tree structure is always current, at only selects the
+	// properties that are shown
+	if tree.At != `` {
+		if at, err = time.Parse(time.RFC3339, tree.At); err != nil {
+			mr.BadRequest(err, q.Section)
+			return
+		}
 	}
 
 	switch tree.Type {
@@ -192,6 +223,24 @@ func (r *TreeRead) tree(q *msg.Request, mr *msg.Result) {
 		mr.ServerError(err, q.Section)
 		return
 	}
+
+	// graphs always show properties, by default the current ones
+	if tree.At == `` && tree.Format != `` {
+		at = time.Now()
+	}
+	if !at.IsZero() {
+		if err = r.treeProperties(&tree, at); err != nil {
+			mr.ServerError(err, q.Section)
+			return
+		}
+	}
+
+	if tree.Format != `` {
+		if mr.Graph, err = r.graph(&tree); err != nil {
+			mr.ServerError(err, q.Section)
+			return
+		}
+	}
 	mr.Tree = tree
 	mr.OK()
 }
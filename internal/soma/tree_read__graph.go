@@ -0,0 +1,234 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// graph exports tree as graph annotated with the check counts of
+// every object. The check counts of all objects are read with a
+// single query.
+func (r *TreeRead) graph(tree *proto.Tree) (*proto.Graph, error) {
+	var (
+		rows               *sql.Rows
+		objectID, sourceID string
+		count              int
+		err                error
+	)
+	g := treeGraph(tree)
+
+	index := make(map[string]int, len(g.Nodes))
+	idList := make([]string, 0, len(g.Nodes))
+	for i := range g.Nodes {
+		if g.Nodes[i].External {
+			continue
+		}
+		index[g.Nodes[i].ID] = i
+		idList = append(idList, g.Nodes[i].ID)
+	}
+
+	if rows, err = r.stmtCheckCount.Query(
+		fmt.Sprintf("{%s}", strings.Join(idList, `,`)),
+	); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&objectID,
+			&sourceID,
+			&count,
+		); err != nil {
+			return nil, err
+		}
+		if i, ok := index[objectID]; ok {
+			addCheckCount(g, i, sourceID, count)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	addExternalSources(g)
+	return g, nil
+}
+
+// treeGraph flattens tree into its objects, their membership and
+// their property inheritance
+func treeGraph(tree *proto.Tree) *proto.Graph {
+	g := &proto.Graph{
+		ID:    tree.ID,
+		Type:  tree.Type,
+		Nodes: []proto.GraphNode{},
+		Edges: []proto.GraphEdge{},
+	}
+	seen := map[proto.GraphEdge]bool{}
+
+	// addObject adds the object to g and links it to its parent
+	addObject := func(parent, id, entity, name string, properties *[]proto.Property) {
+		n := proto.GraphNode{
+			ID:   id,
+			Type: entity,
+			Name: name,
+		}
+		if parent != `` {
+			g.Edges = append(g.Edges, proto.GraphEdge{
+				Source:   parent,
+				Target:   id,
+				Relation: proto.GraphEdgeMember,
+			})
+		}
+		if properties != nil {
+			for i := range *properties {
+				prop := &(*properties)[i]
+				label := proto.PropertyLabel(prop)
+				if !prop.IsInherited {
+					n.Properties = append(n.Properties, label)
+					continue
+				}
+				n.Properties = append(n.Properties, label+` (inherited)`)
+
+				// the same property is inherited once per view
+				e := proto.GraphEdge{
+					Source:   prop.InheritedFrom,
+					Target:   id,
+					Relation: proto.GraphEdgeProperty,
+					Label:    label,
+				}
+				if !seen[e] {
+					seen[e] = true
+					g.Edges = append(g.Edges, e)
+				}
+			}
+		}
+		g.Nodes = append(g.Nodes, n)
+	}
+
+	var addNode func(parent string, node *proto.Node)
+	var addCluster func(parent string, cluster *proto.Cluster)
+	var addGroup func(parent string, group *proto.Group)
+	var addBucket func(parent string, bucket *proto.Bucket)
+
+	addNode = func(parent string, node *proto.Node) {
+		addObject(parent, node.ID, msg.EntityNode, node.Name, node.Properties)
+	}
+	addCluster = func(parent string, cluster *proto.Cluster) {
+		addObject(parent, cluster.ID, msg.EntityCluster, cluster.Name, cluster.Properties)
+		if cluster.Members != nil {
+			for i := range *cluster.Members {
+				addNode(cluster.ID, &(*cluster.Members)[i])
+			}
+		}
+	}
+	addGroup = func(parent string, group *proto.Group) {
+		addObject(parent, group.ID, msg.EntityGroup, group.Name, group.Properties)
+		if group.MemberGroups != nil {
+			for i := range *group.MemberGroups {
+				addGroup(group.ID, &(*group.MemberGroups)[i])
+			}
+		}
+		if group.MemberClusters != nil {
+			for i := range *group.MemberClusters {
+				addCluster(group.ID, &(*group.MemberClusters)[i])
+			}
+		}
+		if group.MemberNodes != nil {
+			for i := range *group.MemberNodes {
+				addNode(group.ID, &(*group.MemberNodes)[i])
+			}
+		}
+	}
+	addBucket = func(parent string, bucket *proto.Bucket) {
+		addObject(parent, bucket.ID, msg.EntityBucket, bucket.Name, bucket.Properties)
+		if bucket.MemberGroups != nil {
+			for i := range *bucket.MemberGroups {
+				addGroup(bucket.ID, &(*bucket.MemberGroups)[i])
+			}
+		}
+		if bucket.MemberClusters != nil {
+			for i := range *bucket.MemberClusters {
+				addCluster(bucket.ID, &(*bucket.MemberClusters)[i])
+			}
+		}
+		if bucket.MemberNodes != nil {
+			for i := range *bucket.MemberNodes {
+				addNode(bucket.ID, &(*bucket.MemberNodes)[i])
+			}
+		}
+	}
+
+	switch tree.Type {
+	case msg.EntityRepository:
+		repo := tree.Repository
+		addObject(``, repo.ID, msg.EntityRepository, repo.Name, repo.Properties)
+		if repo.Members != nil {
+			for i := range *repo.Members {
+				addBucket(repo.ID, &(*repo.Members)[i])
+			}
+		}
+	case msg.EntityBucket:
+		addBucket(``, tree.Bucket)
+	case msg.EntityGroup:
+		addGroup(``, tree.Group)
+	case msg.EntityCluster:
+		addCluster(``, tree.Cluster)
+	case msg.EntityNode:
+		addNode(``, tree.Node)
+	}
+	addExternalSources(g)
+	return g
+}
+
+// addExternalSources adds a stub node for every object outside of
+// the exported subtree that properties or checks are inherited from,
+// so that every edge of g references a declared node
+func addExternalSources(g *proto.Graph) {
+	known := make(map[string]bool, len(g.Nodes))
+	for i := range g.Nodes {
+		known[g.Nodes[i].ID] = true
+	}
+	for i := range g.Edges {
+		id := g.Edges[i].Source
+		if known[id] {
+			continue
+		}
+		known[id] = true
+		g.Nodes = append(g.Nodes, proto.GraphNode{
+			ID:       id,
+			Type:     proto.GraphNodeExternal,
+			Name:     id,
+			External: true,
+		})
+	}
+}
+
+// addCheckCount records on the object at index idx of g that it has
+// count checks that were created on the object sourceID
+func addCheckCount(g *proto.Graph, idx int, sourceID string, count int) {
+	n := &g.Nodes[idx]
+	if sourceID == n.ID {
+		n.Checks += count
+		return
+	}
+	n.InheritedChecks += count
+	g.Edges = append(g.Edges, proto.GraphEdge{
+		Source:   sourceID,
+		Target:   n.ID,
+		Relation: proto.GraphEdgeCheck,
+		Label:    fmt.Sprintf("%d checks", count),
+	})
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
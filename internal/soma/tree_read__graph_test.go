@@ -0,0 +1,311 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func testGraphTree() *proto.Tree {
+	dns := proto.Property{
+		Type:             `system`,
+		View:             `any`,
+		InstanceID:       `i-1`,
+		SourceInstanceID: `i-1`,
+		InheritedFrom:    `bucket-1`,
+		Inheritance:      true,
+		System:           &proto.PropertySystem{Name: `dns_zone`, Value: `example.org`},
+	}
+	inherited := dns
+	inherited.InstanceID = `i-2`
+	inherited.IsInherited = true
+
+	return &proto.Tree{
+		ID:   `bucket-1`,
+		Type: `bucket`,
+		Bucket: &proto.Bucket{
+			ID:         `bucket-1`,
+			Name:       `example`,
+			Properties: &[]proto.Property{dns},
+			MemberGroups: &[]proto.Group{{
+				ID:   `group-1`,
+				Name: `web`,
+				MemberNodes: &[]proto.Node{{
+					ID:         `node-1`,
+					Name:       `web01`,
+					Properties: &[]proto.Property{inherited, inherited},
+				}},
+			}},
+			MemberClusters: &[]proto.Cluster{},
+			MemberNodes: &[]proto.Node{{
+				ID:   `node-2`,
+				Name: `db01`,
+			}},
+		},
+	}
+}
+
+func TestTreeGraph(t *testing.T) {
+	g := treeGraph(testGraphTree())
+
+	if len(g.Nodes) != 4 {
+		t.Fatalf("Expected 4 graph nodes, got %d", len(g.Nodes))
+	}
+	for i, id := range []string{`bucket-1`, `group-1`, `node-1`, `node-2`} {
+		if g.Nodes[i].ID != id {
+			t.Errorf("Node %d is %s, expected %s", i, g.Nodes[i].ID, id)
+		}
+	}
+	if len(g.Nodes[0].Properties) != 1 || len(g.Nodes[2].Properties) != 2 {
+		t.Errorf("Unexpected node properties: %v / %v",
+			g.Nodes[0].Properties, g.Nodes[2].Properties)
+	}
+
+	var member, property int
+	for _, e := range g.Edges {
+		switch e.Relation {
+		case proto.GraphEdgeMember:
+			member++
+		case proto.GraphEdgeProperty:
+			property++
+			if e.Source != `bucket-1` || e.Target != `node-1` {
+				t.Errorf("Wrong property inheritance edge: %+v", e)
+			}
+		}
+	}
+	if member != 3 {
+		t.Errorf("Expected 3 member edges, got %d", member)
+	}
+	// the same inherited property is only linked once
+	if property != 1 {
+		t.Errorf("Expected 1 property edge, got %d", property)
+	}
+}
+
+func TestTreeGraphCheckCount(t *testing.T) {
+	g := treeGraph(testGraphTree())
+	edges := len(g.Edges)
+
+	addCheckCount(g, 0, `bucket-1`, 3)
+	addCheckCount(g, 2, `bucket-1`, 3)
+	addCheckCount(g, 2, `node-1`, 1)
+
+	if g.Nodes[0].Checks != 3 || g.Nodes[0].InheritedChecks != 0 {
+		t.Errorf("Wrong bucket check count: %+v", g.Nodes[0])
+	}
+	if g.Nodes[2].Checks != 1 || g.Nodes[2].InheritedChecks != 3 {
+		t.Errorf("Wrong node check count: %+v", g.Nodes[2])
+	}
+	if len(g.Edges) != edges+1 {
+		t.Fatalf("Expected one check inheritance edge, got %d", len(g.Edges)-edges)
+	}
+	if e := g.Edges[edges]; e.Relation != proto.GraphEdgeCheck ||
+		e.Source != `bucket-1` || e.Target != `node-1` {
+		t.Errorf("Wrong check inheritance edge: %+v", e)
+	}
+}
+
+func TestTreeGraphRender(t *testing.T) {
+	g := treeGraph(testGraphTree())
+
+	for _, format := range []string{
+		proto.GraphFormatDOT,
+		proto.GraphFormatGraphML,
+		proto.GraphFormatJSONGraph,
+	} {
+		b, contentType, err := g.Render(format)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if contentType == `` {
+			t.Errorf("%s: no content type", format)
+		}
+
+		switch format {
+		case proto.GraphFormatDOT:
+			if !bytes.HasPrefix(b, []byte(`digraph "bucket:bucket-1" {`)) ||
+				!bytes.Contains(b, []byte(`"bucket-1" -> "node-1" [style=dashed`)) {
+				t.Errorf("Unexpected DOT output:\n%s", b)
+			}
+		case proto.GraphFormatGraphML:
+			v := struct {
+				Nodes []struct{} `xml:"graph>node"`
+				Edges []struct{} `xml:"graph>edge"`
+			}{}
+			if err = xml.Unmarshal(b, &v); err != nil {
+				t.Errorf("Invalid GraphML: %s", err)
+			} else if len(v.Nodes) != 4 || len(v.Edges) != len(g.Edges) {
+				t.Errorf("Unexpected GraphML output:\n%s", b)
+			}
+		case proto.GraphFormatJSONGraph:
+			v := map[string]map[string]interface{}{}
+			if err = json.Unmarshal(b, &v); err != nil {
+				t.Errorf("Invalid JSON graph: %s", err)
+			} else if len(v[`graph`][`nodes`].(map[string]interface{})) != 4 {
+				t.Errorf("Unexpected JSON graph nodes: %v", v[`graph`][`nodes`])
+			}
+		}
+	}
+
+	if _, _, err := g.Render(`svg`); err == nil {
+		t.Errorf("Rendering unknown format did not fail")
+	}
+}
+
+// testGraphGroupTree returns a group export whose node inherits a
+// property from the bucket above the group
+func testGraphGroupTree() *proto.Tree {
+	inherited := proto.Property{
+		Type:             `system`,
+		View:             `any`,
+		InstanceID:       `i-2`,
+		SourceInstanceID: `i-1`,
+		InheritedFrom:    `bucket-1`,
+		Inheritance:      true,
+		IsInherited:      true,
+		System:           &proto.PropertySystem{Name: `dns_zone`, Value: `example.org`},
+	}
+
+	return &proto.Tree{
+		ID:   `group-1`,
+		Type: `group`,
+		Group: &proto.Group{
+			ID:   `group-1`,
+			Name: `web`,
+			MemberNodes: &[]proto.Node{{
+				ID:         `node-1`,
+				Name:       `web01`,
+				Properties: &[]proto.Property{inherited},
+			}},
+		},
+	}
+}
+
+func TestTreeGraphExternalSources(t *testing.T) {
+	g := treeGraph(testGraphGroupTree())
+	addCheckCount(g, 0, `repo-1`, 2)
+	addCheckCount(g, 1, `repo-1`, 2)
+	addExternalSources(g)
+
+	if len(g.Nodes) != 4 {
+		t.Fatalf("Expected 4 graph nodes, got %d: %+v", len(g.Nodes), g.Nodes)
+	}
+	for i, id := range []string{`bucket-1`, `repo-1`} {
+		n := g.Nodes[2+i]
+		if n.ID != id || !n.External || n.Type != proto.GraphNodeExternal {
+			t.Errorf("Expected external node %s, got %+v", id, n)
+		}
+	}
+
+	for _, format := range []string{
+		proto.GraphFormatDOT,
+		proto.GraphFormatGraphML,
+		proto.GraphFormatJSONGraph,
+	} {
+		b, _, err := g.Render(format)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+
+		switch format {
+		case proto.GraphFormatDOT:
+			if !bytes.Contains(b, []byte(`"repo-1" [shape=note, style=dashed`)) {
+				t.Errorf("External node missing from DOT output:\n%s", b)
+			}
+		case proto.GraphFormatGraphML:
+			v := struct {
+				Nodes []struct {
+					ID string `xml:"id,attr"`
+				} `xml:"graph>node"`
+				Edges []struct {
+					Source string `xml:"source,attr"`
+					Target string `xml:"target,attr"`
+				} `xml:"graph>edge"`
+			}{}
+			if err = xml.Unmarshal(b, &v); err != nil {
+				t.Fatalf("Invalid GraphML: %s", err)
+			}
+			declared := map[string]bool{}
+			for _, n := range v.Nodes {
+				declared[n.ID] = true
+			}
+			for _, e := range v.Edges {
+				if !declared[e.Source] || !declared[e.Target] {
+					t.Errorf("GraphML edge references undeclared node: %+v", e)
+				}
+			}
+		case proto.GraphFormatJSONGraph:
+			v := struct {
+				Graph struct {
+					Nodes map[string]interface{} `json:"nodes"`
+					Edges []proto.GraphEdge      `json:"edges"`
+				} `json:"graph"`
+			}{}
+			if err = json.Unmarshal(b, &v); err != nil {
+				t.Fatalf("Invalid JSON graph: %s", err)
+			}
+			for _, e := range v.Graph.Edges {
+				if v.Graph.Nodes[e.Source] == nil ||
+					v.Graph.Nodes[e.Target] == nil {
+					t.Errorf("JSON graph edge references undeclared node: %+v", e)
+				}
+			}
+		}
+	}
+}
+
+func TestTreeReadGraph(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// the check counts of all objects are read with one query, the
+	// external source is not queried
+	mock.ExpectPrepare(`soma.checks`)
+	mock.ExpectQuery(`soma.checks`).
+		WithArgs(`{group-1,node-1}`).
+		WillReturnRows(sqlmock.NewRows([]string{
+			`object_id`, `source_object_id`, `count`,
+		}).
+			AddRow(`group-1`, `repo-1`, 2).
+			AddRow(`node-1`, `repo-1`, 2).
+			AddRow(`node-1`, `node-1`, 1))
+
+	r := &TreeRead{}
+	if r.stmtCheckCount, err = db.Prepare(stmt.TreeCheckCount); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := r.graph(testGraphGroupTree())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	if g.Nodes[0].InheritedChecks != 2 || g.Nodes[1].Checks != 1 ||
+		g.Nodes[1].InheritedChecks != 2 {
+		t.Errorf("Unexpected check counts: %+v", g.Nodes)
+	}
+	if len(g.Nodes) != 4 || g.Nodes[3].ID != `repo-1` ||
+		!g.Nodes[3].External {
+		t.Errorf("Missing external check source: %+v", g.Nodes)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
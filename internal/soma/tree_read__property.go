@@ -0,0 +1,308 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// propertyHistory returns all property instances that were ever set
+// on the requested object
+func (r *TreeRead) propertyHistory(q *msg.Request, mr *msg.Result) {
+	var (
+		objectID, objectType string
+		rows                 *sql.Rows
+		err                  error
+	)
+
+	switch q.Section {
+	case msg.SectionRepositoryConfig:
+		objectID, objectType = q.Repository.ID, msg.EntityRepository
+	case msg.SectionBucket:
+		objectID, objectType = q.Bucket.ID, msg.EntityBucket
+	case msg.SectionGroup:
+		objectID, objectType = q.Group.ID, msg.EntityGroup
+	case msg.SectionCluster:
+		objectID, objectType = q.Cluster.ID, msg.EntityCluster
+	case msg.SectionNodeConfig:
+		objectID, objectType = q.Node.ID, msg.EntityNode
+	default:
+		mr.UnknownRequest(q)
+		return
+	}
+
+	if rows, err = r.stmtPropertyHistory.Query(
+		objectID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+
+	if mr.PropertyHistory, err = scanPropertyHistory(
+		rows, objectID, objectType, time.Time{},
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		return
+	}
+	mr.OK()
+}
+
+// treeProperties attaches the properties every object in tree had at
+// time at
+func (r *TreeRead) treeProperties(tree *proto.Tree, at time.Time) error {
+	switch tree.Type {
+	case msg.EntityRepository:
+		return r.repositoryProperties(tree.Repository, at)
+	case msg.EntityBucket:
+		return r.bucketProperties(tree.Bucket, at)
+	case msg.EntityGroup:
+		return r.groupProperties(tree.Group, at)
+	case msg.EntityCluster:
+		return r.clusterProperties(tree.Cluster, at)
+	case msg.EntityNode:
+		return r.nodeProperties(tree.Node, at)
+	}
+	return nil
+}
+
+// repositoryProperties sets the properties of repo and its members
+// at time at
+func (r *TreeRead) repositoryProperties(repo *proto.Repository, at time.Time) error {
+	var err error
+
+	if repo.Properties, err = r.propertiesAt(repo.ID,
+		msg.EntityRepository, at); err != nil {
+		return err
+	}
+	for i := range *repo.Members {
+		if err = r.bucketProperties(&(*repo.Members)[i], at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bucketProperties sets the properties of bucket and its members at
+// time at
+func (r *TreeRead) bucketProperties(bucket *proto.Bucket, at time.Time) error {
+	var err error
+
+	if bucket.Properties, err = r.propertiesAt(bucket.ID,
+		msg.EntityBucket, at); err != nil {
+		return err
+	}
+	for i := range *bucket.MemberGroups {
+		if err = r.groupProperties(&(*bucket.MemberGroups)[i], at); err != nil {
+			return err
+		}
+	}
+	for i := range *bucket.MemberClusters {
+		if err = r.clusterProperties(&(*bucket.MemberClusters)[i], at); err != nil {
+			return err
+		}
+	}
+	for i := range *bucket.MemberNodes {
+		if err = r.nodeProperties(&(*bucket.MemberNodes)[i], at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupProperties sets the properties of group and its members at
+// time at
+func (r *TreeRead) groupProperties(group *proto.Group, at time.Time) error {
+	var err error
+
+	if group.Properties, err = r.propertiesAt(group.ID,
+		msg.EntityGroup, at); err != nil {
+		return err
+	}
+	for i := range *group.MemberGroups {
+		if err = r.groupProperties(&(*group.MemberGroups)[i], at); err != nil {
+			return err
+		}
+	}
+	for i := range *group.MemberClusters {
+		if err = r.clusterProperties(&(*group.MemberClusters)[i], at); err != nil {
+			return err
+		}
+	}
+	for i := range *group.MemberNodes {
+		if err = r.nodeProperties(&(*group.MemberNodes)[i], at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clusterProperties sets the properties of cluster and its members
+// at time at
+func (r *TreeRead) clusterProperties(cluster *proto.Cluster, at time.Time) error {
+	var err error
+
+	if cluster.Properties, err = r.propertiesAt(cluster.ID,
+		msg.EntityCluster, at); err != nil {
+		return err
+	}
+	for i := range *cluster.Members {
+		if err = r.nodeProperties(&(*cluster.Members)[i], at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeProperties sets the properties of node at time at
+func (r *TreeRead) nodeProperties(node *proto.Node, at time.Time) error {
+	var err error
+
+	node.Properties, err = r.propertiesAt(node.ID, msg.EntityNode, at)
+	return err
+}
+
+// propertiesAt returns the properties object objectID had at time at
+func (r *TreeRead) propertiesAt(objectID, objectType string,
+	at time.Time) (*[]proto.Property, error) {
+	var (
+		rows    *sql.Rows
+		history []proto.PropertyHistory
+		err     error
+	)
+
+	if rows, err = r.stmtPropertyHistory.Query(
+		objectID,
+	); err != nil {
+		return nil, err
+	}
+
+	if history, err = scanPropertyHistory(
+		rows, objectID, objectType, at,
+	); err != nil {
+		return nil, err
+	}
+
+	properties := make([]proto.Property, len(history))
+	for i := range history {
+		properties[i] = history[i].Property
+	}
+	return &properties, nil
+}
+
+// scanPropertyHistory reads the property history of objectID from
+// rows and closes it. If at is not zero, only the property instances
+// that existed at time at are returned: created no later than at and
+// not deleted until after at.
+func scanPropertyHistory(rows *sql.Rows, objectID, objectType string,
+	at time.Time) ([]proto.PropertyHistory, error) {
+	var (
+		err                                          error
+		instanceID, repositoryID, propertyType, view string
+		sourceInstanceID, sourceType, sourceID       string
+		inheritance, childrenOnly                    bool
+		customID, customName, systemProperty         sql.NullString
+		serviceID, serviceName, oncallID, oncallName sql.NullString
+		value, createdByJob, deletedByJob            sql.NullString
+		createdAt                                    time.Time
+		deletedAt                                    pq.NullTime
+	)
+	defer rows.Close()
+
+	history := []proto.PropertyHistory{}
+	for rows.Next() {
+		if err = rows.Scan(
+			&instanceID,
+			&repositoryID,
+			&propertyType,
+			&view,
+			&sourceInstanceID,
+			&sourceType,
+			&sourceID,
+			&inheritance,
+			&childrenOnly,
+			&customID,
+			&customName,
+			&systemProperty,
+			&serviceID,
+			&serviceName,
+			&oncallID,
+			&oncallName,
+			&value,
+			&createdByJob,
+			&createdAt,
+			&deletedByJob,
+			&deletedAt,
+		); err != nil {
+			return nil, err
+		}
+		if !at.IsZero() && (createdAt.After(at) ||
+			(deletedAt.Valid && !deletedAt.Time.After(at))) {
+			continue
+		}
+
+		entry := proto.PropertyHistory{
+			ObjectID:   objectID,
+			ObjectType: objectType,
+			Property: proto.Property{
+				Type:             propertyType,
+				RepositoryID:     repositoryID,
+				InstanceID:       instanceID,
+				View:             view,
+				Inheritance:      inheritance,
+				ChildrenOnly:     childrenOnly,
+				IsInherited:      instanceID != sourceInstanceID,
+				SourceInstanceID: sourceInstanceID,
+				SourceType:       sourceType,
+				InheritedFrom:    sourceID,
+			},
+			CreatedAt:    createdAt.UTC().Format(time.RFC3339),
+			CreatedByJob: createdByJob.String,
+			DeletedByJob: deletedByJob.String,
+		}
+		if deletedAt.Valid {
+			entry.DeletedAt = deletedAt.Time.UTC().Format(time.RFC3339)
+		}
+
+		switch propertyType {
+		case msg.PropertyCustom:
+			entry.Property.Custom = &proto.PropertyCustom{
+				ID:           customID.String,
+				Name:         customName.String,
+				RepositoryID: repositoryID,
+				Value:        value.String,
+			}
+		case msg.PropertySystem:
+			entry.Property.System = &proto.PropertySystem{
+				Name:  systemProperty.String,
+				Value: value.String,
+			}
+		case msg.PropertyService:
+			entry.Property.Service = &proto.PropertyService{
+				ID:   serviceID.String,
+				Name: serviceName.String,
+			}
+		case msg.PropertyOncall:
+			entry.Property.Oncall = &proto.PropertyOncall{
+				ID:   oncallID.String,
+				Name: oncallName.String,
+			}
+		}
+		history = append(history, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,250 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var testPropertyAt = time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// testPropertyRecord is a property instance in the property history
+type testPropertyRecord struct {
+	instanceID string
+	createdAt  time.Time
+	deletedAt  *time.Time
+}
+
+// testPropertyRows returns the property history rows for records
+func testPropertyRows(records ...testPropertyRecord) sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		`instance_id`, `repository_id`, `property_type`, `view`,
+		`source_instance_id`, `source_object_type`, `source_object_id`,
+		`inheritance_enabled`, `children_only`, `custom_property_id`,
+		`custom_property`, `system_property`, `service_id`, `name`,
+		`oncall_duty_id`, `name`, `value`, `created_by_job`,
+		`created_at`, `deleted_by_job`, `deleted_at`,
+	})
+	for _, rec := range records {
+		var deletedAt, deletedBy driver.Value
+		if rec.deletedAt != nil {
+			deletedAt, deletedBy = *rec.deletedAt, `job-2`
+		}
+		rows.AddRow(rec.instanceID, `repo-1`, msg.PropertySystem, `any`,
+			rec.instanceID, msg.EntityRepository, `repo-1`, true, false,
+			nil, nil, `dns_zone`, nil, nil, nil, nil, `example.org`,
+			`job-1`, rec.createdAt, deletedBy, deletedAt)
+	}
+	return rows
+}
+
+// testTreeRead returns a TreeRead handler that reads the property
+// history from db
+func testTreeRead(t *testing.T, db *sql.DB,
+	mock sqlmock.Sqlmock) *TreeRead {
+	var err error
+
+	_, r := newTreeRead(1)
+	r.conn = db
+	mock.ExpectPrepare(`soma.property_history`)
+	if r.stmtPropertyHistory, err = db.Prepare(
+		stmt.PropertyHistoryList,
+	); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// testInstances returns the instance IDs of properties
+func testInstances(properties *[]proto.Property) []string {
+	instances := []string{}
+	if properties == nil {
+		return instances
+	}
+	for _, p := range *properties {
+		instances = append(instances, p.InstanceID)
+	}
+	return instances
+}
+
+func TestPropertiesAt(t *testing.T) {
+	at := testPropertyAt
+	before := at.Add(-time.Hour)
+	earlier := at.Add(-2 * time.Hour)
+	after := at.Add(time.Hour)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := testTreeRead(t, db, mock)
+
+	mock.ExpectQuery(`soma.property_history`).
+		WithArgs(`repo-1`).
+		WillReturnRows(testPropertyRows(
+			testPropertyRecord{`created-after`, after, nil},
+			testPropertyRecord{`deleted-before`, earlier, &before},
+			testPropertyRecord{`deleted-at`, before, &at},
+			testPropertyRecord{`created-at`, at, nil},
+			testPropertyRecord{`active`, before, nil},
+			testPropertyRecord{`deleted-after`, before, &after},
+		))
+
+	properties, err := r.propertiesAt(`repo-1`, msg.EntityRepository, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{`created-at`, `active`, `deleted-after`}
+	if instances := testInstances(properties); !reflect.DeepEqual(
+		instances, expected) {
+		t.Errorf("Expected properties %v at %s, got %v", expected,
+			at.Format(time.RFC3339), instances)
+	}
+	if p := (*properties)[0]; p.System == nil ||
+		p.System.Name != `dns_zone` || p.System.Value != `example.org` {
+		t.Errorf("Wrong property: %+v", p)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyHistory(t *testing.T) {
+	before := testPropertyAt.Add(-time.Hour)
+	after := testPropertyAt.Add(time.Hour)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := testTreeRead(t, db, mock)
+
+	// the history is not limited to a point in time
+	mock.ExpectQuery(`soma.property_history`).
+		WithArgs(`node-1`).
+		WillReturnRows(testPropertyRows(
+			testPropertyRecord{`deleted`, before, &before},
+			testPropertyRecord{`created-after`, after, nil},
+		))
+
+	q := &msg.Request{
+		Section: msg.SectionNodeConfig,
+		Action:  msg.ActionPropertyHistory,
+		Node:    proto.Node{ID: `node-1`},
+	}
+	result := msg.FromRequest(q)
+	r.propertyHistory(q, &result)
+
+	if result.Code != 200 {
+		t.Fatalf("Expected code 200, got %d: %v", result.Code, result.Error)
+	}
+	if len(result.PropertyHistory) != 2 {
+		t.Fatalf("Expected 2 history entries, got %d",
+			len(result.PropertyHistory))
+	}
+	if h := result.PropertyHistory[0]; h.ObjectID != `node-1` ||
+		h.ObjectType != msg.EntityNode ||
+		h.DeletedAt != before.Format(time.RFC3339) ||
+		h.DeletedByJob != `job-2` {
+		t.Errorf("Wrong history entry: %+v", h)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTreeProperties(t *testing.T) {
+	at := testPropertyAt
+	before := at.Add(-time.Hour)
+	after := at.Add(time.Hour)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	r := testTreeRead(t, db, mock)
+
+	tree := &proto.Tree{
+		ID:   `bucket-1`,
+		Type: msg.EntityBucket,
+		Bucket: &proto.Bucket{
+			ID: `bucket-1`,
+			MemberGroups: &[]proto.Group{{
+				ID:             `group-1`,
+				MemberGroups:   &[]proto.Group{},
+				MemberClusters: &[]proto.Cluster{},
+				MemberNodes:    &[]proto.Node{{ID: `node-1`}},
+			}},
+			MemberClusters: &[]proto.Cluster{},
+			MemberNodes:    &[]proto.Node{{ID: `node-2`}},
+		},
+	}
+
+	// every object of the tree is read once, depth first
+	for _, object := range []struct {
+		id      string
+		records []testPropertyRecord
+	}{
+		{`bucket-1`, []testPropertyRecord{{`b-active`, before, nil}}},
+		{`group-1`, []testPropertyRecord{{`g-created-after`, after, nil}}},
+		{`node-1`, []testPropertyRecord{
+			{`n1-deleted-before`, before, &before},
+			{`n1-active`, before, &after},
+		}},
+		{`node-2`, nil},
+	} {
+		mock.ExpectQuery(`soma.property_history`).
+			WithArgs(object.id).
+			WillReturnRows(testPropertyRows(object.records...))
+	}
+
+	if err = r.treeProperties(tree, at); err != nil {
+		t.Fatal(err)
+	}
+
+	group := (*tree.Bucket.MemberGroups)[0]
+	for _, object := range []struct {
+		id         string
+		properties *[]proto.Property
+		expected   []string
+	}{
+		{`bucket-1`, tree.Bucket.Properties, []string{`b-active`}},
+		{`group-1`, group.Properties, []string{}},
+		{`node-1`, (*group.MemberNodes)[0].Properties,
+			[]string{`n1-active`}},
+		{`node-2`, (*tree.Bucket.MemberNodes)[0].Properties, []string{}},
+	} {
+		if object.properties == nil {
+			t.Errorf("%s: properties not set", object.id)
+			continue
+		}
+		if instances := testInstances(object.properties); !reflect.DeepEqual(
+			instances, object.expected) {
+			t.Errorf("%s: expected properties %v, got %v", object.id,
+				object.expected, instances)
+		}
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
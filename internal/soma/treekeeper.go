@@ -34,6 +34,7 @@ var Metrics = make(map[string]metrics.Registry)
 // TreeKeeper handles the repository tree structure
 type TreeKeeper struct {
 	Input               chan msg.Request
+	System              chan msg.Request
 	Shutdown            chan struct{}
 	Stop                chan struct{}
 	errors              chan *tree.Error
@@ -85,6 +86,13 @@ type TreeKeeper struct {
 		isFrozen        bool
 		requiresRebuild bool
 		rebuildLevel    string
+		isShadow        bool
+	}
+	// orphaned jobs found by startupLoad
+	recovery struct {
+		replay []msg.Request
+		delay  time.Duration
+		failed []string
 	}
 	soma *Soma
 }
@@ -94,6 +102,7 @@ type TreeKeeper struct {
 func newTreeKeeper(length int) (tk *TreeKeeper) {
 	tk = &TreeKeeper{}
 	tk.Input = make(chan msg.Request, length)
+	tk.System = make(chan msg.Request, length)
 	tk.Shutdown = make(chan struct{})
 	tk.Stop = make(chan struct{})
 	return
@@ -146,6 +155,9 @@ func (tk *TreeKeeper) Run() {
 
 	// prepare statements early, some are used in tk.startupLoad()
 	var err error
+	// replayed orphaned jobs are processed before any new job
+	var replay <-chan time.Time
+	input := tk.Input
 	for statement, prepStmt := range map[string]**sql.Stmt{
 		stmt.TreekeeperDeleteDuplicateDetails:          &tk.stmtDelDuplicate,
 		stmt.TxDeployDetailClusterCustProp:             &tk.stmtClusterCustProp,
@@ -254,6 +266,10 @@ broken:
 		b.Inc(1)
 		defer b.Dec(1)
 
+		// a broken TreeKeeper accepts no further jobs, but still
+		// answers system requests so it can be inspected via fsck
+		tk.status.isReady = false
+
 		tickTack := time.NewTicker(time.Second * 10).C
 	hoverloop:
 		for {
@@ -264,6 +280,8 @@ broken:
 						" holding patterns!",
 					tk.meta.repoName, tk.meta.repoID)
 
+			case req := <-tk.System:
+				tk.sysProcess(&req)
 			case <-tk.Shutdown:
 				break hoverloop
 			case <-tk.Stop:
@@ -277,18 +295,32 @@ broken:
 	tk.appLog.Printf("TK[%s]: ready for service!", tk.meta.repoName)
 	tk.status.isReady = true
 
-	// in observer mode, the TreeKeeper does nothing after loading
-	// the tree
+	// release clients waiting on orphaned jobs that were failed
+	for _, jobID := range tk.recovery.failed {
+		tk.notifyJobBlock(jobID)
+	}
+	tk.recovery.failed = nil
+	if len(tk.recovery.replay) > 0 {
+		replay = time.After(tk.recovery.delay)
+		input = nil
+	}
+
+	// in observer mode, the TreeKeeper only answers system requests
+	// after loading the tree
 	if tk.soma.conf.Observer {
 		tk.appLog.Printf(
 			"TreeKeeper [%s] entered observer mode", tk.meta.repoName)
 
-		select {
-		case <-tk.Stop:
-			tk.stop()
-			goto stopsign
-		case <-tk.Shutdown:
-			goto exit
+		for {
+			select {
+			case <-tk.Stop:
+				tk.stop()
+				goto stopsign
+			case <-tk.Shutdown:
+				goto exit
+			case req := <-tk.System:
+				tk.sysProcess(&req)
+			}
 		}
 	}
 
@@ -313,6 +345,8 @@ stopsign:
 			case <-tk.Shutdown:
 				goto exit
 			case <-tk.Stop:
+			case req := <-tk.System:
+				tk.sysProcess(&req)
 			}
 		}
 	}
@@ -324,7 +358,27 @@ runloop:
 		case <-tk.Stop:
 			tk.stop()
 			goto stopsign
-		case req := <-tk.Input:
+		case req := <-tk.System:
+			tk.sysProcess(&req)
+		case <-replay:
+			for i := range tk.recovery.replay {
+				tk.process(&tk.recovery.replay[i])
+				tk.notifyJobBlock(tk.recovery.replay[i].JobID.String())
+				if !tk.status.isFrozen {
+					tk.buildDeploymentDetails()
+					if tk.status.isBroken {
+						goto broken
+					}
+					tk.orderDeploymentDetails()
+					if tk.status.isBroken {
+						goto broken
+					}
+				}
+			}
+			tk.recovery.replay = nil
+			replay = nil
+			input = tk.Input
+		case req := <-input:
 			tk.process(&req)
 			tk.soma.handlerMap.Get(`job_block`).(*JobBlock).Notify <- req.JobID.String()
 			if !tk.status.isFrozen {
@@ -371,6 +425,8 @@ func (tk *TreeKeeper) process(q *msg.Request) {
 		stm                                   map[string]*sql.Stmt
 		jobLog                                *logrus.Logger
 		lfh                                   *os.File
+		clone                                 *repositoryClone
+		newInstances                          uint64
 	)
 	tk.treeLog.Infof("Processing job %s for RequestID %s",
 		q.JobID.String(),
@@ -379,7 +435,7 @@ func (tk *TreeKeeper) process(q *msg.Request) {
 
 	// check if the user is still permitted to issue the asynchronous
 	// request at execution time
-	if !super.IsAuthorized(q) {
+	if !tk.isAuthorized(q) {
 		// open multi-statement transaction so we can close the job
 		// and mark it as failed inside the database, otherwise it would
 		// be loaded and attempted at every startup
@@ -436,61 +492,7 @@ func (tk *TreeKeeper) process(q *msg.Request) {
 
 	tk.tree.Begin()
 
-	// q.Action == `rebuild` will fall through switch
-	switch {
-	// property requests
-	case q.Action == msg.ActionPropertyCreate:
-		tk.addProperty(q)
-	case q.Action == msg.ActionPropertyDestroy:
-		tk.rmProperty(q)
-	case q.Action == msg.ActionPropertyUpdate:
-		tk.updateProperty(q)
-	// check requests
-	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionCreate:
-		err = tk.addCheck(&q.CheckConfig)
-	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionDestroy:
-		err = tk.rmCheck(&q.CheckConfig)
-	// tree object: membership requests
-	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityNode:
-		tk.treeNode(q)
-	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityNode:
-		tk.treeNode(q)
-	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityCluster:
-		tk.treeCluster(q)
-	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityCluster:
-		tk.treeCluster(q)
-	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityGroup:
-		tk.treeGroup(q)
-	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityGroup:
-		tk.treeGroup(q)
-	// tree object: create/destroy requests
-	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionAssign:
-		tk.treeNode(q)
-	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionUnassign:
-		tk.treeNode(q)
-	case q.Section == msg.SectionCluster && q.Action == msg.ActionCreate:
-		tk.treeCluster(q)
-	case q.Section == msg.SectionCluster && q.Action == msg.ActionDestroy:
-		tk.treeCluster(q)
-	case q.Section == msg.SectionGroup && q.Action == msg.ActionCreate:
-		tk.treeGroup(q)
-	case q.Section == msg.SectionGroup && q.Action == msg.ActionDestroy:
-		tk.treeGroup(q)
-	case q.Section == msg.SectionBucket && q.Action == msg.ActionCreate:
-		tk.treeBucket(q)
-	case q.Section == msg.SectionBucket && q.Action == msg.ActionDestroy:
-		tk.treeBucket(q)
-	case q.Section == msg.SectionRepository && q.Action == msg.ActionDestroy:
-		tk.treeRepository(q)
-	// tree object: rename requests
-	case q.Section == msg.SectionBucket && q.Action == msg.ActionRename:
-		tk.treeBucket(q)
-	case q.Section == msg.SectionRepository && q.Action == msg.ActionRename:
-		tk.treeRepository(q)
-	// tree object: repossession requests
-	case q.Section == msg.SectionRepository && q.Action == msg.ActionRepossess:
-		tk.treeRepository(q)
-	}
+	clone, err = tk.apply(q, tx)
 
 	// check if we accumulated an error in one of the switch cases
 	if err != nil {
@@ -521,6 +523,15 @@ func (tk *TreeKeeper) process(q *msg.Request) {
 		); err != nil {
 			goto bailout
 		}
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionUpdate:
+		// save the updated check configuration as part of the
+		// transaction before processing the action channel
+		if err = tk.txCheckConfigUpdate(
+			q.CheckConfig,
+			stm,
+		); err != nil {
+			goto bailout
+		}
 	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionDestroy:
 		// mark the check configuration as deleted
 		if _, err = tx.Exec(
@@ -538,6 +549,34 @@ func (tk *TreeKeeper) process(q *msg.Request) {
 		); err != nil {
 			goto bailout
 		}
+	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionLifecycle:
+		// record the lifecycle state of the node, which the check
+		// instance deployments of the node are evaluated against
+		if _, err = tx.Exec(
+			stmt.TxNodeLifecycleSet,
+			q.Node.ID,
+			q.Node.Lifecycle,
+			q.AuthUser,
+		); err != nil {
+			goto bailout
+		}
+	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionUnassign:
+		// unassigned nodes leave their lifecycle state, it does not
+		// carry over to a later assignment
+		if _, err = tx.Exec(
+			stmt.TxNodeLifecycleSet,
+			q.Node.ID,
+			``,
+			q.AuthUser,
+		); err != nil {
+			goto bailout
+		}
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionClone:
+		// save the cloned repository and its check configurations
+		// before processing the action channel
+		if err = tk.txClone(clone, q.AuthUser, tx, stm); err != nil {
+			goto bailout
+		}
 	}
 
 	// if the error channel has entries, we can fully ignore the
@@ -550,7 +589,7 @@ func (tk *TreeKeeper) process(q *msg.Request) {
 		}
 		hasErrors = true
 		if err == nil {
-			err = fmt.Errorf(e.Action)
+			err = fmt.Errorf("%s", e.Action)
 		}
 	}
 	if hasErrors {
@@ -590,6 +629,7 @@ actionloop:
 				tree.ActionMemberNew,
 				tree.ActionMemberRemoved,
 				tree.ActionNodeAssignment,
+				tree.ActionNodeMove,
 				tree.ActionPropertyDelete,
 				tree.ActionPropertyNew,
 				tree.ActionPropertyUpdate,
@@ -604,7 +644,7 @@ actionloop:
 			tree.ActionPropertyDelete,
 			tree.ActionPropertyNew,
 			tree.ActionPropertyUpdate:
-			if err = tk.txProperty(a, stm); err != nil {
+			if err = tk.txProperty(a, stm, q.JobID.String()); err != nil {
 				break actionloop
 			}
 		case
@@ -620,12 +660,16 @@ actionloop:
 			if err = tk.txCheckInstance(a, stm); err != nil {
 				break actionloop
 			}
+			if a.Action == tree.ActionCheckInstanceCreate {
+				newInstances++
+			}
 		case
 			tree.ActionCreate,
 			tree.ActionDelete,
 			tree.ActionMemberNew,
 			tree.ActionMemberRemoved,
 			tree.ActionNodeAssignment,
+			tree.ActionNodeMove,
 			tree.ActionRename,
 			tree.ActionRepossess,
 			tree.ActionUpdate:
@@ -655,21 +699,8 @@ actionloop:
 		goto bailout
 	}
 
-	if !tk.status.requiresRebuild {
-		// mark job as finished
-		if _, err = tx.Exec(
-			stmt.TxFinishJob,
-			q.JobID.String(),
-			time.Now().UTC(),
-			"success",
-			``, // empty error field
-		); err != nil {
-			goto bailout
-		}
-	}
-
-	// commit transaction
-	if err = tx.Commit(); err != nil {
+	// enforce the check instance budgets and commit transaction
+	if err = tk.commitJob(q, tx, newInstances); err != nil {
 		goto bailout
 	}
 	tk.appLog.Printf("SUCCESS - Finished job: %s", q.JobID.String())
@@ -678,30 +709,17 @@ actionloop:
 	tk.tree.Commit()
 
 	// update permission cache
-	switch q.Section {
-	case msg.SectionRepository, msg.SectionRepositoryMgmt, msg.SectionBucket, msg.SectionGroup, msg.SectionCluster:
-		switch q.Action {
-		case msg.ActionCreate, msg.ActionDestroy:
-			go func() {
-				super := tk.soma.getSupervisor()
-				super.Update <- msg.CacheUpdateFromRequest(q)
-			}()
-		}
-	case msg.SectionNodeConfig:
-		switch q.Action {
-		case msg.ActionAssign, msg.ActionUnassign:
-			go func() {
-				super := tk.soma.getSupervisor()
-				super.Update <- msg.CacheUpdateFromRequest(q)
-			}()
-		}
-	}
+	tk.updateCache(q)
+
 	// shutdown if the successful job was a repository::destroy
 	switch {
 	case q.Section == msg.SectionRepository && q.Action == msg.ActionDestroy:
 		tk.ShutdownNow()
 		keeper := fmt.Sprintf("repository_%s", tk.meta.repoName)
 		tk.soma.handlerMap.Del(keeper)
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionClone:
+		// start the TreeKeeper for the cloned repository
+		tk.spawnClone(clone)
 	}
 	return
 
@@ -739,6 +757,148 @@ unauthorized:
 	return
 }
 
+// apply performs the tree changes of request q. q.Action == `rebuild`
+// will fall through the switch.
+func (tk *TreeKeeper) apply(q *msg.Request, tx *sql.Tx) (*repositoryClone, error) {
+	switch {
+	// property requests
+	case q.Action == msg.ActionPropertyCreate:
+		tk.addProperty(q)
+	case q.Action == msg.ActionPropertyDestroy:
+		tk.rmProperty(q)
+	case q.Action == msg.ActionPropertyUpdate:
+		tk.updateProperty(q)
+	// check requests
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionCreate:
+		return nil, tk.addCheck(&q.CheckConfig)
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionDestroy:
+		return nil, tk.rmCheck(&q.CheckConfig)
+	case q.Section == msg.SectionCheckConfig && q.Action == msg.ActionUpdate:
+		return nil, tk.updateCheck(&q.CheckConfig)
+	// tree object: membership requests
+	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityNode:
+		tk.treeNode(q)
+	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityNode:
+		tk.treeNode(q)
+	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityCluster:
+		tk.treeCluster(q)
+	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityCluster:
+		tk.treeCluster(q)
+	case q.Action == msg.ActionMemberAssign && q.TargetEntity == msg.EntityGroup:
+		tk.treeGroup(q)
+	case q.Action == msg.ActionMemberUnassign && q.TargetEntity == msg.EntityGroup:
+		tk.treeGroup(q)
+	// tree object: create/destroy requests
+	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionAssign:
+		tk.treeNode(q)
+	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionUnassign:
+		tk.treeNode(q)
+	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionMove:
+		tk.treeNode(q)
+	case q.Section == msg.SectionNodeConfig && q.Action == msg.ActionLifecycle:
+		tk.treeNode(q)
+	case q.Section == msg.SectionCluster && q.Action == msg.ActionCreate:
+		tk.treeCluster(q)
+	case q.Section == msg.SectionCluster && q.Action == msg.ActionDestroy:
+		tk.treeCluster(q)
+	case q.Section == msg.SectionGroup && q.Action == msg.ActionCreate:
+		tk.treeGroup(q)
+	case q.Section == msg.SectionGroup && q.Action == msg.ActionDestroy:
+		tk.treeGroup(q)
+	case q.Section == msg.SectionBucket && q.Action == msg.ActionCreate:
+		tk.treeBucket(q)
+	case q.Section == msg.SectionBucket && q.Action == msg.ActionDestroy:
+		tk.treeBucket(q)
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionDestroy:
+		tk.treeRepository(q)
+	// tree object: rename requests
+	case q.Section == msg.SectionBucket && q.Action == msg.ActionRename:
+		tk.treeBucket(q)
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionRename:
+		tk.treeRepository(q)
+	// tree object: repossession requests
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionRepossess:
+		tk.treeRepository(q)
+	// change set requests
+	case q.Section == msg.SectionChangeSet:
+		return nil, tk.applyChangeSet(q, tx)
+	// tree object: clone requests
+	case q.Section == msg.SectionRepository && q.Action == msg.ActionClone:
+		return tk.treeClone(q, tx)
+	}
+	return nil, nil
+}
+
+// updateCache updates the permission cache with the tree objects
+// created or destroyed by the committed request q
+func (tk *TreeKeeper) updateCache(q *msg.Request) {
+	switch q.Section {
+	case msg.SectionChangeSet:
+		for i := range q.ChangeSet {
+			tk.updateCache(&q.ChangeSet[i])
+		}
+	case msg.SectionRepository, msg.SectionRepositoryMgmt, msg.SectionBucket, msg.SectionGroup, msg.SectionCluster:
+		switch q.Action {
+		case msg.ActionCreate, msg.ActionDestroy:
+			go func() {
+				super := tk.soma.getSupervisor()
+				super.Update <- msg.CacheUpdateFromRequest(q)
+			}()
+		}
+	case msg.SectionNodeConfig:
+		switch q.Action {
+		case msg.ActionAssign, msg.ActionUnassign, msg.ActionMove:
+			go func() {
+				super := tk.soma.getSupervisor()
+				super.Update <- msg.CacheUpdateFromRequest(q)
+			}()
+		}
+	}
+}
+
+// commitJob marks job q as finished and commits its transaction tx.
+// Jobs that exceed the check instance budgets with the newInstances
+// check instances they created are not committed, they must be rolled
+// back by the caller.
+func (tk *TreeKeeper) commitJob(q *msg.Request, tx *sql.Tx,
+	newInstances uint64) error {
+	if !tk.status.requiresRebuild {
+		if err := tk.checkBudget(q, tx, newInstances); err != nil {
+			return err
+		}
+
+		// mark job as finished
+		if _, err := tx.Exec(
+			stmt.TxFinishJob,
+			q.JobID.String(),
+			time.Now().UTC(),
+			"success",
+			``, // empty error field
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// isAuthorized checks if the user is still permitted to issue the
+// asynchronous request at execution time. For change sets, the user
+// must still be permitted to issue every step.
+func (tk *TreeKeeper) isAuthorized(q *msg.Request) bool {
+	if !super.IsAuthorized(q) {
+		return false
+	}
+	if q.Flag.OverrideBudget && !super.IsAuthorized(msg.BudgetOverrideFromRequest(q)) {
+		return false
+	}
+	for i := range q.ChangeSet {
+		if !super.IsAuthorized(&q.ChangeSet[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // ShutdownNow signals the handler to shut down
 func (tk *TreeKeeper) ShutdownNow() {
 	if !tk.isStopped() {
@@ -787,6 +947,8 @@ func (tk *TreeKeeper) startTx() (
 	for name, statement := range map[string]string{
 		`PropertyInstanceCreate`:          stmt.TxPropertyInstanceCreate,
 		`PropertyInstanceDelete`:          stmt.TxPropertyInstanceDelete,
+		`PropertyHistoryCreate`:           stmt.TxPropertyHistoryCreate,
+		`PropertyHistoryDelete`:           stmt.TxPropertyHistoryDelete,
 		`RepositoryPropertyOncallCreate`:  stmt.TxRepositoryPropertyOncallCreate,
 		`RepositoryPropertyOncallDelete`:  stmt.TxRepositoryPropertyOncallDelete,
 		`RepositoryPropertyServiceCreate`: stmt.TxRepositoryPropertyServiceCreate,
@@ -876,6 +1038,8 @@ func (tk *TreeKeeper) startTx() (
 		`CreateCheckConfigurationConstraintCustom`:    stmt.TxCreateCheckConfigurationConstraintCustom,
 		`CreateCheckConfigurationConstraintService`:   stmt.TxCreateCheckConfigurationConstraintService,
 		`CreateCheckConfigurationConstraintAttribute`: stmt.TxCreateCheckConfigurationConstraintAttribute,
+		`UpdateCheckConfigurationBase`:                stmt.TxUpdateCheckConfigurationBase,
+		`DeleteCheckConfigurationThresholds`:          stmt.TxDeleteCheckConfigurationThresholds,
 	} {
 		if stMap[name], err = tx.Prepare(statement); err != nil {
 			err = fmt.Errorf("tk.Prepare(%s) error: %s",
@@ -904,6 +1068,10 @@ func (tk *TreeKeeper) startTx() (
 		`GroupMemberRemoveGroup`:   stmt.TxGroupMemberRemoveGroup,
 		`GroupMemberRemoveNode`:    stmt.TxGroupMemberRemoveNode,
 		`GroupUpdate`:              stmt.TxGroupUpdate,
+		`NodeMoveCheckConfigs`:     stmt.TxNodeMoveCheckConfigs,
+		`NodeMoveChecks`:           stmt.TxNodeMoveChecks,
+		`NodeMoveCustomProperties`: stmt.TxNodeMoveCustomProperties,
+		`NodeMoveGrants`:           stmt.TxNodeMoveGrants,
 		`NodeUnassignFromBucket`:   stmt.TxNodeUnassignFromBucket,
 		`UpdateNodeState`:          stmt.TxUpdateNodeState,
 		`repository::rename`:       stmt.TxRepositoryRename,
@@ -0,0 +1,44 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mjolnir42/soma/internal/msg"
+)
+
+// applyChangeSet performs the tree changes of all steps of change set
+// q in order, as part of the job of q. The first step that fails
+// aborts the change set, which rolls back the changes of all steps.
+func (tk *TreeKeeper) applyChangeSet(q *msg.Request, tx *sql.Tx) error {
+	for i := range q.ChangeSet {
+		step := &q.ChangeSet[i]
+		step.JobID = q.JobID
+
+		if _, err := tk.apply(step, tx); err != nil {
+			return changeSetError(i, step, err)
+		}
+
+		// tree errors of this step are reported with the step, the
+		// error channel must be empty when the job is aborted
+		if len(tk.errors) > 0 {
+			e := <-tk.errors
+			tk.drain(`error`)
+			err := e.Error()
+			if e.Text != `` {
+				err = fmt.Errorf("%s: %s", err, e.Text)
+			}
+			return changeSetError(i, step, err)
+		}
+	}
+	return nil
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,125 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/mjolnir42/soma/internal/config"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+)
+
+// checkBudget verifies that job q, which created created new check
+// instances, stays within the configured check instance budgets. It
+// must run inside the job's transaction after all check instances
+// have been persisted.
+func (tk *TreeKeeper) checkBudget(q *msg.Request, tx *sql.Tx,
+	created uint64) error {
+	var (
+		err            error
+		count          uint64
+		monitoring     string
+		rows           *sql.Rows
+		exceeded       []string
+		budget         config.Budget
+		repositoryName string
+	)
+	budget = tk.soma.conf.CheckBudget
+	repositoryName = tk.meta.repoName
+
+	// jobs that do not create check instances can not exceed the
+	// budget, even if the limits have been lowered in the meantime
+	if created == 0 {
+		return nil
+	}
+
+	if q.Flag.OverrideBudget {
+		tk.treeLog.Printf("Check instance budget overridden for job %s,"+
+			" %d new check instances", q.JobID.String(), created)
+		return nil
+	}
+
+	exceeded = append(exceeded, budgetExceeded(
+		`this job`, `creates`, created, budget.Job)...)
+
+	if budget.Repository > 0 {
+		if err = tx.QueryRow(
+			stmt.TxCheckBudgetRepository,
+			tk.meta.repoID,
+		).Scan(
+			&count,
+		); err != nil {
+			return err
+		}
+		exceeded = append(exceeded, budgetExceeded(
+			fmt.Sprintf("repository %s", repositoryName),
+			`has`, count, budget.Repository)...)
+	}
+
+	if budget.Team > 0 {
+		if err = tx.QueryRow(
+			stmt.TxCheckBudgetTeam,
+			tk.meta.repoID,
+		).Scan(
+			&count,
+		); err != nil {
+			return err
+		}
+		exceeded = append(exceeded, budgetExceeded(
+			fmt.Sprintf("the team owning repository %s", repositoryName),
+			`has`, count, budget.Team)...)
+	}
+
+	if budget.Monitoring > 0 {
+		if rows, err = tx.Query(
+			stmt.TxCheckBudgetMonitoring,
+			tk.meta.repoID,
+		); err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			if err = rows.Scan(
+				&monitoring,
+				&count,
+			); err != nil {
+				return err
+			}
+			exceeded = append(exceeded, budgetExceeded(
+				fmt.Sprintf("monitoring system %s", monitoring),
+				`has`, count, budget.Monitoring)...)
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	if len(exceeded) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Check instance budget exceeded: %s. The job"+
+		" would create %d new check instances, deliberate large"+
+		" rollouts require the budget to be overridden",
+		strings.Join(exceeded, `; `), created)
+}
+
+// budgetExceeded returns a description of the exceeded limit if
+// count is above limit. A limit of 0 is unlimited.
+func budgetExceeded(scope, verb string, count, limit uint64) []string {
+	if limit == 0 || count <= limit {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s %s %d check instances, the limit is %d",
+		scope, verb, count, limit)}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
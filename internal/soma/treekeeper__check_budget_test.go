@@ -0,0 +1,109 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/config"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/satori/go.uuid"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestBudgetExceeded(t *testing.T) {
+	tests := []struct {
+		name         string
+		count, limit uint64
+		exceeded     bool
+	}{
+		{`unlimited`, 1000000, 0, false},
+		{`below`, 9, 10, false},
+		{`at limit`, 10, 10, false},
+		{`above`, 11, 10, true},
+	}
+
+	for _, test := range tests {
+		res := budgetExceeded(`repository example`, `has`,
+			test.count, test.limit)
+		if (len(res) != 0) != test.exceeded {
+			t.Errorf("%s: budgetExceeded(%d, %d) returned %v",
+				test.name, test.count, test.limit, res)
+		}
+	}
+
+	res := budgetExceeded(`this job`, `creates`, 12000, 10000)
+	if len(res) != 1 || res[0] != `this job creates 12000 check instances, the limit is 10000` {
+		t.Errorf("Unexpected description: %v", res)
+	}
+}
+
+func TestCommitJobOverBudget(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tk := testRecoveryKeeper(db)
+	tk.treeLog = logrus.New()
+	tk.treeLog.Out = ioutil.Discard
+	tk.soma.conf.CheckBudget = config.Budget{Repository: 50000}
+
+	q := &msg.Request{
+		Section: msg.SectionCheckConfig,
+		Action:  msg.ActionCreate,
+		JobID:   uuid.Must(uuid.NewV4()),
+	}
+
+	// the job is neither marked finished nor committed, the caller
+	// rolls it back
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COUNT`).
+		WithArgs(tk.meta.repoID).
+		WillReturnRows(sqlmock.NewRows([]string{`count`}).AddRow(int64(62000)))
+	mock.ExpectRollback()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tk.commitJob(q, tx, 12000)
+	if err == nil || !strings.HasPrefix(err.Error(),
+		`Check instance budget exceeded: repository example has 62000`) {
+		t.Errorf("Over budget job was not rejected: %v", err)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Error(err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// an overridden budget commits the same job
+	q.Flag.OverrideBudget = true
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE soma.job SET finished_at`).
+		WithArgs(q.JobID.String(), sqlmock.AnyArg(), `success`, ``).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if tx, err = db.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if err = tk.commitJob(q, tx, 12000); err != nil {
+		t.Errorf("Overridden job was not committed: %s", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,503 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/internal/tree"
+	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
+)
+
+// repositoryClone holds the state of a repository clone while the
+// job that creates it is processed
+type repositoryClone struct {
+	repository proto.Repository
+	tree       *tree.Tree
+	actions    chan *tree.Action
+	errors     chan *tree.Error
+	// custom property definitions of the new repository, and the
+	// mapping from source to clone custom property IDs
+	custom   []proto.PropertyCustom
+	customID map[string]string
+	// cloned objects in creation order
+	objects []cloneObject
+	// check configurations of the new repository
+	configs []proto.CheckConfig
+}
+
+// cloneObject maps an object of the source repository onto its copy
+type cloneObject struct {
+	entity     string
+	sourceID   string
+	cloneID    string
+	bucketID   string
+	properties []tree.Property
+	checks     []tree.Check
+}
+
+// treeClone builds a copy of the repository tree inside a new,
+// separate tree. Buckets, groups, clusters, properties and checks are
+// copied with new IDs, node assignments are not. The new repository
+// is owned by the team of the source repository, since service
+// properties and constraints are bound to the team. The actions of
+// the new tree are forwarded onto the action channel of the
+// TreeKeeper for persistence.
+func (tk *TreeKeeper) treeClone(q *msg.Request, tx *sql.Tx) (*repositoryClone, error) {
+	var err error
+
+	if q.Update.Repository.TeamID != tk.meta.teamID {
+		return nil, fmt.Errorf("Repository %s is not owned by team %s",
+			tk.meta.repoName, q.Update.Repository.TeamID)
+	}
+
+	c := &repositoryClone{
+		repository: proto.Repository{
+			ID:       q.Update.Repository.ID,
+			Name:     q.Update.Repository.Name,
+			TeamID:   q.Update.Repository.TeamID,
+			IsActive: true,
+		},
+		actions:  make(chan *tree.Action, 1024000),
+		errors:   make(chan *tree.Error, 1024000),
+		custom:   []proto.PropertyCustom{},
+		customID: map[string]string{},
+		objects:  []cloneObject{},
+		configs:  []proto.CheckConfig{},
+	}
+	if _, err = uuid.FromString(c.repository.ID); err != nil {
+		return nil, err
+	}
+
+	if err = tk.cloneCustomProperties(c, tx); err != nil {
+		return nil, err
+	}
+
+	c.tree = tree.New(tree.Spec{
+		ID:     uuid.Must(uuid.NewV4()).String(),
+		Name:   fmt.Sprintf("root_%s", c.repository.Name),
+		Action: c.actions,
+		Log:    tk.treeLog,
+	})
+	c.tree.RegisterErrChan(c.errors)
+
+	tree.NewRepository(tree.RepositorySpec{
+		ID:      c.repository.ID,
+		Name:    c.repository.Name,
+		Team:    c.repository.TeamID,
+		Deleted: false,
+		Active:  c.repository.IsActive,
+	}).Attach(tree.AttachRequest{
+		Root:       c.tree,
+		ParentType: "root",
+		ParentID:   c.tree.GetID(),
+	})
+	c.tree.SetError()
+
+	// clone the structure of the repository
+	source := tk.tree.Child.Clone()
+	c.objects = append(c.objects, cloneObject{
+		entity:   msg.EntityRepository,
+		sourceID: source.ID.String(),
+		cloneID:  c.repository.ID,
+		properties: cloneProperties(source.PropertyOncall,
+			source.PropertyService, source.PropertySystem,
+			source.PropertyCustom),
+		checks: cloneChecks(source.Checks),
+	})
+	for _, child := range source.Children {
+		bucket, ok := child.(*tree.Bucket)
+		if !ok || bucket.Deleted {
+			continue
+		}
+		tk.cloneBucket(c, bucket)
+	}
+
+	// copy properties and checks onto the new objects
+	if err = tk.cloneObjectData(c, tx); err != nil {
+		return nil, err
+	}
+	c.tree.ComputeCheckInstances()
+
+	// forward errors and actions of the new tree
+	for i := len(c.errors); i > 0; i-- {
+		tk.errors <- <-c.errors
+	}
+	for i := len(c.actions); i > 0; i-- {
+		a := <-c.actions
+		switch {
+		case a.Type == `errorchannel`, a.Type == `fault`:
+			continue
+		case a.Type == msg.EntityRepository && a.Action == tree.ActionCreate:
+			// the repository is created by txClone
+			continue
+		}
+		tk.actions <- a
+	}
+	return c, nil
+}
+
+// cloneCustomProperties creates new definitions for all custom
+// properties of the source repository
+func (tk *TreeKeeper) cloneCustomProperties(c *repositoryClone,
+	tx *sql.Tx) error {
+	var (
+		err                    error
+		rows                   *sql.Rows
+		customID, repoID, name string
+		rawSchema              sql.NullString
+		schema                 *proto.PropertySchema
+	)
+
+	if rows, err = tx.Query(
+		stmt.PropertyCustomList,
+		tk.meta.repoID,
+	); err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&customID,
+			&repoID,
+			&name,
+			&rawSchema,
+		); err != nil {
+			rows.Close()
+			return err
+		}
+		if schema, err = decodePropertySchema(rawSchema); err != nil {
+			rows.Close()
+			return err
+		}
+		cloneID := uuid.Must(uuid.NewV4()).String()
+		c.customID[customID] = cloneID
+		c.custom = append(c.custom, proto.PropertyCustom{
+			ID:           cloneID,
+			RepositoryID: c.repository.ID,
+			Name:         name,
+			Schema:       schema,
+		})
+	}
+	return rows.Err()
+}
+
+// cloneBucket copies bucket and all its groups and clusters into
+// the new tree
+func (tk *TreeKeeper) cloneBucket(c *repositoryClone, bucket *tree.Bucket) {
+	bucketID := uuid.Must(uuid.NewV4()).String()
+
+	// buckets are prefixed with the name of their repository
+	name := fmt.Sprintf("%s_%s", c.repository.Name, bucket.Name)
+	if strings.HasPrefix(bucket.Name, tk.meta.repoName) {
+		name = c.repository.Name + strings.TrimPrefix(
+			bucket.Name, tk.meta.repoName)
+	}
+
+	tree.NewBucket(tree.BucketSpec{
+		ID:          bucketID,
+		Name:        name,
+		Environment: bucket.Environment,
+		Team:        c.repository.TeamID,
+		Deleted:     false,
+		Frozen:      bucket.Frozen,
+		Repository:  c.repository.ID,
+	}).Attach(tree.AttachRequest{
+		Root:       c.tree,
+		ParentType: msg.EntityRepository,
+		ParentID:   c.repository.ID,
+		ParentName: c.repository.Name,
+	})
+	c.objects = append(c.objects, cloneObject{
+		entity:   msg.EntityBucket,
+		sourceID: bucket.ID.String(),
+		cloneID:  bucketID,
+		bucketID: bucketID,
+		properties: cloneProperties(bucket.PropertyOncall,
+			bucket.PropertyService, bucket.PropertySystem,
+			bucket.PropertyCustom),
+		checks: cloneChecks(bucket.Checks),
+	})
+
+	for _, child := range bucket.Children {
+		tk.cloneMember(c, child, msg.EntityBucket, bucketID, bucketID)
+	}
+}
+
+// cloneMember copies a group or cluster into the new tree. Groups
+// and clusters are created inside the bucket and then assigned to
+// their parent group, the same way the API would build them. Nodes
+// are not cloned.
+func (tk *TreeKeeper) cloneMember(c *repositoryClone, child interface{},
+	parentType, parentID, bucketID string) {
+
+	switch m := child.(type) {
+	case *tree.Group:
+		groupID := uuid.Must(uuid.NewV4()).String()
+		tree.NewGroup(tree.GroupSpec{
+			ID:   groupID,
+			Name: m.Name,
+			Team: c.repository.TeamID,
+		}).Attach(tree.AttachRequest{
+			Root:       c.tree,
+			ParentType: msg.EntityBucket,
+			ParentID:   bucketID,
+		})
+		if parentType == msg.EntityGroup {
+			c.tree.Find(tree.FindRequest{
+				ElementType: msg.EntityGroup,
+				ElementID:   groupID,
+			}, true).(tree.BucketAttacher).ReAttach(tree.AttachRequest{
+				Root:       c.tree,
+				ParentType: msg.EntityGroup,
+				ParentID:   parentID,
+			})
+		}
+		c.objects = append(c.objects, cloneObject{
+			entity:   msg.EntityGroup,
+			sourceID: m.ID.String(),
+			cloneID:  groupID,
+			bucketID: bucketID,
+			properties: cloneProperties(m.PropertyOncall,
+				m.PropertyService, m.PropertySystem,
+				m.PropertyCustom),
+			checks: cloneChecks(m.Checks),
+		})
+		for _, ch := range m.Children {
+			tk.cloneMember(c, ch, msg.EntityGroup, groupID, bucketID)
+		}
+	case *tree.Cluster:
+		clusterID := uuid.Must(uuid.NewV4()).String()
+		tree.NewCluster(tree.ClusterSpec{
+			ID:   clusterID,
+			Name: m.Name,
+			Team: c.repository.TeamID,
+		}).Attach(tree.AttachRequest{
+			Root:       c.tree,
+			ParentType: msg.EntityBucket,
+			ParentID:   bucketID,
+		})
+		if parentType == msg.EntityGroup {
+			c.tree.Find(tree.FindRequest{
+				ElementType: msg.EntityCluster,
+				ElementID:   clusterID,
+			}, true).(tree.BucketAttacher).ReAttach(tree.AttachRequest{
+				Root:       c.tree,
+				ParentType: msg.EntityGroup,
+				ParentID:   parentID,
+			})
+		}
+		c.objects = append(c.objects, cloneObject{
+			entity:   msg.EntityCluster,
+			sourceID: m.ID.String(),
+			cloneID:  clusterID,
+			bucketID: bucketID,
+			properties: cloneProperties(m.PropertyOncall,
+				m.PropertyService, m.PropertySystem,
+				m.PropertyCustom),
+			checks: cloneChecks(m.Checks),
+		})
+	}
+}
+
+// cloneObjectData sets the properties and checks of the source
+// objects on their copies. Check configurations are read from the
+// database and stored with new IDs.
+func (tk *TreeKeeper) cloneObjectData(c *repositoryClone, tx *sql.Tx) error {
+	var err error
+	txMap := map[string]*sql.Stmt{}
+
+	for name, statement := range map[string]string{
+		`base`:          stmt.CheckConfigShowBase,
+		`threshold`:     stmt.CheckConfigShowThreshold,
+		`cstrCustom`:    stmt.CheckConfigShowConstrCustom,
+		`cstrSystem`:    stmt.CheckConfigShowConstrSystem,
+		`cstrNative`:    stmt.CheckConfigShowConstrNative,
+		`cstrService`:   stmt.CheckConfigShowConstrService,
+		`cstrAttribute`: stmt.CheckConfigShowConstrAttribute,
+		`cstrOncall`:    stmt.CheckConfigShowConstrOncall,
+	} {
+		if txMap[name], err = tx.Prepare(statement); err != nil {
+			return err
+		}
+		defer txMap[name].Close()
+	}
+
+	for _, obj := range c.objects {
+		for _, prop := range obj.properties {
+			pp := prop.MakeAction().Property
+			if pp.Type == msg.PropertyCustom {
+				customID, ok := c.customID[pp.Custom.ID]
+				if !ok {
+					return fmt.Errorf("Unknown custom property %s on %s %s",
+						pp.Custom.Name, obj.entity, obj.sourceID)
+				}
+				pp.Custom.ID = customID
+			}
+			c.tree.Find(tree.FindRequest{
+				ElementType: obj.entity,
+				ElementID:   obj.cloneID,
+			}, true).(tree.Propertier).SetProperty(tk.pTT(`add`, pp))
+		}
+
+		for _, chk := range obj.checks {
+			var conf *proto.CheckConfig
+			var treechk *tree.Check
+
+			if conf, err = exportCheckConfig(
+				txMap[`base`],
+				chk.ConfigID.String(),
+			); err != nil {
+				return err
+			} else if conf == nil {
+				return fmt.Errorf("Check configuration %s not found",
+					chk.ConfigID.String())
+			}
+			if conf.Thresholds, err = exportCheckConfigThresholds(
+				txMap[`threshold`],
+				chk.ConfigID.String(),
+			); err != nil {
+				return err
+			}
+			if conf.Constraints, err = exportCheckConfigConstraints(
+				txMap[`cstrCustom`],
+				txMap[`cstrSystem`],
+				txMap[`cstrNative`],
+				txMap[`cstrService`],
+				txMap[`cstrAttribute`],
+				txMap[`cstrOncall`],
+				chk.ConfigID.String(),
+			); err != nil {
+				return err
+			}
+
+			conf.ID = uuid.Must(uuid.NewV4()).String()
+			conf.RepositoryID = c.repository.ID
+			conf.BucketID = obj.bucketID
+			conf.ObjectID = obj.cloneID
+			for i := range conf.Constraints {
+				if conf.Constraints[i].ConstraintType != msg.ConstraintCustom {
+					continue
+				}
+				customID, ok := c.customID[conf.Constraints[i].Custom.ID]
+				if !ok {
+					return fmt.Errorf("Unknown custom property %s in"+
+						" check configuration %s",
+						conf.Constraints[i].Custom.Name, conf.Name)
+				}
+				conf.Constraints[i].Custom.ID = customID
+				conf.Constraints[i].Custom.RepositoryID = c.repository.ID
+			}
+
+			if treechk, err = tk.convertCheck(conf); err != nil {
+				return err
+			}
+			c.tree.Find(tree.FindRequest{
+				ElementType: obj.entity,
+				ElementID:   obj.cloneID,
+			}, true).SetCheck(*treechk)
+			c.configs = append(c.configs, *conf)
+		}
+	}
+	return nil
+}
+
+// txClone saves the new repository, its custom property definitions
+// and check configurations as part of the job transaction
+func (tk *TreeKeeper) txClone(c *repositoryClone, user string,
+	tx *sql.Tx, stm map[string]*sql.Stmt) error {
+	var (
+		err error
+		res sql.Result
+	)
+
+	if res, err = tx.Exec(
+		stmt.ForestAddRepository,
+		c.repository.ID,
+		c.repository.Name,
+		c.repository.IsActive,
+		false,
+		c.repository.TeamID,
+		user,
+	); err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows != 1 {
+		return fmt.Errorf("Repository %s could not be created,"+
+			" the name is already in use", c.repository.Name)
+	}
+
+	for _, custom := range c.custom {
+		var schema interface{}
+		if schema, err = encodePropertySchema(custom.Schema); err != nil {
+			return err
+		}
+		if _, err = tx.Exec(
+			stmt.PropertyCustomAdd,
+			custom.ID,
+			custom.RepositoryID,
+			custom.Name,
+			schema,
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, conf := range c.configs {
+		if err = tk.txCheckConfig(conf, stm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// spawnClone requests a TreeKeeper for the cloned repository from
+// the ForestCustodian
+func (tk *TreeKeeper) spawnClone(c *repositoryClone) {
+	handler := tk.soma.handlerMap.Get(`forest_custodian`).(*ForestCustodian)
+	handler.System <- msg.Request{
+		Section:    msg.SectionRepositoryMgmt,
+		Action:     msg.ActionClone,
+		Reply:      make(chan msg.Result, 1),
+		Repository: c.repository.Clone(),
+	}
+}
+
+// cloneProperties returns all properties that are set directly on
+// an object
+func cloneProperties(props ...map[string]tree.Property) []tree.Property {
+	local := []tree.Property{}
+	for _, pmap := range props {
+		for _, prop := range pmap {
+			if prop.GetIsInherited() {
+				continue
+			}
+			local = append(local, prop)
+		}
+	}
+	return local
+}
+
+// cloneChecks returns all checks that are set directly on an object
+func cloneChecks(checks map[string]tree.Check) []tree.Check {
+	local := []tree.Check{}
+	for _, chk := range checks {
+		if chk.Inherited {
+			continue
+		}
+		local = append(local, chk)
+	}
+	return local
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
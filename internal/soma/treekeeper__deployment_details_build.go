@@ -115,6 +115,7 @@ deploymentbuilder:
 
 			err = thresh.Scan(
 				&thr.Predicate.Symbol,
+				&thr.Predicate.Escalation,
 				&thr.Value,
 				&thr.Level.Name,
 				&thr.Level.ShortName,
@@ -126,6 +127,8 @@ deploymentbuilder:
 			}
 			detail.CheckConfig.Thresholds = append(detail.CheckConfig.Thresholds, thr)
 		}
+		detail.Thresholds = proto.SortThresholds(
+			detail.CheckConfig.Thresholds)
 
 		// XXX TODO
 		//detail.CheckConfiguration.Constraints = []somaproto.CheckConfigurationConstraint{}
@@ -0,0 +1,43 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"fmt"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
+)
+
+// evaluateCheck reports how the constraints of a check configuration
+// evaluate on the objects of the tree that the check reaches. The
+// tree is not modified.
+func (tk *TreeKeeper) evaluateCheck(q *msg.Request, mr *msg.Result) {
+	if _, err := uuid.FromString(q.CheckConfig.ID); err != nil {
+		mr.BadRequest(err, q.Section)
+		return
+	}
+
+	report := proto.CheckEvaluation{
+		RepositoryID:  tk.meta.repoID,
+		CheckConfigID: q.CheckConfig.ID,
+		Objects:       tk.tree.EvaluateCheck(q.CheckConfig.ID),
+	}
+	if len(report.Objects) == 0 {
+		mr.NotFound(fmt.Errorf("Check configuration %s not found in"+
+			" repository %s", q.CheckConfig.ID, tk.meta.repoName),
+			q.Section)
+		return
+	}
+
+	mr.Evaluation = append(mr.Evaluation, report)
+	mr.OK()
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
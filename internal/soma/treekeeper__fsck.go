@@ -0,0 +1,274 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/tree"
+	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
+)
+
+// sysProcess handles requests that are answered by the TreeKeeper
+// outside of the job queue
+func (tk *TreeKeeper) sysProcess(q *msg.Request) {
+	result := msg.FromRequest(q)
+
+	switch {
+	case tk.isStopped():
+		result.Unavailable(fmt.Errorf("TreeKeeper for repository %s is"+
+			" stopped", tk.meta.repoName))
+	case q.Action == msg.ActionFsck && tk.isBroken():
+		// broken repositories are the main reason to run fsck
+		tk.fsck(&result)
+	case !tk.isReady():
+		result.Unavailable(fmt.Errorf("TreeKeeper for repository %s is"+
+			" not ready", tk.meta.repoName))
+	case q.Action == msg.ActionFsck:
+		tk.fsck(&result)
+	case q.Action == msg.ActionEvaluate:
+		tk.evaluateCheck(q, &result)
+	default:
+		result.UnknownRequest(q)
+	}
+	q.Reply <- result
+}
+
+// fsck compares the tree held by the TreeKeeper with the tree that
+// would be loaded from the database on startup. Neither the tree nor
+// the database are modified. Jobs are not processed while the
+// comparison is running.
+func (tk *TreeKeeper) fsck(mr *msg.Result) {
+	report := proto.Fsck{
+		RepositoryID:   tk.meta.repoID,
+		RepositoryName: tk.meta.repoName,
+		Findings:       []proto.FsckFinding{},
+	}
+
+	if tk.isBroken() {
+		report.Findings = append(report.Findings, proto.FsckFinding{
+			Category:   `load`,
+			Problem:    `treeKeeperBroken`,
+			ObjectID:   tk.meta.repoID,
+			ObjectType: `repository`,
+			ObjectName: tk.meta.repoName,
+		})
+	}
+
+	live := tk.tree.Inventory()
+	stored, findings := tk.fsckLoad()
+	report.Findings = append(report.Findings, findings...)
+	report.Findings = append(report.Findings, fsckCompare(live, stored)...)
+	report.IsConsistent = len(report.Findings) == 0
+
+	mr.Fsck = append(mr.Fsck, report)
+	mr.OK()
+}
+
+// fsckLoad loads a shadow copy of the repository tree from the
+// database, using the regular startup code
+func (tk *TreeKeeper) fsckLoad() (tree.Inventory, []proto.FsckFinding) {
+	findings := []proto.FsckFinding{}
+	actionChan := make(chan *tree.Action, 1024000)
+	errChan := make(chan *tree.Error, 1024000)
+
+	discard := logrus.New()
+	discard.Out = ioutil.Discard
+	loadBuffer := &bytes.Buffer{}
+	loadLog := logrus.New()
+	loadLog.Out = loadBuffer
+
+	sTree := tree.New(tree.Spec{
+		ID:     uuid.Must(uuid.NewV4()).String(),
+		Name:   fmt.Sprintf("fsck_%s", tk.meta.repoName),
+		Action: actionChan,
+		Log:    loadLog,
+	})
+	sTree.RegisterErrChan(errChan)
+
+	tree.NewRepository(tree.RepositorySpec{
+		ID:      tk.meta.repoID,
+		Name:    tk.meta.repoName,
+		Team:    tk.meta.teamID,
+		Deleted: tk.tree.Child.Deleted,
+		Active:  tk.tree.Child.Active,
+	}).Attach(tree.AttachRequest{
+		Root:       sTree,
+		ParentType: "root",
+		ParentID:   sTree.GetID(),
+	})
+	sTree.SetError()
+
+	shadow := new(TreeKeeper)
+	shadow.conn = tk.conn
+	shadow.tree = sTree
+	shadow.errors = errChan
+	shadow.actions = actionChan
+	shadow.status.isShadow = true
+	shadow.meta.repoID = tk.meta.repoID
+	shadow.meta.repoName = tk.meta.repoName
+	shadow.meta.teamID = tk.meta.teamID
+	shadow.appLog = tk.appLog
+	shadow.treeLog = discard
+	shadow.startLog = loadLog
+	shadow.soma = tk.soma
+
+	shadow.startupLoad()
+
+	if shadow.status.isBroken {
+		findings = append(findings, proto.FsckFinding{
+			Category:      `load`,
+			Problem:       `loadFailed`,
+			ObjectID:      tk.meta.repoID,
+			ObjectType:    `repository`,
+			ObjectName:    tk.meta.repoName,
+			DatabaseValue: strings.TrimSpace(loadBuffer.String()),
+		})
+	}
+	shadow.drain(`action`)
+	shadow.drain(`error`)
+
+	return sTree.Inventory(), findings
+}
+
+// fsckCompare returns the differences between the live and the
+// stored inventory
+func fsckCompare(live, stored tree.Inventory) []proto.FsckFinding {
+	findings := []proto.FsckFinding{}
+
+	for id, l := range live {
+		s, ok := stored[id]
+		if !ok {
+			findings = append(findings, fsckFinding(`object`,
+				`missingInDatabase`, l, ``, ``, l.Name, ``))
+			continue
+		}
+		if l.Name != s.Name {
+			findings = append(findings, fsckFinding(`object`,
+				`mismatch`, l, ``, `name`, l.Name, s.Name))
+		}
+		if l.ParentID != s.ParentID {
+			findings = append(findings, fsckFinding(`object`,
+				`mismatch`, l, ``, `parentId`, l.ParentID, s.ParentID))
+		}
+
+		// property instances
+		for pID, lp := range l.Properties {
+			sp, ok := s.Properties[pID]
+			switch {
+			case !ok:
+				findings = append(findings, fsckFinding(`property`,
+					`missingInDatabase`, l, pID, ``, lp.String(), ``))
+			case lp != sp:
+				findings = append(findings, fsckFinding(`property`,
+					`mismatch`, l, pID, ``, lp.String(), sp.String()))
+			}
+		}
+		for pID, sp := range s.Properties {
+			if _, ok := l.Properties[pID]; !ok {
+				findings = append(findings, fsckFinding(`property`,
+					`missingInTree`, l, pID, ``, ``, sp.String()))
+			}
+		}
+
+		// checks
+		for cID, lc := range l.Checks {
+			sc, ok := s.Checks[cID]
+			switch {
+			case !ok:
+				findings = append(findings, fsckFinding(`check`,
+					`missingInDatabase`, l, cID, `configId`, lc, ``))
+			case lc != sc:
+				findings = append(findings, fsckFinding(`check`,
+					`mismatch`, l, cID, `configId`, lc, sc))
+			}
+		}
+		for cID, sc := range s.Checks {
+			if _, ok := l.Checks[cID]; !ok {
+				findings = append(findings, fsckFinding(`check`,
+					`missingInTree`, l, cID, `configId`, ``, sc))
+			}
+		}
+
+		// check instances and instance configurations
+		for iID, li := range l.Instances {
+			si, ok := s.Instances[iID]
+			if !ok {
+				findings = append(findings, fsckFinding(`instance`,
+					`missingInDatabase`, l, iID, `instanceConfigId`,
+					li.InstanceConfigID, ``))
+				continue
+			}
+			for _, f := range []struct{ field, lv, sv string }{
+				{`checkId`, li.CheckID, si.CheckID},
+				{`configId`, li.ConfigID, si.ConfigID},
+				{`instanceConfigId`, li.InstanceConfigID, si.InstanceConfigID},
+				{`version`, strconv.FormatUint(li.Version, 10),
+					strconv.FormatUint(si.Version, 10)},
+			} {
+				if f.lv != f.sv {
+					findings = append(findings, fsckFinding(`instance`,
+						`mismatch`, l, iID, f.field, f.lv, f.sv))
+				}
+			}
+		}
+		for iID, si := range s.Instances {
+			if _, ok := l.Instances[iID]; !ok {
+				findings = append(findings, fsckFinding(`instance`,
+					`missingInTree`, l, iID, `instanceConfigId`, ``,
+					si.InstanceConfigID))
+			}
+		}
+	}
+
+	for id, s := range stored {
+		if _, ok := live[id]; !ok {
+			findings = append(findings, fsckFinding(`object`,
+				`missingInTree`, s, ``, ``, ``, s.Name))
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		switch {
+		case findings[i].ObjectID != findings[j].ObjectID:
+			return findings[i].ObjectID < findings[j].ObjectID
+		case findings[i].Category != findings[j].Category:
+			return findings[i].Category < findings[j].Category
+		case findings[i].ElementID != findings[j].ElementID:
+			return findings[i].ElementID < findings[j].ElementID
+		}
+		return findings[i].Field < findings[j].Field
+	})
+	return findings
+}
+
+// fsckFinding is a helper to assemble a proto.FsckFinding
+func fsckFinding(category, problem string, obj tree.InventoryEntry,
+	elementID, field, treeValue, dbValue string) proto.FsckFinding {
+	return proto.FsckFinding{
+		Category:      category,
+		Problem:       problem,
+		ObjectID:      obj.ID,
+		ObjectType:    obj.Type,
+		ObjectName:    obj.Name,
+		ElementID:     elementID,
+		Field:         field,
+		TreeValue:     treeValue,
+		DatabaseValue: dbValue,
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -131,6 +131,40 @@ constrloop:
 	return nil
 }
 
+// txCheckConfigUpdate saves the mutable settings of an updated
+// check configuration. The thresholds are replaced.
+func (tk *TreeKeeper) txCheckConfigUpdate(conf proto.CheckConfig,
+	stm map[string]*sql.Stmt) error {
+	var err error
+
+	if _, err = stm[`UpdateCheckConfigurationBase`].Exec(
+		conf.ID,
+		int64(conf.Interval),
+		conf.IsEnabled,
+		conf.ExternalID,
+	); err != nil {
+		return err
+	}
+
+	if _, err = stm[`DeleteCheckConfigurationThresholds`].Exec(
+		conf.ID,
+	); err != nil {
+		return err
+	}
+
+	for _, thr := range conf.Thresholds {
+		if _, err = stm[`CreateCheckConfigurationThreshold`].Exec(
+			conf.ID,
+			thr.Predicate.Symbol,
+			strconv.FormatInt(thr.Value, 10),
+			thr.Level.Name,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (tk *TreeKeeper) txCheck(a *tree.Action,
 	stm map[string]*sql.Stmt) error {
 	switch a.Action {
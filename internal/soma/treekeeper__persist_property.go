@@ -17,12 +17,12 @@ import (
 )
 
 func (tk *TreeKeeper) txProperty(a *tree.Action,
-	stm map[string]*sql.Stmt) error {
+	stm map[string]*sql.Stmt, jobID string) error {
 	switch a.Action {
 	case tree.ActionPropertyNew:
-		return tk.txPropertyNew(a, stm)
+		return tk.txPropertyNew(a, stm, jobID)
 	case tree.ActionPropertyDelete:
-		return tk.txPropertyDelete(a, stm)
+		return tk.txPropertyDelete(a, stm, jobID)
 	case tree.ActionPropertyUpdate:
 		return tk.txPropertyUpdate(a, stm)
 	default:
@@ -33,7 +33,7 @@ func (tk *TreeKeeper) txProperty(a *tree.Action,
 //
 // PROPERTY NEW
 func (tk *TreeKeeper) txPropertyNew(a *tree.Action,
-	stm map[string]*sql.Stmt) error {
+	stm map[string]*sql.Stmt, jobID string) error {
 	if _, err := stm[`PropertyInstanceCreate`].Exec(
 		a.Property.InstanceID,
 		a.Property.RepositoryID,
@@ -44,6 +44,10 @@ func (tk *TreeKeeper) txPropertyNew(a *tree.Action,
 		return err
 	}
 
+	if err := tk.txPropertyHistoryNew(a, stm, jobID); err != nil {
+		return err
+	}
+
 	switch a.Property.Type {
 	case msg.PropertyCustom:
 		return tk.txPropertyNewCustom(a, stm)
@@ -213,13 +217,20 @@ func (tk *TreeKeeper) txPropertyNewOncall(a *tree.Action,
 //
 // PROPERTY DELETE
 func (tk *TreeKeeper) txPropertyDelete(a *tree.Action,
-	stm map[string]*sql.Stmt) error {
+	stm map[string]*sql.Stmt, jobID string) error {
 	if _, err := stm[`PropertyInstanceDelete`].Exec(
 		a.Property.InstanceID,
 	); err != nil {
 		return err
 	}
 
+	if _, err := stm[`PropertyHistoryDelete`].Exec(
+		a.Property.InstanceID,
+		jobID,
+	); err != nil {
+		return err
+	}
+
 	var statement *sql.Stmt
 	switch a.Property.Type {
 	case `custom`:
@@ -0,0 +1,80 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/tree"
+)
+
+// txPropertyHistoryNew records in the property history that the
+// property instance from action a was created by job jobID
+func (tk *TreeKeeper) txPropertyHistoryNew(a *tree.Action,
+	stm map[string]*sql.Stmt, jobID string) error {
+	var (
+		objectID                        string
+		customID, systemProperty, value sql.NullString
+		serviceID, oncallID             sql.NullString
+	)
+
+	switch a.Type {
+	case msg.EntityRepository:
+		objectID = a.Property.RepositoryID
+	case msg.EntityBucket:
+		objectID = a.Bucket.ID
+	case msg.EntityGroup:
+		objectID = a.Group.ID
+	case msg.EntityCluster:
+		objectID = a.Cluster.ID
+	case msg.EntityNode:
+		objectID = a.Node.ID
+	default:
+		return fmt.Errorf("Impossible property object type: %s", a.Type)
+	}
+
+	switch a.Property.Type {
+	case msg.PropertyCustom:
+		customID = sql.NullString{String: a.Property.Custom.ID, Valid: true}
+		value = sql.NullString{String: a.Property.Custom.Value, Valid: true}
+	case msg.PropertySystem:
+		systemProperty = sql.NullString{String: a.Property.System.Name, Valid: true}
+		value = sql.NullString{String: a.Property.System.Value, Valid: true}
+	case msg.PropertyService:
+		serviceID = sql.NullString{String: a.Property.Service.ID, Valid: true}
+	case msg.PropertyOncall:
+		oncallID = sql.NullString{String: a.Property.Oncall.ID, Valid: true}
+	default:
+		return fmt.Errorf(`Impossible property type`)
+	}
+
+	_, err := stm[`PropertyHistoryCreate`].Exec(
+		a.Property.InstanceID,
+		a.Property.RepositoryID,
+		objectID,
+		a.Type,
+		a.Property.Type,
+		a.Property.View,
+		a.Property.SourceInstanceID,
+		a.Property.SourceType,
+		a.Property.InheritedFrom,
+		a.Property.Inheritance,
+		a.Property.ChildrenOnly,
+		customID,
+		systemProperty,
+		serviceID,
+		oncallID,
+		value,
+		jobID,
+	)
+	return err
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -33,6 +33,8 @@ func (tk *TreeKeeper) txTree(a *tree.Action,
 		return tk.txTreeMemberNew(a, stm)
 	case tree.ActionMemberRemoved:
 		return tk.txTreeMemberRemoved(a, stm)
+	case tree.ActionNodeMove:
+		return tk.txTreeNodeMove(a, stm)
 	default:
 		return fmt.Errorf("Illegal tree action: %s", a.Action)
 	}
@@ -272,4 +274,33 @@ func (tk *TreeKeeper) txTreeMemberRemoved(a *tree.Action,
 	return err
 }
 
+func (tk *TreeKeeper) txTreeNodeMove(a *tree.Action,
+	stm map[string]*sql.Stmt) error {
+	var err error
+	if _, err = stm[`NodeUnassignFromBucket`].Exec(
+		a.Node.ID,
+		a.Node.Config.BucketID,
+		a.Node.TeamID,
+	); err != nil {
+		return err
+	}
+	// objects that reference the node's bucket assignment follow
+	// the node into its new bucket
+	for _, name := range []string{
+		`NodeMoveCheckConfigs`,
+		`NodeMoveChecks`,
+		`NodeMoveCustomProperties`,
+		`NodeMoveGrants`,
+	} {
+		if _, err = stm[name].Exec(
+			a.Node.ID,
+			a.Node.Config.BucketID,
+			a.Bucket.ID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,148 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mjolnir42/soma/internal/msg"
+)
+
+// jobResultOrphaned is the result recorded for jobs that were
+// interrupted by a crash of somad and could not be recovered
+const jobResultOrphaned = `orphaned`
+
+// jobBackoffMax caps the delay before an interrupted job is replayed
+const jobBackoffMax = 5 * time.Minute
+
+// replayableJobs are the job types that can safely be replayed after
+// they were interrupted. All their effects are part of the job
+// transaction, which the database rolls back if somad dies mid-job.
+// Cloning and destroying a repository also start or stop
+// TreeKeepers and are therefore failed instead.
+var replayableJobs = map[string]bool{
+	`bucket::create`:                      true,
+	`bucket::destroy`:                     true,
+	`bucket::property-create`:             true,
+	`bucket::property-destroy`:            true,
+	`bucket::property-update`:             true,
+	`bucket::rename`:                      true,
+	`changeset::apply`:                    true,
+	`check-config::create`:                true,
+	`check-config::destroy`:               true,
+	`check-config::update`:                true,
+	`cluster::create`:                     true,
+	`cluster::destroy`:                    true,
+	`cluster::member-assign`:              true,
+	`cluster::member-unassign`:            true,
+	`cluster::property-create`:            true,
+	`cluster::property-destroy`:           true,
+	`cluster::property-update`:            true,
+	`group::create`:                       true,
+	`group::destroy`:                      true,
+	`group::member-assign`:                true,
+	`group::member-unassign`:              true,
+	`group::property-create`:              true,
+	`group::property-destroy`:             true,
+	`group::property-update`:              true,
+	`node-config::assign`:                 true,
+	`node-config::lifecycle`:              true,
+	`node-config::move`:                   true,
+	`node-config::property-create`:        true,
+	`node-config::property-destroy`:       true,
+	`node-config::property-update`:        true,
+	`node-config::unassign`:               true,
+	`repository-config::property-create`:  true,
+	`repository-config::property-destroy`: true,
+	`repository-config::property-update`:  true,
+	`repository::rename`:                  true,
+	`repository::repossess`:               true,
+}
+
+// orphanedJob is a job that was found unprocessed while starting
+// up a TreeKeeper
+type orphanedJob struct {
+	id       string
+	status   string
+	attempts uint64
+	request  msg.Request
+	// set if the stored job could not be decoded
+	err error
+}
+
+// jobType returns the job type of j as recorded by GuidePost
+func (j *orphanedJob) jobType() string {
+	return fmt.Sprintf("%s::%s", j.request.Section, j.request.Action)
+}
+
+// jobRecovery is the recovery decision for an orphaned job. Jobs are
+// either replayed after delay, or failed for reason.
+type jobRecovery struct {
+	job    orphanedJob
+	replay bool
+	delay  time.Duration
+	reason string
+}
+
+// planJobRecovery decides how the orphaned jobs of a repository are
+// recovered. The jobs must be ordered by their serial and are
+// returned in the same order. Jobs that were never started are
+// replayed. Jobs that were interrupted are replayed with exponential
+// backoff if their job type is replayable and they have been started
+// fewer than maxAttempts times, otherwise they are failed.
+func planJobRecovery(jobs []orphanedJob, maxAttempts uint64,
+	backoff time.Duration) []jobRecovery {
+	plan := make([]jobRecovery, 0, len(jobs))
+
+	for _, j := range jobs {
+		r := jobRecovery{job: j}
+
+		switch {
+		case j.err != nil:
+			r.reason = fmt.Sprintf(
+				"Orphaned job could not be decoded: %s", j.err)
+		case j.status == `queued` && j.attempts == 0:
+			r.replay = true
+		case !replayableJobs[j.jobType()]:
+			r.reason = fmt.Sprintf(
+				"Job of type %s was interrupted by a restart"+
+					" and can not be replayed safely",
+				j.jobType())
+		case j.attempts >= maxAttempts:
+			r.reason = fmt.Sprintf(
+				"Job was interrupted %d times, giving up",
+				j.attempts)
+		default:
+			r.replay = true
+			r.delay = jobBackoff(j.attempts, backoff)
+		}
+		plan = append(plan, r)
+	}
+	return plan
+}
+
+// notifyJobBlock informs JobBlock that jobID has been processed
+func (tk *TreeKeeper) notifyJobBlock(jobID string) {
+	tk.soma.handlerMap.Get(`job_block`).(*JobBlock).Notify <- jobID
+}
+
+// jobBackoff returns the delay before a job that was started
+// attempts times is replayed
+func jobBackoff(attempts uint64, backoff time.Duration) time.Duration {
+	delay := backoff
+	for i := uint64(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= jobBackoffMax {
+			return jobBackoffMax
+		}
+	}
+	return delay
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
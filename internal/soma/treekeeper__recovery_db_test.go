@@ -0,0 +1,167 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/satori/go.uuid"
+)
+
+// TestJobRecoveryDatabase checks the job bookkeeping statements of
+// a TreeKeeper against the schema of a SOMA database, which is set
+// up by somadbctl. Killing somad mid-job is covered by
+// TestJobRecoveryKilledDaemon, this test verifies that the database
+// records the interrupted job as TestJobRecoveryKilledDaemon expects
+// it. The DSN of the database is read from SOMA_TEST_DATABASE, the
+// test is skipped if it is not set. All changes are rolled back.
+func TestJobRecoveryDatabase(t *testing.T) {
+	dsn := os.Getenv(`SOMA_TEST_DATABASE`)
+	if dsn == `` {
+		t.Skip(`SOMA_TEST_DATABASE is not set`)
+	}
+
+	db, err := sql.Open(`postgres`, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	// the referenced repository, user and team do not exist
+	if _, err = tx.Exec(stmt.TxDeferAllConstraints); err != nil {
+		t.Fatal(err)
+	}
+
+	repoID := uuid.Must(uuid.NewV4()).String()
+	interrupted := testOrphan(msg.SectionBucket, msg.ActionCreate, 0)
+	queued := testOrphan(msg.SectionGroup, msg.ActionCreate, 0)
+	for _, j := range []orphanedJob{interrupted, queued} {
+		job, err := json.Marshal(j.request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tx.Exec(`
+INSERT INTO soma.job (id, status, result, type, repository_id,
+                      user_id, team_id, job)
+VALUES ($1::uuid, 'queued', 'pending', $2::varchar, $3::uuid,
+        $4::uuid, $4::uuid, $5::jsonb);`,
+			j.id,
+			j.jobType(),
+			repoID,
+			uuid.Nil.String(),
+			string(job),
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// kill somad while the job transaction is open: the job is
+	// marked started outside of its transaction, everything inside
+	// the transaction is lost
+	kill := func() {
+		if _, err := tx.Exec(stmt.TreekeeperStartJob,
+			interrupted.id, time.Now().UTC()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.Exec(`SAVEPOINT job;`); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.Exec(`UPDATE soma.job SET error = 'partial'
+WHERE id = $1::uuid;`, interrupted.id); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT job;`); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// restart of the TreeKeeper
+	load := func() []orphanedJob {
+		rows, err := tx.Query(stmt.TkStartLoadJob, repoID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+
+		jobs := []orphanedJob{}
+		for rows.Next() {
+			var (
+				job      string
+				attempts int64
+			)
+			oj := orphanedJob{}
+			if err = rows.Scan(&oj.id, &oj.status, &attempts,
+				&job); err != nil {
+				t.Fatal(err)
+			}
+			oj.attempts = uint64(attempts)
+			oj.err = json.Unmarshal([]byte(job), &oj.request)
+			jobs = append(jobs, oj)
+		}
+		if err = rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return jobs
+	}
+
+	kill()
+	jobs := load()
+	if len(jobs) != 2 || jobs[0].id != interrupted.id ||
+		jobs[0].status != `in_progress` || jobs[0].attempts != 1 ||
+		jobs[1].status != `queued` || jobs[1].attempts != 0 {
+		t.Fatalf("Unexpected orphaned jobs after first kill: %+v", jobs)
+	}
+	plan := planJobRecovery(jobs, 2, time.Second)
+	if !plan[0].replay || plan[0].delay != time.Second || !plan[1].replay {
+		t.Fatalf("Unexpected recovery after first kill: %+v", plan)
+	}
+
+	// the replay is killed as well, which exhausts the attempts
+	kill()
+	jobs = load()
+	if jobs[0].attempts != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", jobs[0].attempts)
+	}
+	plan = planJobRecovery(jobs, 2, time.Second)
+	if plan[0].replay || !plan[1].replay {
+		t.Fatalf("Unexpected recovery after second kill: %+v", plan)
+	}
+	if _, err = tx.Exec(stmt.TxFinishJob, plan[0].job.id,
+		time.Now().UTC(), jobResultOrphaned, plan[0].reason); err != nil {
+		t.Fatal(err)
+	}
+
+	var status, result, reason string
+	if err = tx.QueryRow(`SELECT status, result, error FROM soma.job
+WHERE id = $1::uuid;`, interrupted.id).Scan(
+		&status, &result, &reason); err != nil {
+		t.Fatal(err)
+	}
+	if status != `processed` || result != jobResultOrphaned ||
+		reason != plan[0].reason {
+		t.Errorf("Orphaned job recorded as %s/%s: %s",
+			status, result, reason)
+	}
+	if jobs = load(); len(jobs) != 1 || jobs[0].id != queued.id {
+		t.Errorf("Unexpected orphaned jobs after recovery: %+v", jobs)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,469 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"bufio"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/config"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/internal/super"
+	"github.com/mjolnir42/soma/internal/tree"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+const (
+	// the repository the killed TreeKeeper works on
+	killRepoID   = `3b6c6b3e-5f0f-4c4e-9a55-8f1b1e0c0a01`
+	killBucketID = `3b6c6b3e-5f0f-4c4e-9a55-8f1b1e0c0a02`
+	killNodeID   = `3b6c6b3e-5f0f-4c4e-9a55-8f1b1e0c0a03`
+	killTeamID   = `3b6c6b3e-5f0f-4c4e-9a55-8f1b1e0c0a04`
+	killServerID = `3b6c6b3e-5f0f-4c4e-9a55-8f1b1e0c0a05`
+
+	// killJournal is the environment variable that starts
+	// TestJobRecoveryHelperProcess as somad to be killed
+	killJournal = `SOMA_TEST_KILL_JOURNAL`
+
+	// killRunning is printed by the helper process once it is
+	// inside the job transaction
+	killRunning = `job transaction open`
+)
+
+func init() {
+	sql.Register(`somajournal`, journalDriver{})
+}
+
+// journalJob is a row of soma.job as kept by journalDriver
+type journalJob struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	Result       string `json:"result"`
+	Error        string `json:"error"`
+	Attempts     int64  `json:"attempts"`
+	RepositoryID string `json:"repositoryID"`
+	Job          string `json:"job"`
+}
+
+// journalDriver is a database/sql driver that keeps the job
+// bookkeeping of a TreeKeeper in a file, which outlives a killed
+// process. As in the database, statements outside of a transaction
+// are written immediately and statements inside a transaction are
+// only written on commit. The DSN is the path of the file, with
+// suffix ?block the connection blocks on the first statement
+// executed inside a transaction.
+type journalDriver struct{}
+
+func (journalDriver) Open(dsn string) (driver.Conn, error) {
+	return &journalConn{
+		path:  strings.TrimSuffix(dsn, `?block`),
+		block: strings.HasSuffix(dsn, `?block`),
+	}, nil
+}
+
+type journalConn struct {
+	path    string
+	block   bool
+	pending []func([]journalJob)
+	inTx    bool
+}
+
+func (c *journalConn) Prepare(query string) (driver.Stmt, error) {
+	return &journalStmt{conn: c, query: query}, nil
+}
+
+func (c *journalConn) Close() error { return nil }
+
+func (c *journalConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	c.pending = nil
+	return c, nil
+}
+
+func (c *journalConn) Commit() error {
+	c.inTx = false
+	return c.write(c.pending...)
+}
+
+func (c *journalConn) Rollback() error {
+	c.inTx = false
+	c.pending = nil
+	return nil
+}
+
+func (c *journalConn) read() ([]journalJob, error) {
+	jobs := []journalJob{}
+	b, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, json.Unmarshal(b, &jobs)
+}
+
+// write applies the updates to the journal and replaces it
+// atomically
+func (c *journalConn) write(updates ...func([]journalJob)) error {
+	jobs, err := c.read()
+	if err != nil {
+		return err
+	}
+	for _, update := range updates {
+		update(jobs)
+	}
+	b, err := json.Marshal(jobs)
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(c.path+`.new`, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(c.path+`.new`, c.path)
+}
+
+type journalStmt struct {
+	conn  *journalConn
+	query string
+}
+
+func (s *journalStmt) Close() error { return nil }
+
+func (s *journalStmt) NumInput() int { return -1 }
+
+func (s *journalStmt) Exec(args []driver.Value) (driver.Result, error) {
+	var update func([]journalJob)
+
+	switch s.query {
+	case stmt.TreekeeperStartJob:
+		update = func(jobs []journalJob) {
+			for i := range jobs {
+				if jobs[i].ID == args[0].(string) &&
+					jobs[i].Status != `processed` {
+					jobs[i].Status = `in_progress`
+					jobs[i].Attempts++
+				}
+			}
+		}
+	case stmt.TxFinishJob:
+		update = func(jobs []journalJob) {
+			for i := range jobs {
+				if jobs[i].ID == args[0].(string) {
+					jobs[i].Status = `processed`
+					jobs[i].Result = args[2].(string)
+					jobs[i].Error = args[3].(string)
+				}
+			}
+		}
+	default:
+		if s.conn.inTx && s.conn.block {
+			// somad is killed while it waits for the database
+			fmt.Println(killRunning)
+			time.Sleep(time.Hour)
+		}
+		// all other statements write the tree, which is not
+		// journaled
+		return driver.RowsAffected(1), nil
+	}
+
+	if s.conn.inTx {
+		s.conn.pending = append(s.conn.pending, update)
+		return driver.RowsAffected(1), nil
+	}
+	return driver.RowsAffected(1), s.conn.write(update)
+}
+
+func (s *journalStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.query != stmt.TkStartLoadJob {
+		return nil, fmt.Errorf("journal: unexpected query: %s", s.query)
+	}
+	jobs, err := s.conn.read()
+	if err != nil {
+		return nil, err
+	}
+	rows := &journalRows{}
+	for _, j := range jobs {
+		if j.RepositoryID == args[0].(string) && j.Status != `processed` {
+			rows.jobs = append(rows.jobs, j)
+		}
+	}
+	return rows, nil
+}
+
+type journalRows struct {
+	jobs []journalJob
+}
+
+func (r *journalRows) Columns() []string {
+	return []string{`id`, `status`, `attempts`, `job`}
+}
+
+func (r *journalRows) Close() error { return nil }
+
+func (r *journalRows) Next(dest []driver.Value) error {
+	if len(r.jobs) == 0 {
+		return io.EOF
+	}
+	dest[0] = r.jobs[0].ID
+	dest[1] = r.jobs[0].Status
+	dest[2] = r.jobs[0].Attempts
+	dest[3] = r.jobs[0].Job
+	r.jobs = r.jobs[1:]
+	return nil
+}
+
+// testLifecycleJob returns a queued job that moves the node into
+// lifecycle state
+func testLifecycleJob(t *testing.T, state string) journalJob {
+	q := testOrphan(msg.SectionNodeConfig, msg.ActionLifecycle, 0).request
+	q.Node = proto.Node{ID: killNodeID, Lifecycle: state}
+	q.State = proto.State{Name: state, CheckHandling: `keep`}
+	job, err := json.Marshal(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return journalJob{
+		ID:           q.JobID.String(),
+		Status:       `queued`,
+		Result:       `pending`,
+		RepositoryID: killRepoID,
+		Job:          string(job),
+	}
+}
+
+// testKillKeeper starts a TreeKeeper on the journal at dsn as somad
+// would, with the job log below logPath
+func testKillKeeper(t *testing.T, dsn, logPath string) *TreeKeeper {
+	discard := logrus.New()
+	discard.Out = ioutil.Discard
+
+	// jobs are authorized at execution time
+	super.New(&config.Config{
+		OpenInstance: true,
+		QueueLen:     8,
+		Auth: config.AuthConfig{
+			TokenSeed: `5eed`,
+			TokenKey:  `4e1f`,
+		},
+	})
+
+	db, err := sql.Open(`somajournal`, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the journal keeps transactions per connection
+	db.SetMaxOpenConns(1)
+
+	tk := testRecoveryKeeper(db)
+	tk.meta.repoID = killRepoID
+	tk.meta.teamID = killTeamID
+	tk.appLog = discard
+	tk.treeLog = discard
+	tk.soma.conf.LogPath = logPath
+	tk.actions = make(chan *tree.Action, 1024)
+	tk.errors = make(chan *tree.Error, 1024)
+	if tk.stmtStartJob, err = db.Prepare(
+		stmt.TreekeeperStartJob,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tree as loaded from the database
+	tk.tree = tree.New(tree.Spec{
+		ID:     killRepoID,
+		Name:   `root_example`,
+		Action: tk.actions,
+		Log:    discard,
+	})
+	tk.tree.RegisterErrChan(tk.errors)
+	tree.NewRepository(tree.RepositorySpec{
+		ID:     killRepoID,
+		Name:   tk.meta.repoName,
+		Team:   killTeamID,
+		Active: true,
+	}).Attach(tree.AttachRequest{
+		Root:       tk.tree,
+		ParentType: `root`,
+		ParentID:   tk.tree.GetID(),
+	})
+	tk.tree.SetError()
+	tree.NewBucket(tree.BucketSpec{
+		ID:          killBucketID,
+		Name:        `example_live`,
+		Environment: `live`,
+		Team:        killTeamID,
+		Repository:  killRepoID,
+	}).Attach(tree.AttachRequest{
+		Root:       tk.tree,
+		ParentType: `repository`,
+		ParentID:   killRepoID,
+		ParentName: tk.meta.repoName,
+	})
+	tree.NewNode(tree.NodeSpec{
+		ID:       killNodeID,
+		AssetID:  1,
+		Name:     `web01`,
+		Team:     killTeamID,
+		ServerID: killServerID,
+		Online:   true,
+	}).Attach(tree.AttachRequest{
+		Root:       tk.tree,
+		ParentType: `bucket`,
+		ParentID:   killBucketID,
+	})
+	tk.drain(`action`)
+	tk.drain(`error`)
+
+	loadJob, err := db.Prepare(stmt.TkStartLoadJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loadJob.Close()
+	tk.startupJobs(map[string]*sql.Stmt{`LoadJob`: loadJob})
+	if tk.status.isBroken {
+		t.Fatal(`TreeKeeper broke while loading its jobs`)
+	}
+	return tk
+}
+
+// TestJobRecoveryHelperProcess is the somad that is killed by
+// TestJobRecoveryKilledDaemon while its first job is running
+func TestJobRecoveryHelperProcess(t *testing.T) {
+	dsn := os.Getenv(killJournal)
+	if dsn == `` {
+		t.Skip(`not started by TestJobRecoveryKilledDaemon`)
+	}
+
+	tk := testKillKeeper(t, dsn, filepath.Dir(dsn))
+	if len(tk.recovery.replay) == 0 {
+		t.Fatal(`No queued jobs`)
+	}
+	tk.process(&tk.recovery.replay[0])
+	t.Fatal(`Job finished without being killed`)
+}
+
+func TestJobRecoveryKilledDaemon(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `soma-recovery`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = os.Mkdir(filepath.Join(dir, `job`), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	journal := filepath.Join(dir, `soma.job`)
+	interrupted := testLifecycleJob(t, `maintenance`)
+	queued := testLifecycleJob(t, `decommissioned`)
+	b, err := json.Marshal([]journalJob{interrupted, queued})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(journal, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// start somad and kill it while it processes the first job
+	cmd := exec.Command(os.Args[0],
+		`-test.run=^TestJobRecoveryHelperProcess$`)
+	cmd.Env = append(os.Environ(), killJournal+`=`+journal+`?block`)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	running := make(chan bool)
+	output := []string{}
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if scanner.Text() == killRunning {
+				running <- true
+				return
+			}
+			output = append(output, scanner.Text())
+		}
+		close(running)
+	}()
+	select {
+	case ok := <-running:
+		if !ok {
+			cmd.Wait()
+			t.Fatalf("somad exited before the job was running:\n%s",
+				strings.Join(output, "\n"))
+		}
+	case <-time.After(time.Minute):
+		cmd.Process.Kill()
+		cmd.Wait()
+		t.Fatal(`Timeout waiting for the job to run`)
+	}
+	if err = cmd.Process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	cmd.Wait()
+
+	// restart somad
+	tk := testKillKeeper(t, journal, dir)
+	if len(tk.recovery.failed) != 0 {
+		t.Fatalf("Jobs failed after restart: %v", tk.recovery.failed)
+	}
+	if len(tk.recovery.replay) != 2 ||
+		tk.recovery.replay[0].JobID.String() != interrupted.ID ||
+		tk.recovery.replay[1].JobID.String() != queued.ID {
+		t.Fatalf("Unexpected replay: %+v", tk.recovery.replay)
+	}
+	if tk.recovery.delay != 5*time.Second {
+		t.Errorf("Expected replay delay of 5s, got %s",
+			tk.recovery.delay)
+	}
+
+	// the run loop replays the jobs in order
+	for i := range tk.recovery.replay {
+		tk.process(&tk.recovery.replay[i])
+	}
+
+	jobs, err := (&journalConn{path: journal}).read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, j := range jobs {
+		if j.Status != `processed` || j.Result != `success` {
+			t.Errorf("Job %s was not replayed: %s/%s %s",
+				j.ID, j.Status, j.Result, j.Error)
+		}
+	}
+	if jobs[0].Attempts != 2 || jobs[1].Attempts != 1 {
+		t.Errorf("Unexpected attempts: %d, %d",
+			jobs[0].Attempts, jobs[1].Attempts)
+	}
+	node := tk.tree.Find(tree.FindRequest{
+		ElementType: msg.EntityNode,
+		ElementID:   killNodeID,
+	}, true).(*tree.Node)
+	if node.Lifecycle.State != `decommissioned` {
+		t.Errorf("Node is in lifecycle state %s after the replay",
+			node.Lifecycle.State)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
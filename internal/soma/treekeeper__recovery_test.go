@@ -0,0 +1,280 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package soma
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/config"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/satori/go.uuid"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+// testOrphan returns an orphaned job as left behind in the database
+// if somad died after having started the job attempts times
+func testOrphan(section, action string, attempts uint64) orphanedJob {
+	j := orphanedJob{
+		id:       uuid.Must(uuid.NewV4()).String(),
+		status:   `queued`,
+		attempts: attempts,
+		request: msg.Request{
+			Section: section,
+			Action:  action,
+			JobID:   uuid.Must(uuid.NewV4()),
+		},
+	}
+	if attempts > 0 {
+		j.status = `in_progress`
+	}
+	return j
+}
+
+func TestJobRecoveryKilledMidJob(t *testing.T) {
+	backoff := 5 * time.Second
+
+	// somad was killed while processing the first job, the other
+	// jobs were queued behind it
+	jobs := []orphanedJob{
+		testOrphan(msg.SectionBucket, msg.ActionCreate, 1),
+		testOrphan(msg.SectionGroup, msg.ActionCreate, 0),
+		testOrphan(msg.SectionNodeConfig, msg.ActionAssign, 0),
+	}
+
+	plan := planJobRecovery(jobs, 3, backoff)
+	if len(plan) != len(jobs) {
+		t.Fatalf("Expected %d recovery steps, got %d",
+			len(jobs), len(plan))
+	}
+	for i := range plan {
+		if plan[i].job.id != jobs[i].id {
+			t.Errorf("Step %d: jobs not replayed in serial order", i)
+		}
+		if !plan[i].replay {
+			t.Errorf("Step %d: expected replay, job failed: %s",
+				i, plan[i].reason)
+		}
+	}
+	if plan[0].delay != backoff {
+		t.Errorf("Interrupted job: expected delay %s, got %s",
+			backoff, plan[0].delay)
+	}
+	for i := 1; i < len(plan); i++ {
+		if plan[i].delay != 0 {
+			t.Errorf("Step %d: unstarted job delayed by %s",
+				i, plan[i].delay)
+		}
+	}
+}
+
+func TestJobRecoveryBackoff(t *testing.T) {
+	backoff := 5 * time.Second
+
+	// somad was repeatedly killed while processing the same job
+	for attempts, expected := range map[uint64]time.Duration{
+		1: 5 * time.Second,
+		2: 10 * time.Second,
+		3: 20 * time.Second,
+		9: jobBackoffMax,
+	} {
+		plan := planJobRecovery([]orphanedJob{
+			testOrphan(msg.SectionCluster, msg.ActionMemberAssign, attempts),
+		}, 10, backoff)
+
+		if !plan[0].replay {
+			t.Errorf("Attempt %d: expected replay, job failed: %s",
+				attempts, plan[0].reason)
+		}
+		if plan[0].delay != expected {
+			t.Errorf("Attempt %d: expected delay %s, got %s",
+				attempts, expected, plan[0].delay)
+		}
+	}
+}
+
+func TestJobRecoveryAttemptsExhausted(t *testing.T) {
+	plan := planJobRecovery([]orphanedJob{
+		testOrphan(msg.SectionBucket, msg.ActionRename, 3),
+		testOrphan(msg.SectionBucket, msg.ActionDestroy, 0),
+	}, 3, time.Second)
+
+	if plan[0].replay {
+		t.Errorf(`Job exceeding the restart policy was replayed`)
+	}
+	if plan[0].reason == `` {
+		t.Errorf(`Failed job has no reason`)
+	}
+	if !plan[1].replay {
+		t.Errorf(`Queued job behind failed job was not replayed`)
+	}
+}
+
+func TestJobRecoveryNotReplayable(t *testing.T) {
+	for _, action := range []string{
+		msg.ActionClone,
+		msg.ActionDestroy,
+	} {
+		// interrupted repository jobs are failed
+		plan := planJobRecovery([]orphanedJob{
+			testOrphan(msg.SectionRepository, action, 1),
+		}, 3, time.Second)
+		if plan[0].replay {
+			t.Errorf("Interrupted repository::%s was replayed", action)
+		}
+
+		// unstarted repository jobs are replayed
+		plan = planJobRecovery([]orphanedJob{
+			testOrphan(msg.SectionRepository, action, 0),
+		}, 3, time.Second)
+		if !plan[0].replay {
+			t.Errorf("Queued repository::%s was not replayed", action)
+		}
+	}
+}
+
+func TestJobRecoveryUndecodable(t *testing.T) {
+	j := testOrphan(msg.SectionBucket, msg.ActionCreate, 0)
+	j.err = fmt.Errorf(`unexpected end of JSON input`)
+
+	plan := planJobRecovery([]orphanedJob{j}, 3, time.Second)
+	if plan[0].replay {
+		t.Errorf(`Undecodable job was replayed`)
+	}
+}
+
+// testRecoveryKeeper returns a TreeKeeper that loads its orphaned
+// jobs from db
+func testRecoveryKeeper(db *sql.DB) *TreeKeeper {
+	tk := newTreeKeeper(8)
+	tk.conn = db
+	tk.meta.repoID = uuid.Must(uuid.NewV4()).String()
+	tk.meta.repoName = `example`
+	tk.startLog = logrus.New()
+	tk.startLog.Out = ioutil.Discard
+	tk.soma = &Soma{conf: &config.Config{
+		JobAttempts: 3,
+		JobBackoff:  5,
+	}}
+	return tk
+}
+
+// testJobRow returns the row TkStartLoadJob returns for j
+func testJobRow(t *testing.T, j orphanedJob) []driver.Value {
+	job, err := json.Marshal(j.request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []driver.Value{j.id, j.status, int64(j.attempts), string(job)}
+}
+
+func TestStartupJobsKilledMidJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tk := testRecoveryKeeper(db)
+
+	// somad was killed while processing the first job, the job
+	// behind it was queued and the last job is corrupt
+	interrupted := testOrphan(msg.SectionBucket, msg.ActionCreate, 1)
+	queued := testOrphan(msg.SectionGroup, msg.ActionCreate, 0)
+	corrupt := testOrphan(msg.SectionNodeConfig, msg.ActionAssign, 0)
+
+	rows := sqlmock.NewRows([]string{`id`, `status`, `attempts`, `job`})
+	for _, j := range []orphanedJob{interrupted, queued} {
+		rows.AddRow(testJobRow(t, j)...)
+	}
+	rows.AddRow(corrupt.id, corrupt.status, int64(0), `{"section":`)
+
+	mock.ExpectPrepare(`soma.job`).ExpectQuery().
+		WithArgs(tk.meta.repoID).
+		WillReturnRows(rows)
+	mock.ExpectExec(`UPDATE soma.job SET finished_at`).
+		WithArgs(corrupt.id, sqlmock.AnyArg(), jobResultOrphaned,
+			sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	loadJob, err := db.Prepare(stmt.TkStartLoadJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.startupJobs(map[string]*sql.Stmt{`LoadJob`: loadJob})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if tk.status.isBroken {
+		t.Fatal(`TreeKeeper broke while recovering jobs`)
+	}
+	// the replay is scheduled for the run loop instead of blocking
+	// the startup
+	if len(tk.Input) != 0 {
+		t.Errorf("Startup queued %d jobs into the input channel",
+			len(tk.Input))
+	}
+	if len(tk.recovery.replay) != 2 ||
+		tk.recovery.replay[0].JobID != interrupted.request.JobID ||
+		tk.recovery.replay[1].JobID != queued.request.JobID {
+		t.Errorf("Unexpected replay: %+v", tk.recovery.replay)
+	}
+	if tk.recovery.delay != 5*time.Second {
+		t.Errorf("Expected replay delay of 5s, got %s",
+			tk.recovery.delay)
+	}
+	if len(tk.recovery.failed) != 1 || tk.recovery.failed[0] != corrupt.id {
+		t.Errorf("Failed job is not released: %v", tk.recovery.failed)
+	}
+}
+
+func TestStartupJobsFinishFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tk := testRecoveryKeeper(db)
+
+	// the job was interrupted too often
+	exhausted := testOrphan(msg.SectionBucket, msg.ActionRename, 3)
+	mock.ExpectPrepare(`soma.job`).ExpectQuery().
+		WithArgs(tk.meta.repoID).
+		WillReturnRows(sqlmock.NewRows(
+			[]string{`id`, `status`, `attempts`, `job`},
+		).AddRow(testJobRow(t, exhausted)...))
+	mock.ExpectExec(`UPDATE soma.job SET finished_at`).
+		WithArgs(exhausted.id, sqlmock.AnyArg(), jobResultOrphaned,
+			sqlmock.AnyArg()).
+		WillReturnError(fmt.Errorf(`connection refused`))
+
+	loadJob, err := db.Prepare(stmt.TkStartLoadJob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.startupJobs(map[string]*sql.Stmt{`LoadJob`: loadJob})
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if !tk.status.isBroken {
+		t.Error(`Failing to record the orphaned job did not break the tree`)
+	}
+	if len(tk.recovery.failed) != 0 || len(tk.recovery.replay) != 0 {
+		t.Errorf("Unexpected recovery state: %+v", tk.recovery)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -3,8 +3,8 @@ package soma
 import (
 	"database/sql"
 	"encoding/json"
+	"time"
 
-	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/stmt"
 )
 
@@ -75,14 +75,14 @@ func (tk *TreeKeeper) startupLoad() {
 
 	// these run as part of a job, but not inside the job's transaction. If there are leftovers
 	// after a crash, fix them up
-	if !tk.soma.conf.Observer {
+	if !tk.soma.conf.Observer && !tk.status.isShadow {
 		tk.buildDeploymentDetails()
 		tk.orderDeploymentDetails()
 	}
 
-	// preload pending/unfinished jobs if not rebuilding the tree or
-	// running in observer mode
-	if !tk.status.requiresRebuild && !tk.soma.conf.Observer {
+	// preload pending/unfinished jobs if not rebuilding the tree,
+	// running in observer mode or loading a shadow tree
+	if !tk.status.requiresRebuild && !tk.soma.conf.Observer && !tk.status.isShadow {
 		tk.startupJobs(stMap)
 	}
 
@@ -99,46 +99,82 @@ func (tk *TreeKeeper) startupJobs(stMap map[string]*sql.Stmt) {
 	}
 
 	var (
-		err  error
-		rows *sql.Rows
-		job  string
+		err      error
+		rows     *sql.Rows
+		job      string
+		attempts int64
+		jobs     []orphanedJob
 	)
 
 	tk.startLog.Printf("TK[%s]: loading pending jobs", tk.meta.repoName)
 	rows, err = stMap[`LoadJob`].Query(tk.meta.repoID)
 	if err != nil {
-		tk.startLog.Printf("TK[%s] Error loading clusters: %s", tk.meta.repoName, err.Error())
+		tk.startLog.Printf("TK[%s] Error loading jobs: %s", tk.meta.repoName, err.Error())
 		tk.status.isBroken = true
 		return
 	}
 	defer rows.Close()
 
-jobloop:
 	for rows.Next() {
-		err = rows.Scan(
+		oj := orphanedJob{}
+		if err = rows.Scan(
+			&oj.id,
+			&oj.status,
+			&attempts,
 			&job,
-		)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				break jobloop
-			}
+		); err != nil {
 			tk.startLog.Printf("TK[%s] Error: %s", tk.meta.repoName, err.Error())
 			tk.status.isBroken = true
 			return
 		}
+		oj.attempts = uint64(attempts)
 
 		// XXX BUG
 		// REQUIRES MIGRATION TOOL TO CONVERT PENDING JOBS FROM treeRequest
 		// TO msg.Request
-		tr := msg.Request{}
-		err = json.Unmarshal([]byte(job), &tr)
-		if err != nil {
-			tk.startLog.Printf("TK[%s] Error: %s", tk.meta.repoName, err.Error())
-			tk.status.isBroken = true
-			return
+		oj.err = json.Unmarshal([]byte(job), &oj.request)
+		jobs = append(jobs, oj)
+	}
+	if err = rows.Err(); err != nil {
+		tk.startLog.Printf("TK[%s] Error: %s", tk.meta.repoName, err.Error())
+		tk.status.isBroken = true
+		return
+	}
+
+	// replay the jobs in serial order, or fail those that can not
+	// be recovered
+	for _, r := range planJobRecovery(
+		jobs,
+		tk.soma.conf.JobAttempts,
+		time.Duration(tk.soma.conf.JobBackoff)*time.Second,
+	) {
+		if !r.replay {
+			if _, err = tk.conn.Exec(
+				stmt.TxFinishJob,
+				r.job.id,
+				time.Now().UTC(),
+				jobResultOrphaned,
+				r.reason,
+			); err != nil {
+				tk.startLog.Printf("TK[%s] Error failing job %s: %s", tk.meta.repoName, r.job.id, err.Error())
+				tk.status.isBroken = true
+				return
+			}
+			tk.recovery.failed = append(tk.recovery.failed, r.job.id)
+			tk.startLog.Printf("TK[%s] Failed orphaned job %s: %s", tk.meta.repoName, r.job.id, r.reason)
+			continue
+		}
+
+		// the replay is started from the run loop, all jobs wait
+		// for the longest backoff to keep their serial order
+		if r.delay > 0 {
+			tk.startLog.Printf("TK[%s] Job %s was interrupted %d times, replaying in %s", tk.meta.repoName, r.job.id, r.job.attempts, r.delay)
+		}
+		if r.delay > tk.recovery.delay {
+			tk.recovery.delay = r.delay
 		}
-		tk.Input <- tr
-		tk.startLog.Printf("TK[%s] Loaded job %s (%s)", tk.meta.repoName, tr.JobID, tr.Action)
+		tk.recovery.replay = append(tk.recovery.replay, r.job.request)
+		tk.startLog.Printf("TK[%s] Loaded job %s (%s)", tk.meta.repoName, r.job.request.JobID, r.job.request.Action)
 	}
 }
 
@@ -3,6 +3,7 @@ package soma
 import (
 	"database/sql"
 
+	"github.com/lib/pq"
 	"github.com/mjolnir42/soma/internal/msg"
 	"github.com/mjolnir42/soma/internal/tree"
 	"github.com/mjolnir42/soma/lib/proto"
@@ -80,6 +81,10 @@ bucketloop:
 			Action:  msg.ActionCreate,
 			Bucket:  bucket.Clone(),
 		}
+		// shadow trees must not update the permission cache
+		if tk.status.isShadow {
+			continue
+		}
 		go func(q *msg.Request) {
 			super.Update <- msg.CacheUpdateFromRequest(q)
 		}(&req)
@@ -150,6 +155,10 @@ grouploop:
 			Action:  msg.ActionCreate,
 			Group:   group.Clone(),
 		}
+		// shadow trees must not update the permission cache
+		if tk.status.isShadow {
+			continue
+		}
 		go func(q *msg.Request) {
 			super.Update <- msg.CacheUpdateFromRequest(q)
 		}(&req)
@@ -271,6 +280,10 @@ clusterloop:
 			Action:  msg.ActionCreate,
 			Cluster: cluster.Clone(),
 		}
+		// shadow trees must not update the permission cache
+		if tk.status.isShadow {
+			continue
+		}
 		go func(q *msg.Request) {
 			super.Update <- msg.CacheUpdateFromRequest(q)
 		}(&req)
@@ -340,6 +353,10 @@ clusterloop:
 			Action:  msg.ActionCreate,
 			Cluster: cluster.Clone(),
 		}
+		// shadow trees must not update the permission cache
+		if tk.status.isShadow {
+			continue
+		}
 		go func(q *msg.Request) {
 			super.Update <- msg.CacheUpdateFromRequest(q)
 		}(&req)
@@ -358,6 +375,8 @@ func (tk *TreeKeeper) startupNodes(stMap map[string]*sql.Stmt) {
 		assetID                                      int
 		nodeOnline, nodeDeleted                      bool
 		clusterID, groupID                           sql.NullString
+		lifecycle, checkHandling                     sql.NullString
+		views                                        pq.StringArray
 	)
 
 	tk.startLog.Printf("TK[%s]: loading nodes", tk.meta.repoName)
@@ -384,6 +403,9 @@ nodeloop:
 			&bucketID,
 			&clusterID,
 			&groupID,
+			&lifecycle,
+			&checkHandling,
+			&views,
 		)
 		if err != nil {
 			if err == sql.ErrNoRows {
@@ -402,6 +424,11 @@ nodeloop:
 			ServerID: serverID,
 			Online:   nodeOnline,
 			Deleted:  nodeDeleted,
+			Lifecycle: tree.NodeLifecycle{
+				State:         lifecycle.String,
+				CheckHandling: checkHandling.String,
+				Views:         []string(views),
+			},
 		})
 		if clusterID.Valid {
 			node.Attach(tree.AttachRequest{
@@ -425,6 +452,10 @@ nodeloop:
 		tk.drain(`action`)
 		tk.drain(`error`)
 
+		// shadow trees must not update the permission cache
+		if tk.status.isShadow {
+			continue
+		}
 		go func() {
 			super.Update <- msg.CacheUpdateFromRequest(&msg.Request{
 				Section: msg.SectionNodeConfig,
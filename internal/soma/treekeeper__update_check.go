@@ -41,6 +41,19 @@ func (tk *TreeKeeper) rmCheck(config *proto.CheckConfig) error {
 	return err
 }
 
+func (tk *TreeKeeper) updateCheck(config *proto.CheckConfig) error {
+	var err error
+	var chk *tree.Check
+	if chk, err = tk.convertCheckForUpdate(config); err == nil {
+		tk.tree.Find(tree.FindRequest{
+			ElementType: config.ObjectType,
+			ElementID:   config.ObjectID,
+		}, true).UpdateCheck(*chk)
+		return nil
+	}
+	return err
+}
+
 func (tk *TreeKeeper) convertCheck(conf *proto.CheckConfig) (*tree.Check, error) {
 	treechk := &tree.Check{
 		ID:            uuid.Nil,
@@ -49,6 +62,7 @@ func (tk *TreeKeeper) convertCheck(conf *proto.CheckConfig) (*tree.Check, error)
 		Inheritance:   conf.Inheritance,
 		ChildrenOnly:  conf.ChildrenOnly,
 		Interval:      conf.Interval,
+		Disabled:      !conf.IsEnabled,
 	}
 	treechk.CapabilityID, _ = uuid.FromString(conf.CapabilityID)
 	treechk.ConfigID, _ = uuid.FromString(conf.ID)
@@ -111,4 +125,28 @@ func (tk *TreeKeeper) convertCheckForDelete(conf *proto.CheckConfig) (*tree.Chec
 	return treechk, nil
 }
 
+func (tk *TreeKeeper) convertCheckForUpdate(conf *proto.CheckConfig) (*tree.Check, error) {
+	var err error
+	treechk := &tree.Check{
+		ID:            uuid.Nil,
+		SourceID:      uuid.Nil,
+		InheritedFrom: uuid.Nil,
+		Interval:      conf.Interval,
+		Disabled:      !conf.IsEnabled,
+	}
+	if treechk.ConfigID, err = uuid.FromString(conf.ID); err != nil {
+		return nil, err
+	}
+
+	treechk.Thresholds = make([]tree.CheckThreshold, len(conf.Thresholds))
+	for i, thr := range conf.Thresholds {
+		treechk.Thresholds[i] = tree.CheckThreshold{
+			Predicate: thr.Predicate.Symbol,
+			Level:     uint8(thr.Level.Numeric),
+			Value:     thr.Value,
+		}
+	}
+	return treechk, nil
+}
+
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
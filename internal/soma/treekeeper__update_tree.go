@@ -179,6 +179,33 @@ func (tk *TreeKeeper) treeNode(q *msg.Request) {
 				ElementType: msg.EntityNode,
 				ElementID:   q.Node.ID,
 			}, true).(tree.BucketAttacher).Destroy()
+		case msg.ActionMove:
+			var parentID string
+			switch q.TargetEntity {
+			case msg.EntityGroup:
+				parentID = q.Group.ID
+			case msg.EntityCluster:
+				parentID = q.Cluster.ID
+			default:
+				parentID = q.Update.Node.Config.BucketID
+			}
+			tk.tree.Find(tree.FindRequest{
+				ElementType: msg.EntityNode,
+				ElementID:   q.Node.ID,
+			}, true).(tree.NodeAttacher).Move(tree.AttachRequest{
+				Root:       tk.tree,
+				ParentType: q.TargetEntity,
+				ParentID:   parentID,
+			})
+		case msg.ActionLifecycle:
+			tk.tree.Find(tree.FindRequest{
+				ElementType: msg.EntityNode,
+				ElementID:   q.Node.ID,
+			}, true).(tree.NodeAttacher).SetLifecycle(tree.NodeLifecycle{
+				State:         q.Node.Lifecycle,
+				CheckHandling: q.State.CheckHandling,
+				Views:         q.State.Views,
+			})
 		}
 	}
 
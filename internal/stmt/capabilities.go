@@ -53,6 +53,7 @@ SELECT smc.capability_id,
        smc.capability_metric,
        smc.capability_view,
        smc.threshold_amount,
+       smc.min_interval,
        sms.monitoring_name
 FROM   soma.monitoring_capabilities smc
 JOIN   soma.monitoring_systems sms
@@ -62,6 +63,11 @@ WHERE  smc.capability_id = $1::uuid;`
 	CapabilityThresholds = `
 SELECT threshold_amount
 FROM   soma.monitoring_capabilities
+WHERE  capability_id = $1::uuid;`
+
+	CapabilityMinInterval = `
+SELECT min_interval
+FROM   soma.monitoring_capabilities
 WHERE  capability_id = $1::uuid;`
 
 	AddCapability = `
@@ -70,8 +76,9 @@ INSERT INTO soma.monitoring_capabilities (
             capability_monitoring,
             capability_metric,
             capability_view,
-            threshold_amount)
-SELECT   $1::uuid, $2::uuid, $3::varchar, $4::varchar, $5::integer
+            threshold_amount,
+            min_interval)
+SELECT   $1::uuid, $2::uuid, $3::varchar, $4::varchar, $5::integer, $6::integer
 WHERE    NOT EXISTS (
     SELECT capability_id
     FROM   soma.monitoring_capabilities
@@ -87,6 +94,7 @@ WHERE  capability_id = $1::uuid;`
 
 func init() {
 	m[AddCapability] = `AddCapability`
+	m[CapabilityMinInterval] = `CapabilityMinInterval`
 	m[CapabilityThresholds] = `CapabilityThresholds`
 	m[DelCapability] = `DelCapability`
 	m[ListAllCapabilities] = `ListAllCapabilities`
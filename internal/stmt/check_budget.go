@@ -0,0 +1,67 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package stmt
+
+const (
+	CheckBudgetStatements = ``
+
+	// active check instances of a repository
+	TxCheckBudgetRepository = `
+SELECT COUNT(sci.check_instance_id)
+FROM   soma.check_instances sci
+JOIN   soma.checks sc
+  ON   sci.check_id = sc.check_id
+WHERE  sc.repository_id = $1::uuid
+  AND  NOT sci.deleted;`
+
+	// active check instances of all repositories owned by the team
+	// that owns the repository
+	TxCheckBudgetTeam = `
+SELECT COUNT(sci.check_instance_id)
+FROM   soma.check_instances sci
+JOIN   soma.checks sc
+  ON   sci.check_id = sc.check_id
+JOIN   soma.repository sr
+  ON   sc.repository_id = sr.id
+WHERE  sr.team_id = (
+       SELECT team_id
+       FROM   soma.repository
+       WHERE  id = $1::uuid)
+  AND  NOT sci.deleted;`
+
+	// active check instances of all monitoring systems that have
+	// checks in the repository
+	TxCheckBudgetMonitoring = `
+SELECT   sms.monitoring_name,
+         COUNT(sci.check_instance_id)
+FROM     soma.check_instances sci
+JOIN     soma.checks sc
+  ON     sci.check_id = sc.check_id
+JOIN     soma.monitoring_capabilities smc
+  ON     sc.capability_id = smc.capability_id
+JOIN     soma.monitoring_systems sms
+  ON     smc.capability_monitoring = sms.monitoring_id
+WHERE    NOT sci.deleted
+  AND    smc.capability_monitoring IN (
+         SELECT smc.capability_monitoring
+         FROM   soma.checks sc
+         JOIN   soma.monitoring_capabilities smc
+           ON   sc.capability_id = smc.capability_id
+         WHERE  sc.repository_id = $1::uuid
+           AND  NOT sc.deleted)
+GROUP BY sms.monitoring_name;`
+)
+
+func init() {
+	m[TxCheckBudgetMonitoring] = `TxCheckBudgetMonitoring`
+	m[TxCheckBudgetRepository] = `TxCheckBudgetRepository`
+	m[TxCheckBudgetTeam] = `TxCheckBudgetTeam`
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -28,6 +28,23 @@ WHERE  scc.configuration_id = $1::uuid
   AND  sc.check_id          = sc.source_check_id
   AND  NOT sc.deleted;`
 
+	CheckDetailsForUpdate = `
+SELECT scc.configuration_name,
+       scc.bucket_id,
+       scc.capability_id,
+       scc.configuration_object,
+       scc.configuration_object_type,
+       scc.configuration_active,
+       scc.inheritance_enabled,
+       scc.children_only,
+       scc.interval,
+       scc.enabled,
+       scc.external_id
+FROM   soma.check_configurations scc
+WHERE  scc.configuration_id = $1::uuid
+  AND  scc.repository_id    = $2::uuid
+  AND  NOT scc.deleted;`
+
 	CheckConfigList = `
 SELECT configuration_id,
        repository_id,
@@ -167,11 +184,227 @@ WHERE  scc.configuration_id = $1::uuid
 SELECT sc.configuration_id
 FROM   soma.checks sc
 WHERE  sc.object_id = $1::uuid;`
+
+	CheckConfigBundleObjectName = `
+SELECT sr.name
+FROM   soma.repository sr
+WHERE  sr.id = $1::uuid
+  AND  $2::varchar = 'repository'
+UNION ALL
+SELECT sb.bucket_name
+FROM   soma.buckets sb
+WHERE  sb.bucket_id = $1::uuid
+  AND  $2::varchar = 'bucket'
+UNION ALL
+SELECT sg.group_name
+FROM   soma.groups sg
+WHERE  sg.group_id = $1::uuid
+  AND  $2::varchar = 'group'
+UNION ALL
+SELECT sc.cluster_name
+FROM   soma.clusters sc
+WHERE  sc.cluster_id = $1::uuid
+  AND  $2::varchar = 'cluster'
+UNION ALL
+SELECT sn.node_name
+FROM   soma.nodes sn
+WHERE  sn.node_id = $1::uuid
+  AND  $2::varchar = 'node';`
+
+	CheckConfigBundleObjectID = `
+SELECT sr.id,
+       NULL::uuid
+FROM   soma.repository sr
+WHERE  sr.id = $1::uuid
+  AND  $2::varchar = 'repository'
+UNION ALL
+SELECT sb.bucket_id,
+       sb.bucket_id
+FROM   soma.buckets sb
+WHERE  sb.repository_id = $1::uuid
+  AND  $2::varchar = 'bucket'
+  AND  sb.bucket_name = $4::varchar
+UNION ALL
+SELECT sg.group_id,
+       sb.bucket_id
+FROM   soma.groups sg
+JOIN   soma.buckets sb
+  ON   sg.bucket_id = sb.bucket_id
+WHERE  sb.repository_id = $1::uuid
+  AND  $2::varchar = 'group'
+  AND  sb.bucket_name = $3::varchar
+  AND  sg.group_name = $4::varchar
+UNION ALL
+SELECT sc.cluster_id,
+       sb.bucket_id
+FROM   soma.clusters sc
+JOIN   soma.buckets sb
+  ON   sc.bucket_id = sb.bucket_id
+WHERE  sb.repository_id = $1::uuid
+  AND  $2::varchar = 'cluster'
+  AND  sb.bucket_name = $3::varchar
+  AND  sc.cluster_name = $4::varchar
+UNION ALL
+SELECT sn.node_id,
+       sb.bucket_id
+FROM   soma.nodes sn
+JOIN   soma.node_bucket_assignment snba
+  ON   sn.node_id = snba.node_id
+JOIN   soma.buckets sb
+  ON   snba.bucket_id = sb.bucket_id
+WHERE  sb.repository_id = $1::uuid
+  AND  $2::varchar = 'node'
+  AND  sb.bucket_name = $3::varchar
+  AND  sn.node_name = $4::varchar;`
+
+	CheckConfigBundleCapabilityID = `
+SELECT smc.capability_id
+FROM   soma.monitoring_capabilities smc
+JOIN   soma.monitoring_systems sms
+  ON   smc.capability_monitoring = sms.monitoring_id
+WHERE  sms.monitoring_name = $1::varchar
+  AND  smc.capability_metric = $2::varchar
+  AND  smc.capability_view = $3::varchar;`
+
+	CheckConfigBundleCustomID = `
+SELECT scp.custom_property_id
+FROM   soma.custom_properties scp
+WHERE  scp.repository_id = $1::uuid
+  AND  scp.custom_property = $2::varchar;`
+
+	CheckConfigBundleOncallID = `
+SELECT iot.id,
+       iot.phone_number
+FROM   inventory.oncall_team iot
+WHERE  iot.name = $1::varchar;`
+
+	CheckConfigBundleServiceID = `
+SELECT ssp.id,
+       ssp.team_id
+FROM   soma.repository sr
+JOIN   soma.service_property ssp
+  ON   sr.team_id = ssp.team_id
+WHERE  sr.id = $1::uuid
+  AND  ssp.name = $2::varchar;`
+
+	CheckConfigLintConstraint = `
+SELECT snp.native_property
+FROM   soma.native_properties snp
+WHERE  $1::varchar = 'native'
+  AND  snp.native_property = $2::varchar
+UNION ALL
+SELECT ssp.system_property
+FROM   soma.system_properties ssp
+WHERE  $1::varchar = 'system'
+  AND  ssp.system_property = $2::varchar
+UNION ALL
+SELECT scp.custom_property
+FROM   soma.custom_properties scp
+WHERE  $1::varchar = 'custom'
+  AND  scp.custom_property_id::text = $2::varchar
+  AND  scp.repository_id = $3::uuid
+UNION ALL
+SELECT ssvp.name
+FROM   soma.repository sr
+JOIN   soma.service_property ssvp
+  ON   sr.team_id = ssvp.team_id
+WHERE  $1::varchar = 'service'
+  AND  ssvp.name = $2::varchar
+  AND  sr.id = $3::uuid
+UNION ALL
+SELECT sa.attribute
+FROM   soma.attribute sa
+WHERE  $1::varchar = 'attribute'
+  AND  sa.attribute = $2::varchar
+UNION ALL
+SELECT iot.name
+FROM   inventory.oncall_team iot
+WHERE  $1::varchar = 'oncall'
+  AND  iot.id::text = $2::varchar;`
+
+	CheckConfigLintDuplicate = `
+WITH configs AS (
+    SELECT configuration_id,
+           configuration_name
+    FROM   soma.check_configurations
+    WHERE  repository_id = $1::uuid
+      AND  configuration_object = $2::uuid
+      AND  capability_id = $3::uuid
+      AND  NOT deleted
+)
+SELECT c.configuration_id,
+       c.configuration_name,
+       ''::varchar,
+       ''::varchar,
+       ''::text
+FROM   configs c
+UNION ALL
+SELECT c.configuration_id,
+       c.configuration_name,
+       'native'::varchar,
+       scnp.native_property,
+       scnp.property_value
+FROM   configs c
+JOIN   soma.constraints_native_property scnp
+  ON   c.configuration_id = scnp.configuration_id
+UNION ALL
+SELECT c.configuration_id,
+       c.configuration_name,
+       'system'::varchar,
+       scsp.system_property,
+       scsp.property_value
+FROM   configs c
+JOIN   soma.constraints_system_property scsp
+  ON   c.configuration_id = scsp.configuration_id
+UNION ALL
+SELECT c.configuration_id,
+       c.configuration_name,
+       'custom'::varchar,
+       sccp.custom_property_id::varchar,
+       sccp.property_value
+FROM   configs c
+JOIN   soma.constraints_custom_property sccp
+  ON   c.configuration_id = sccp.configuration_id
+UNION ALL
+SELECT c.configuration_id,
+       c.configuration_name,
+       'service'::varchar,
+       scsvp.name,
+       scsvp.team_id::text
+FROM   configs c
+JOIN   soma.constraints_service_property scsvp
+  ON   c.configuration_id = scsvp.configuration_id
+UNION ALL
+SELECT c.configuration_id,
+       c.configuration_name,
+       'attribute'::varchar,
+       scsa.attribute,
+       COALESCE(scsa.value, '')::text
+FROM   configs c
+JOIN   soma.constraints_service_attribute scsa
+  ON   c.configuration_id = scsa.configuration_id
+UNION ALL
+SELECT c.configuration_id,
+       c.configuration_name,
+       'oncall'::varchar,
+       scop.oncall_duty_id::varchar,
+       ''::text
+FROM   configs c
+JOIN   soma.constraints_oncall_property scop
+  ON   c.configuration_id = scop.configuration_id;`
 )
 
 func init() {
+	m[CheckConfigBundleCapabilityID] = `CheckConfigBundleCapabilityID`
+	m[CheckConfigBundleCustomID] = `CheckConfigBundleCustomID`
+	m[CheckConfigBundleObjectID] = `CheckConfigBundleObjectID`
+	m[CheckConfigBundleObjectName] = `CheckConfigBundleObjectName`
+	m[CheckConfigBundleOncallID] = `CheckConfigBundleOncallID`
+	m[CheckConfigBundleServiceID] = `CheckConfigBundleServiceID`
 	m[CheckConfigForChecksOnObject] = `CheckConfigForChecksOnObject`
 	m[CheckConfigInstanceInfo] = `CheckConfigInstanceInfo`
+	m[CheckConfigLintConstraint] = `CheckConfigLintConstraint`
+	m[CheckConfigLintDuplicate] = `CheckConfigLintDuplicate`
 	m[CheckConfigList] = `CheckConfigList`
 	m[CheckConfigObjectInstanceInfo] = `CheckConfigObjectInstanceInfo`
 	m[CheckConfigShowBase] = `CheckConfigShowBase`
@@ -183,6 +416,7 @@ func init() {
 	m[CheckConfigShowConstrSystem] = `CheckConfigShowConstrSystem`
 	m[CheckConfigShowThreshold] = `CheckConfigShowThreshold`
 	m[CheckDetailsForDelete] = `CheckDetailsForDelete`
+	m[CheckDetailsForUpdate] = `CheckDetailsForUpdate`
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -49,6 +49,7 @@ SELECT id,
        started_at,
        finished_at,
        error,
+       warnings,
        job
 FROM   soma.job
 WHERE  id = $1::uuid;`
@@ -66,6 +67,7 @@ SELECT id,
        started_at,
        finished_at,
        error,
+       warnings,
        job
 FROM   soma.job
 WHERE  id = any($1::uuid[]);`
@@ -79,7 +81,8 @@ INSERT INTO soma.job (
             repository_id,
             user_id,
             team_id,
-            job)
+            job,
+            warnings)
 SELECT $1::uuid,
        $2::varchar,
        $3::varchar,
@@ -87,7 +90,8 @@ SELECT $1::uuid,
        $5::uuid,
        inventory.user.id,
        inventory.user.team_id,
-       $7::jsonb
+       $7::jsonb,
+       $8::jsonb
 FROM   inventory.user
 LEFT   JOIN auth.admin
   ON   inventory.user.uid = auth.admin.user_uid
@@ -158,7 +158,18 @@ SELECT scic.check_instance_config_id,
 FROM   soma.check_instance_configurations scic
 JOIN   soma.check_instances sci
   ON   scic.check_instance_id = sci.check_instance_id
-WHERE  sci.deleted
+JOIN   soma.check_configurations scc
+  ON   sci.check_configuration_id = scc.configuration_id
+JOIN   soma.checks sc
+  ON   sci.check_id = sc.check_id
+LEFT JOIN soma.nodes sn
+  ON   sc.object_id = sn.node_id
+ AND   sc.object_type = 'node'::varchar
+LEFT JOIN soma.object_states sos
+  ON   sn.lifecycle_state = sos.object_state
+WHERE  (   sci.deleted
+        OR NOT scc.enabled
+        OR sos.check_handling = '` + proto.StateChecksSuspend + `'::varchar)
   AND  scic.status = '` + proto.DeploymentActive + `'::varchar
   AND  scic.next_status = '` + proto.DeploymentNone + `'::varchar;`
 
@@ -168,6 +179,22 @@ SET    status = '` + proto.DeploymentAwaitingDeprovision + `'::varchar,
        next_status = '` + proto.DeploymentDeprovisionInProgress + `'::varchar
 WHERE  check_instance_config_id = $1::uuid;`
 
+	LifecycleUnassignDue = `
+SELECT sn.node_id,
+       snba.bucket_id,
+       sb.repository_id,
+       sn.lifecycle_state_by
+FROM   soma.nodes sn
+JOIN   soma.object_states sos
+  ON   sn.lifecycle_state = sos.object_state
+JOIN   soma.node_bucket_assignment snba
+  ON   sn.node_id = snba.node_id
+JOIN   soma.buckets sb
+  ON   snba.bucket_id = sb.bucket_id
+WHERE  sos.unassign_after > 0
+  AND  sn.lifecycle_state_by IS NOT NULL
+  AND  NOW() > (sn.lifecycle_state_since + sos.unassign_after * '1 second'::interval);`
+
 	LifecycleDeadLockResolver = `
 SELECT ci.check_instance_id,
        ci.current_instance_config_id
@@ -195,6 +222,7 @@ func init() {
 	m[LifecycleReadyDeployments] = `LifecycleReadyDeployments`
 	m[LifecycleRescheduleDeployments] = `LifecycleRescheduleDeployments`
 	m[LifecycleSetNotified] = `LifecycleSetNotified`
+	m[LifecycleUnassignDue] = `LifecycleUnassignDue`
 	m[LifecycleUpdateConfig] = `LifecycleUpdateConfig`
 	m[LifecycleUpdateInstance] = `LifecycleUpdateInstance`
 }
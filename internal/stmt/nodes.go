@@ -33,7 +33,8 @@ SELECT node_id,
        server_id,
        object_state,
        node_online,
-       node_deleted
+       node_deleted,
+       lifecycle_state
 FROM   soma.nodes
 WHERE  node_id = $1;`
 
@@ -17,7 +17,10 @@ SELECT object_state
 FROM   soma.object_states;`
 
 	ObjectStateShow = `
-SELECT object_state
+SELECT object_state,
+       check_handling,
+       restrict_views,
+       unassign_after
 FROM   soma.object_states
 WHERE  object_state = $1::varchar;`
 
@@ -39,6 +42,30 @@ UPDATE soma.object_states
 SET    object_state = $1::varchar
 WHERE  object_state = $2::varchar;`
 
+	ObjectStateUpdate = `
+UPDATE soma.object_states
+SET    check_handling = $1::varchar,
+       restrict_views = $2::varchar[],
+       unassign_after = $3::integer
+WHERE  object_state = $4::varchar;`
+
+	ObjectStateLock = `
+SELECT check_handling,
+       restrict_views
+FROM   soma.object_states
+WHERE  object_state = $1::varchar
+FOR    UPDATE;`
+
+	ObjectStateUsage = `
+SELECT (SELECT COUNT(1)
+        FROM   soma.nodes
+        WHERE  lifecycle_state = $1::varchar)
+     + (SELECT COUNT(1)
+        FROM   soma.job
+        WHERE  type = 'node-config::lifecycle'
+          AND  status != 'processed'
+          AND  job->'State'->>'name' = $1::varchar);`
+
 	EntityList = `
 SELECT object_type
 FROM   soma.object_types;`
@@ -70,9 +97,12 @@ WHERE  object_type = $2::varchar;`
 func init() {
 	m[ObjectStateAdd] = `ObjectStateAdd`
 	m[ObjectStateList] = `ObjectStateList`
+	m[ObjectStateLock] = `ObjectStateLock`
 	m[ObjectStateRemove] = `ObjectStateRemove`
 	m[ObjectStateRename] = `ObjectStateRename`
 	m[ObjectStateShow] = `ObjectStateShow`
+	m[ObjectStateUsage] = `ObjectStateUsage`
+	m[ObjectStateUpdate] = `ObjectStateUpdate`
 	m[EntityAdd] = `EntityAdd`
 	m[EntityDel] = `EntityDel`
 	m[EntityList] = `EntityList`
@@ -108,6 +108,107 @@ FROM   inventory.oncall_membership
 JOIN   inventory.user
   ON   inventory.oncall_membership.user_id = inventory.user.id
 WHERE  inventory.oncall_membership.oncall_id = $1::uuid;`
+
+	OncallRotationShow = `
+SELECT inventory.oncall_rotation.period,
+       inventory.oncall_rotation.handover,
+       inventory.oncall_rotation.start_date,
+       inventory.oncall_rotation.time_zone
+FROM   inventory.oncall_rotation
+WHERE  inventory.oncall_rotation.oncall_id = $1::uuid;`
+
+	OncallRotationMembers = `
+SELECT inventory.oncall_rotation_member.user_id,
+       inventory.user.uid
+FROM   inventory.oncall_rotation_member
+JOIN   inventory.user
+  ON   inventory.oncall_rotation_member.user_id = inventory.user.id
+WHERE  inventory.oncall_rotation_member.oncall_id = $1::uuid
+ORDER  BY inventory.oncall_rotation_member.position ASC;`
+
+	OncallRotationAdd = `
+INSERT INTO inventory.oncall_rotation (
+            oncall_id,
+            period,
+            handover,
+            start_date,
+            time_zone,
+            created_by)
+SELECT $1::uuid,
+       $2::varchar,
+       $3::varchar,
+       $4::date,
+       $5::varchar,
+       ( SELECT inventory.user.id FROM inventory.user
+         LEFT JOIN auth.admin
+         ON inventory.user.uid = auth.admin.user_uid
+         WHERE (   inventory.user.uid = $6::varchar
+                OR auth.admin.uid     = $6::varchar ));`
+
+	OncallRotationMemberAdd = `
+INSERT INTO inventory.oncall_rotation_member (
+            oncall_id,
+            user_id,
+            position)
+SELECT $1::uuid,
+       $2::uuid,
+       $3::smallint;`
+
+	OncallRotationRemove = `
+DELETE FROM inventory.oncall_rotation
+WHERE  inventory.oncall_rotation.oncall_id = $1::uuid;`
+
+	OncallOverrideList = `
+SELECT inventory.oncall_override.id,
+       inventory.oncall_override.user_id,
+       inventory.user.uid,
+       inventory.oncall_override.starts_at,
+       inventory.oncall_override.ends_at
+FROM   inventory.oncall_override
+JOIN   inventory.user
+  ON   inventory.oncall_override.user_id = inventory.user.id
+WHERE  inventory.oncall_override.oncall_id = $1::uuid
+  AND  inventory.oncall_override.ends_at > $2::timestamptz
+ORDER  BY inventory.oncall_override.starts_at ASC;`
+
+	OncallOverrideActive = `
+SELECT inventory.oncall_override.id,
+       inventory.oncall_override.user_id,
+       inventory.user.uid,
+       inventory.oncall_override.starts_at,
+       inventory.oncall_override.ends_at
+FROM   inventory.oncall_override
+JOIN   inventory.user
+  ON   inventory.oncall_override.user_id = inventory.user.id
+WHERE  inventory.oncall_override.oncall_id = $1::uuid
+  AND  inventory.oncall_override.starts_at <= $2::timestamptz
+  AND  inventory.oncall_override.ends_at > $2::timestamptz
+ORDER  BY inventory.oncall_override.created_at DESC
+LIMIT  1;`
+
+	OncallOverrideAdd = `
+INSERT INTO inventory.oncall_override (
+            id,
+            oncall_id,
+            user_id,
+            starts_at,
+            ends_at,
+            created_by)
+SELECT $1::uuid,
+       $2::uuid,
+       $3::uuid,
+       $4::timestamptz,
+       $5::timestamptz,
+       ( SELECT inventory.user.id FROM inventory.user
+         LEFT JOIN auth.admin
+         ON inventory.user.uid = auth.admin.user_uid
+         WHERE (   inventory.user.uid = $6::varchar
+                OR auth.admin.uid     = $6::varchar ));`
+
+	OncallOverrideRemove = `
+DELETE FROM inventory.oncall_override
+WHERE  inventory.oncall_override.id = $1::uuid
+  AND  inventory.oncall_override.oncall_id = $2::uuid;`
 )
 
 func init() {
@@ -116,7 +217,16 @@ func init() {
 	m[OncallMemberAssign] = `OncallMemberAssign`
 	m[OncallMemberList] = `OncallMemberList`
 	m[OncallMemberUnassign] = `OncallMemberUnassign`
+	m[OncallOverrideActive] = `OncallOverrideActive`
+	m[OncallOverrideAdd] = `OncallOverrideAdd`
+	m[OncallOverrideList] = `OncallOverrideList`
+	m[OncallOverrideRemove] = `OncallOverrideRemove`
 	m[OncallRemove] = `OncallRemove`
+	m[OncallRotationAdd] = `OncallRotationAdd`
+	m[OncallRotationMemberAdd] = `OncallRotationMemberAdd`
+	m[OncallRotationMembers] = `OncallRotationMembers`
+	m[OncallRotationRemove] = `OncallRotationRemove`
+	m[OncallRotationShow] = `OncallRotationShow`
 	m[OncallSearch] = `OncallSearch`
 	m[OncallShow] = `OncallShow`
 	m[OncallUpdate] = `OncallUpdate`
@@ -17,14 +17,17 @@ SELECT predicate
 FROM   soma.configuration_predicates;`
 
 	PredicateShow = `
-SELECT predicate
+SELECT predicate,
+       escalation
 FROM   soma.configuration_predicates
 WHERE  predicate = $1;`
 
 	PredicateAdd = `
 INSERT INTO soma.configuration_predicates (
-            predicate)
-SELECT $1::varchar
+            predicate,
+            escalation)
+SELECT $1::varchar,
+       $2::varchar
 WHERE  NOT EXISTS (
    SELECT predicate
    FROM   soma.configuration_predicates
@@ -58,7 +58,8 @@ FROM   soma.template_property;`
 	PropertyCustomList = `
 SELECT custom_property_id,
        repository_id,
-       custom_property
+       custom_property,
+       value_schema
 FROM   soma.custom_properties
 WHERE  repository_id = $1::uuid;`
 
@@ -75,7 +76,8 @@ WHERE  native_property = $1::varchar;`
 	PropertyCustomShow = `
 SELECT custom_property_id,
        repository_id,
-       custom_property
+       custom_property,
+       value_schema
 FROM   soma.custom_properties
 WHERE  custom_property_id = $1::uuid
 AND    repository_id = $2::uuid;`
@@ -123,14 +125,48 @@ WHERE  NOT EXISTS (
 INSERT INTO soma.custom_properties (
             custom_property_id,
             repository_id,
-            custom_property)
-SELECT $1::uuid, $2::uuid, $3::varchar
+            custom_property,
+            value_schema)
+SELECT $1::uuid, $2::uuid, $3::varchar, $4::jsonb
 WHERE  NOT EXISTS (
    SELECT custom_property
    FROM   soma.custom_properties
    WHERE  custom_property = $3::varchar
      AND  repository_id = $2::uuid);`
 
+	PropertyCustomSchemaUpdate = `
+UPDATE soma.custom_properties
+SET    value_schema = $3::jsonb
+WHERE  repository_id = $1::uuid
+AND    custom_property_id = $2::uuid;`
+
+	PropertyCustomSchemaLoad = `
+SELECT value_schema
+FROM   soma.custom_properties
+WHERE  custom_property_id = $1::uuid
+AND    repository_id = $2::uuid;`
+
+	PropertyCustomValues = `
+SELECT value
+FROM   soma.repository_custom_properties
+WHERE  custom_property_id = $1::uuid
+UNION
+SELECT value
+FROM   soma.bucket_custom_properties
+WHERE  custom_property_id = $1::uuid
+UNION
+SELECT value
+FROM   soma.group_custom_properties
+WHERE  custom_property_id = $1::uuid
+UNION
+SELECT value
+FROM   soma.cluster_custom_properties
+WHERE  custom_property_id = $1::uuid
+UNION
+SELECT value
+FROM   soma.node_custom_properties
+WHERE  custom_property_id = $1::uuid;`
+
 	PropertyServiceAdd = `
 INSERT INTO soma.service_property (
             id,
@@ -203,7 +239,10 @@ func init() {
 	m[PropertyCustomAdd] = `PropertyCustomAdd`
 	m[PropertyCustomDel] = `PropertyCustomDel`
 	m[PropertyCustomList] = `PropertyCustomList`
+	m[PropertyCustomSchemaLoad] = `PropertyCustomSchemaLoad`
+	m[PropertyCustomSchemaUpdate] = `PropertyCustomSchemaUpdate`
 	m[PropertyCustomShow] = `PropertyCustomShow`
+	m[PropertyCustomValues] = `PropertyCustomValues`
 	m[PropertyNativeAdd] = `PropertyNativeAdd`
 	m[PropertyNativeDel] = `PropertyNativeDel`
 	m[PropertyNativeList] = `PropertyNativeList`
@@ -0,0 +1,52 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package stmt
+
+const (
+	PropertyHistoryStatements = ``
+
+	// all recorded property instances of an object
+	PropertyHistoryList = `
+SELECT sph.instance_id,
+       sph.repository_id,
+       sph.property_type,
+       sph.view,
+       sph.source_instance_id,
+       sph.source_object_type,
+       sph.source_object_id,
+       sph.inheritance_enabled,
+       sph.children_only,
+       sph.custom_property_id,
+       scp.custom_property,
+       sph.system_property,
+       sph.service_id,
+       ssp.name,
+       sph.oncall_duty_id,
+       iot.name,
+       sph.value,
+       sph.created_by_job,
+       sph.created_at,
+       sph.deleted_by_job,
+       sph.deleted_at
+FROM   soma.property_history sph
+LEFT   JOIN soma.custom_properties scp
+  ON   sph.custom_property_id = scp.custom_property_id
+LEFT   JOIN soma.service_property ssp
+  ON   sph.service_id = ssp.id
+LEFT   JOIN inventory.oncall_team iot
+  ON   sph.oncall_duty_id = iot.id
+WHERE  sph.object_id = $1::uuid
+ORDER  BY sph.created_at, sph.instance_id;`
+)
+
+func init() {
+	m[PropertyHistoryList] = `PropertyHistoryList`
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
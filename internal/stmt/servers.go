@@ -88,6 +88,17 @@ SET    server_deleted = 'yes'::boolean,
 WHERE  server_id = $1::uuid
 AND    server_id != '00000000-0000-0000-0000-000000000000'::uuid;`
 
+	RestoreServers = `
+UPDATE inventory.servers
+SET    server_asset_id = $2::numeric,
+       server_datacenter_name = $3::varchar,
+       server_datacenter_location = $4::varchar,
+       server_name = $5::varchar,
+       server_online = $6::boolean,
+       server_deleted = 'no'::boolean
+WHERE  server_id = $1::uuid
+  AND  server_deleted;`
+
 	PurgeServers = `
 DELETE FROM inventory.servers
 WHERE  server_id = $1::uuid
@@ -100,6 +111,7 @@ func init() {
 	m[DeleteServers] = `DeleteServers`
 	m[ListServers] = `ListServers`
 	m[PurgeServers] = `PurgeServers`
+	m[RestoreServers] = `RestoreServers`
 	m[SearchServer] = `SearchServer`
 	m[ShowServers] = `ShowServers`
 	m[SyncServers] = `SyncServers`
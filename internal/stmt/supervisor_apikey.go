@@ -0,0 +1,166 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package stmt
+
+const (
+	SupervisorAPIKeyStatements = ``
+
+	// lookup the user an API key is created for
+	APIKeyUser = `
+SELECT uid,
+       is_system,
+       is_active
+FROM   inventory.user
+WHERE  id = $1::uuid
+AND    NOT is_deleted;`
+
+	// insert a new API key, created_by is looked up from the
+	// authenticated user or admin account
+	APIKeyAdd = `
+INSERT INTO auth.api_keys (
+            id,
+            user_id,
+            name,
+            key_hash,
+            created_at,
+            valid_until,
+            created_by)
+SELECT $1::uuid,
+       $2::uuid,
+       $3::varchar,
+       $4::varchar,
+       $5::timestamptz,
+       $6::timestamptz,
+       inventory.user.id
+FROM   inventory.user
+LEFT   JOIN auth.admin
+  ON   inventory.user.uid = auth.admin.user_uid
+WHERE  (   inventory.user.uid = $7::varchar
+        OR auth.admin.uid     = $7::varchar );`
+
+	// restrict an API key to a permission
+	APIKeyPermissionAdd = `
+INSERT INTO auth.api_key_permissions (
+            api_key_id,
+            permission_id
+) VALUES (
+            $1::uuid,
+            $2::uuid);`
+
+	// list all API keys of a user
+	APIKeyList = `
+SELECT    ak.id,
+          ak.name,
+          ak.created_at,
+          cu.uid,
+          ak.valid_until,
+          ak.last_used_at,
+          ak.revoked_at,
+          ru.uid
+FROM      auth.api_keys ak
+JOIN      inventory.user cu
+  ON      ak.created_by = cu.id
+LEFT JOIN inventory.user ru
+  ON      ak.revoked_by = ru.id
+WHERE     ak.user_id = $1::uuid
+ORDER BY  ak.name,
+          ak.created_at;`
+
+	// list the permissions an API key is restricted to
+	APIKeyPermissionList = `
+SELECT sp.id,
+       sp.name,
+       sp.category
+FROM   auth.api_key_permissions akp
+JOIN   soma.permission sp
+  ON   akp.permission_id = sp.id
+WHERE  akp.api_key_id = $1::uuid;`
+
+	// revoke an active API key of a user
+	APIKeyRevoke = `
+UPDATE auth.api_keys
+SET    revoked_at = $1::timestamptz,
+       revoked_by = (
+           SELECT inventory.user.id
+           FROM   inventory.user
+           LEFT   JOIN auth.admin
+             ON   inventory.user.uid = auth.admin.user_uid
+           WHERE  (   inventory.user.uid = $2::varchar
+                   OR auth.admin.uid     = $2::varchar ))
+WHERE  id = $3::uuid
+AND    user_id = $4::uuid
+AND    revoked_at IS NULL;`
+
+	// record the last use of an API key
+	APIKeyUsed = `
+UPDATE auth.api_keys
+SET    last_used_at = $1::timestamptz
+WHERE  id = $2::uuid;`
+
+	// lookup a specific API key (readonly instances)
+	SelectAPIKey = `
+SELECT ak.id,
+       ak.user_id,
+       iu.uid,
+       ak.created_at,
+       ak.valid_until
+FROM   auth.api_keys ak
+JOIN   inventory.user iu
+  ON   ak.user_id = iu.id
+WHERE  ak.key_hash = $1::varchar
+AND    ak.revoked_at IS NULL
+AND    NOT iu.is_deleted;`
+
+	// lookup the permission IDs of an API key (readonly instances)
+	SelectAPIKeyScope = `
+SELECT permission_id
+FROM   auth.api_key_permissions
+WHERE  api_key_id = $1::uuid;`
+
+	// startup loading all active API keys
+	LoadAllAPIKeys = `
+SELECT ak.id,
+       ak.user_id,
+       iu.uid,
+       ak.key_hash,
+       ak.created_at,
+       ak.valid_until
+FROM   auth.api_keys ak
+JOIN   inventory.user iu
+  ON   ak.user_id = iu.id
+WHERE  ak.revoked_at IS NULL
+AND    NOW() < ak.valid_until
+AND    NOT iu.is_deleted;`
+
+	// startup loading the permission IDs of all active API keys
+	LoadAllAPIKeyPermissions = `
+SELECT akp.api_key_id,
+       akp.permission_id
+FROM   auth.api_key_permissions akp
+JOIN   auth.api_keys ak
+  ON   akp.api_key_id = ak.id
+WHERE  ak.revoked_at IS NULL
+AND    NOW() < ak.valid_until;`
+)
+
+func init() {
+	m[APIKeyAdd] = `APIKeyAdd`
+	m[APIKeyList] = `APIKeyList`
+	m[APIKeyPermissionAdd] = `APIKeyPermissionAdd`
+	m[APIKeyPermissionList] = `APIKeyPermissionList`
+	m[APIKeyRevoke] = `APIKeyRevoke`
+	m[APIKeyUsed] = `APIKeyUsed`
+	m[APIKeyUser] = `APIKeyUser`
+	m[LoadAllAPIKeyPermissions] = `LoadAllAPIKeyPermissions`
+	m[LoadAllAPIKeys] = `LoadAllAPIKeys`
+	m[SelectAPIKey] = `SelectAPIKey`
+	m[SelectAPIKeyScope] = `SelectAPIKeyScope`
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
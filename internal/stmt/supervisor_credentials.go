@@ -46,6 +46,44 @@ AND    auth.admin.is_active
 AND    NOW() > auth.admin_authentication.valid_from
 AND    NOW() < auth.admin_authentication.valid_until;`
 
+	// load the current credentials of a single user or admin, used
+	// to apply credential changes made by other instances
+	LoadUserCredential = `
+SELECT   aua.user_id,
+         aua.crypt,
+         aua.reset_pending,
+         aua.valid_from,
+         aua.valid_until
+FROM     inventory.user iu
+JOIN     auth.user_authentication aua
+ON       iu.id = aua.user_id
+WHERE    iu.uid = $1::varchar
+AND      iu.id != '00000000-0000-0000-0000-000000000000'::uuid
+AND      NOW() < aua.valid_until
+AND      NOT iu.is_deleted
+AND      iu.is_active
+ORDER BY aua.valid_from DESC
+LIMIT    1;`
+
+	LoadAdminCredential = `
+SELECT   auth.admin_authentication.admin_id,
+         auth.admin_authentication.crypt,
+         auth.admin_authentication.reset_pending,
+         auth.admin_authentication.valid_from,
+         auth.admin_authentication.valid_until
+FROM     auth.admin
+JOIN     auth.admin_authentication
+ON       auth.admin.id = auth.admin_authentication.admin_id
+JOIN     inventory.user
+ON       auth.admin.user_uid = inventory.user.uid
+WHERE    auth.admin.uid = $1::varchar
+AND      NOT inventory.user.is_deleted
+AND      inventory.user.is_active
+AND      auth.admin.is_active
+AND      NOW() < auth.admin_authentication.valid_until
+ORDER BY auth.admin_authentication.valid_from DESC
+LIMIT    1;`
+
 	FindUserID = `
 SELECT id
 FROM   inventory.user
@@ -135,8 +173,10 @@ func init() {
 	m[FindAdminID] = `FindAdminID`
 	m[FindUserName] = `FindUserName`
 	m[InvalidateUserCredential] = `InvalidateUserCredential`
-	m[LoadAllUserCredentials] = `LoadAllUserCredentials`
+	m[LoadAdminCredential] = `LoadAdminCredential`
 	m[LoadAllAdminCredentials] = `LoadAllAdminCredentials`
+	m[LoadAllUserCredentials] = `LoadAllUserCredentials`
+	m[LoadUserCredential] = `LoadUserCredential`
 	m[SetUserCredential] = `SetUserCredential`
 	m[SetAdminCredential] = `SetAdminCredential`
 }
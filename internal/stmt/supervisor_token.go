@@ -34,6 +34,21 @@ INSERT INTO auth.token_revocations (
     $1::uuid,
     $2::timestamptz);`
 
+	// load all account revocations that can still affect issued
+	// tokens
+	LoadTokenRevocations = `
+SELECT   iu.uid,
+         MAX(atr.revoked_at)
+FROM     auth.token_revocations atr
+JOIN     inventory.user iu
+  ON     atr.user_id = iu.id
+WHERE    atr.revoked_at > $1::timestamptz
+GROUP BY iu.uid;`
+
+	// propagate a token event to all other instances
+	NotifyToken = `
+SELECT pg_notify($1::text, $2::text);`
+
 	// lookup a specific token (readonly instances)
 	SelectToken = `
 SELECT salt,
@@ -62,6 +77,8 @@ func init() {
 	m[ExpireToken] = `ExpireToken`
 	m[InsertToken] = `InsertToken`
 	m[LoadAllTokens] = `LoadAllTokens`
+	m[LoadTokenRevocations] = `LoadTokenRevocations`
+	m[NotifyToken] = `NotifyToken`
 	m[RevokeTokensForUser] = `RevokeTokensForUser`
 	m[SelectToken] = `SelectToken`
 }
@@ -126,10 +126,22 @@ WHERE  sgmn.group_id = $1::uuid;`
 SELECT scm.node_id
 FROM   soma.cluster_membership scm
 WHERE  scm.cluster_id = $1::uuid;`
+
+	// checks on an object, grouped by the object they were created on
+	TreeCheckCount = `
+SELECT sc.object_id,
+       sc.source_object_id,
+       COUNT(sc.check_id)
+FROM   soma.checks sc
+WHERE  sc.object_id = any($1::uuid[])
+  AND  NOT sc.deleted
+GROUP  BY sc.object_id,
+          sc.source_object_id;`
 )
 
 func init() {
 	m[TreeBucketsInRepository] = `TreeBucketsInRepository`
+	m[TreeCheckCount] = `TreeCheckCount`
 	m[TreeClustersInBucket] = `TreeClustersInBucket`
 	m[TreeClustersInGroup] = `TreeClustersInGroup`
 	m[TreeGroupsInBucket] = `TreeGroupsInBucket`
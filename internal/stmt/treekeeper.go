@@ -18,10 +18,11 @@ const (
 
 	TreekeeperStartJob = `
 UPDATE soma.job
-SET    started_at = $2::timestamptz,
-       status = 'in_progress'
+SET    started_at = COALESCE(started_at, $2::timestamptz),
+       status = 'in_progress',
+       attempts = attempts + 1
 WHERE  id = $1::uuid
-AND    started_at IS NULL;`
+AND    status != 'processed';`
 
 	TreekeeperGetViewFromCapability = `
 SELECT capability_view
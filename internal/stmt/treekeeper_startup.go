@@ -259,7 +259,10 @@ SELECT    sn.node_id,
           sn.node_deleted,
           snba.bucket_id,
           scm.cluster_id,
-          sgmn.group_id
+          sgmn.group_id,
+          sn.lifecycle_state,
+          sos.check_handling,
+          sos.restrict_views
 FROM      soma.repository
 JOIN      soma.buckets sb
 ON        soma.repository.id = sb.repository_id
@@ -271,11 +274,16 @@ LEFT JOIN soma.cluster_membership scm
 ON        sn.node_id = scm.node_id
 LEFT JOIN soma.group_membership_nodes sgmn
 ON        sn.node_id = sgmn.child_node_id
+LEFT JOIN soma.object_states sos
+ON        sn.lifecycle_state = sos.object_state
 WHERE     soma.repository.id = $1::uuid
 AND       NOT sb.bucket_deleted;`
 
 	TkStartLoadJob = `
-SELECT   job
+SELECT   id,
+         status,
+         attempts,
+         job
 FROM     soma.job
 WHERE    repository_id = $1::uuid
 AND      status != 'processed'
@@ -142,6 +142,17 @@ SELECT $1::uuid,
        $3::varchar,
        $4::varchar;`
 
+	TxUpdateCheckConfigurationBase = `
+UPDATE soma.check_configurations
+SET    interval    = $2::integer,
+       enabled     = $3::boolean,
+       external_id = $4::varchar
+WHERE  configuration_id = $1::uuid;`
+
+	TxDeleteCheckConfigurationThresholds = `
+DELETE FROM soma.configuration_thresholds
+WHERE  configuration_id = $1::uuid;`
+
 	TxCreateCheckConfigurationConstraintSystem = `
 INSERT INTO soma.constraints_system_property (
             configuration_id,
@@ -213,6 +224,52 @@ SELECT $1::uuid,
 DELETE FROM soma.property_instances
 WHERE       instance_id = $1::uuid;`
 
+	TxPropertyHistoryCreate = `
+INSERT INTO soma.property_history (
+            instance_id,
+            repository_id,
+            object_id,
+            object_type,
+            property_type,
+            view,
+            source_instance_id,
+            source_object_type,
+            source_object_id,
+            inheritance_enabled,
+            children_only,
+            custom_property_id,
+            system_property,
+            service_id,
+            oncall_duty_id,
+            value,
+            created_by_job,
+            created_at)
+SELECT $1::uuid,
+       $2::uuid,
+       $3::uuid,
+       $4::varchar,
+       $5::varchar,
+       $6::varchar,
+       $7::uuid,
+       $8::varchar,
+       $9::uuid,
+       $10::boolean,
+       $11::boolean,
+       $12::uuid,
+       $13::varchar,
+       $14::uuid,
+       $15::uuid,
+       $16::text,
+       $17::uuid,
+       NOW()::timestamptz;`
+
+	TxPropertyHistoryDelete = `
+UPDATE soma.property_history
+SET    deleted_by_job = $2::uuid,
+       deleted_at = NOW()::timestamptz
+WHERE  instance_id = $1::uuid
+AND    deleted_at IS NULL;`
+
 	TxFinishJob = `
 UPDATE soma.job
 SET    finished_at = $2::timestamptz,
@@ -333,6 +390,40 @@ WHERE       node_id = $1::uuid
 AND         bucket_id = $2::uuid
 AND         organizational_team_id = $3::uuid;`
 
+	TxNodeLifecycleSet = `
+UPDATE soma.nodes
+SET    lifecycle_state = NULLIF($2::varchar, ''),
+       lifecycle_state_since = NOW()::timestamptz,
+       lifecycle_state_by = $3::varchar
+WHERE  node_id = $1::uuid;`
+
+	TxNodeMoveCheckConfigs = `
+UPDATE soma.check_configurations
+SET    bucket_id = $3::uuid
+WHERE  configuration_object = $1::uuid
+AND    configuration_object_type = 'node'::varchar
+AND    bucket_id = $2::uuid;`
+
+	TxNodeMoveChecks = `
+UPDATE soma.checks
+SET    bucket_id = $3::uuid
+WHERE  object_id = $1::uuid
+AND    object_type = 'node'::varchar
+AND    bucket_id = $2::uuid
+AND    NOT deleted;`
+
+	TxNodeMoveCustomProperties = `
+UPDATE soma.node_custom_properties
+SET    bucket_id = $3::uuid
+WHERE  node_id = $1::uuid
+AND    bucket_id = $2::uuid;`
+
+	TxNodeMoveGrants = `
+UPDATE soma.authorizations_repository
+SET    bucket_id = $3::uuid
+WHERE  node_id = $1::uuid
+AND    bucket_id = $2::uuid;`
+
 	TxNodePropertyOncallCreate = `
 INSERT INTO soma.node_oncall_property (
             instance_id,
@@ -1046,6 +1137,7 @@ WHERE  configuration_id = $1::uuid;`
 
 	TxDeployDetailsCheckConfigThreshold = `
 SELECT sct.predicate,
+       scp.escalation,
        sct.threshold,
        sct.notification_level,
        snl.level_shortname,
@@ -1053,6 +1145,8 @@ SELECT sct.predicate,
 FROM   soma.configuration_thresholds sct
 JOIN   soma.notification_levels snl
 ON     sct.notification_level = snl.level_name
+JOIN   soma.configuration_predicates scp
+ON     sct.predicate = scp.predicate
 WHERE  sct.configuration_id = $1::uuid;`
 
 	TxDeployDetailsCapabilityMonitoringMetric = `
@@ -1306,6 +1400,7 @@ func init() {
 	m[TxCreateCheckInstance] = `TxCreateCheckInstance`
 	m[TxCreateCheck] = `TxCreateCheck`
 	m[TxDeferAllConstraints] = `TxDeferAllConstraints`
+	m[TxDeleteCheckConfigurationThresholds] = `TxDeleteCheckConfigurationThresholds`
 	m[TxDeployDetailClusterCustProp] = `TxDeployDetailClusterCustProp`
 	m[TxDeployDetailClusterSysProp] = `TxDeployDetailClusterSysProp`
 	m[TxDeployDetailDefaultDatacenter] = `TxDeployDetailDefaultDatacenter`
@@ -1352,6 +1447,11 @@ func init() {
 	m[TxMarkCheckConfigDeleted] = `TxMarkCheckConfigDeleted`
 	m[TxMarkCheckDeleted] = `TxMarkCheckDeleted`
 	m[TxMarkCheckInstanceDeleted] = `TxMarkCheckInstanceDeleted`
+	m[TxNodeLifecycleSet] = `TxNodeLifecycleSet`
+	m[TxNodeMoveCheckConfigs] = `TxNodeMoveCheckConfigs`
+	m[TxNodeMoveChecks] = `TxNodeMoveChecks`
+	m[TxNodeMoveCustomProperties] = `TxNodeMoveCustomProperties`
+	m[TxNodeMoveGrants] = `TxNodeMoveGrants`
 	m[TxNodePropertyCustomCreate] = `TxNodePropertyCustomCreate`
 	m[TxNodePropertyCustomDelete] = `TxNodePropertyCustomDelete`
 	m[TxNodePropertyOncallCreate] = `TxNodePropertyOncallCreate`
@@ -1361,6 +1461,8 @@ func init() {
 	m[TxNodePropertySystemCreate] = `TxNodePropertySystemCreate`
 	m[TxNodePropertySystemDelete] = `TxNodePropertySystemDelete`
 	m[TxNodeUnassignFromBucket] = `TxNodeUnassignFromBucket`
+	m[TxPropertyHistoryCreate] = `TxPropertyHistoryCreate`
+	m[TxPropertyHistoryDelete] = `TxPropertyHistoryDelete`
 	m[TxPropertyInstanceCreate] = `TxPropertyInstanceCreate`
 	m[TxPropertyInstanceDelete] = `TxPropertyInstanceDelete`
 	m[TxRepositoryPropertyCustomCreate] = `TxRepositoryPropertyCustomCreate`
@@ -1371,6 +1473,7 @@ func init() {
 	m[TxRepositoryPropertyServiceDelete] = `TxRepositoryPropertyServiceDelete`
 	m[TxRepositoryPropertySystemCreate] = `TxRepositoryPropertySystemCreate`
 	m[TxRepositoryPropertySystemDelete] = `TxRepositoryPropertySystemDelete`
+	m[TxUpdateCheckConfigurationBase] = `TxUpdateCheckConfigurationBase`
 	m[TxUpdateNodeState] = `TxUpdateNodeState`
 	m[TxRepositoryDestroy] = `TxRepositoryDestroy`
 	m[TxRepositoryRename] = `TxRepositoryRename`
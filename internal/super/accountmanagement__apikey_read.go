@@ -0,0 +1,134 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package super // import "github.com/mjolnir42/soma/internal/super"
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
+)
+
+func (s *Supervisor) apiKeyRead(q *msg.Request, mr *msg.Result) {
+	switch q.Action {
+	case msg.ActionList:
+		s.apiKeyList(q, mr)
+	}
+}
+
+func (s *Supervisor) apiKeyList(q *msg.Request, mr *msg.Result) {
+	var (
+		err                   error
+		rows, permRows        *sql.Rows
+		id, name, createdBy   string
+		createdAt, expiresAt  pq.NullTime
+		lastUsedAt, revokedAt pq.NullTime
+		revokedBy             sql.NullString
+	)
+
+	if _, err = uuid.FromString(q.APIKey.UserID); err != nil {
+		mr.BadRequest(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return
+	}
+
+	if rows, err = s.stmtAPIKeyList.Query(
+		q.APIKey.UserID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&id,
+			&name,
+			&createdAt,
+			&createdBy,
+			&expiresAt,
+			&lastUsedAt,
+			&revokedAt,
+			&revokedBy,
+		); err != nil {
+			mr.ServerError(err, q.Section)
+			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+			return
+		}
+		key := proto.APIKey{
+			ID:          id,
+			Name:        name,
+			UserID:      q.APIKey.UserID,
+			CreatedBy:   createdBy,
+			Permissions: []proto.Permission{},
+		}
+		if createdAt.Valid {
+			key.CreatedAt = createdAt.Time.UTC().Format(msg.RFC3339Milli)
+		}
+		if expiresAt.Valid {
+			key.ExpiresAt = expiresAt.Time.UTC().Format(msg.RFC3339Milli)
+		}
+		if lastUsedAt.Valid {
+			key.LastUsedAt = lastUsedAt.Time.UTC().Format(msg.RFC3339Milli)
+		}
+		if revokedAt.Valid {
+			key.RevokedAt = revokedAt.Time.UTC().Format(msg.RFC3339Milli)
+			key.Details = &proto.APIKeyDetails{
+				RevokedBy: revokedBy.String,
+			}
+		}
+		mr.APIKey = append(mr.APIKey, key)
+	}
+	if err = rows.Err(); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return
+	}
+
+	// load the permissions each API key is restricted to
+	for i := range mr.APIKey {
+		if permRows, err = s.stmtAPIKeyPermissionList.Query(
+			mr.APIKey[i].ID,
+		); err != nil {
+			mr.ServerError(err, q.Section)
+			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+			return
+		}
+
+		for permRows.Next() {
+			perm := proto.Permission{}
+			if err = permRows.Scan(
+				&perm.ID,
+				&perm.Name,
+				&perm.Category,
+			); err != nil {
+				permRows.Close()
+				mr.ServerError(err, q.Section)
+				mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+				return
+			}
+			mr.APIKey[i].Permissions = append(
+				mr.APIKey[i].Permissions, perm)
+		}
+		if err = permRows.Err(); err != nil {
+			permRows.Close()
+			mr.ServerError(err, q.Section)
+			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+			return
+		}
+		permRows.Close()
+	}
+
+	mr.OK()
+	mr.Super.Audit.WithField(`Code`, mr.Code).Infoln(`OK`)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,272 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package super // import "github.com/mjolnir42/soma/internal/super"
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	"github.com/mjolnir42/soma/lib/proto"
+	uuid "github.com/satori/go.uuid"
+)
+
+func (s *Supervisor) apiKeyWrite(q *msg.Request, mr *msg.Result) {
+	if s.readonly {
+		mr.ReadOnly()
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
+	if _, err := uuid.FromString(q.APIKey.UserID); err != nil {
+		mr.BadRequest(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return
+	}
+
+	switch q.Action {
+	case msg.ActionAdd:
+		s.apiKeyAdd(q, mr)
+	case msg.ActionRevoke:
+		s.apiKeyRevoke(q, mr)
+	}
+}
+
+func (s *Supervisor) apiKeyAdd(q *msg.Request, mr *msg.Result) {
+	var (
+		err                         error
+		tx                          *sql.Tx
+		res                         sql.Result
+		userName, key, permissionID string
+		isSystem, isActive          bool
+		now, expiresAt              time.Time
+	)
+	txMap := map[string]*sql.Stmt{}
+	permissions := []proto.Permission{}
+	seen := map[string]bool{}
+
+	if q.APIKey.Name == `` {
+		mr.BadRequest(fmt.Errorf(`API key name must not be empty`),
+			q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
+	// API keys can only be issued for active system users
+	if err = s.stmtAPIKeyUser.QueryRow(q.APIKey.UserID).Scan(
+		&userName,
+		&isSystem,
+		&isActive,
+	); err == sql.ErrNoRows {
+		mr.NotFound(fmt.Errorf("User %s not found", q.APIKey.UserID),
+			q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	} else if err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return
+	}
+	if !isSystem || !isActive {
+		mr.BadRequest(fmt.Errorf(
+			"API keys can only be issued for active system users,"+
+				" %s is not", userName), q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
+	// resolve the permissions the API key is restricted to
+	if len(q.APIKey.Permissions) == 0 {
+		mr.BadRequest(fmt.Errorf(
+			`API key must be restricted to at least one permission`),
+			q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+	for _, perm := range q.APIKey.Permissions {
+		var name string
+		if err = s.stmtPermissionSearch.QueryRow(
+			perm.Name,
+			perm.Category,
+		).Scan(
+			&permissionID,
+			&name,
+		); err == sql.ErrNoRows {
+			mr.NotFound(fmt.Errorf("Permission %s::%s not found",
+				perm.Category, perm.Name), q.Section)
+			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+			return
+		} else if err != nil {
+			mr.ServerError(err, q.Section)
+			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+			return
+		}
+		if seen[permissionID] {
+			continue
+		}
+		seen[permissionID] = true
+		permissions = append(permissions, proto.Permission{
+			ID:       permissionID,
+			Name:     name,
+			Category: perm.Category,
+		})
+	}
+
+	// API keys expire after at most apiKeyExpiry days
+	now = time.Now().UTC()
+	maxExpiry := now.Add(time.Duration(s.apiKeyExpiry) * 24 * time.Hour)
+	expiresAt = maxExpiry
+	if q.APIKey.ExpiresAt != `` {
+		if expiresAt, err = time.Parse(
+			time.RFC3339, q.APIKey.ExpiresAt,
+		); err != nil {
+			mr.BadRequest(err, q.Section)
+			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+			return
+		}
+		expiresAt = expiresAt.UTC()
+		if !expiresAt.After(now) || expiresAt.After(maxExpiry) {
+			mr.BadRequest(fmt.Errorf(
+				"API key expiry must be in the future and"+
+					" within %d days", s.apiKeyExpiry), q.Section)
+			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+			return
+		}
+	}
+
+	if key, err = newAPIKey(); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return
+	}
+	keyID := uuid.Must(uuid.NewV4()).String()
+	hash := hashAPIKey(key)
+
+	// open multi-statement transaction
+	if tx, err = s.conn.Begin(); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return
+	}
+
+	// prepare statements for this transaction
+	for name, statement := range map[string]string{
+		`apikey_add_tx_key`:  stmt.APIKeyAdd,
+		`apikey_add_tx_perm`: stmt.APIKeyPermissionAdd,
+	} {
+		if txMap[name], err = tx.Prepare(statement); err != nil {
+			err = fmt.Errorf("s.APIKeyTx.Prepare(%s) error: %s",
+				name, err.Error())
+			mr.ServerError(err, q.Section)
+			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+			tx.Rollback()
+			return
+		}
+	}
+
+	if res, err = txMap[`apikey_add_tx_key`].Exec(
+		keyID,
+		q.APIKey.UserID,
+		q.APIKey.Name,
+		hash,
+		now,
+		expiresAt,
+		q.AuthUser,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		tx.Rollback()
+		return
+	}
+	// sets r.OK()
+	if !mr.RowCnt(res.RowsAffected()) {
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		tx.Rollback()
+		return
+	}
+
+	scope := make([]string, 0, len(permissions))
+	for _, perm := range permissions {
+		if _, err = txMap[`apikey_add_tx_perm`].Exec(
+			keyID,
+			perm.ID,
+		); err != nil {
+			mr.ServerError(err, q.Section)
+			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+			tx.Rollback()
+			return
+		}
+		scope = append(scope, perm.ID)
+	}
+
+	// close transaction
+	if err = tx.Commit(); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return
+	}
+
+	s.apiKeys.insert(hash, apiKey{
+		id:        keyID,
+		userID:    q.APIKey.UserID,
+		userName:  userName,
+		validFrom: now,
+		expiresAt: expiresAt,
+		scope:     scope,
+	})
+
+	mr.APIKey = append(mr.APIKey, proto.APIKey{
+		ID:          keyID,
+		Name:        q.APIKey.Name,
+		UserID:      q.APIKey.UserID,
+		UserName:    userName,
+		Key:         key,
+		Permissions: permissions,
+		CreatedAt:   now.Format(msg.RFC3339Milli),
+		CreatedBy:   q.AuthUser,
+		ExpiresAt:   expiresAt.Format(msg.RFC3339Milli),
+	})
+	mr.Super.Audit.WithField(`Code`, mr.Code).
+		WithField(`APIKeyID`, keyID).
+		Infoln(fmt.Sprintf("Successfully issued API key %s for %s",
+			q.APIKey.Name, userName))
+}
+
+func (s *Supervisor) apiKeyRevoke(q *msg.Request, mr *msg.Result) {
+	var (
+		err error
+		res sql.Result
+	)
+
+	if res, err = s.stmtAPIKeyRevoke.Exec(
+		time.Now().UTC(),
+		q.AuthUser,
+		q.APIKey.ID,
+		q.APIKey.UserID,
+	); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return
+	}
+	// sets r.OK()
+	if !mr.RowCnt(res.RowsAffected()) {
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
+	s.apiKeys.remove(q.APIKey.ID)
+
+	mr.APIKey = append(mr.APIKey, q.APIKey)
+	mr.Super.Audit.WithField(`Code`, mr.Code).
+		WithField(`APIKeyID`, q.APIKey.ID).
+		Infoln(`Successfully revoked API key`)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
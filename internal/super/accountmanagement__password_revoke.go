@@ -75,6 +75,10 @@ func (s *Supervisor) passwordRevoke(q *msg.Request, mr *msg.Result) {
 		tx.Rollback()
 		return
 	}
+	if !s.credentialNotify(tx, q.Super.RevokeForName, mr) {
+		tx.Rollback()
+		return
+	}
 	s.credentials.revoke(q.Super.RevokeForName)
 
 	// commit transaction
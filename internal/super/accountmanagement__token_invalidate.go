@@ -24,6 +24,7 @@ func (s *Supervisor) tokenInvalidate(q *msg.Request, mr *msg.Result) {
 	var (
 		userID string
 		err    error
+		tx     *sql.Tx
 		res    sql.Result
 		cnt    int64
 	)
@@ -50,12 +51,21 @@ func (s *Supervisor) tokenInvalidate(q *msg.Request, mr *msg.Result) {
 	// revocation time for the token
 	expiredAt := time.Now().UTC()
 
+	// the revocation is propagated to all other instances as part
+	// of the same transaction
+	if tx, err = s.conn.Begin(); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
 	// update token in database
-	if res, err = s.conn.Exec(
+	if res, err = tx.Exec(
 		stmt.ExpireToken,
 		expiredAt,
 		q.Super.AuthToken,
 	); err != nil {
+		tx.Rollback()
 		mr.ServerError(err, q.Section)
 		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
 		return
@@ -63,6 +73,7 @@ func (s *Supervisor) tokenInvalidate(q *msg.Request, mr *msg.Result) {
 
 	// token row has unique constraint
 	if cnt, err = res.RowsAffected(); err != nil {
+		tx.Rollback()
 		mr.ServerError(err, q.Section)
 		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
 		return
@@ -73,6 +84,7 @@ func (s *Supervisor) tokenInvalidate(q *msg.Request, mr *msg.Result) {
 		// the token that was used to authenticate this request was
 		// not found in the database, the authentication system is
 		// corrupt. HARD crash.
+		tx.Rollback()
 		s.errLog.Errorln(`Supervisor corrupted, emergency crash. Check supervisor audit log`)
 		mr.Super.Audit.Fatalf("Supervisor corruption detected! "+
 			"Token %s used to authenticate this "+
@@ -82,6 +94,23 @@ func (s *Supervisor) tokenInvalidate(q *msg.Request, mr *msg.Result) {
 		)
 	}
 
+	// propagate the revocation to all other instances
+	if err = s.tokenNotify(tx, tokenEvent{
+		Event: tokenEventRevoke,
+		Token: q.Super.AuthToken,
+	}); err != nil {
+		tx.Rollback()
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
 	// remove the token from the in-memory map. the r/w master instance
 	// has the authoritative copy of all tokens in memory and does
 	// not load them from the database at runtime
@@ -101,6 +130,7 @@ func (s *Supervisor) tokenInvalidateAccount(q *msg.Request, mr *msg.Result) {
 	var (
 		userID string
 		err    error
+		tx     *sql.Tx
 		res    sql.Result
 		cnt    int64
 	)
@@ -145,12 +175,24 @@ func (s *Supervisor) tokenInvalidateAccount(q *msg.Request, mr *msg.Result) {
 		WithField(`RevokedUserName`, q.Super.RevokeForName).
 		WithField(`RevokedUserID`, q.Super.RevokeForID)
 
+	// revocation time for the account
+	revokedAt := time.Now().UTC()
+
+	// the revocation is propagated to all other instances as part
+	// of the same transaction
+	if tx, err = s.conn.Begin(); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
 	// insert revocation into database
-	if res, err = s.conn.Exec(
+	if res, err = tx.Exec(
 		stmt.RevokeTokensForUser,
 		q.Super.RevokeForID,
-		time.Now().UTC(),
+		revokedAt,
 	); err != nil {
+		tx.Rollback()
 		mr.ServerError(err, q.Section)
 		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
 		return
@@ -158,6 +200,7 @@ func (s *Supervisor) tokenInvalidateAccount(q *msg.Request, mr *msg.Result) {
 
 	// check how many rows were inserted
 	if cnt, err = res.RowsAffected(); err != nil {
+		tx.Rollback()
 		mr.ServerError(err, q.Section)
 		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
 		return
@@ -165,6 +208,7 @@ func (s *Supervisor) tokenInvalidateAccount(q *msg.Request, mr *msg.Result) {
 	switch cnt {
 	case 1:
 	default:
+		tx.Rollback()
 		mr.ServerError(fmt.Errorf(
 			"Revocation inserted %d rows, expected 1", cnt),
 			q.Section,
@@ -173,8 +217,26 @@ func (s *Supervisor) tokenInvalidateAccount(q *msg.Request, mr *msg.Result) {
 		return
 	}
 
+	// propagate the revocation to all other instances
+	if err = s.tokenNotify(tx, tokenEvent{
+		Event: tokenEventRevokeAccount,
+		User:  q.Super.RevokeForName,
+		At:    revokedAt.Format(msg.RFC3339Milli),
+	}); err != nil {
+		tx.Rollback()
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
 	// add revocation to in-memory map
-	s.tokens.expireAccount(q.Super.RevokeForName)
+	s.tokens.expireAccountAt(q.Super.RevokeForName, revokedAt)
 
 	mr.Super.Verdict = 200
 	mr.OK()
@@ -189,6 +251,7 @@ func (s *Supervisor) tokenInvalidateGlobal(q *msg.Request, mr *msg.Result) {
 	var (
 		userID string
 		err    error
+		tx     *sql.Tx
 		res    sql.Result
 		cnt    int64
 	)
@@ -219,13 +282,23 @@ func (s *Supervisor) tokenInvalidateGlobal(q *msg.Request, mr *msg.Result) {
 	s.tokens.lock()
 	defer s.tokens.unlock()
 
+	// the revocation is propagated to all other instances as part
+	// of the same transaction
+	if tx, err = s.conn.Begin(); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
+	revoked := []string{}
 	for tokenID := range s.tokens.iterateStringUnlocked(revokeAt) {
 		// update token in database
-		if res, err = s.conn.Exec(
+		if res, err = tx.Exec(
 			stmt.ExpireToken,
 			revokeAt,
 			tokenID,
 		); err != nil {
+			tx.Rollback()
 			mr.ServerError(err, q.Section)
 			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
 			return
@@ -233,6 +306,7 @@ func (s *Supervisor) tokenInvalidateGlobal(q *msg.Request, mr *msg.Result) {
 
 		// token row has unique constraint
 		if cnt, err = res.RowsAffected(); err != nil {
+			tx.Rollback()
 			mr.ServerError(err, q.Section)
 			mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
 			return
@@ -242,6 +316,7 @@ func (s *Supervisor) tokenInvalidateGlobal(q *msg.Request, mr *msg.Result) {
 		default:
 			// the token to invalidate was not found in the database,
 			// the authentication system is corrupt. HARD crash.
+			tx.Rollback()
 			s.errLog.Errorln(`Supervisor corrupted, emergency crash. Check supervisor audit log`)
 			mr.Super.Audit.Fatalf("Supervisor corruption detected! "+
 				"Token %s to be invalidated was found in the database %d times!",
@@ -249,10 +324,30 @@ func (s *Supervisor) tokenInvalidateGlobal(q *msg.Request, mr *msg.Result) {
 				cnt,
 			)
 		}
+		revoked = append(revoked, tokenID)
+	}
+
+	// propagate the revocation to all other instances
+	if err = s.tokenNotify(tx, tokenEvent{
+		Event: tokenEventRevokeGlobal,
+		At:    revokeAt.Format(msg.RFC3339Milli),
+	}); err != nil {
+		tx.Rollback()
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(mr.Error)
+		return
+	}
 
-		// remove the token from the in-memory map. the r/w master instance
-		// has the authoritative copy of all tokens in memory and does
-		// not load them from the database at runtime
+	// remove the tokens from the in-memory map. the r/w master
+	// instance has the authoritative copy of all tokens in memory and
+	// does not load them from the database at runtime
+	for _, tokenID := range revoked {
 		s.tokens.removeUnlocked(tokenID)
 	}
 
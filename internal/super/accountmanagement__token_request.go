@@ -103,6 +103,17 @@ func (s *Supervisor) tokenRequest(q *msg.Request, mr *msg.Result) {
 		return
 	}
 
+	// announce the new token to all other instances once the
+	// transaction commits
+	if err = s.tokenNotify(tx, tokenEvent{
+		Event: tokenEventIssue,
+		Token: token.Token,
+	}); err != nil {
+		mr.ServerError(err, q.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return
+	}
+
 	// store token in inmemory token map while db transaction is still
 	// open
 	if err = s.tokens.insert(token.Token, token.ValidFrom, token.ExpiresAt,
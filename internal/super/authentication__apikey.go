@@ -0,0 +1,162 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package super // import "github.com/mjolnir42/soma/internal/super"
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mjolnir42/soma/internal/msg"
+)
+
+// IMPORTANT!
+//
+// differentiated error returns are for logging purposes only. Failed
+// Authentication is returned to the client as 401/Unauthorized.
+
+// authenticateAPIKey performs BasicAuth authentication with an API
+// key as password
+func (s *Supervisor) authenticateAPIKey(q *msg.Request, mr *msg.Result) {
+	hash := hashAPIKey(q.Super.BasicAuth.Token)
+
+	// the rw instance knows every active API key. readonly instances
+	// always reload the API key from the database, since they are not
+	// informed about revocations
+	var key *apiKey
+	switch {
+	case !s.readonly:
+		if key = s.apiKeys.read(hash); key == nil {
+			mr.NotFound(fmt.Errorf(
+				`Unknown API key: not found in in-memory APIKeyMap`))
+			mr.Super.Audit.
+				WithField(`Code`, mr.Code).
+				Warningln(mr.Error)
+			return
+		}
+	default:
+		if !s.fetchAPIKeyFromDB(hash) {
+			mr.NotFound(fmt.Errorf(
+				`Unknown API key: not found in pgSQL database`))
+			mr.Super.Audit.
+				WithField(`Code`, mr.Code).
+				Warningln(mr.Error)
+			return
+		}
+		key = s.apiKeys.read(hash)
+	}
+
+	// API keys can only be used by the account they were issued to
+	if key.userName != q.Super.BasicAuth.User {
+		mr.Unauthorized(fmt.Errorf(
+			`Authentication failed, API key used by wrong account`))
+		mr.Super.Audit.
+			WithField(`Code`, mr.Code).
+			WithField(`APIKeyID`, key.id).
+			Warningln(mr.Error)
+		return
+	}
+
+	now := time.Now().UTC()
+	if now.Before(key.validFrom.UTC()) || now.After(key.expiresAt.UTC()) {
+		mr.Unauthorized(fmt.Errorf(
+			`Authentication failed, API key invalid: ` +
+				`expired or not valid yet`))
+		mr.Super.Audit.
+			WithField(`Code`, mr.Code).
+			WithField(`APIKeyID`, key.id).
+			Warningln(mr.Error)
+		return
+	}
+
+	// record the use of the API key, the database is only updated
+	// once per apiKeyPersistInterval
+	if s.apiKeys.touch(hash, now) && !s.readonly {
+		if _, err := s.stmtAPIKeyUsed.Exec(
+			now,
+			key.id,
+		); err != nil {
+			s.errLog.WithField(`Function`, `authenticateAPIKey`).
+				Errorln(err)
+		}
+	}
+
+	// the provided API key was valid
+	mr.OK()
+	mr.Super.Verdict = 200
+	mr.Super.APIKeyID = key.id
+	mr.Super.Audit.
+		WithField(`Code`, mr.Code).
+		WithField(`Verdict`, mr.Super.Verdict).
+		WithField(`APIKeyID`, key.id).
+		Infoln(`Authentication OK`)
+}
+
+// fetchAPIKeyFromDB loads the active API key with the hash from the
+// database into the in-memory map
+func (s *Supervisor) fetchAPIKeyFromDB(hash string) bool {
+	var (
+		err                     error
+		keyID, userID, userName string
+		permissionID            string
+		validFrom, expiresAt    time.Time
+	)
+
+	if err = s.stmtAPIKeySelect.QueryRow(hash).Scan(
+		&keyID,
+		&userID,
+		&userName,
+		&validFrom,
+		&expiresAt,
+	); err != nil {
+		if err != sql.ErrNoRows {
+			s.errLog.WithField(`Function`, `fetchAPIKeyFromDB`).
+				Errorln(err)
+		}
+		return false
+	}
+
+	key := apiKey{
+		id:        keyID,
+		userID:    userID,
+		userName:  userName,
+		validFrom: validFrom.UTC(),
+		expiresAt: expiresAt.UTC(),
+		scope:     []string{},
+	}
+	// keep the last use of an already known API key
+	if known := s.apiKeys.read(hash); known != nil {
+		key.lastUsedAt = known.lastUsedAt
+		key.lastSaved = known.lastSaved
+	}
+
+	rows, err := s.stmtAPIKeyScope.Query(keyID)
+	if err != nil {
+		s.errLog.WithField(`Function`, `fetchAPIKeyFromDB`).Errorln(err)
+		return false
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&permissionID); err != nil {
+			s.errLog.WithField(`Function`, `fetchAPIKeyFromDB`).
+				Errorln(err)
+			return false
+		}
+		key.scope = append(key.scope, permissionID)
+	}
+	if err = rows.Err(); err != nil {
+		s.errLog.WithField(`Function`, `fetchAPIKeyFromDB`).Errorln(err)
+		return false
+	}
+
+	s.apiKeys.insert(hash, key)
+	return true
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -45,6 +45,12 @@ func (s *Supervisor) authenticateBasicAuth(q *msg.Request, mr *msg.Result) {
 		return
 	}
 
+	// API keys are used instead of a token
+	if isAPIKey(q.Super.BasicAuth.Token) {
+		s.authenticateAPIKey(q, mr)
+		return
+	}
+
 	// unknown or incorrect provided hmac authentication token will
 	// fail here, since it will neither be found within the in-memory
 	// map or the database
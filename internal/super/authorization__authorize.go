@@ -71,6 +71,24 @@ func IsAuthorized(q *msg.Request) bool {
 // authorize forwards the request to the permission cache for
 // assessment
 func (s *Supervisor) authorize(q *msg.Request) {
+	// requests authenticated with an API key are restricted to the
+	// permissions of the API key
+	if q.Super.Authorize.AuthAPIKey != `` {
+		key := s.apiKeys.readByID(q.Super.Authorize.AuthAPIKey)
+		if key == nil || key.isExpired() ||
+			key.userName != q.Super.Authorize.AuthUser {
+			result := msg.FromRequest(q)
+			result.Super.Verdict = 403
+			result.Super.Audit = result.Super.Audit.
+				WithField(`APIKeyID`, q.Super.Authorize.AuthAPIKey).
+				WithField(`permCache::error`, `APIKeyInvalid`)
+			q.Reply <- result
+			return
+		}
+		q.Super.APIKeyID = key.id
+		q.Super.Scope = key.scope
+		q.Super.Audit = q.Super.Audit.WithField(`APIKeyID`, key.id)
+	}
 	q.Reply <- s.permCache.IsAuthorized(q)
 }
 
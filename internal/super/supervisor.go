@@ -19,6 +19,7 @@ import (
 	"github.com/mjolnir42/soma/internal/perm"
 	"github.com/mjolnir42/soma/internal/stmt"
 	"github.com/mjolnir42/soma/lib/auth"
+	uuid "github.com/satori/go.uuid"
 )
 
 var (
@@ -36,17 +37,27 @@ type Supervisor struct {
 	seed                              []byte
 	key                               []byte
 	readonly                          bool
+	instanceID                        string
 	tokenExpiry                       uint64
 	kexExpiry                         uint64
 	credExpiry                        uint64
+	apiKeyExpiry                      uint64
 	activation                        string
 	rootDisabled                      bool
 	rootRestricted                    bool
 	kex                               *kexMap
 	tokens                            *tokenMap
+	apiKeys                           *apiKeyMap
 	credentials                       *credentialMap
 	permCache                         *perm.Cache
 	stmtTokenSelect                   *sql.Stmt
+	stmtAPIKeySelect                  *sql.Stmt
+	stmtAPIKeyScope                   *sql.Stmt
+	stmtAPIKeyUsed                    *sql.Stmt
+	stmtAPIKeyUser                    *sql.Stmt
+	stmtAPIKeyList                    *sql.Stmt
+	stmtAPIKeyPermissionList          *sql.Stmt
+	stmtAPIKeyRevoke                  *sql.Stmt
 	stmtFindUserID                    *sql.Stmt
 	stmtFindAdminID                   *sql.Stmt
 	stmtFindUserName                  *sql.Stmt
@@ -108,6 +119,7 @@ func New(c *config.Config) *Supervisor {
 	s.Update = make(chan msg.Request, s.conf.QueueLen)
 	s.Shutdown = make(chan struct{})
 	s.readonly = s.conf.ReadOnly
+	s.instanceID = uuid.Must(uuid.NewV4()).String()
 	if s.seed, err = hex.DecodeString(
 		s.conf.Auth.TokenSeed,
 	); err != nil {
@@ -127,6 +139,7 @@ func New(c *config.Config) *Supervisor {
 	s.tokenExpiry = s.conf.Auth.TokenExpirySeconds
 	s.kexExpiry = s.conf.Auth.KexExpirySeconds
 	s.credExpiry = s.conf.Auth.CredentialExpiryDays
+	s.apiKeyExpiry = s.conf.Auth.APIKeyExpiryDays
 	s.activation = s.conf.Auth.Activation
 
 	// set package variable config for functions
@@ -167,6 +180,9 @@ func (s *Supervisor) RegisterRequests(hmap *handler.Map) {
 	hmap.Request(msg.SectionPermission, msg.ActionRemove, `supervisor`)
 	hmap.Request(msg.SectionPermission, msg.ActionMap, `supervisor`)
 	hmap.Request(msg.SectionPermission, msg.ActionUnmap, `supervisor`)
+	hmap.Request(msg.SectionAPIKeyMgmt, msg.ActionAdd, `supervisor`)
+	hmap.Request(msg.SectionAPIKeyMgmt, msg.ActionList, `supervisor`)
+	hmap.Request(msg.SectionAPIKeyMgmt, msg.ActionRevoke, `supervisor`)
 	hmap.Request(msg.SectionRight, msg.ActionList, `supervisor`)
 	hmap.Request(msg.SectionRight, msg.ActionShow, `supervisor`)
 	hmap.Request(msg.SectionRight, msg.ActionGrant, `supervisor`)
@@ -210,6 +226,7 @@ func (s *Supervisor) Run() {
 
 	// initialize maps
 	s.tokens = newTokenMap()
+	s.apiKeys = newAPIKeyMap()
 	s.credentials = newCredentialMap()
 	s.kex = newKexMap()
 
@@ -220,6 +237,9 @@ func (s *Supervisor) Run() {
 	s.startupLoad()
 
 	for statement, prepStmt := range map[string]**sql.Stmt{
+		stmt.APIKeyList:                    &s.stmtAPIKeyList,
+		stmt.APIKeyPermissionList:          &s.stmtAPIKeyPermissionList,
+		stmt.APIKeyUser:                    &s.stmtAPIKeyUser,
 		stmt.CategoryList:                  &s.stmtCategoryList,
 		stmt.CategoryShow:                  &s.stmtCategoryShow,
 		stmt.FindAdminID:                   &s.stmtFindAdminID,
@@ -229,6 +249,8 @@ func (s *Supervisor) Run() {
 		stmt.PermissionSearchByName:        &s.stmtPermissionSearch,
 		stmt.SectionList:                   &s.stmtSectionList,
 		stmt.SelectToken:                   &s.stmtTokenSelect,
+		stmt.SelectAPIKey:                  &s.stmtAPIKeySelect,
+		stmt.SelectAPIKeyScope:             &s.stmtAPIKeyScope,
 		stmt.SectionShow:                   &s.stmtSectionShow,
 		stmt.SectionSearch:                 &s.stmtSectionSearch,
 		stmt.ActionList:                    &s.stmtActionList,
@@ -255,6 +277,8 @@ func (s *Supervisor) Run() {
 
 	if !s.readonly {
 		for statement, prepStmt := range map[string]**sql.Stmt{
+			stmt.APIKeyRevoke:                  &s.stmtAPIKeyRevoke,
+			stmt.APIKeyUsed:                    &s.stmtAPIKeyUsed,
 			stmt.CheckUserActive:               &s.stmtCheckUserActive,
 			stmt.CheckAdminActive:              &s.stmtCheckAdminActive,
 			stmt.SectionAdd:                    &s.stmtSectionAdd,
@@ -277,6 +301,9 @@ func (s *Supervisor) Run() {
 		}
 	}
 
+	// receive token events from other instances
+	s.tokenListen()
+
 	// start 5-min garbage collection timer
 	gc := time.NewTicker(5 * time.Minute)
 
@@ -336,6 +363,8 @@ func (s *Supervisor) process(q *msg.Request) {
 		case msg.ActionGC:
 			s.gc()
 		}
+	case msg.SectionAPIKeyMgmt:
+		s.apiKey(q)
 	case msg.SectionCategory:
 		s.category(q)
 	case msg.SectionPermission:
@@ -0,0 +1,206 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package super // import "github.com/mjolnir42/soma/internal/super"
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiKeyPrefix marks BasicAuth passwords as API keys. Regular
+// tokens are hex strings and can never contain it.
+const apiKeyPrefix = `apikey_`
+
+// apiKeyPersistInterval is the minimum interval in which the last
+// use of an API key is written to the database
+const apiKeyPersistInterval = time.Minute
+
+// apiKey is the internal storage format for API keys
+type apiKey struct {
+	id         string
+	userID     string
+	userName   string
+	validFrom  time.Time
+	expiresAt  time.Time
+	lastUsedAt time.Time
+	lastSaved  time.Time
+	// IDs of the permissions the API key is restricted to
+	scope  []string
+	gcMark bool
+}
+
+// isExpired returns if an API key is expired
+func (k *apiKey) isExpired() bool {
+	return time.Now().UTC().After(k.expiresAt.UTC())
+}
+
+// isAPIKey returns true if s has the format of an API key
+func isAPIKey(s string) bool {
+	return strings.HasPrefix(s, apiKeyPrefix)
+}
+
+// newAPIKey returns a new random API key
+func newAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return ``, err
+	}
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// hashAPIKey returns the hash of key as it is stored in the
+// database. API keys are 256 bit random values and do not require
+// a slow password hash.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyMap is a read/write locked map of API keys
+type apiKeyMap struct {
+	// hash(hex.string) -> apiKey
+	KMap map[string]apiKey
+	// apiKey.id -> hash(hex.string)
+	IMap  map[string]string
+	mutex sync.RWMutex
+}
+
+// newAPIKeyMap returns a new apiKeyMap
+func newAPIKeyMap() *apiKeyMap {
+	m := apiKeyMap{}
+	m.KMap = make(map[string]apiKey)
+	m.IMap = make(map[string]string)
+	return &m
+}
+
+// Map manipulation
+
+// read returns a copy of the API key with the requested hash
+func (t *apiKeyMap) read(hash string) *apiKey {
+	t.rlock()
+	defer t.runlock()
+	if key, ok := t.KMap[hash]; ok {
+		return &key
+	}
+	return nil
+}
+
+// readByID returns a copy of the API key with the requested ID
+func (t *apiKeyMap) readByID(id string) *apiKey {
+	t.rlock()
+	defer t.runlock()
+	if key, ok := t.KMap[t.IMap[id]]; ok {
+		return &key
+	}
+	return nil
+}
+
+// insert adds a new API key to the apiKeyMap
+func (t *apiKeyMap) insert(hash string, key apiKey) {
+	// acquire write lock
+	t.lock()
+	defer t.unlock()
+
+	t.KMap[hash] = key
+	t.IMap[key.id] = hash
+}
+
+// remove deletes an API key by ID from the apiKeyMap
+func (t *apiKeyMap) remove(id string) {
+	// acquire write lock
+	t.lock()
+	defer t.unlock()
+
+	delete(t.KMap, t.IMap[id])
+	delete(t.IMap, id)
+}
+
+// touch records the use of an API key at time at. It returns true
+// if the last use should be written to the database
+func (t *apiKeyMap) touch(hash string, at time.Time) bool {
+	// acquire write lock
+	t.lock()
+	defer t.unlock()
+
+	key, ok := t.KMap[hash]
+	if !ok {
+		return false
+	}
+	key.lastUsedAt = at
+	persist := at.Sub(key.lastSaved) >= apiKeyPersistInterval
+	if persist {
+		key.lastSaved = at
+	}
+	t.KMap[hash] = key
+	return persist
+}
+
+// Garbage collection bulk functions with external locking
+
+// iterateExpiredUnlocked returns the hashes of all expired API keys in a
+// channel without acquiring the mutex lock. Locking must be done
+// externally.
+func (t *apiKeyMap) iterateExpiredUnlocked() chan string {
+	ret := make(chan string, len(t.KMap)+1)
+	defer close(ret)
+
+	for hash := range t.KMap {
+		key := t.KMap[hash]
+		if key.isExpired() {
+			ret <- hash
+		}
+	}
+	return ret
+}
+
+// markUnlocked sets the garbage collection mark on an API key
+// without acquiring the mutex lock. Locking must be done externally.
+func (t *apiKeyMap) markUnlocked(hash string) {
+	key := t.KMap[hash]
+	key.gcMark = true
+	t.KMap[hash] = key
+}
+
+// sweepUnlocked deletes all API keys marked for garbage collection
+// without acquiring the mutex lock. Locking must be done externally.
+func (t *apiKeyMap) sweepUnlocked() {
+	for hash := range t.KMap {
+		if t.KMap[hash].gcMark {
+			delete(t.IMap, t.KMap[hash].id)
+			delete(t.KMap, hash)
+		}
+	}
+}
+
+// Locking
+
+// lock acquires the writelock on apiKeyMap t
+func (t *apiKeyMap) lock() {
+	t.mutex.Lock()
+}
+
+// rlock acquires the readlock on apiKeyMap t
+func (t *apiKeyMap) rlock() {
+	t.mutex.RLock()
+}
+
+// unlock releases the writelock on apiKeyMap t
+func (t *apiKeyMap) unlock() {
+	t.mutex.Unlock()
+}
+
+// runlock releases the readlock on apiKeyMap t
+func (t *apiKeyMap) runlock() {
+	t.mutex.RUnlock()
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -24,6 +24,11 @@ func (s *Supervisor) gc() {
 	s.tokens.lock()
 	defer s.tokens.unlock()
 
+	// lock API key map
+	s.appLog.Debug(`Supervisor.GC locking API key map`)
+	s.apiKeys.lock()
+	defer s.apiKeys.unlock()
+
 	// lock credentials map
 	s.appLog.Debug(`Supervisor.GC locking credential map`)
 	s.credentials.lock()
@@ -48,7 +53,7 @@ func (s *Supervisor) gc() {
 // garbage collection cycle.
 func (s *Supervisor) gcMarkForNext() {
 	wg := sync.WaitGroup{}
-	wg.Add(3)
+	wg.Add(4)
 
 	// key exchanges
 	go func() {
@@ -66,6 +71,14 @@ func (s *Supervisor) gcMarkForNext() {
 		s.appLog.Debug(`Supervisor.GC: s.gcMarkTokens()::end`)
 	}()
 
+	// API keys
+	go func() {
+		s.appLog.Debug(`Supervisor.GC: s.gcMarkAPIKeys()::start`)
+		defer wg.Done()
+		s.gcMarkAPIKeys()
+		s.appLog.Debug(`Supervisor.GC: s.gcMarkAPIKeys()::end`)
+	}()
+
 	// credentials
 	go func() {
 		s.appLog.Debug(`Supervisor.GC: s.gcMarkCredentials()::start`)
@@ -96,6 +109,14 @@ func (s *Supervisor) gcMarkTokens() {
 	}
 }
 
+// gcMarkAPIKeys iterates over stored API keys and marks expired ones
+// for garbage collection
+func (s *Supervisor) gcMarkAPIKeys() {
+	for hash := range s.apiKeys.iterateExpiredUnlocked() {
+		s.apiKeys.markUnlocked(hash)
+	}
+}
+
 func (s *Supervisor) gcMarkCredentials() {
 	for credential := range s.credentials.iterateUnlocked() {
 		if credential.isExpired() {
@@ -107,7 +128,7 @@ func (s *Supervisor) gcMarkCredentials() {
 // gcSweep removes data marked for garbage collection
 func (s *Supervisor) gcSweep() {
 	wg := sync.WaitGroup{}
-	wg.Add(3)
+	wg.Add(4)
 
 	// sweep key exchanges marked for garbage collection
 	go func() {
@@ -125,6 +146,14 @@ func (s *Supervisor) gcSweep() {
 		s.appLog.Debug(`Supervisor.GC: s.tokens.sweepUnlocked()::end`)
 	}()
 
+	// sweep API keys marked for garbage collection
+	go func() {
+		s.appLog.Debug(`Supervisor.GC: s.apiKeys.sweepUnlocked()::start`)
+		defer wg.Done()
+		s.apiKeys.sweepUnlocked()
+		s.appLog.Debug(`Supervisor.GC: s.apiKeys.sweepUnlocked()::end`)
+	}()
+
 	// sweep credentials marked for garbage collection
 	go func() {
 		s.appLog.Debug(`Supervisor.GC: s.credentials.sweepUnlocked()::start`)
@@ -282,6 +282,9 @@ func (s *Supervisor) saveCred(tx *sql.Tx, user, subject string, userUUID uuid.UU
 	) {
 		return false
 	}
+	if !s.credentialNotify(tx, user, mr) {
+		return false
+	}
 	s.credentials.insert(
 		user,
 		userUUID,
@@ -381,6 +384,9 @@ func (s *Supervisor) saveAdminCred(tx *sql.Tx, user, subject string, userUUID uu
 	) {
 		return false
 	}
+	if !s.credentialNotify(tx, user, mr) {
+		return false
+	}
 	s.credentials.insert(
 		user,
 		userUUID,
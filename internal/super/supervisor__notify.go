@@ -0,0 +1,264 @@
+/*-
+ * Copyright (c) 2017, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package super // import "github.com/mjolnir42/soma/internal/super"
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mjolnir42/scrypth64"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	uuid "github.com/satori/go.uuid"
+)
+
+// tokenChannel is the PostgreSQL notification channel used to
+// propagate token and credential events between somad instances.
+//
+// Key exchanges are not propagated. A key exchange holds the private
+// key of the instance for exactly one client request and is never
+// persisted, while notification payloads are readable by every
+// session of the database user. Clients must complete a key exchange
+// on the instance that started it.
+const tokenChannel = `soma_token_events`
+
+// token events exchanged between instances
+const (
+	tokenEventIssue         = `issue`
+	tokenEventRevoke        = `revoke`
+	tokenEventRevokeAccount = `revoke-account`
+	tokenEventRevokeGlobal  = `revoke-global`
+	tokenEventCredential    = `credential`
+)
+
+// tokenEvent is the notification payload of a token event
+type tokenEvent struct {
+	Origin string `json:"origin"`
+	Event  string `json:"event"`
+	Token  string `json:"token,omitempty"`
+	User   string `json:"user,omitempty"`
+	At     string `json:"at,omitempty"`
+}
+
+// execer is implemented by *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// tokenNotify publishes a token event to all instances. If ex is a
+// transaction, the event is delivered when it commits.
+func (s *Supervisor) tokenNotify(ex execer, ev tokenEvent) error {
+	ev.Origin = s.instanceID
+	payload, err := json.Marshal(&ev)
+	if err != nil {
+		return err
+	}
+	_, err = ex.Exec(stmt.NotifyToken, tokenChannel, string(payload))
+	return err
+}
+
+// credentialNotify publishes that the credentials of user changed
+// as part of transaction tx. Root credentials are only loaded by the
+// r/w master instance and are not propagated.
+func (s *Supervisor) credentialNotify(tx *sql.Tx, user string,
+	mr *msg.Result) bool {
+	if user == msg.SubjectRoot {
+		return true
+	}
+	if err := s.tokenNotify(tx, tokenEvent{
+		Event: tokenEventCredential,
+		User:  user,
+	}); err != nil {
+		mr.ServerError(err, mr.Section)
+		mr.Super.Audit.WithField(`Code`, mr.Code).Warningln(err)
+		return false
+	}
+	return true
+}
+
+// tokenListen subscribes to token events published by other
+// instances and applies them to the in-memory token map until the
+// supervisor is shut down
+func (s *Supervisor) tokenListen() {
+	connect := fmt.Sprintf("dbname='%s' user='%s' password='%s' host='%s' port='%s' sslmode='%s' connect_timeout='%s'",
+		s.conf.Database.Name,
+		s.conf.Database.User,
+		s.conf.Database.Pass,
+		s.conf.Database.Host,
+		s.conf.Database.Port,
+		s.conf.Database.TLSMode,
+		s.conf.Database.Timeout,
+	)
+
+	listener := pq.NewListener(connect, 5*time.Second, time.Minute,
+		func(event pq.ListenerEventType, err error) {
+			switch event {
+			case pq.ListenerEventDisconnected:
+				s.errLog.WithField(`Function`, `tokenListen`).
+					Errorln(`Lost token event connection:`, err)
+			case pq.ListenerEventConnectionAttemptFailed:
+				s.errLog.WithField(`Function`, `tokenListen`).
+					Errorln(`Token event reconnect failed:`, err)
+			case pq.ListenerEventReconnected:
+				s.appLog.Infoln(`Supervisor re-established token event connection`)
+			}
+		})
+	if err := listener.Listen(tokenChannel); err != nil {
+		s.errLog.Fatal(`supervisor/token-listen: `, err)
+	}
+	s.appLog.Infof("Supervisor listening for token events on %s",
+		tokenChannel)
+
+	go func() {
+		ping := time.NewTicker(90 * time.Second)
+		defer ping.Stop()
+		defer listener.Close()
+
+		for {
+			select {
+			case <-s.Shutdown:
+				return
+			case n := <-listener.Notify:
+				if n == nil {
+					// the listener sends nil after a reconnect,
+					// events may have been lost in between
+					s.tokenResync()
+					continue
+				}
+				s.tokenEventApply(n.Extra)
+			case <-ping.C:
+				go listener.Ping()
+			}
+		}
+	}()
+}
+
+// tokenEventApply updates the in-memory token map from a received
+// token event
+func (s *Supervisor) tokenEventApply(payload string) {
+	var (
+		err error
+		ev  tokenEvent
+		at  time.Time
+	)
+
+	if err = json.Unmarshal([]byte(payload), &ev); err != nil {
+		s.errLog.WithField(`Function`, `tokenEventApply`).Errorln(err)
+		return
+	}
+	// events published by this instance are already applied
+	if ev.Origin == s.instanceID {
+		return
+	}
+	if ev.At != `` {
+		if at, err = time.Parse(msg.RFC3339Milli, ev.At); err != nil {
+			s.errLog.WithField(`Function`, `tokenEventApply`).Errorln(err)
+			return
+		}
+	}
+
+	switch ev.Event {
+	case tokenEventIssue:
+		if s.tokens.read(ev.Token) != nil {
+			return
+		}
+		if !s.fetchTokenFromDB(ev.Token) {
+			s.errLog.WithField(`Function`, `tokenEventApply`).
+				Errorln(`Issued token not found in database`)
+		}
+	case tokenEventRevoke:
+		s.tokens.remove(ev.Token)
+	case tokenEventRevokeAccount:
+		s.tokens.expireAccountAt(ev.User, at)
+	case tokenEventRevokeGlobal:
+		s.tokens.lock()
+		for tokenID := range s.tokens.iterateStringUnlocked(at) {
+			s.tokens.removeUnlocked(tokenID)
+		}
+		s.tokens.unlock()
+	case tokenEventCredential:
+		if err = s.credentialReload(ev.User); err != nil {
+			s.errLog.WithField(`Function`, `tokenEventApply`).Errorln(err)
+			return
+		}
+	default:
+		s.errLog.WithField(`Function`, `tokenEventApply`).
+			Errorf("Unknown token event: %s", ev.Event)
+		return
+	}
+	s.appLog.Debugf("Supervisor applied token event %s from %s",
+		ev.Event, ev.Origin)
+}
+
+// credentialReload replaces the in-memory credentials of user with
+// the current credentials from the database. Credentials of a user
+// that has none left are revoked.
+func (s *Supervisor) credentialReload(user string) error {
+	var (
+		err                  error
+		id, crypt            string
+		reset                bool
+		validFrom, expiresAt time.Time
+		uid                  uuid.UUID
+		mcf                  scrypth64.Mcf
+	)
+
+	query := stmt.LoadUserCredential
+	if strings.HasPrefix(user, `admin_`) {
+		query = stmt.LoadAdminCredential
+	}
+
+	if err = s.conn.QueryRow(query, user).Scan(
+		&id,
+		&crypt,
+		&reset,
+		&validFrom,
+		&expiresAt,
+	); err == sql.ErrNoRows {
+		if s.credentials.read(user) != nil {
+			s.credentials.revoke(user)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if uid, err = uuid.FromString(id); err != nil {
+		return err
+	}
+	if mcf, err = scrypth64.FromString(crypt); err != nil {
+		return err
+	}
+	s.credentials.restore(user, uid, validFrom, expiresAt, mcf, reset, true)
+	return nil
+}
+
+// tokenResync reloads all tokens and account revocations from the
+// database, since events may have been missed while the notification
+// connection was down
+func (s *Supervisor) tokenResync() {
+	fresh := newTokenMap()
+	since := time.Now().UTC()
+
+	if err := s.loadTokens(fresh); err != nil {
+		s.errLog.WithField(`Function`, `tokenResync`).Errorln(err)
+		return
+	}
+	if err := s.loadTokenRevocations(fresh); err != nil {
+		s.errLog.WithField(`Function`, `tokenResync`).Errorln(err)
+		return
+	}
+	s.tokens.replace(fresh, since)
+	s.appLog.Infoln(`Supervisor resynchronized token map from database`)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,229 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package super
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/scrypth64"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/internal/stmt"
+	uuid "github.com/satori/go.uuid"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+// notifyRecorder records the notifications published via Exec
+type notifyRecorder struct {
+	queries  []string
+	payloads []string
+}
+
+func (n *notifyRecorder) Exec(query string, args ...interface{}) (
+	sql.Result, error) {
+	n.queries = append(n.queries, query)
+	if len(args) == 2 && args[0] == tokenChannel {
+		n.payloads = append(n.payloads, args[1].(string))
+	}
+	return sqlmock.NewResult(0, 1), nil
+}
+
+func testSupervisor(instanceID string) *Supervisor {
+	discard := logrus.New()
+	discard.Out = ioutil.Discard
+	return &Supervisor{
+		instanceID:  instanceID,
+		tokens:      newTokenMap(),
+		credentials: newCredentialMap(),
+		appLog:      discard,
+		errLog:      discard,
+	}
+}
+
+func TestTokenNotifyPayload(t *testing.T) {
+	s := testSupervisor(`instance-a`)
+	rec := &notifyRecorder{}
+	at := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, ev := range []tokenEvent{
+		{Event: tokenEventIssue, Token: `abcd`},
+		{Event: tokenEventRevoke, Token: `abcd`},
+		{Event: tokenEventRevokeAccount, User: `alice`,
+			At: at.Format(msg.RFC3339Milli)},
+		{Event: tokenEventRevokeGlobal, At: at.Format(msg.RFC3339Milli)},
+		{Event: tokenEventCredential, User: `admin_alice`},
+	} {
+		// the origin is always set to the publishing instance
+		ev.Origin = `forged`
+		if err := s.tokenNotify(rec, ev); err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := tokenEvent{}
+		if err := json.Unmarshal(
+			[]byte(rec.payloads[len(rec.payloads)-1]),
+			&decoded,
+		); err != nil {
+			t.Fatal(err)
+		}
+		ev.Origin = `instance-a`
+		if decoded != ev {
+			t.Errorf("Payload decoded to %+v, expected %+v", decoded, ev)
+		}
+	}
+
+	for _, query := range rec.queries {
+		if query != stmt.NotifyToken {
+			t.Errorf("Unexpected notification statement: %s", query)
+		}
+	}
+	if len(rec.payloads) != 5 {
+		t.Errorf("Expected 5 notifications, got %d", len(rec.payloads))
+	}
+}
+
+func TestTokenEventApply(t *testing.T) {
+	publisher := testSupervisor(`instance-a`)
+	receiver := testSupervisor(`instance-b`)
+	rec := &notifyRecorder{}
+
+	valid := time.Now().UTC().Add(-time.Minute)
+	expires := valid.Add(time.Hour)
+	for _, tok := range []string{`aa01`, `aa02`, `aa03`} {
+		if err := receiver.tokens.insert(
+			tok,
+			valid.Format(msg.RFC3339Milli),
+			expires.Format(msg.RFC3339Milli),
+			`5a17`,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publish := func(ev tokenEvent) string {
+		if err := publisher.tokenNotify(rec, ev); err != nil {
+			t.Fatal(err)
+		}
+		return rec.payloads[len(rec.payloads)-1]
+	}
+
+	// malformed payloads and unknown events are ignored
+	receiver.tokenEventApply(`{"event":`)
+	receiver.tokenEventApply(publish(tokenEvent{Event: `unknown`}))
+	if len(receiver.tokens.TMap) != 3 {
+		t.Fatalf("Ignored events modified the token map")
+	}
+
+	// events published by the receiving instance are already applied
+	payload := publish(tokenEvent{Event: tokenEventRevoke, Token: `aa01`})
+	receiver.instanceID = `instance-a`
+	receiver.tokenEventApply(payload)
+	if receiver.tokens.read(`aa01`) == nil {
+		t.Errorf("Own token event was applied twice")
+	}
+	receiver.instanceID = `instance-b`
+
+	receiver.tokenEventApply(payload)
+	if receiver.tokens.read(`aa01`) != nil {
+		t.Errorf("Revoked token is still accepted")
+	}
+
+	revokedAt := time.Now().UTC()
+	receiver.tokenEventApply(publish(tokenEvent{
+		Event: tokenEventRevokeAccount,
+		User:  `alice`,
+		At:    revokedAt.Format(msg.RFC3339Milli),
+	}))
+	if at, ok := receiver.tokens.isExpired(`alice`); !ok ||
+		at.Format(msg.RFC3339Milli) != revokedAt.Format(msg.RFC3339Milli) {
+		t.Errorf("Account revocation not applied: %s, %t", at, ok)
+	}
+
+	receiver.tokenEventApply(publish(tokenEvent{
+		Event: tokenEventRevokeGlobal,
+		At:    time.Now().UTC().Format(msg.RFC3339Milli),
+	}))
+	if len(receiver.tokens.TMap) != 0 {
+		t.Errorf("Global revocation left %d tokens",
+			len(receiver.tokens.TMap))
+	}
+}
+
+func TestCredentialEventApply(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	receiver := testSupervisor(`instance-b`)
+	receiver.conn = db
+
+	mcf, err := scrypth64.Digest(`secret`, scrypth64.DefaultParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := uuid.Must(uuid.NewV4())
+	validFrom := time.Now().UTC().Add(-time.Minute)
+	validUntil := validFrom.Add(24 * time.Hour)
+	payload, _ := json.Marshal(&tokenEvent{
+		Origin: `instance-a`,
+		Event:  tokenEventCredential,
+		User:   `alice`,
+	})
+
+	// new credentials of alice are loaded
+	mock.ExpectQuery(`SELECT (.+) FROM inventory.user iu`).
+		WithArgs(`alice`).
+		WillReturnRows(sqlmock.NewRows([]string{
+			`user_id`, `crypt`, `reset_pending`, `valid_from`,
+			`valid_until`,
+		}).AddRow(userID.String(), mcf.String(), false, validFrom,
+			validUntil))
+	receiver.tokenEventApply(string(payload))
+
+	cred := receiver.credentials.read(`alice`)
+	if cred == nil || !uuid.Equal(cred.id, userID) || cred.isExpired() ||
+		cred.cryptMCF.String() != mcf.String() {
+		t.Fatalf("Credentials not loaded: %+v", cred)
+	}
+
+	// credentials of alice have been revoked
+	mock.ExpectQuery(`SELECT (.+) FROM inventory.user iu`).
+		WithArgs(`alice`).
+		WillReturnError(sql.ErrNoRows)
+	receiver.tokenEventApply(string(payload))
+	if cred = receiver.credentials.read(`alice`); cred == nil ||
+		!cred.isExpired() {
+		t.Errorf("Credentials not revoked: %+v", cred)
+	}
+
+	// admin accounts are loaded from the admin credentials
+	payload, _ = json.Marshal(&tokenEvent{
+		Origin: `instance-a`,
+		Event:  tokenEventCredential,
+		User:   `admin_alice`,
+	})
+	mock.ExpectQuery(`SELECT (.+) FROM auth.admin`).
+		WithArgs(`admin_alice`).
+		WillReturnError(sql.ErrNoRows)
+	receiver.tokenEventApply(string(payload))
+	if receiver.credentials.read(`admin_alice`) != nil {
+		t.Errorf("Unknown admin credentials were created")
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
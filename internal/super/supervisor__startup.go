@@ -30,6 +30,10 @@ func (s *Supervisor) startupLoad() {
 
 	s.startupTokens()
 
+	s.startupTokenRevocations()
+
+	s.startupAPIKeys()
+
 	s.startupTeam()
 
 	s.startupUser()
@@ -195,6 +199,19 @@ func (s *Supervisor) startupAdminCredentials() {
 }
 
 func (s *Supervisor) startupTokens() {
+	if err := s.loadTokens(s.tokens); err != nil {
+		s.errLog.Fatal(`supervisor/load-tokens: `, err)
+	}
+}
+
+func (s *Supervisor) startupTokenRevocations() {
+	if err := s.loadTokenRevocations(s.tokens); err != nil {
+		s.errLog.Fatal(`supervisor/load-token-revocations: `, err)
+	}
+}
+
+// loadTokens inserts all currently valid tokens into tm
+func (s *Supervisor) loadTokens(tm *tokenMap) error {
 	var (
 		err                         error
 		token, salt, valid, expires string
@@ -202,9 +219,8 @@ func (s *Supervisor) startupTokens() {
 		rows                        *sql.Rows
 	)
 
-	rows, err = s.conn.Query(stmt.LoadAllTokens)
-	if err != nil {
-		s.errLog.Fatal(`supervisor/load-tokens,query: `, err)
+	if rows, err = s.conn.Query(stmt.LoadAllTokens); err != nil {
+		return err
 	}
 	defer rows.Close()
 
@@ -215,17 +231,117 @@ func (s *Supervisor) startupTokens() {
 			&validFrom,
 			&expiresAt,
 		); err != nil {
-			s.errLog.Fatal(`supervisor/load-tokens,scan: `, err)
+			return err
 		}
 		valid = validFrom.Format(msg.RFC3339Milli)
 		expires = expiresAt.Format(msg.RFC3339Milli)
 
-		if err = s.tokens.insert(token, valid, expires, salt); err != nil {
-			s.errLog.Fatal(`supervisor/load-tokens,insert: `, err)
+		if err = tm.insert(token, valid, expires, salt); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// loadTokenRevocations records all account revocations in tm that
+// are recent enough to still affect valid tokens
+func (s *Supervisor) loadTokenRevocations(tm *tokenMap) error {
+	var (
+		err       error
+		user      string
+		revokedAt time.Time
+		rows      *sql.Rows
+	)
+
+	cutoff := time.Now().UTC().Add(
+		-time.Duration(s.tokenExpiry) * time.Second,
+	)
+
+	if rows, err = s.conn.Query(
+		stmt.LoadTokenRevocations,
+		cutoff,
+	); err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&user,
+			&revokedAt,
+		); err != nil {
+			return err
 		}
+		tm.expireAccountAt(user, revokedAt)
+	}
+	return rows.Err()
+}
+
+func (s *Supervisor) startupAPIKeys() {
+	var (
+		err                                   error
+		keyID, userID, userName, hash, permID string
+		validFrom, expiresAt                  time.Time
+		rows                                  *sql.Rows
+	)
+	keys := map[string]apiKey{}
+	hashes := map[string]string{}
+
+	rows, err = s.conn.Query(stmt.LoadAllAPIKeys)
+	if err != nil {
+		s.errLog.Fatal(`supervisor/load-apikeys,query: `, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&keyID,
+			&userID,
+			&userName,
+			&hash,
+			&validFrom,
+			&expiresAt,
+		); err != nil {
+			s.errLog.Fatal(`supervisor/load-apikeys,scan: `, err)
+		}
+		keys[keyID] = apiKey{
+			id:        keyID,
+			userID:    userID,
+			userName:  userName,
+			validFrom: validFrom.UTC(),
+			expiresAt: expiresAt.UTC(),
+			scope:     []string{},
+		}
+		hashes[keyID] = hash
 	}
 	if err = rows.Err(); err != nil {
-		s.errLog.Fatal(`supervisor/load-tokens,next: `, err)
+		s.errLog.Fatal(`supervisor/load-apikeys,next: `, err)
+	}
+
+	rows, err = s.conn.Query(stmt.LoadAllAPIKeyPermissions)
+	if err != nil {
+		s.errLog.Fatal(`supervisor/load-apikey-permissions,query: `, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(
+			&keyID,
+			&permID,
+		); err != nil {
+			s.errLog.Fatal(`supervisor/load-apikey-permissions,scan: `, err)
+		}
+		if key, ok := keys[keyID]; ok {
+			key.scope = append(key.scope, permID)
+			keys[keyID] = key
+		}
+	}
+	if err = rows.Err(); err != nil {
+		s.errLog.Fatal(`supervisor/load-apikey-permissions,next: `, err)
+	}
+
+	for keyID := range keys {
+		s.apiKeys.insert(hashes[keyID], keys[keyID])
 	}
 }
 
@@ -109,14 +109,38 @@ func (t *tokenMap) remove(token string) {
 	delete(t.TMap, token)
 }
 
-// expireAccount marks the account as having all tokens issued
-// until now expired
-func (t *tokenMap) expireAccount(user string) {
+// expireAccountAt marks the account as having all tokens issued
+// until revokedAt expired. Revocations older than an already recorded
+// revocation are ignored.
+func (t *tokenMap) expireAccountAt(user string, revokedAt time.Time) {
 	// acquire write lock
 	t.lock()
 	defer t.unlock()
 
-	t.Expire[user] = time.Now().UTC()
+	if prev, ok := t.Expire[user]; ok && prev.After(revokedAt) {
+		return
+	}
+	t.Expire[user] = revokedAt.UTC()
+}
+
+// replace swaps the contents of the tokenMap for the contents of
+// fresh. Tokens that became valid after since are kept, since they
+// may have been issued while fresh was loaded.
+func (t *tokenMap) replace(fresh *tokenMap, since time.Time) {
+	// acquire write lock
+	t.lock()
+	defer t.unlock()
+
+	for id := range t.TMap {
+		if _, ok := fresh.TMap[id]; ok {
+			continue
+		}
+		if t.TMap[id].validFrom.After(since) {
+			fresh.TMap[id] = t.TMap[id]
+		}
+	}
+	t.TMap = fresh.TMap
+	t.Expire = fresh.Expire
 }
 
 // isExpired returns if and when the tokens for this account have
@@ -0,0 +1,356 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/satori/go.uuid"
+)
+
+func TestNodeMoveKeepsInstances(t *testing.T) {
+	deterministicInheritanceOrder = true
+
+	actionC := make(chan *Action, 512)
+	errC := make(chan *Error, 128)
+
+	rootID := uuid.Must(uuid.NewV4()).String()
+	teamID := uuid.Must(uuid.NewV4()).String()
+	repoID := uuid.Must(uuid.NewV4()).String()
+	bck1ID := uuid.Must(uuid.NewV4()).String()
+	bck2ID := uuid.Must(uuid.NewV4()).String()
+	grp1ID := uuid.Must(uuid.NewV4()).String()
+	nod1ID := uuid.Must(uuid.NewV4()).String()
+
+	discardLog := logrus.New()
+	discardLog.Out = ioutil.Discard
+
+	sTree := New(Spec{
+		ID:     rootID,
+		Name:   `root_moveTest`,
+		Action: actionC,
+		Log:    discardLog,
+	})
+	sTree.RegisterErrChan(errC)
+
+	NewRepository(RepositorySpec{
+		ID:      repoID,
+		Name:    `moveTest`,
+		Team:    teamID,
+		Deleted: false,
+		Active:  true,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `root`,
+		ParentID:   rootID,
+	})
+	sTree.SetError()
+
+	for _, bucketID := range []string{bck1ID, bck2ID} {
+		NewBucket(BucketSpec{
+			ID:          bucketID,
+			Name:        `moveTest_` + bucketID,
+			Environment: `testing`,
+			Team:        teamID,
+			Repository:  repoID,
+		}).Attach(AttachRequest{
+			Root:       sTree,
+			ParentType: `repository`,
+			ParentID:   repoID,
+		})
+	}
+
+	NewGroup(GroupSpec{
+		ID:   grp1ID,
+		Name: `testGroup1`,
+		Team: teamID,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `bucket`,
+		ParentID:   bck2ID,
+	})
+
+	NewNode(NodeSpec{
+		ID:       nod1ID,
+		AssetID:  1,
+		Name:     `testnode1`,
+		Team:     teamID,
+		ServerID: uuid.Must(uuid.NewV4()).String(),
+		Online:   true,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `bucket`,
+		ParentID:   bck1ID,
+	})
+
+	// one check inherited from the repository, one from the bucket
+	// the node is moved out of
+	repoCheck := testMoveCheck()
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementID:   repoID,
+	}, true).SetCheck(repoCheck)
+
+	bucketCheck := testMoveCheck()
+	sTree.Find(FindRequest{
+		ElementType: `bucket`,
+		ElementID:   bck1ID,
+	}, true).SetCheck(bucketCheck)
+
+	sTree.ComputeCheckInstances()
+
+	node := sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementID:   nod1ID,
+	}, true).(*Node)
+
+	if len(node.Instances) != 2 {
+		t.Fatalf("Expected 2 check instances before move, got %d",
+			len(node.Instances))
+	}
+	instances := map[string]string{}
+	for _, inst := range node.Instances {
+		instances[node.Checks[inst.CheckID.String()].ConfigID.String()] =
+			inst.InstanceID.String()
+	}
+
+	// drain the actions of the tree setup
+	for len(actionC) > 0 {
+		<-actionC
+	}
+
+	node.Move(AttachRequest{
+		Root:       sTree,
+		ParentType: `group`,
+		ParentID:   grp1ID,
+	})
+	sTree.ComputeCheckInstances()
+
+	close(actionC)
+	close(errC)
+
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+
+	if node.State != `grouped` {
+		t.Errorf("Node has state %s after move, expected grouped",
+			node.State)
+	}
+	if len(node.Instances) != 1 {
+		t.Fatalf("Expected 1 check instance after move, got %d",
+			len(node.Instances))
+	}
+	for _, inst := range node.Instances {
+		if inst.InstanceID.String() != instances[repoCheck.ConfigID.String()] {
+			t.Errorf("Check instance was not kept: %s",
+				inst.InstanceID.String())
+		}
+	}
+
+	seen := map[string]int{}
+	for a := range actionC {
+		if a.Type != `node` {
+			continue
+		}
+		seen[a.Action]++
+	}
+	for action, count := range map[string]int{
+		ActionNodeMove:            1,
+		ActionCheckNew:            0,
+		ActionCheckRemoved:        1,
+		ActionCheckInstanceCreate: 0,
+		ActionCheckInstanceDelete: 1,
+		ActionCheckInstanceUpdate: 1,
+	} {
+		if seen[action] != count {
+			t.Errorf("Expected %d %s actions, received %d",
+				count, action, seen[action])
+		}
+	}
+	deterministicInheritanceOrder = false
+}
+
+func TestBrokenConstraintPrunesInstances(t *testing.T) {
+	deterministicInheritanceOrder = true
+
+	actionC := make(chan *Action, 512)
+	errC := make(chan *Error, 128)
+
+	rootID := uuid.Must(uuid.NewV4()).String()
+	teamID := uuid.Must(uuid.NewV4()).String()
+	repoID := uuid.Must(uuid.NewV4()).String()
+	bck1ID := uuid.Must(uuid.NewV4()).String()
+	grp1ID := uuid.Must(uuid.NewV4()).String()
+	clr1ID := uuid.Must(uuid.NewV4()).String()
+	prop := uuid.Must(uuid.NewV4())
+
+	discardLog := logrus.New()
+	discardLog.Out = ioutil.Discard
+
+	sTree := New(Spec{
+		ID:     rootID,
+		Name:   `root_pruneTest`,
+		Action: actionC,
+		Log:    discardLog,
+	})
+	sTree.RegisterErrChan(errC)
+
+	NewRepository(RepositorySpec{
+		ID:      repoID,
+		Name:    `pruneTest`,
+		Team:    teamID,
+		Deleted: false,
+		Active:  true,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `root`,
+		ParentID:   rootID,
+	})
+	sTree.SetError()
+
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementID:   repoID,
+	}, true).(Propertier).SetProperty(&PropertySystem{
+		ID:          prop,
+		Inheritance: true,
+		View:        `any`,
+		Key:         `testkey`,
+		Value:       `testvalue`,
+	})
+
+	NewBucket(BucketSpec{
+		ID:          bck1ID,
+		Name:        `pruneTest_test`,
+		Environment: `testing`,
+		Team:        teamID,
+		Repository:  repoID,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `repository`,
+		ParentID:   repoID,
+	})
+
+	NewGroup(GroupSpec{
+		ID:   grp1ID,
+		Name: `testGroup1`,
+		Team: teamID,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `bucket`,
+		ParentID:   bck1ID,
+	})
+
+	NewCluster(ClusterSpec{
+		ID:   clr1ID,
+		Name: `testCluster1`,
+		Team: teamID,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `bucket`,
+		ParentID:   bck1ID,
+	})
+
+	chk := testMoveCheck()
+	chk.Constraints = []CheckConstraint{{
+		Type:  msg.ConstraintSystem,
+		Key:   `testkey`,
+		Value: `testvalue`,
+	}}
+	sTree.Find(FindRequest{
+		ElementType: `bucket`,
+		ElementID:   bck1ID,
+	}, true).SetCheck(chk)
+	sTree.ComputeCheckInstances()
+
+	group := sTree.Find(FindRequest{
+		ElementType: `group`,
+		ElementID:   grp1ID,
+	}, true).(*Group)
+	cluster := sTree.Find(FindRequest{
+		ElementType: `cluster`,
+		ElementID:   clr1ID,
+	}, true).(*Cluster)
+
+	if len(group.Instances) != 1 || len(cluster.Instances) != 1 {
+		t.Fatalf("Expected 1 check instance each, got %d and %d",
+			len(group.Instances), len(cluster.Instances))
+	}
+
+	// drain the actions of the tree setup
+	for len(actionC) > 0 {
+		<-actionC
+	}
+
+	// the changed property breaks the constraint of the check
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementID:   repoID,
+	}, true).(Propertier).UpdateProperty(&PropertySystem{
+		SourceID:    prop,
+		Inheritance: true,
+		View:        `any`,
+		Key:         `testkey`,
+		Value:       `testvalueUPDATED`,
+	})
+	sTree.ComputeCheckInstances()
+
+	close(actionC)
+	close(errC)
+
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+
+	if len(group.Instances) != 0 || len(cluster.Instances) != 0 {
+		t.Errorf("Expected no check instances, got %d and %d",
+			len(group.Instances), len(cluster.Instances))
+	}
+
+	seen := map[string]int{}
+	for a := range actionC {
+		if a.Action == ActionCheckInstanceDelete {
+			seen[a.Type]++
+		}
+	}
+	for _, typ := range []string{`group`, `cluster`} {
+		if seen[typ] != 1 {
+			t.Errorf("Expected 1 %s %s action, received %d",
+				typ, ActionCheckInstanceDelete, seen[typ])
+		}
+	}
+	deterministicInheritanceOrder = false
+}
+
+func testMoveCheck() Check {
+	return Check{
+		ID:            uuid.Nil,
+		SourceID:      uuid.Nil,
+		InheritedFrom: uuid.Nil,
+		Inheritance:   true,
+		ChildrenOnly:  false,
+		Interval:      60,
+		ConfigID:      uuid.Must(uuid.NewV4()),
+		CapabilityID:  uuid.Must(uuid.NewV4()),
+		View:          `any`,
+		Thresholds: []CheckThreshold{
+			{
+				Predicate: `>=`,
+				Level:     1,
+				Value:     100,
+			},
+		},
+		Constraints: []CheckConstraint{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -62,6 +62,14 @@ type BucketAttacher interface {
 	attachToBucket(a AttachRequest)
 }
 
+// implemented by: nodes
+type NodeAttacher interface {
+	BucketAttacher
+
+	Move(a AttachRequest)
+	SetLifecycle(l NodeLifecycle)
+}
+
 // implemented by: groups, clusters, nodes
 type GroupAttacher interface {
 	Attacher
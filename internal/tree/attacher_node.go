@@ -67,6 +67,106 @@ func (ten *Node) ReAttach(a AttachRequest) {
 	ten.Parent.(Checker).syncCheck(ten.ID.String())
 }
 
+// Move relocates the node to a new parent, which can be located in
+// a different bucket of the same repository. Inherited checks that
+// are inherited from the same source check at the new position keep
+// their check ID, which allows the instance computation to match and
+// keep the existing check instances.
+func (ten *Node) Move(a AttachRequest) {
+	if ten.Parent == nil {
+		panic(`Node.Move: not attached`)
+	}
+	target := a.Root.(*Tree).Find(FindRequest{
+		ElementType: a.ParentType,
+		ElementID:   a.ParentID,
+	}, true)
+	if _, ok := target.(*Fault); ok {
+		a.Root.(*Tree).AttachError(Error{Action: `move_node`})
+		return
+	}
+	if target.(Builder).GetID() == ten.Parent.(Builder).GetID() {
+		a.Root.(*Tree).AttachError(Error{Action: `move_node_same_parent`})
+		return
+	}
+	oldBucket := ten.Parent.(Bucketeer).GetBucket()
+	newBucket := target.(Bucketeer).GetBucket()
+	crossBucket := oldBucket.(Builder).GetID() != newBucket.(Builder).GetID()
+
+	ten.deletePropertyAllInherited()
+
+	// set aside all inherited checks, they are either picked up
+	// again by syncCheck or removed after the move
+	ten.movedChecks = make(map[string]Check)
+	for id, check := range ten.Checks {
+		if !check.GetIsInherited() {
+			continue
+		}
+		ten.movedChecks[check.SourceID.String()] = check
+		delete(ten.Checks, id)
+	}
+
+	// receiving the node in a bucket always creates a new bucket
+	// assignment, the old one has to be released first
+	if crossBucket || a.ParentType == `bucket` {
+		ten.actionMove(newBucket.(*Bucket).export())
+	}
+
+	ten.Parent.Unlink(UnlinkRequest{
+		ParentType: ten.Parent.(Builder).GetType(),
+		ParentName: ten.Parent.(Builder).GetName(),
+		ParentID:   ten.Parent.(Builder).GetID(),
+		ChildType:  ten.GetType(),
+		ChildName:  ten.GetName(),
+		ChildID:    ten.GetID(),
+	},
+	)
+
+	if crossBucket && a.ParentType != `bucket` {
+		// nodes are always assigned to a bucket, the group or
+		// cluster membership is created afterwards
+		newBucket.Receive(ReceiveRequest{
+			ParentType: newBucket.(Builder).GetType(),
+			ParentID:   newBucket.(Builder).GetID(),
+			ParentName: newBucket.(Builder).GetName(),
+			ChildType:  ten.Type,
+			Node:       ten,
+		},
+		)
+		ten.Parent.Unlink(UnlinkRequest{
+			ParentType: ten.Parent.(Builder).GetType(),
+			ParentName: ten.Parent.(Builder).GetName(),
+			ParentID:   ten.Parent.(Builder).GetID(),
+			ChildType:  ten.GetType(),
+			ChildName:  ten.GetName(),
+			ChildID:    ten.GetID(),
+		},
+		)
+	}
+
+	a.Root.Receive(ReceiveRequest{
+		ParentType: a.ParentType,
+		ParentID:   a.ParentID,
+		ParentName: a.ParentName,
+		ChildType:  ten.GetType(),
+		Node:       ten,
+	},
+	)
+
+	if ten.Parent == nil {
+		panic(`Node.Move: not reattached`)
+	}
+	ten.actionUpdate()
+	ten.Parent.(Propertier).syncProperty(ten.ID.String())
+	ten.Parent.(Checker).syncCheck(ten.ID.String())
+
+	// checks that were not inherited again no longer apply
+	for _, check := range ten.movedChecks {
+		ten.actionCheckRemoved(ten.setupCheckAction(check))
+	}
+	ten.movedChecks = nil
+	ten.hasUpdate = true
+}
+
 func (ten *Node) Destroy() {
 	if ten.Parent == nil {
 		panic(`Node.Destroy called without Parent to unlink from`)
@@ -24,6 +24,7 @@ type Checker interface {
 	SetCheck(c Check)
 	LoadInstance(i CheckInstance)
 	DeleteCheck(c Check)
+	UpdateCheck(c Check)
 
 	setCheckInherited(c Check)
 	setCheckOnChildren(c Check)
@@ -34,6 +35,10 @@ type Checker interface {
 	deleteCheckLocalAll()
 	rmCheck(c Check)
 
+	updateCheckInherited(c Check)
+	updateCheckOnChildren(c Check)
+	replaceCheck(c Check)
+
 	syncCheck(childID string)
 	checkCheck(checkID string) bool
 }
@@ -65,6 +70,7 @@ type Check struct {
 	ChildrenOnly  bool
 	View          string
 	Interval      uint64
+	Disabled      bool
 	Thresholds    []CheckThreshold
 	Constraints   []CheckConstraint
 	Items         []CheckItem
@@ -78,6 +84,7 @@ func (c *Check) Clone() Check {
 		ChildrenOnly: c.ChildrenOnly,
 		View:         c.View,
 		Interval:     c.Interval,
+		Disabled:     c.Disabled,
 	}
 	ng.ID, _ = uuid.FromString(c.ID.String())
 	ng.SourceID, _ = uuid.FromString(c.SourceID.String())
@@ -103,6 +110,19 @@ func (c *Check) Clone() Check {
 	return ng
 }
 
+// withSettings returns a copy of c that uses the mutable settings
+// of check u
+func (c *Check) withSettings(u Check) Check {
+	ng := c.Clone()
+	ng.Interval = u.Interval
+	ng.Disabled = u.Disabled
+	ng.Thresholds = make([]CheckThreshold, len(u.Thresholds))
+	for i := range u.Thresholds {
+		ng.Thresholds[i] = u.Thresholds[i].Clone()
+	}
+	return ng
+}
+
 type CheckItem struct {
 	ObjectID   uuid.UUID
 	ObjectType string
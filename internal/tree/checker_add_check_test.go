@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/lib/proto"
 	"github.com/satori/go.uuid"
 )
 
@@ -281,6 +282,361 @@ func TestCheckerDeleteCheck(t *testing.T) {
 	deterministicInheritanceOrder = false
 }
 
+func TestCheckerUpdateCheck(t *testing.T) {
+	deterministicInheritanceOrder = true
+
+	sTree, actionC, errC := testSpawnCheckTree()
+
+	chkConfigID := uuid.Must(uuid.NewV4())
+	capID := uuid.Must(uuid.NewV4())
+	chkID := uuid.Must(uuid.NewV4())
+
+	chk := Check{
+		ID:            chkID,
+		SourceID:      uuid.Nil,
+		InheritedFrom: uuid.Nil,
+		Inheritance:   true,
+		ChildrenOnly:  false,
+		Interval:      60,
+		ConfigID:      chkConfigID,
+		CapabilityID:  capID,
+		View:          `any`,
+		Thresholds: []CheckThreshold{
+			{
+				Predicate: `>=`,
+				Level:     1,
+				Value:     100,
+			},
+		},
+		Constraints: []CheckConstraint{},
+	}
+
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementName: `checkTest`,
+	}, true).SetCheck(chk)
+
+	sTree.ComputeCheckInstances()
+
+	updChk := Check{
+		ID:            uuid.Nil,
+		InheritedFrom: uuid.Nil,
+		SourceID:      uuid.Nil,
+		ConfigID:      chkConfigID,
+		Interval:      300,
+		Thresholds: []CheckThreshold{
+			{
+				Predicate: `>=`,
+				Level:     1,
+				Value:     200,
+			},
+			{
+				Predicate: `>=`,
+				Level:     3,
+				Value:     450,
+			},
+		},
+	}
+
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementName: `checkTest`,
+	}, true).UpdateCheck(updChk)
+
+	sTree.ComputeCheckInstances()
+
+	close(actionC)
+	close(errC)
+
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+
+	created := map[string]uint64{}
+	updated := 0
+	for a := range actionC {
+		switch a.Action {
+		case ActionCheckInstanceCreate:
+			created[a.CheckInstance.InstanceID] = a.CheckInstance.Version
+		case ActionCheckInstanceUpdate:
+			updated++
+			version, ok := created[a.CheckInstance.InstanceID]
+			if !ok {
+				t.Error(`Updated unknown check instance`,
+					a.CheckInstance.InstanceID)
+				continue
+			}
+			if a.CheckInstance.Version != version+1 {
+				t.Error(`Received incorrect version`,
+					a.CheckInstance.Version, `for instance`,
+					a.CheckInstance.InstanceID)
+			}
+		case ActionCheckNew, ActionCheckRemoved,
+			ActionCheckInstanceDelete:
+			if len(created) > 0 {
+				t.Error(`Received unexpected action`,
+					a.Type, a.Action)
+			}
+		}
+	}
+	if len(created) != 8 || updated != len(created) {
+		t.Error(`Expected 8 instance updates, created`, len(created),
+			`and updated`, updated)
+	}
+
+	deterministicInheritanceOrder = false
+}
+
+func TestCheckerDisableCheck(t *testing.T) {
+	deterministicInheritanceOrder = true
+
+	sTree, actionC, errC := testSpawnCheckTree()
+
+	chkConfigID := uuid.Must(uuid.NewV4())
+	capID := uuid.Must(uuid.NewV4())
+	chkID := uuid.Must(uuid.NewV4())
+
+	chk := Check{
+		ID:            chkID,
+		SourceID:      uuid.Nil,
+		InheritedFrom: uuid.Nil,
+		Inheritance:   true,
+		ChildrenOnly:  false,
+		Interval:      60,
+		ConfigID:      chkConfigID,
+		CapabilityID:  capID,
+		View:          `any`,
+		Thresholds: []CheckThreshold{
+			{
+				Predicate: `>=`,
+				Level:     1,
+				Value:     100,
+			},
+		},
+		Constraints: []CheckConstraint{},
+	}
+
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementName: `checkTest`,
+	}, true).SetCheck(chk)
+
+	sTree.ComputeCheckInstances()
+
+	updChk := Check{
+		ID:            uuid.Nil,
+		InheritedFrom: uuid.Nil,
+		SourceID:      uuid.Nil,
+		ConfigID:      chkConfigID,
+		Interval:      60,
+		Disabled:      true,
+		Thresholds:    chk.Thresholds,
+	}
+
+	// disabling the check must not touch its instances
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementName: `checkTest`,
+	}, true).UpdateCheck(updChk)
+
+	sTree.ComputeCheckInstances()
+
+	// enabling the check rolls out new versions of the same instances
+	updChk.Disabled = false
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementName: `checkTest`,
+	}, true).UpdateCheck(updChk)
+
+	sTree.ComputeCheckInstances()
+
+	close(actionC)
+	close(errC)
+
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+
+	created := map[string]uint64{}
+	updated := 0
+	for a := range actionC {
+		switch a.Action {
+		case ActionCheckInstanceCreate:
+			created[a.CheckInstance.InstanceID] = a.CheckInstance.Version
+		case ActionCheckInstanceUpdate:
+			updated++
+			version, ok := created[a.CheckInstance.InstanceID]
+			if !ok {
+				t.Error(`Updated unknown check instance`,
+					a.CheckInstance.InstanceID)
+				continue
+			}
+			if a.CheckInstance.Version != version+1 {
+				t.Error(`Received incorrect version`,
+					a.CheckInstance.Version, `for instance`,
+					a.CheckInstance.InstanceID)
+			}
+		case ActionCheckNew, ActionCheckRemoved,
+			ActionCheckInstanceDelete:
+			if len(created) > 0 {
+				t.Error(`Received unexpected action`,
+					a.Type, a.Action)
+			}
+		}
+	}
+	if len(created) != 8 || updated != len(created) {
+		t.Error(`Expected 8 instance updates, created`, len(created),
+			`and updated`, updated)
+	}
+
+	deterministicInheritanceOrder = false
+}
+
+func TestCheckerNodeLifecycleDeprovision(t *testing.T) {
+	deterministicInheritanceOrder = true
+
+	sTree, actionC, errC := testSpawnCheckTree()
+
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementName: `checkTest`,
+	}, true).SetCheck(testLifecycleCheck())
+
+	sTree.ComputeCheckInstances()
+
+	// moving the node into a deprovisioning state deletes its
+	// instances
+	sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode1`,
+	}, true).(NodeAttacher).SetLifecycle(NodeLifecycle{
+		State:         `decommissioning`,
+		CheckHandling: proto.StateChecksDeprovision,
+	})
+
+	sTree.ComputeCheckInstances()
+
+	// moving the node back creates them again
+	sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode1`,
+	}, true).(NodeAttacher).SetLifecycle(NodeLifecycle{})
+
+	sTree.ComputeCheckInstances()
+
+	close(actionC)
+	close(errC)
+
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+
+	created, deleted, updated := 0, 0, 0
+	for a := range actionC {
+		switch a.Action {
+		case ActionCheckInstanceCreate:
+			created++
+		case ActionCheckInstanceDelete:
+			deleted++
+		case ActionCheckInstanceUpdate:
+			updated++
+		}
+	}
+	if created != 9 || deleted != 1 || updated != 0 {
+		t.Error(`Expected 9 created and 1 deleted instance, got`,
+			created, deleted, updated)
+	}
+
+	deterministicInheritanceOrder = false
+}
+
+func TestCheckerNodeLifecycleSuspend(t *testing.T) {
+	deterministicInheritanceOrder = true
+
+	sTree, actionC, errC := testSpawnCheckTree()
+
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementName: `checkTest`,
+	}, true).SetCheck(testLifecycleCheck())
+
+	sTree.ComputeCheckInstances()
+
+	// suspending the checks of the node must not touch its
+	// instances
+	sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode1`,
+	}, true).(NodeAttacher).SetLifecycle(NodeLifecycle{
+		State:         `maintenance`,
+		CheckHandling: proto.StateChecksSuspend,
+	})
+
+	sTree.ComputeCheckInstances()
+
+	// moving the node back rolls out a new version of its instance
+	sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementName: `testnode1`,
+	}, true).(NodeAttacher).SetLifecycle(NodeLifecycle{})
+
+	sTree.ComputeCheckInstances()
+
+	close(actionC)
+	close(errC)
+
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+
+	created := map[string]uint64{}
+	updated := 0
+	for a := range actionC {
+		switch a.Action {
+		case ActionCheckInstanceCreate:
+			created[a.CheckInstance.InstanceID] = a.CheckInstance.Version
+		case ActionCheckInstanceUpdate:
+			updated++
+			if a.CheckInstance.Version !=
+				created[a.CheckInstance.InstanceID]+1 {
+				t.Error(`Received incorrect version`,
+					a.CheckInstance.Version, `for instance`,
+					a.CheckInstance.InstanceID)
+			}
+		case ActionCheckInstanceDelete:
+			t.Error(`Received unexpected action`, a.Type, a.Action)
+		}
+	}
+	if len(created) != 8 || updated != 1 {
+		t.Error(`Expected 8 created and 1 updated instance, got`,
+			len(created), updated)
+	}
+
+	deterministicInheritanceOrder = false
+}
+
+func testLifecycleCheck() Check {
+	return Check{
+		ID:            uuid.Must(uuid.NewV4()),
+		SourceID:      uuid.Nil,
+		InheritedFrom: uuid.Nil,
+		Inheritance:   true,
+		ChildrenOnly:  false,
+		Interval:      60,
+		ConfigID:      uuid.Must(uuid.NewV4()),
+		CapabilityID:  uuid.Must(uuid.NewV4()),
+		View:          `any`,
+		Thresholds: []CheckThreshold{
+			{
+				Predicate: `>=`,
+				Level:     1,
+				Value:     100,
+			},
+		},
+		Constraints: []CheckConstraint{},
+	}
+}
+
 func TestCheckerDestroyRepoWithChecks(t *testing.T) {
 	deterministicInheritanceOrder = true
 
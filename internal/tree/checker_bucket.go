@@ -166,6 +166,63 @@ func (teb *Bucket) rmCheck(c Check) {
 	}
 }
 
+//
+// Checker:> Update Check
+
+func (teb *Bucket) UpdateCheck(c Check) {
+	teb.updateCheckOnChildren(c)
+	teb.replaceCheck(c)
+}
+
+func (teb *Bucket) updateCheckInherited(c Check) {
+	teb.updateCheckOnChildren(c)
+	teb.replaceCheck(c)
+}
+
+func (teb *Bucket) updateCheckOnChildren(c Check) {
+	switch deterministicInheritanceOrder {
+	case true:
+		// groups
+		for i := 0; i < teb.ordNumChildGrp; i++ {
+			if child, ok := teb.ordChildrenGrp[i]; ok {
+				teb.Children[child].(Checker).updateCheckInherited(c)
+			}
+		}
+		// clusters
+		for i := 0; i < teb.ordNumChildClr; i++ {
+			if child, ok := teb.ordChildrenClr[i]; ok {
+				teb.Children[child].(Checker).updateCheckInherited(c)
+			}
+		}
+		// nodes
+		for i := 0; i < teb.ordNumChildNod; i++ {
+			if child, ok := teb.ordChildrenNod[i]; ok {
+				teb.Children[child].(Checker).updateCheckInherited(c)
+			}
+		}
+	default:
+		var wg sync.WaitGroup
+		for child := range teb.Children {
+			wg.Add(1)
+			go func(stc Check, ch string) {
+				defer wg.Done()
+				teb.Children[ch].(Checker).updateCheckInherited(stc)
+			}(c, child)
+		}
+		wg.Wait()
+	}
+}
+
+func (teb *Bucket) replaceCheck(c Check) {
+	for id := range teb.Checks {
+		if uuid.Equal(teb.Checks[id].ConfigID, c.ConfigID) {
+			f := teb.Checks[id]
+			teb.Checks[id] = f.withSettings(c)
+			return
+		}
+	}
+}
+
 //
 // Checker:> Meta
 
@@ -33,6 +33,17 @@ func (ten *Node) SetCheck(c Check) {
 func (ten *Node) setCheckInherited(c Check) {
 	// we keep a local copy, that way we know it is ours....
 	f := c.Clone()
+	if moved, ok := ten.movedChecks[f.SourceID.String()]; ok {
+		// the check was already inherited before the node was moved,
+		// keep its ID so the existing check instances can be matched
+		// against it
+		delete(ten.movedChecks, f.SourceID.String())
+		f.ID, _ = uuid.FromString(moved.ID.String())
+		f.Items = nil
+		ten.hasUpdate = true
+		ten.Checks[f.ID.String()] = f
+		return
+	}
 	f.ID = f.GetItemID(ten.Type, ten.ID)
 	if uuid.Equal(f.ID, uuid.Nil) {
 		f.ID = uuid.Must(uuid.NewV4())
@@ -92,6 +103,31 @@ func (ten *Node) rmCheck(c Check) {
 	}
 }
 
+//
+// Checker:> Update Check
+
+func (ten *Node) UpdateCheck(c Check) {
+	ten.replaceCheck(c)
+}
+
+func (ten *Node) updateCheckInherited(c Check) {
+	ten.replaceCheck(c)
+}
+
+func (ten *Node) updateCheckOnChildren(c Check) {
+}
+
+func (ten *Node) replaceCheck(c Check) {
+	for id := range ten.Checks {
+		if uuid.Equal(ten.Checks[id].ConfigID, c.ConfigID) {
+			ten.hasUpdate = true
+			f := ten.Checks[id]
+			ten.Checks[id] = f.withSettings(c)
+			return
+		}
+	}
+}
+
 // noop, satisfy interface
 func (ten *Node) syncCheck(childID string) {
 }
@@ -141,6 +141,51 @@ func (ter *Repository) rmCheck(c Check) {
 	}
 }
 
+//
+// Checker:> Update Check
+
+func (ter *Repository) UpdateCheck(c Check) {
+	ter.updateCheckOnChildren(c)
+	ter.replaceCheck(c)
+}
+
+func (ter *Repository) updateCheckInherited(c Check) {
+	ter.updateCheckOnChildren(c)
+	ter.replaceCheck(c)
+}
+
+func (ter *Repository) updateCheckOnChildren(c Check) {
+	switch deterministicInheritanceOrder {
+	case true:
+		// buckets
+		for i := 0; i < ter.ordNumChildBck; i++ {
+			if child, ok := ter.ordChildrenBck[i]; ok {
+				ter.Children[child].(Checker).updateCheckInherited(c)
+			}
+		}
+	default:
+		var wg sync.WaitGroup
+		for child := range ter.Children {
+			wg.Add(1)
+			go func(stc Check, ch string) {
+				defer wg.Done()
+				ter.Children[ch].(Checker).updateCheckInherited(stc)
+			}(c, child)
+		}
+		wg.Wait()
+	}
+}
+
+func (ter *Repository) replaceCheck(c Check) {
+	for id := range ter.Checks {
+		if uuid.Equal(ter.Checks[id].ConfigID, c.ConfigID) {
+			f := ter.Checks[id]
+			ter.Checks[id] = f.withSettings(c)
+			return
+		}
+	}
+}
+
 //
 // Checker:> Meta
 
@@ -276,6 +276,16 @@ func (c *Cluster) processCheckForUpdates(chkName string, startup bool) {
 		return
 	}
 
+	if c.Checks[chkName].Disabled {
+		// skip check if its check configuration is disabled, the
+		// existing instances are kept for when it is enabled again
+		c.lock.RUnlock()
+		if startup {
+			c.keepLoadedCheckInstances(chkName)
+		}
+		return
+	}
+
 	ctx := newCheckContext(chkName, c.Checks[chkName].View, startup)
 	c.lock.RUnlock()
 
@@ -286,6 +296,15 @@ func (c *Cluster) processCheckForUpdates(chkName string, startup bool) {
 		c.ID.String(), chkName, ctx.brokeConstraint,
 	)
 	if ctx.brokeConstraint {
+		// the check no longer matches, for example after a
+		// property was changed. Existing instances of this check
+		// are deleted
+		if !ctx.startup {
+			c.pruneOldCheckInstances(ctx)
+			c.lock.Lock()
+			delete(c.CheckInstances, ctx.uuid)
+			c.lock.Unlock()
+		}
 		return
 	}
 
@@ -328,6 +347,26 @@ func (c *Cluster) processCheckForUpdates(chkName string, startup bool) {
 	c.CheckInstances[ctx.uuid] = ctx.newCheckInstances
 }
 
+// keepLoadedCheckInstances takes over the loaded instances of a
+// disabled check unchanged, since they are not computed
+func (c *Cluster) keepLoadedCheckInstances(chkName string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	instances := []string{}
+	for instanceID, inst := range c.loadedInstances[chkName] {
+		c.Instances[instanceID] = inst
+		instances = append(instances, instanceID)
+		c.log.Printf(
+			"TK[%s]: Action=%s, ObjectType=%s, ObjectID=%s, CheckID=%s, InstanceID=%s",
+			c.GetRepositoryName(), `KeepDisabledInstance`, `cluster`, c.ID.String(),
+			chkName, instanceID,
+		)
+	}
+	delete(c.loadedInstances, chkName)
+	c.CheckInstances[chkName] = instances
+}
+
 func (c *Cluster) constraintCheck(ctx *checkContext) {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
@@ -276,6 +276,16 @@ func (g *Group) processCheckForUpdates(chkName string, startup bool) {
 		return
 	}
 
+	if g.Checks[chkName].Disabled {
+		// skip check if its check configuration is disabled, the
+		// existing instances are kept for when it is enabled again
+		g.lock.RUnlock()
+		if startup {
+			g.keepLoadedCheckInstances(chkName)
+		}
+		return
+	}
+
 	ctx := newCheckContext(chkName, g.Checks[chkName].View, startup)
 	g.lock.RUnlock()
 
@@ -286,6 +296,15 @@ func (g *Group) processCheckForUpdates(chkName string, startup bool) {
 		g.ID.String(), chkName, ctx.brokeConstraint,
 	)
 	if ctx.brokeConstraint {
+		// the check no longer matches, for example after a
+		// property was changed. Existing instances of this check
+		// are deleted
+		if !ctx.startup {
+			g.pruneOldCheckInstances(ctx)
+			g.lock.Lock()
+			delete(g.CheckInstances, ctx.uuid)
+			g.lock.Unlock()
+		}
 		return
 	}
 
@@ -328,6 +347,26 @@ func (g *Group) processCheckForUpdates(chkName string, startup bool) {
 	g.CheckInstances[ctx.uuid] = ctx.newCheckInstances
 }
 
+// keepLoadedCheckInstances takes over the loaded instances of a
+// disabled check unchanged, since they are not computed
+func (g *Group) keepLoadedCheckInstances(chkName string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	instances := []string{}
+	for instanceID, inst := range g.loadedInstances[chkName] {
+		g.Instances[instanceID] = inst
+		instances = append(instances, instanceID)
+		g.log.Printf(
+			"TK[%s]: Action=%s, ObjectType=%s, ObjectID=%s, CheckID=%s, InstanceID=%s",
+			g.GetRepositoryName(), `KeepDisabledInstance`, `group`, g.ID.String(),
+			chkName, instanceID,
+		)
+	}
+	delete(g.loadedInstances, chkName)
+	g.CheckInstances[chkName] = instances
+}
+
 func (g *Group) constraintCheck(ctx *checkContext) {
 	g.lock.RLock()
 	defer g.lock.RUnlock()
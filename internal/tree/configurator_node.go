@@ -203,6 +203,11 @@ func (n *Node) removeDisabledCheckInstances() {
 		); hit {
 			disableThis = true
 		}
+		// disable this check if the lifecycle state of the node
+		// removes the check instances of the check's view
+		if n.Lifecycle.removesCheck(n.Checks[chk].View) {
+			disableThis = true
+		}
 		// if there was a reason to disable this check, all instances
 		// are deleted
 		if disableThis {
@@ -263,6 +268,24 @@ func (n *Node) processCheckForUpdates(chkName string, startup bool) {
 		return
 	}
 
+	if n.Lifecycle.removesCheck(n.Checks[chkName].View) {
+		// skip check if the lifecycle state of the node removes
+		// the check instances of the check's view
+		n.lock.RUnlock()
+		return
+	}
+
+	if n.Checks[chkName].Disabled || n.Lifecycle.suspendsChecks() {
+		// skip check if its check configuration is disabled or the
+		// lifecycle state of the node suspends its checks, the
+		// existing instances are kept for when it is enabled again
+		n.lock.RUnlock()
+		if startup {
+			n.keepLoadedCheckInstances(chkName)
+		}
+		return
+	}
+
 	ctx := newCheckContext(chkName, n.Checks[chkName].View, startup)
 	n.lock.RUnlock()
 
@@ -273,6 +296,14 @@ func (n *Node) processCheckForUpdates(chkName string, startup bool) {
 		n.ID.String(), chkName, ctx.brokeConstraint,
 	)
 	if ctx.brokeConstraint {
+		// the check no longer matches, for example after the node
+		// was moved. Existing instances of this check are deleted
+		if !ctx.startup {
+			n.pruneOldCheckInstances(ctx)
+			n.lock.Lock()
+			delete(n.CheckInstances, ctx.uuid)
+			n.lock.Unlock()
+		}
 		return
 	}
 
@@ -315,6 +346,26 @@ func (n *Node) processCheckForUpdates(chkName string, startup bool) {
 	n.CheckInstances[ctx.uuid] = ctx.newCheckInstances
 }
 
+// keepLoadedCheckInstances takes over the loaded instances of a
+// disabled check unchanged, since they are not computed
+func (n *Node) keepLoadedCheckInstances(chkName string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	instances := []string{}
+	for instanceID, inst := range n.loadedInstances[chkName] {
+		n.Instances[instanceID] = inst
+		instances = append(instances, instanceID)
+		n.log.Printf(
+			"TK[%s]: Action=%s, ObjectType=%s, ObjectID=%s, CheckID=%s, InstanceID=%s",
+			n.GetRepositoryName(), `KeepDisabledInstance`, `node`, n.ID.String(),
+			chkName, instanceID,
+		)
+	}
+	delete(n.loadedInstances, chkName)
+	n.CheckInstances[chkName] = instances
+}
+
 func (n *Node) constraintCheck(ctx *checkContext) {
 	n.lock.RLock()
 	defer n.lock.RUnlock()
@@ -19,6 +19,7 @@ const (
 	ActionMemberNew           = `member_new`
 	ActionMemberRemoved       = `member_removed`
 	ActionNodeAssignment      = `node_assignment`
+	ActionNodeMove            = `node_move`
 	ActionPropertyDelete      = `property_delete`
 	ActionPropertyNew         = `property_new`
 	ActionPropertyUpdate      = `property_update`
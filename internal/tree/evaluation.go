@@ -0,0 +1,360 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"sort"
+
+	"github.com/mjolnir42/soma/internal/msg"
+	"github.com/mjolnir42/soma/lib/proto"
+)
+
+// constraintEvaluator is implemented by the tree elements that can
+// have check instances
+type constraintEvaluator interface {
+	evalNativeProp(prop string, val string) bool
+	evalSystemProp(prop string, val string, view string) (string, bool, string)
+	evalOncallProp(prop string, val string, view string) (string, bool)
+	evalCustomProp(prop string, val string, view string) (string, bool, string)
+	evalServiceProp(prop string, val string, view string) (string, bool, string)
+	evalAttributeOfService(svcID string, view string, attribute string, value string) (bool, string)
+	evalAttributeProp(view string, attr string, value string) (bool, map[string]string)
+	constraintCheck(ctx *checkContext)
+}
+
+// evaluationSubject holds the data of a tree element that is required
+// to report on the evaluation of a check
+type evaluationSubject struct {
+	id             string
+	typ            string
+	name           string
+	eval           constraintEvaluator
+	native         map[string]string
+	oncall         map[string]Property
+	service        map[string]Property
+	system         map[string]Property
+	custom         map[string]Property
+	checks         map[string]Check
+	checkInstances map[string][]string
+	lifecycle      NodeLifecycle
+}
+
+// EvaluateCheck returns how the constraints of the check
+// configuration configID evaluate on every element of st that has a
+// check created from it
+func (st *Tree) EvaluateCheck(configID string) []proto.CheckEvaluationObject {
+	res := []proto.CheckEvaluationObject{}
+	if st.Child == nil {
+		return res
+	}
+	st.Child.evaluateCheck(configID, &res)
+	return res
+}
+
+func (ter *Repository) evaluateCheck(configID string, res *[]proto.CheckEvaluationObject) {
+	evaluationSubject{
+		id:     ter.ID.String(),
+		typ:    ter.Type,
+		name:   ter.Name,
+		checks: ter.Checks,
+	}.evaluate(configID, res)
+	for _, child := range ter.Children {
+		evaluateCheckChild(configID, child, res)
+	}
+}
+
+// evaluateCheckChild recursively evaluates the check on child and its
+// children
+func evaluateCheckChild(configID string, child interface{}, res *[]proto.CheckEvaluationObject) {
+	switch c := child.(type) {
+	case *Bucket:
+		evaluationSubject{
+			id:     c.ID.String(),
+			typ:    c.Type,
+			name:   c.Name,
+			checks: c.Checks,
+		}.evaluate(configID, res)
+		for _, ch := range c.Children {
+			evaluateCheckChild(configID, ch, res)
+		}
+	case *Group:
+		evaluationSubject{
+			id:   c.ID.String(),
+			typ:  c.Type,
+			name: c.Name,
+			eval: c,
+			native: map[string]string{
+				msg.NativePropertyEnvironment: c.Parent.(Bucketeer).GetEnvironment(),
+				msg.NativePropertyEntity:      msg.EntityGroup,
+				msg.NativePropertyState:       c.State,
+			},
+			oncall:         c.PropertyOncall,
+			service:        c.PropertyService,
+			system:         c.PropertySystem,
+			custom:         c.PropertyCustom,
+			checks:         c.Checks,
+			checkInstances: c.CheckInstances,
+		}.evaluate(configID, res)
+		for _, ch := range c.Children {
+			evaluateCheckChild(configID, ch, res)
+		}
+	case *Cluster:
+		evaluationSubject{
+			id:   c.ID.String(),
+			typ:  c.Type,
+			name: c.Name,
+			eval: c,
+			native: map[string]string{
+				msg.NativePropertyEnvironment: c.Parent.(Bucketeer).GetEnvironment(),
+				msg.NativePropertyEntity:      msg.EntityCluster,
+				msg.NativePropertyState:       c.State,
+			},
+			oncall:         c.PropertyOncall,
+			service:        c.PropertyService,
+			system:         c.PropertySystem,
+			custom:         c.PropertyCustom,
+			checks:         c.Checks,
+			checkInstances: c.CheckInstances,
+		}.evaluate(configID, res)
+		for _, ch := range c.Children {
+			evaluateCheckChild(configID, ch, res)
+		}
+	case *Node:
+		evaluationSubject{
+			id:   c.ID.String(),
+			typ:  c.Type,
+			name: c.Name,
+			eval: c,
+			native: map[string]string{
+				msg.NativePropertyEnvironment: c.Parent.(Bucketeer).GetEnvironment(),
+				msg.NativePropertyEntity:      msg.EntityNode,
+				msg.NativePropertyState:       c.State,
+			},
+			oncall:         c.PropertyOncall,
+			service:        c.PropertyService,
+			system:         c.PropertySystem,
+			custom:         c.PropertyCustom,
+			checks:         c.Checks,
+			checkInstances: c.CheckInstances,
+			lifecycle:      c.Lifecycle,
+		}.evaluate(configID, res)
+	}
+}
+
+// evaluate appends the evaluation of all checks of s that were
+// created from configID to res. The skip conditions and the final
+// match follow processCheckForUpdates and constraintCheck
+func (s evaluationSubject) evaluate(configID string, res *[]proto.CheckEvaluationObject) {
+	checkIDs := []string{}
+	for checkID := range s.checks {
+		if s.checks[checkID].ConfigID.String() == configID {
+			checkIDs = append(checkIDs, checkID)
+		}
+	}
+	sort.Strings(checkIDs)
+
+	for _, checkID := range checkIDs {
+		chk := s.checks[checkID]
+		obj := proto.CheckEvaluationObject{
+			ObjectID:    s.id,
+			ObjectType:  s.typ,
+			ObjectName:  s.name,
+			CheckID:     checkID,
+			View:        chk.View,
+			IsInherited: chk.Inherited,
+		}
+
+		switch {
+		case s.eval == nil:
+			obj.SkipReason = `noInstances`
+		case !chk.Inherited && chk.ChildrenOnly:
+			obj.SkipReason = `childrenOnly`
+		case s.hasSystemProp(msg.SystemPropertyDisableAllMonitoring,
+			`true`, chk.View):
+			obj.SkipReason = `disableAllMonitoring`
+		case s.hasSystemProp(msg.SystemPropertyDisableCheckConfiguration,
+			chk.ConfigID.String(), chk.View):
+			obj.SkipReason = `disableCheckConfiguration`
+		case s.lifecycle.removesCheck(chk.View):
+			obj.SkipReason = `lifecycle`
+		case chk.Disabled:
+			obj.SkipReason = `disabled`
+		case s.lifecycle.suspendsChecks():
+			obj.SkipReason = `lifecycle`
+		default:
+			obj.IsEvaluated = true
+			obj.Constraints = s.evaluateConstraints(chk)
+
+			ctx := newCheckContext(checkID, chk.View, false)
+			s.eval.constraintCheck(ctx)
+			obj.IsMatch = !ctx.brokeConstraint
+		}
+
+		for _, instanceID := range s.checkInstances[checkID] {
+			obj.InstanceIDs = append(obj.InstanceIDs, instanceID)
+		}
+		*res = append(*res, obj)
+	}
+}
+
+func (s evaluationSubject) hasSystemProp(prop, val, view string) bool {
+	_, hit, _ := s.eval.evalSystemProp(prop, val, view)
+	return hit
+}
+
+// evaluateConstraints evaluates every constraint of chk on its own
+// and records the values of s it was compared against
+func (s evaluationSubject) evaluateConstraints(chk Check) []proto.CheckEvaluationConstraint {
+	res := make([]proto.CheckEvaluationConstraint, len(chk.Constraints))
+	hasServiceConstraint := false
+	boundServices := map[string]bool{}
+
+	for i, cc := range chk.Constraints {
+		res[i] = proto.CheckEvaluationConstraint{
+			ConstraintType: cc.Type,
+			Key:            cc.Key,
+			Value:          cc.Value,
+			Compared:       []proto.CheckEvaluationValue{},
+		}
+
+		switch cc.Type {
+		case msg.ConstraintNative:
+			res[i].IsMatch = s.eval.evalNativeProp(cc.Key, cc.Value)
+			if val, ok := s.native[cc.Key]; ok {
+				res[i].Compared = append(res[i].Compared,
+					proto.CheckEvaluationValue{
+						Value:   val,
+						IsMatch: res[i].IsMatch,
+					})
+			}
+		case msg.ConstraintSystem:
+			_, res[i].IsMatch, _ = s.eval.evalSystemProp(cc.Key, cc.Value, chk.View)
+			for _, id := range sortedPropertyIDs(s.system) {
+				t := s.system[id].(*PropertySystem)
+				if t.Key != cc.Key {
+					continue
+				}
+				res[i].Compared = append(res[i].Compared,
+					proto.CheckEvaluationValue{
+						View:    t.View,
+						Value:   t.Value,
+						IsMatch: evalValue(t.Value, cc.Value) && evalView(t.View, chk.View),
+					})
+			}
+		case msg.ConstraintCustom:
+			_, res[i].IsMatch, _ = s.eval.evalCustomProp(cc.Key, cc.Value, chk.View)
+			for _, id := range sortedPropertyIDs(s.custom) {
+				t := s.custom[id].(*PropertyCustom)
+				if t.Key != cc.Key {
+					continue
+				}
+				res[i].Compared = append(res[i].Compared,
+					proto.CheckEvaluationValue{
+						View:    t.View,
+						Value:   t.Value,
+						IsMatch: evalValue(t.Value, cc.Value) && evalView(t.View, chk.View),
+					})
+			}
+		case msg.ConstraintOncall:
+			_, res[i].IsMatch = s.eval.evalOncallProp(cc.Key, cc.Value, chk.View)
+			for _, id := range sortedPropertyIDs(s.oncall) {
+				t := s.oncall[id].(*PropertyOncall)
+				res[i].Compared = append(res[i].Compared,
+					proto.CheckEvaluationValue{
+						Source: t.Name,
+						View:   t.View,
+						Value:  t.ID.String(),
+						IsMatch: cc.Key == `OncallID` && t.ID.String() == cc.Value &&
+							evalView(t.View, chk.View),
+					})
+			}
+		case msg.ConstraintService:
+			hasServiceConstraint = true
+			var id string
+			id, res[i].IsMatch, _ = s.eval.evalServiceProp(cc.Key, cc.Value, chk.View)
+			if res[i].IsMatch {
+				boundServices[id] = true
+			}
+			for _, id := range sortedPropertyIDs(s.service) {
+				t := s.service[id].(*PropertyService)
+				res[i].Compared = append(res[i].Compared,
+					proto.CheckEvaluationValue{
+						View:  t.View,
+						Value: t.ServiceName,
+						IsMatch: cc.Key == `name` && evalValue(t.ServiceName, cc.Value) &&
+							evalView(t.View, chk.View),
+					})
+			}
+		}
+	}
+
+	// attribute constraints are evaluated against the services bound
+	// by service constraints, or against all services if the check
+	// has no service constraints
+	for i, cc := range chk.Constraints {
+		if cc.Type != msg.ConstraintAttribute {
+			continue
+		}
+
+		switch {
+		case hasServiceConstraint:
+			res[i].IsMatch = len(boundServices) > 0
+			for id := range boundServices {
+				if hit, _ := s.eval.evalAttributeOfService(id, chk.View, cc.Key, cc.Value); !hit {
+					res[i].IsMatch = false
+				}
+			}
+		default:
+			res[i].IsMatch, _ = s.eval.evalAttributeProp(chk.View, cc.Key, cc.Value)
+		}
+
+		for _, id := range sortedPropertyIDs(s.service) {
+			if hasServiceConstraint && !boundServices[id] {
+				continue
+			}
+			t := s.service[id].(*PropertyService)
+			for _, a := range t.Attributes {
+				if a.Name != cc.Key {
+					continue
+				}
+				res[i].Compared = append(res[i].Compared,
+					proto.CheckEvaluationValue{
+						Source:  t.ServiceName,
+						View:    t.View,
+						Value:   a.Value,
+						IsMatch: evalValue(a.Value, cc.Value) && evalView(t.View, chk.View),
+					})
+			}
+		}
+	}
+	return res
+}
+
+// evalValue returns true if value matches the constraint value
+// expected, which may be @defined
+func evalValue(value, expected string) bool {
+	return value == expected || expected == `@defined`
+}
+
+// evalView returns true if a property in view applies to a check in
+// checkView
+func evalView(view, checkView string) bool {
+	return view == checkView || view == `any`
+}
+
+// sortedPropertyIDs returns the keys of props in sorted order
+func sortedPropertyIDs(props map[string]Property) []string {
+	ids := make([]string, 0, len(props))
+	for id := range props {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,204 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/mjolnir42/soma/lib/proto"
+	"github.com/satori/go.uuid"
+)
+
+func TestEvaluateCheck(t *testing.T) {
+	actionC := make(chan *Action, 512)
+	errC := make(chan *Error, 128)
+
+	rootID := uuid.Must(uuid.NewV4()).String()
+	teamID := uuid.Must(uuid.NewV4()).String()
+	repoID := uuid.Must(uuid.NewV4()).String()
+	buckID := uuid.Must(uuid.NewV4()).String()
+	nodeID := uuid.Must(uuid.NewV4()).String()
+
+	discardLog := logrus.New()
+	discardLog.Out = ioutil.Discard
+
+	sTree := New(Spec{
+		ID:     rootID,
+		Name:   `root_evaluateTest`,
+		Action: actionC,
+		Log:    discardLog,
+	})
+	sTree.RegisterErrChan(errC)
+
+	NewRepository(RepositorySpec{
+		ID:      repoID,
+		Name:    `evaluateTest`,
+		Team:    teamID,
+		Deleted: false,
+		Active:  true,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `root`,
+		ParentID:   rootID,
+	})
+	sTree.SetError()
+
+	NewBucket(BucketSpec{
+		ID:          buckID,
+		Name:        `evaluateTest_testing`,
+		Environment: `testing`,
+		Team:        teamID,
+		Repository:  repoID,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `repository`,
+		ParentID:   repoID,
+	})
+
+	NewNode(NodeSpec{
+		ID:       nodeID,
+		AssetID:  1,
+		Name:     `testnode1`,
+		Team:     teamID,
+		ServerID: uuid.Must(uuid.NewV4()).String(),
+		Online:   true,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `bucket`,
+		ParentID:   buckID,
+	})
+
+	sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementID:   nodeID,
+	}, true).(Propertier).SetProperty(&PropertyService{
+		ID:          uuid.Must(uuid.NewV4()),
+		Inheritance: true,
+		View:        `any`,
+		ServiceID:   uuid.Must(uuid.NewV4()),
+		ServiceName: `https`,
+		Attributes: []proto.ServiceAttribute{
+			{Name: `port`, Value: `443`},
+		},
+	})
+
+	chk := testMoveCheck()
+	chk.Constraints = []CheckConstraint{
+		{Type: `native`, Key: `environment`, Value: `testing`},
+		{Type: `attribute`, Key: `port`, Value: `@defined`},
+		{Type: `system`, Key: `fqdn`, Value: `host.example.org`},
+	}
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementID:   repoID,
+	}, true).SetCheck(chk)
+	sTree.ComputeCheckInstances()
+
+	close(actionC)
+	close(errC)
+	if len(errC) > 0 {
+		t.Error(`Error channel not empty`)
+	}
+
+	objects := map[string]proto.CheckEvaluationObject{}
+	for _, obj := range sTree.EvaluateCheck(chk.ConfigID.String()) {
+		objects[obj.ObjectType] = obj
+	}
+	if len(objects) != 3 {
+		t.Fatalf("Expected evaluation of 3 objects, got %d", len(objects))
+	}
+	for _, typ := range []string{`repository`, `bucket`} {
+		if objects[typ].IsEvaluated || objects[typ].SkipReason != `noInstances` {
+			t.Errorf("Expected %s to be skipped without instances", typ)
+		}
+	}
+
+	node := objects[`node`]
+	if !node.IsEvaluated || node.IsMatch || len(node.InstanceIDs) != 0 {
+		t.Fatalf("Expected node to be evaluated without match: %#v", node)
+	}
+	if len(node.Constraints) != 3 {
+		t.Fatalf("Expected 3 evaluated constraints, got %d",
+			len(node.Constraints))
+	}
+	for i, exp := range []struct {
+		match    bool
+		compared int
+		value    string
+	}{
+		{true, 1, `testing`},
+		{true, 1, `443`},
+		{false, 0, ``},
+	} {
+		cc := node.Constraints[i]
+		if cc.IsMatch != exp.match || len(cc.Compared) != exp.compared {
+			t.Errorf("Unexpected evaluation of %s constraint %s: %#v",
+				cc.ConstraintType, cc.Key, cc)
+			continue
+		}
+		if exp.compared > 0 && (cc.Compared[0].Value != exp.value ||
+			!cc.Compared[0].IsMatch) {
+			t.Errorf("Unexpected compared value for %s constraint %s: %#v",
+				cc.ConstraintType, cc.Key, cc.Compared[0])
+		}
+	}
+
+	if res := sTree.EvaluateCheck(uuid.Must(uuid.NewV4()).String()); len(res) != 0 {
+		t.Errorf("Expected no evaluation for unknown check, got %d",
+			len(res))
+	}
+
+	// the constraints of a disabled check configuration are not
+	// evaluated
+	n := sTree.Find(FindRequest{
+		ElementType: `node`,
+		ElementID:   nodeID,
+	}, true).(*Node)
+	for id, c := range n.Checks {
+		c.Disabled = true
+		n.Checks[id] = c
+	}
+	for _, obj := range sTree.EvaluateCheck(chk.ConfigID.String()) {
+		if obj.ObjectType == `node` &&
+			(obj.IsEvaluated || obj.SkipReason != `disabled`) {
+			t.Errorf("Expected disabled check to be skipped: %#v", obj)
+		}
+	}
+	for id, c := range n.Checks {
+		c.Disabled = false
+		n.Checks[id] = c
+	}
+
+	// the lifecycle state of the node skips the check if it removes
+	// or suspends its check instances
+	for _, test := range []struct {
+		lifecycle NodeLifecycle
+		reason    string
+	}{
+		{NodeLifecycle{CheckHandling: proto.StateChecksActive}, ``},
+		{NodeLifecycle{CheckHandling: proto.StateChecksSuspend}, `lifecycle`},
+		{NodeLifecycle{CheckHandling: proto.StateChecksDeprovision}, `lifecycle`},
+		{NodeLifecycle{CheckHandling: proto.StateChecksRestrict,
+			Views: []string{chk.View}}, ``},
+		{NodeLifecycle{CheckHandling: proto.StateChecksRestrict,
+			Views: []string{`external`}}, `lifecycle`},
+	} {
+		n.Lifecycle = test.lifecycle
+		for _, obj := range sTree.EvaluateCheck(chk.ConfigID.String()) {
+			if obj.ObjectType == `node` && (obj.IsEvaluated !=
+				(test.reason == ``) || obj.SkipReason != test.reason) {
+				t.Errorf("Unexpected evaluation in lifecycle %s: %#v",
+					test.lifecycle.CheckHandling, obj)
+			}
+		}
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
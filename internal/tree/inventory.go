@@ -0,0 +1,158 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"strconv"
+)
+
+// Inventory is a flat listing of all objects inside a tree, keyed
+// by the object ID. It is used to compare two trees of the same
+// repository with each other.
+type Inventory map[string]InventoryEntry
+
+// InventoryEntry describes a single object inside an Inventory
+type InventoryEntry struct {
+	ID         string
+	Type       string
+	Name       string
+	ParentID   string
+	Properties map[string]InventoryProperty
+	Checks     map[string]string
+	Instances  map[string]InventoryInstance
+}
+
+// InventoryProperty describes a property instance on an object
+type InventoryProperty struct {
+	Type           string
+	Key            string
+	Value          string
+	View           string
+	Inherited      bool
+	SourceInstance string
+}
+
+// String returns a printable representation of p
+func (p InventoryProperty) String() string {
+	return p.Type + `/` + p.View + `/` + p.Key + `=` + p.Value +
+		`/inherited:` + strconv.FormatBool(p.Inherited)
+}
+
+// InventoryInstance describes a check instance on an object
+type InventoryInstance struct {
+	CheckID          string
+	ConfigID         string
+	InstanceConfigID string
+	Version          uint64
+}
+
+// Inventory returns the Inventory of st
+func (st *Tree) Inventory() Inventory {
+	inv := make(Inventory)
+	if st.Child == nil {
+		return inv
+	}
+	st.Child.inventory(inv)
+	return inv
+}
+
+func (ter *Repository) inventory(inv Inventory) {
+	inv[ter.ID.String()] = newInventoryEntry(
+		ter.ID.String(), ter.Type, ter.Name, ``,
+		[]map[string]Property{ter.PropertyOncall, ter.PropertyService,
+			ter.PropertySystem, ter.PropertyCustom},
+		ter.Checks, nil,
+	)
+	for _, child := range ter.Children {
+		inventoryChild(inv, child, ter.ID.String())
+	}
+}
+
+// inventoryChild recursively adds child and its children to inv
+func inventoryChild(inv Inventory, child interface{}, parentID string) {
+	switch c := child.(type) {
+	case *Bucket:
+		inv[c.ID.String()] = newInventoryEntry(
+			c.ID.String(), c.Type, c.Name, parentID,
+			[]map[string]Property{c.PropertyOncall, c.PropertyService,
+				c.PropertySystem, c.PropertyCustom},
+			c.Checks, nil,
+		)
+		for _, ch := range c.Children {
+			inventoryChild(inv, ch, c.ID.String())
+		}
+	case *Group:
+		inv[c.ID.String()] = newInventoryEntry(
+			c.ID.String(), c.Type, c.Name, parentID,
+			[]map[string]Property{c.PropertyOncall, c.PropertyService,
+				c.PropertySystem, c.PropertyCustom},
+			c.Checks, c.Instances,
+		)
+		for _, ch := range c.Children {
+			inventoryChild(inv, ch, c.ID.String())
+		}
+	case *Cluster:
+		inv[c.ID.String()] = newInventoryEntry(
+			c.ID.String(), c.Type, c.Name, parentID,
+			[]map[string]Property{c.PropertyOncall, c.PropertyService,
+				c.PropertySystem, c.PropertyCustom},
+			c.Checks, c.Instances,
+		)
+		for _, ch := range c.Children {
+			inventoryChild(inv, ch, c.ID.String())
+		}
+	case *Node:
+		inv[c.ID.String()] = newInventoryEntry(
+			c.ID.String(), c.Type, c.Name, parentID,
+			[]map[string]Property{c.PropertyOncall, c.PropertyService,
+				c.PropertySystem, c.PropertyCustom},
+			c.Checks, c.Instances,
+		)
+	}
+}
+
+func newInventoryEntry(id, typ, name, parentID string,
+	props []map[string]Property, checks map[string]Check,
+	instances map[string]CheckInstance) InventoryEntry {
+
+	e := InventoryEntry{
+		ID:         id,
+		Type:       typ,
+		Name:       name,
+		ParentID:   parentID,
+		Properties: make(map[string]InventoryProperty),
+		Checks:     make(map[string]string),
+		Instances:  make(map[string]InventoryInstance),
+	}
+	for _, pmap := range props {
+		for _, prop := range pmap {
+			e.Properties[prop.GetID()] = InventoryProperty{
+				Type:           prop.GetType(),
+				Key:            prop.GetKey(),
+				Value:          prop.GetValue(),
+				View:           prop.GetView(),
+				Inherited:      prop.GetIsInherited(),
+				SourceInstance: prop.GetSourceInstance(),
+			}
+		}
+	}
+	for id, check := range checks {
+		e.Checks[id] = check.ConfigID.String()
+	}
+	for id, inst := range instances {
+		e.Instances[id] = InventoryInstance{
+			CheckID:          inst.CheckID.String(),
+			ConfigID:         inst.ConfigID.String(),
+			InstanceConfigID: inst.InstanceConfigID.String(),
+			Version:          inst.Version,
+		}
+	}
+	return e
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
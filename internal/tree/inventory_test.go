@@ -0,0 +1,137 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package tree
+
+import (
+	"testing"
+
+	"github.com/satori/go.uuid"
+)
+
+func TestInventory(t *testing.T) {
+	deterministicInheritanceOrder = true
+	actionChan := make(chan *Action, 1024)
+	errChan := make(chan *Error, 1024)
+
+	treeID := `10001000-1000-4000-1000-100010001000`
+	repoID := `20002000-2000-4000-2000-200020002000`
+	propID := `30003000-3000-4000-3000-300030003000`
+	teamID := `40004000-4000-4000-4000-400040004000`
+	buckID := `50005000-5000-4000-5000-500050005000`
+	grupID := `60006000-6000-4000-6000-600060006000`
+	nodeID := `80008000-8000-4000-8000-800080008000`
+
+	propUUID, _ := uuid.FromString(propID)
+
+	sTree := New(Spec{
+		ID:     treeID,
+		Name:   `root_testing`,
+		Action: actionChan,
+	})
+	sTree.RegisterErrChan(errChan)
+
+	NewRepository(RepositorySpec{
+		ID:      repoID,
+		Name:    `testrepo`,
+		Team:    teamID,
+		Deleted: false,
+		Active:  true,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `root`,
+		ParentID:   treeID,
+	})
+	sTree.SetError()
+
+	NewBucket(BucketSpec{
+		ID:          buckID,
+		Name:        `testrepo_test`,
+		Environment: `testing`,
+		Team:        teamID,
+		Deleted:     false,
+		Frozen:      false,
+		Repository:  repoID,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `repository`,
+		ParentID:   repoID,
+		ParentName: `testrepo`,
+	})
+
+	NewGroup(GroupSpec{
+		ID:   grupID,
+		Name: `testgroup`,
+		Team: teamID,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `bucket`,
+		ParentID:   buckID,
+	})
+
+	NewNode(NodeSpec{
+		ID:       nodeID,
+		AssetID:  1,
+		Name:     `testnode`,
+		Team:     teamID,
+		ServerID: `00000000-0000-0000-0000-000000000000`,
+		Online:   true,
+		Deleted:  false,
+	}).Attach(AttachRequest{
+		Root:       sTree,
+		ParentType: `group`,
+		ParentID:   grupID,
+	})
+
+	sTree.Find(FindRequest{
+		ElementType: `repository`,
+		ElementID:   repoID,
+	}, true).(Propertier).SetProperty(&PropertySystem{
+		ID:           propUUID,
+		Inheritance:  true,
+		ChildrenOnly: false,
+		View:         `testview`,
+		Key:          `testkey`,
+		Value:        `testvalue`,
+	})
+
+	inv := sTree.Inventory()
+	if len(inv) != 4 {
+		t.Fatalf("Expected 4 objects in inventory, got %d", len(inv))
+	}
+
+	for id, parent := range map[string]string{
+		repoID: ``,
+		buckID: repoID,
+		grupID: buckID,
+		nodeID: grupID,
+	} {
+		if inv[id].ParentID != parent {
+			t.Errorf("Object %s: expected parent %s, got %s",
+				id, parent, inv[id].ParentID)
+		}
+		if len(inv[id].Properties) != 1 {
+			t.Errorf("Object %s: expected 1 property, got %d",
+				id, len(inv[id].Properties))
+		}
+		for _, prop := range inv[id].Properties {
+			if prop.Key != `testkey` || prop.Value != `testvalue` {
+				t.Errorf("Object %s: unexpected property %s",
+					id, prop.String())
+			}
+			if prop.Inherited == (id == repoID) {
+				t.Errorf("Object %s: wrong inheritance flag", id)
+			}
+		}
+	}
+
+	if inv[nodeID].Type != `node` || inv[nodeID].Name != `testnode` {
+		t.Errorf("Unexpected node entry: %#v", inv[nodeID])
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
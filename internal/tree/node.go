@@ -27,6 +27,7 @@ type Node struct {
 	State           string
 	Online          bool
 	Deleted         bool
+	Lifecycle       NodeLifecycle
 	Type            string
 	Parent          NodeReceiver `json:"-"`
 	Fault           *Fault       `json:"-"`
@@ -39,19 +40,65 @@ type Node struct {
 	CheckInstances  map[string][]string
 	Instances       map[string]CheckInstance
 	loadedInstances map[string]map[string]CheckInstance
+	movedChecks     map[string]Check
 	hasUpdate       bool
 	log             *log.Logger
 	lock            *sync.RWMutex
 }
 
 type NodeSpec struct {
-	ID       string
-	AssetID  uint64
-	Name     string
-	Team     string
-	ServerID string
-	Online   bool
-	Deleted  bool
+	ID        string
+	AssetID   uint64
+	Name      string
+	Team      string
+	ServerID  string
+	Online    bool
+	Deleted   bool
+	Lifecycle NodeLifecycle
+}
+
+// NodeLifecycle is the lifecycle state of a node, together with the
+// handling of the node's check instances while it is in that state
+type NodeLifecycle struct {
+	State         string
+	CheckHandling string
+	Views         []string
+}
+
+// Clone returns a copy of l
+func (l NodeLifecycle) Clone() NodeLifecycle {
+	cl := NodeLifecycle{
+		State:         l.State,
+		CheckHandling: l.CheckHandling,
+	}
+	if l.Views != nil {
+		cl.Views = make([]string, len(l.Views))
+		copy(cl.Views, l.Views)
+	}
+	return cl
+}
+
+// suspendsChecks returns true if the check instances of the node
+// are kept, but not rolled out
+func (l NodeLifecycle) suspendsChecks() bool {
+	return l.CheckHandling == proto.StateChecksSuspend
+}
+
+// removesCheck returns true if the lifecycle state removes the check
+// instances of checks in view
+func (l NodeLifecycle) removesCheck(view string) bool {
+	switch l.CheckHandling {
+	case proto.StateChecksDeprovision:
+		return true
+	case proto.StateChecksRestrict:
+		for _, v := range l.Views {
+			if v == view {
+				return false
+			}
+		}
+		return true
+	}
+	return false
 }
 
 //
@@ -71,6 +118,7 @@ func NewNode(spec NodeSpec) *Node {
 	ten.ServerID, _ = uuid.FromString(spec.ServerID)
 	ten.Online = spec.Online
 	ten.Deleted = spec.Deleted
+	ten.Lifecycle = spec.Lifecycle.Clone()
 	ten.Type = "node"
 	ten.State = "floating"
 	ten.Parent = nil
@@ -88,13 +136,14 @@ func NewNode(spec NodeSpec) *Node {
 
 func (ten Node) Clone() *Node {
 	cl := Node{
-		Name:    ten.Name,
-		State:   ten.State,
-		Online:  ten.Online,
-		Deleted: ten.Deleted,
-		Type:    ten.Type,
-		log:     ten.log,
-		lock:    &sync.RWMutex{},
+		Name:      ten.Name,
+		State:     ten.State,
+		Online:    ten.Online,
+		Deleted:   ten.Deleted,
+		Lifecycle: ten.Lifecycle.Clone(),
+		Type:      ten.Type,
+		log:       ten.log,
+		lock:      &sync.RWMutex{},
 	}
 	cl.ID, _ = uuid.FromString(ten.ID.String())
 	cl.AssetID = ten.AssetID
@@ -222,6 +271,16 @@ func (ten *Node) clearParent() {
 	ten.State = "floating"
 }
 
+// SetLifecycle moves the node into lifecycle state l. The check
+// instances of the node are recomputed for the new state.
+func (ten *Node) SetLifecycle(l NodeLifecycle) {
+	ten.lock.Lock()
+	defer ten.lock.Unlock()
+
+	ten.Lifecycle = l.Clone()
+	ten.hasUpdate = true
+}
+
 func (ten *Node) setFault(f *Fault) {
 	ten.Fault = f
 }
@@ -280,6 +339,15 @@ func (ten *Node) actionUpdate() {
 	}
 }
 
+func (ten *Node) actionMove(target proto.Bucket) {
+	ten.Action <- &Action{
+		Action: ActionNodeMove,
+		Type:   ten.Type,
+		Bucket: target,
+		Node:   ten.export(),
+	}
+}
+
 func (ten *Node) actionDelete() {
 	ten.Action <- &Action{
 		Action: ActionDelete,
@@ -0,0 +1,48 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+// APIKey describes a long-lived authentication key of a system user
+// that is restricted to a subset of the user's permissions
+type APIKey struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	UserID   string `json:"userID,omitempty"`
+	UserName string `json:"userName,omitempty"`
+	// Key is only returned once when the API key is created
+	Key         string         `json:"key,omitempty"`
+	Permissions []Permission   `json:"permissions,omitempty"`
+	CreatedAt   string         `json:"createdAt,omitempty"`
+	CreatedBy   string         `json:"createdBy,omitempty"`
+	ExpiresAt   string         `json:"expiresAt,omitempty"`
+	LastUsedAt  string         `json:"lastUsedAt,omitempty"`
+	RevokedAt   string         `json:"revokedAt,omitempty"`
+	Details     *APIKeyDetails `json:"details,omitempty"`
+}
+
+// APIKeyDetails contains the revocation details of an API key
+type APIKeyDetails struct {
+	RevokedBy string `json:"revokedBy,omitempty"`
+}
+
+func NewAPIKeyRequest() Request {
+	return Request{
+		Flags:  &Flags{},
+		APIKey: &APIKey{},
+	}
+}
+
+func NewAPIKeyResult() Result {
+	return Result{
+		Errors:  &[]string{},
+		APIKeys: &[]APIKey{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
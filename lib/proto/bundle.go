@@ -0,0 +1,84 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+// Bundle is a signed set of check configurations exported from a
+// SOMA instance. All references to other objects are made by name, so
+// that the bundle can be imported into the same repository on a SOMA
+// instance for a different environment.
+type Bundle struct {
+	Environment    string        `json:"environment"`
+	InstanceName   string        `json:"instanceName,omitempty"`
+	RepositoryName string        `json:"repositoryName"`
+	ExportedAt     string        `json:"exportedAt"`
+	Entries        []BundleEntry `json:"entries"`
+	Signature      string        `json:"signature,omitempty"`
+}
+
+// BundleEntry is a check configuration inside a Bundle. The ID fields
+// of the check configuration are not set.
+type BundleEntry struct {
+	CheckConfig CheckConfig `json:"checkConfig"`
+	Monitoring  string      `json:"monitoring"`
+	Metric      string      `json:"metric"`
+	View        string      `json:"view"`
+	BucketName  string      `json:"bucketName,omitempty"`
+	ObjectName  string      `json:"objectName"`
+}
+
+// BundleImport is the report of what importing a Bundle into a
+// repository would change
+type BundleImport struct {
+	RepositoryID      string              `json:"repositoryId"`
+	SourceEnvironment string              `json:"sourceEnvironment"`
+	TargetEnvironment string              `json:"targetEnvironment"`
+	Entries           []BundleImportEntry `json:"entries"`
+}
+
+// BundleImportEntry is the import report for a single check
+// configuration of a Bundle
+type BundleImportEntry struct {
+	Name string `json:"name"`
+	// Action is one of: create, change, unchanged, error
+	Action string `json:"action"`
+	// ExistingID is the ID of the check configuration with the same
+	// name that already exists in the repository
+	ExistingID string   `json:"existingId,omitempty"`
+	Changes    []string `json:"changes,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+	// CheckConfig is the check configuration with all references
+	// mapped to the target repository
+	CheckConfig *CheckConfig `json:"checkConfig,omitempty"`
+}
+
+// NewBundleRequest returns a new request for importing a Bundle
+func NewBundleRequest() Request {
+	return Request{
+		Bundle: &Bundle{},
+	}
+}
+
+// NewBundleResult returns a new Result with an empty Bundle list
+func NewBundleResult() Result {
+	return Result{
+		Errors:  &[]string{},
+		Bundles: &[]Bundle{},
+	}
+}
+
+// NewBundleImportResult returns a new Result with an empty
+// BundleImport list
+func NewBundleImportResult() Result {
+	return Result{
+		Errors:  &[]string{},
+		Imports: &[]BundleImport{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
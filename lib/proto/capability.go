@@ -19,6 +19,7 @@ type Capability struct {
 	Metric       string                   `json:"metric,omitempty"`
 	View         string                   `json:"view,omitempty"`
 	Thresholds   uint64                   `json:"thresholds,omitempty"`
+	MinInterval  uint64                   `json:"minInterval,omitempty"`
 	Demux        *[]Attribute             `json:"demux,omitempty"`
 	Constraints  *[]CheckConfigConstraint `json:"constraints,omitempty"`
 	Details      *CapabilityDetails       `json:"details,omitempty"`
@@ -33,6 +34,7 @@ func (c *Capability) Clone() Capability {
 		Metric:       c.Metric,
 		View:         c.View,
 		Thresholds:   c.Thresholds,
+		MinInterval:  c.MinInterval,
 	}
 	if c.Details != nil {
 		clone.Details = c.Details.Clone()
@@ -0,0 +1,48 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+// ChangeSet is an ordered list of tree actions within a repository
+// that are applied as a single job. Either all steps are applied, or
+// none.
+type ChangeSet struct {
+	RepositoryID string          `json:"repositoryId,omitempty"`
+	Steps        []ChangeSetStep `json:"steps,omitempty"`
+}
+
+// ChangeSetStep is a single tree action of a ChangeSet. Request
+// carries the same payload as the request for the individual action.
+// To reference objects created by an earlier step, their ID can be
+// chosen by the client.
+type ChangeSetStep struct {
+	Section string  `json:"section"`
+	Action  string  `json:"action"`
+	Request Request `json:"request"`
+}
+
+// NewChangeSetRequest returns a new request
+func NewChangeSetRequest() Request {
+	return Request{
+		Flags:     &Flags{},
+		ChangeSet: &ChangeSet{},
+	}
+}
+
+// NewChangeSetResult returns a new result. It contains the tree
+// objects created by the change set.
+func NewChangeSetResult() Result {
+	return Result{
+		Errors:   &[]string{},
+		Buckets:  &[]Bucket{},
+		Groups:   &[]Group{},
+		Clusters: &[]Cluster{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
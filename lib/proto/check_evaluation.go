@@ -0,0 +1,67 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+// CheckEvaluation is the report of how the constraints of a check
+// configuration evaluate on every object the check reaches
+type CheckEvaluation struct {
+	RepositoryID  string                  `json:"repositoryId,omitempty"`
+	CheckConfigID string                  `json:"checkConfigId,omitempty"`
+	Objects       []CheckEvaluationObject `json:"objects,omitempty"`
+}
+
+// CheckEvaluationObject is the evaluation of the check on a single
+// object
+type CheckEvaluationObject struct {
+	ObjectID    string `json:"objectId"`
+	ObjectType  string `json:"objectType"`
+	ObjectName  string `json:"objectName,omitempty"`
+	CheckID     string `json:"checkId"`
+	View        string `json:"view,omitempty"`
+	IsInherited bool   `json:"isInherited"`
+	IsEvaluated bool   `json:"isEvaluated"`
+	IsMatch     bool   `json:"isMatch"`
+	// SkipReason is set if the constraints were not evaluated and is
+	// one of: noInstances, childrenOnly, disableAllMonitoring,
+	// disableCheckConfiguration, lifecycle, disabled
+	SkipReason  string                      `json:"skipReason,omitempty"`
+	Constraints []CheckEvaluationConstraint `json:"constraints,omitempty"`
+	InstanceIDs []string                    `json:"instanceIds,omitempty"`
+}
+
+// CheckEvaluationConstraint is the evaluation of a single constraint
+// of the check
+type CheckEvaluationConstraint struct {
+	ConstraintType string                 `json:"constraintType"`
+	Key            string                 `json:"key"`
+	Value          string                 `json:"value"`
+	IsMatch        bool                   `json:"isMatch"`
+	Compared       []CheckEvaluationValue `json:"compared,omitempty"`
+}
+
+// CheckEvaluationValue is a value on the object that a constraint
+// was compared against
+type CheckEvaluationValue struct {
+	// Source is the name of the service for attribute values
+	Source  string `json:"source,omitempty"`
+	View    string `json:"view,omitempty"`
+	Value   string `json:"value"`
+	IsMatch bool   `json:"isMatch"`
+}
+
+// NewCheckEvaluationResult returns a new Result with an empty
+// CheckEvaluation list
+func NewCheckEvaluationResult() Result {
+	return Result{
+		Errors:          &[]string{},
+		CheckEvaluation: &[]CheckEvaluation{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -9,6 +9,8 @@
 
 package proto
 
+import "sort"
+
 type CheckConfig struct {
 	ID           string                  `json:"ID,omitempty"`
 	Name         string                  `json:"name,omitempty"`
@@ -152,6 +154,17 @@ func (c *CheckConfigThreshold) Clone() CheckConfigThreshold {
 	}
 }
 
+// SortThresholds returns a copy of thresholds, ordered by increasing
+// severity of the notification levels
+func SortThresholds(thresholds []CheckConfigThreshold) []CheckConfigThreshold {
+	sorted := make([]CheckConfigThreshold, len(thresholds))
+	copy(sorted, thresholds)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Level.Numeric < sorted[j].Level.Numeric
+	})
+	return sorted
+}
+
 func (c *CheckConfigThreshold) DeepCompareSlice(a []CheckConfigThreshold) bool {
 	if a == nil {
 		return false
@@ -193,6 +206,8 @@ type CheckConfigFilter struct {
 	ID           string `json:"ID,omitempty"`
 	Name         string `json:"name,omitempty"`
 	CapabilityID string `json:"capabilityID,omitempty"`
+	// Names selects check configurations by name
+	Names []string `json:"names,omitempty"`
 }
 
 type CheckInstanceInfo struct {
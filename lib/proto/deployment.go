@@ -57,8 +57,11 @@ type Deployment struct {
 	Node             *Node             `json:"node,omitempty"`
 	Server           *Server           `json:"server,omitempty"`
 	CheckConfig      *CheckConfig      `json:"checkConfig"`
-	Check            *Check            `json:"check"`
-	CheckInstance    *CheckInstance    `json:"checkInstance"`
+	// Thresholds of the check configuration, ordered by increasing
+	// severity
+	Thresholds    []CheckConfigThreshold `json:"thresholds,omitempty"`
+	Check         *Check                 `json:"check"`
+	CheckInstance *CheckInstance         `json:"checkInstance"`
 }
 
 func (dd *Deployment) DeepCompare(alternate *Deployment) bool {
@@ -0,0 +1,37 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+// DirectorySync describes one difference between the LDAP directory
+// and the users and teams of SOMA, and how it was resolved
+type DirectorySync struct {
+	// user or team
+	ObjectType string `json:"objectType"`
+	// create, update, deactivate, orphaned or conflict
+	Action  string   `json:"action"`
+	Name    string   `json:"name"`
+	ID      string   `json:"id,omitempty"`
+	Changes []string `json:"changes,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+func NewDirectorySyncRequest() Request {
+	return Request{
+		Flags: &Flags{},
+	}
+}
+
+func NewDirectorySyncResult() Result {
+	return Result{
+		Errors:        &[]string{},
+		DirectorySync: &[]DirectorySync{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -20,6 +20,12 @@ type Flags struct {
 	Forced   bool `json:"forced"`   // workflow
 	Add      bool `json:"add"`      // permission map
 	Remove   bool `json:"remove"`   // permission unmap
+	DryRun   bool `json:"dryRun"`   // directory sync, inventory
+	Enable   bool `json:"enable"`   // check config
+	Disable  bool `json:"disable"`  // check config
+	// jobs that create check instances, exceed the check instance
+	// budgets
+	OverrideBudget bool `json:"overrideBudget"`
 }
 
 // vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,44 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+// Fsck is the report of a repository consistency check that compares
+// the in-memory tree with the database contents
+type Fsck struct {
+	RepositoryID   string        `json:"repositoryId,omitempty"`
+	RepositoryName string        `json:"repositoryName,omitempty"`
+	IsConsistent   bool          `json:"isConsistent"`
+	Findings       []FsckFinding `json:"findings,omitempty"`
+}
+
+// FsckFinding describes a single difference found by Fsck
+type FsckFinding struct {
+	// Category is one of: load, object, property, check, instance
+	Category string `json:"category"`
+	// Problem is one of: loadFailed, treeKeeperBroken,
+	// missingInDatabase, missingInTree, mismatch
+	Problem       string `json:"problem"`
+	ObjectID      string `json:"objectId,omitempty"`
+	ObjectType    string `json:"objectType,omitempty"`
+	ObjectName    string `json:"objectName,omitempty"`
+	ElementID     string `json:"elementId,omitempty"`
+	Field         string `json:"field,omitempty"`
+	TreeValue     string `json:"treeValue,omitempty"`
+	DatabaseValue string `json:"databaseValue,omitempty"`
+}
+
+// NewFsckResult returns a new Result with an empty Fsck list
+func NewFsckResult() Result {
+	return Result{
+		Errors: &[]string{},
+		Fsck:   &[]Fsck{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
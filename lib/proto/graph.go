@@ -0,0 +1,329 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Export formats of a Graph
+const (
+	GraphFormatDOT       = `dot`
+	GraphFormatGraphML   = `graphml`
+	GraphFormatJSONGraph = `jsongraph`
+)
+
+// GraphNodeExternal is the type of a GraphNode that stands in for an
+// object outside of the exported subtree
+const GraphNodeExternal = `external`
+
+// Relations of a GraphEdge
+const (
+	GraphEdgeMember   = `member`
+	GraphEdgeProperty = `property`
+	GraphEdgeCheck    = `check`
+)
+
+// Graph is a repository tree flattened into objects and the
+// membership and inheritance relations between them
+type Graph struct {
+	ID    string      `json:"id,omitempty"`
+	Type  string      `json:"type,omitempty"`
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is an object of the tree
+type GraphNode struct {
+	ID         string   `json:"id"`
+	Type       string   `json:"type"`
+	Name       string   `json:"name"`
+	Properties []string `json:"properties,omitempty"`
+	// checks created on the object itself
+	Checks int `json:"checks"`
+	// checks the object inherited from its parents
+	InheritedChecks int `json:"inheritedChecks"`
+	// the object is outside of the exported subtree and only
+	// referenced as source of an inheritance
+	External bool `json:"external,omitempty"`
+}
+
+// GraphEdge is a relation between two objects. Member edges point
+// from parent to child, inheritance edges point from the source of the
+// inheritance to the object that inherited
+type GraphEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+	Label    string `json:"label,omitempty"`
+}
+
+// ValidGraphFormat returns true if format is a supported export
+// format
+func ValidGraphFormat(format string) bool {
+	switch format {
+	case GraphFormatDOT, GraphFormatGraphML, GraphFormatJSONGraph:
+		return true
+	}
+	return false
+}
+
+// PropertyLabel returns a short human readable description of p
+func PropertyLabel(p *Property) string {
+	var label string
+	switch {
+	case p.Custom != nil:
+		label = fmt.Sprintf("%s=%s", p.Custom.Name, p.Custom.Value)
+	case p.System != nil:
+		label = fmt.Sprintf("%s=%s", p.System.Name, p.System.Value)
+	case p.Service != nil:
+		label = p.Service.Name
+	case p.Oncall != nil:
+		label = p.Oncall.Name
+	}
+	return fmt.Sprintf("%s:%s@%s", p.Type, label, p.View)
+}
+
+// Render exports g in format and returns the document together with
+// its content type
+func (g *Graph) Render(format string) ([]byte, string, error) {
+	switch format {
+	case GraphFormatDOT:
+		return g.DOT(), `text/vnd.graphviz`, nil
+	case GraphFormatGraphML:
+		b, err := g.GraphML()
+		return b, `application/graphml+xml`, err
+	case GraphFormatJSONGraph:
+		b, err := g.JSONGraph()
+		return b, `application/json`, err
+	}
+	return nil, ``, fmt.Errorf("Unknown graph format: %s", format)
+}
+
+// DOT exports g for graphviz
+func (g *Graph) DOT() []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.Type+`:`+g.ID))
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [fontname=\"Helvetica\", fontsize=10];\n")
+	b.WriteString("\tedge [fontname=\"Helvetica\", fontsize=8];\n")
+
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if n.External {
+			fmt.Fprintf(&b, "\t%s [shape=%s, style=dashed, label=%s];\n",
+				dotQuote(n.ID),
+				dotShape(n.Type),
+				dotQuote(n.ID+"\n(outside of export)"),
+			)
+			continue
+		}
+		lines := []string{
+			fmt.Sprintf("%s (%s)", n.Name, n.Type),
+			fmt.Sprintf("checks: %d own, %d inherited",
+				n.Checks, n.InheritedChecks),
+		}
+		lines = append(lines, n.Properties...)
+		fmt.Fprintf(&b, "\t%s [shape=%s, label=%s];\n",
+			dotQuote(n.ID),
+			dotShape(n.Type),
+			dotQuote(strings.Join(lines, "\n")),
+		)
+	}
+
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		style := `solid`
+		switch e.Relation {
+		case GraphEdgeProperty:
+			style = `dashed, color=blue, fontcolor=blue`
+		case GraphEdgeCheck:
+			style = `dotted, color=red, fontcolor=red`
+		}
+		fmt.Fprintf(&b, "\t%s -> %s [style=%s, label=%s];\n",
+			dotQuote(e.Source),
+			dotQuote(e.Target),
+			style,
+			dotQuote(e.Label),
+		)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// dotQuote returns s as quoted DOT ID
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
+
+// dotShape returns the node shape used for objects of type entity
+func dotShape(entity string) string {
+	switch entity {
+	case `repository`:
+		return `box3d`
+	case `bucket`:
+		return `folder`
+	case `group`:
+		return `box`
+	case `cluster`:
+		return `component`
+	case GraphNodeExternal:
+		return `note`
+	}
+	return `ellipse`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// GraphML exports g as GraphML document
+func (g *Graph) GraphML() ([]byte, error) {
+	doc := graphML{
+		XMLNS: `http://graphml.graphdrawing.org/xmlns`,
+		Keys: []graphMLKey{
+			{ID: `type`, For: `node`, AttrName: `type`, AttrType: `string`},
+			{ID: `name`, For: `node`, AttrName: `name`, AttrType: `string`},
+			{ID: `properties`, For: `node`, AttrName: `properties`, AttrType: `string`},
+			{ID: `checks`, For: `node`, AttrName: `checks`, AttrType: `int`},
+			{ID: `inheritedChecks`, For: `node`, AttrName: `inheritedChecks`, AttrType: `int`},
+			{ID: `external`, For: `node`, AttrName: `external`, AttrType: `boolean`},
+			{ID: `relation`, For: `edge`, AttrName: `relation`, AttrType: `string`},
+			{ID: `label`, For: `edge`, AttrName: `label`, AttrType: `string`},
+		},
+		Graph: graphMLGraph{
+			ID:          g.ID,
+			EdgeDefault: `directed`,
+			Nodes:       make([]graphMLNode, 0, len(g.Nodes)),
+			Edges:       make([]graphMLEdge, 0, len(g.Edges)),
+		},
+	}
+
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: n.ID,
+			Data: []graphMLData{
+				{Key: `type`, Value: n.Type},
+				{Key: `name`, Value: n.Name},
+				{Key: `properties`, Value: strings.Join(n.Properties, "\n")},
+				{Key: `checks`, Value: fmt.Sprintf("%d", n.Checks)},
+				{Key: `inheritedChecks`, Value: fmt.Sprintf("%d", n.InheritedChecks)},
+				{Key: `external`, Value: fmt.Sprintf("%t", n.External)},
+			},
+		})
+	}
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.Source,
+			Target: e.Target,
+			Data: []graphMLData{
+				{Key: `relation`, Value: e.Relation},
+				{Key: `label`, Value: e.Label},
+			},
+		})
+	}
+
+	b, err := xml.MarshalIndent(&doc, ``, `  `)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(b, '\n')...), nil
+}
+
+type jsonGraph struct {
+	Graph jsonGraphGraph `json:"graph"`
+}
+
+type jsonGraphGraph struct {
+	ID       string                   `json:"id"`
+	Type     string                   `json:"type"`
+	Directed bool                     `json:"directed"`
+	Nodes    map[string]jsonGraphNode `json:"nodes"`
+	Edges    []jsonGraphEdge          `json:"edges"`
+}
+
+type jsonGraphNode struct {
+	Label    string      `json:"label"`
+	Metadata interface{} `json:"metadata"`
+}
+
+type jsonGraphEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+	Label    string `json:"label,omitempty"`
+}
+
+// JSONGraph exports g in JSON Graph Format
+func (g *Graph) JSONGraph() ([]byte, error) {
+	doc := jsonGraph{Graph: jsonGraphGraph{
+		ID:       g.ID,
+		Type:     g.Type,
+		Directed: true,
+		Nodes:    make(map[string]jsonGraphNode, len(g.Nodes)),
+		Edges:    make([]jsonGraphEdge, 0, len(g.Edges)),
+	}}
+
+	for i := range g.Nodes {
+		n := g.Nodes[i]
+		doc.Graph.Nodes[n.ID] = jsonGraphNode{
+			Label:    n.Name,
+			Metadata: n,
+		}
+	}
+	for i := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, jsonGraphEdge(g.Edges[i]))
+	}
+	return json.Marshal(&doc)
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
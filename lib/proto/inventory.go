@@ -0,0 +1,69 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+// InventoryDrift describes one difference between the external
+// inventory source and the servers and nodes of SOMA
+type InventoryDrift struct {
+	// server or node
+	ObjectType string `json:"objectType"`
+	// create, update, remove, orphaned, vanished or conflict
+	Action  string   `json:"action"`
+	Name    string   `json:"name"`
+	AssetID uint64   `json:"assetId"`
+	ID      string   `json:"id,omitempty"`
+	Changes []string `json:"changes,omitempty"`
+	// Applied is true if the difference was resolved, otherwise it
+	// is only proposed
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+// InventorySource is the document an external inventory source
+// provides for reconciliation. Servers and nodes are identified by
+// their asset ID.
+type InventorySource struct {
+	Servers []InventoryServer `json:"servers"`
+	Nodes   []InventoryNode   `json:"nodes"`
+}
+
+// InventoryServer is a server as described by the inventory source
+type InventoryServer struct {
+	AssetID    uint64 `json:"assetId"`
+	Name       string `json:"name"`
+	Datacenter string `json:"datacenter"`
+	Location   string `json:"location"`
+	IsOnline   bool   `json:"isOnline"`
+}
+
+// InventoryNode is a node as described by the inventory source
+type InventoryNode struct {
+	AssetID uint64 `json:"assetId"`
+	Name    string `json:"name"`
+	// name of the team that owns the node
+	Team string `json:"team"`
+	// asset ID of the server the node runs on, 0 for none
+	ServerAssetID uint64 `json:"serverAssetId"`
+	IsOnline      bool   `json:"isOnline"`
+}
+
+func NewInventoryRequest() Request {
+	return Request{
+		Flags: &Flags{},
+	}
+}
+
+func NewInventoryDriftResult() Result {
+	return Result{
+		Errors:         &[]string{},
+		InventoryDrift: &[]InventoryDrift{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -23,6 +23,7 @@ type Job struct {
 	TsStarted    string      `json:"started,omitempty"`
 	TsFinished   string      `json:"finished,omitempty"`
 	Error        string      `json:"error,omitempty"`
+	Warnings     []string    `json:"warnings,omitempty"`
 	Details      *JobDetails `json:"details,omitempty"`
 }
 
@@ -41,6 +42,10 @@ func (j *Job) Clone() Job {
 		TsStarted:    j.TsStarted,
 		TsFinished:   j.TsFinished,
 	}
+	if j.Warnings != nil {
+		clone.Warnings = make([]string, len(j.Warnings))
+		copy(clone.Warnings, j.Warnings)
+	}
 	if j.Details != nil {
 		clone.Details = j.Details.Clone()
 	}
@@ -73,6 +78,21 @@ type JobFilter struct {
 	IDList []string `json:"idlist,omitempty"`
 }
 
+// JobWait specifies a list of jobs a client blocks on
+type JobWait struct {
+	IDList  []string `json:"idlist"`
+	Mode    string   `json:"mode,omitempty"`
+	Timeout uint64   `json:"timeout,omitempty"`
+}
+
+// Modes of waiting on a list of jobs
+const (
+	// JobWaitAll blocks until all jobs are processed
+	JobWaitAll = `all`
+	// JobWaitAny blocks until at least one job is processed
+	JobWaitAny = `any`
+)
+
 // NewJobFilter returns a new Request with fields preallocated
 // for filling in a Job filter, ensuring no nilptr-deref takes place.
 func NewJobFilter() Request {
@@ -84,6 +104,16 @@ func NewJobFilter() Request {
 	}
 }
 
+// NewJobWaitRequest returns a new Request with fields preallocated
+// for filling in a JobWait specification, ensuring no nilptr-deref
+// takes place.
+func NewJobWaitRequest() Request {
+	return Request{
+		Flags:   &Flags{},
+		JobWait: &JobWait{},
+	}
+}
+
 // NewJobResult returns a new Result with fields preallocated
 // for filling in Job data, ensuring no nilptr-deref takes place.
 func NewJobResult() Result {
@@ -16,6 +16,7 @@ type Node struct {
 	TeamID     string      `json:"teamID,omitempty"`
 	ServerID   string      `json:"serverID,omitempty"`
 	State      string      `json:"state,omitempty"`
+	Lifecycle  string      `json:"lifecycle,omitempty"`
 	IsOnline   bool        `json:"isOnline,omitempty"`
 	IsDeleted  bool        `json:"isDeleted,omitempty"`
 	Details    *Details    `json:"details,omitempty"`
@@ -31,6 +32,7 @@ func (p *Node) Clone() Node {
 		TeamID:    p.TeamID,
 		ServerID:  p.ServerID,
 		State:     p.State,
+		Lifecycle: p.Lifecycle,
 		IsOnline:  p.IsOnline,
 		IsDeleted: p.IsDeleted,
 	}
@@ -85,7 +87,7 @@ func (p *Node) DeepCompare(a *Node) bool {
 
 	if p.ID != a.ID || p.AssetID != a.AssetID || p.Name != a.Name ||
 		p.TeamID != a.TeamID || p.ServerID != a.ServerID || p.State != a.State ||
-		p.IsOnline != a.IsOnline || p.IsDeleted != a.IsDeleted {
+		p.Lifecycle != a.Lifecycle || p.IsOnline != a.IsOnline || p.IsDeleted != a.IsDeleted {
 		return false
 	}
 	return true
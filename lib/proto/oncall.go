@@ -12,11 +12,14 @@ package proto // import "github.com/mjolnir42/soma/lib/proto"
 
 // Oncall defines an oncall duty team
 type Oncall struct {
-	ID      string          `json:"id,omitempty"`
-	Name    string          `json:"name,omitempty"`
-	Number  string          `json:"number,omitempty"`
-	Members *[]OncallMember `json:"members,omitempty"`
-	Details *OncallDetails  `json:"details,omitempty"`
+	ID        string            `json:"id,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Number    string            `json:"number,omitempty"`
+	Members   *[]OncallMember   `json:"members,omitempty"`
+	Rotation  *OncallRotation   `json:"rotation,omitempty"`
+	Overrides *[]OncallOverride `json:"overrides,omitempty"`
+	Duty      *OncallDuty       `json:"duty,omitempty"`
+	Details   *OncallDetails    `json:"details,omitempty"`
 }
 
 // Clone returns a copy of o
@@ -30,6 +33,18 @@ func (o *Oncall) Clone() Oncall {
 	if o.Details != nil {
 		clone.Details = o.Details.Clone()
 	}
+	if o.Rotation != nil {
+		clone.Rotation = o.Rotation.Clone()
+	}
+	if o.Duty != nil {
+		duty := *o.Duty
+		clone.Duty = &duty
+	}
+	if o.Overrides != nil {
+		overrides := make([]OncallOverride, len(*o.Overrides))
+		copy(overrides, *o.Overrides)
+		clone.Overrides = &overrides
+	}
 	if o.Members != nil {
 		for i := range *o.Members {
 			*clone.Members = append(*clone.Members, (*o.Members)[i].Clone())
@@ -45,6 +60,9 @@ func (o *Oncall) Clone() Oncall {
 func (o *Oncall) Sanitize() {
 	o.ID = ``
 	o.Members = nil
+	o.Rotation = nil
+	o.Overrides = nil
+	o.Duty = nil
 	o.Details = nil
 }
 
@@ -128,6 +146,59 @@ func (o *OncallMember) DeepCompareSlice(a *[]OncallMember) bool {
 	return false
 }
 
+// OncallRotation describes the schedule by which the members of an
+// oncall duty team take turns
+type OncallRotation struct {
+	// daily or weekly
+	Period string `json:"period,omitempty"`
+	// wall clock time of the handover within TimeZone, format 15:04
+	Handover string `json:"handover,omitempty"`
+	// date the first shift starts on, format 2006-01-02. Weekly
+	// rotations hand over on the weekday of this date
+	Start    string `json:"start,omitempty"`
+	TimeZone string `json:"timeZone,omitempty"`
+	// members in the order they are on duty
+	Members []OncallMember `json:"members,omitempty"`
+}
+
+// Clone returns a copy of o
+func (o *OncallRotation) Clone() *OncallRotation {
+	clone := &OncallRotation{
+		Period:   o.Period,
+		Handover: o.Handover,
+		Start:    o.Start,
+		TimeZone: o.TimeZone,
+	}
+	if o.Members != nil {
+		clone.Members = make([]OncallMember, len(o.Members))
+		copy(clone.Members, o.Members)
+	}
+	return clone
+}
+
+// OncallOverride replaces the scheduled member of an oncall duty team
+// with another member for a period of time
+type OncallOverride struct {
+	ID       string `json:"id,omitempty"`
+	UserID   string `json:"userID,omitempty"`
+	UserName string `json:"userName,omitempty"`
+	// RFC3339 timestamps, the override ends exclusive
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+// OncallDuty describes who is on duty for an oncall duty team at a
+// specific point in time
+type OncallDuty struct {
+	At         string `json:"at,omitempty"`
+	UserID     string `json:"userID,omitempty"`
+	UserName   string `json:"userName,omitempty"`
+	OverrideID string `json:"overrideID,omitempty"`
+	// RFC3339 timestamps of the shift or override
+	From  string `json:"from,omitempty"`
+	Until string `json:"until,omitempty"`
+}
+
 // OncallFilter defines by which attributes an oncall duty team can be
 // searched for
 type OncallFilter struct {
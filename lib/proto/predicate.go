@@ -9,9 +9,33 @@
 
 package proto
 
+// Constants for the escalation direction of predicates, which
+// describes how threshold values of increasing severity are ordered
+const (
+	// higher values are worse, eg. >
+	EscalationRising = `rising`
+	// lower values are worse, eg. <
+	EscalationFalling = `falling`
+	// thresholds have no order, eg. ==
+	EscalationNone = `none`
+)
+
 type Predicate struct {
-	Symbol  string            `json:"symbol,omitempty"`
-	Details *PredicateDetails `json:"details,omitempty"`
+	Symbol     string            `json:"symbol,omitempty"`
+	Escalation string            `json:"escalation,omitempty"`
+	Details    *PredicateDetails `json:"details,omitempty"`
+}
+
+// DefaultEscalation returns the escalation direction for predicates
+// that were declared without one
+func (p *Predicate) DefaultEscalation() string {
+	switch p.Symbol {
+	case `>`, `>=`:
+		return EscalationRising
+	case `<`, `<=`:
+		return EscalationFalling
+	}
+	return EscalationNone
 }
 
 type PredicateDetails struct {
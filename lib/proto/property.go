@@ -83,19 +83,24 @@ func (t *PropertyDetails) Clone() *PropertyDetails {
 }
 
 type PropertyCustom struct {
-	ID           string `json:"ID,omitempty"`
-	Name         string `json:"name,omitempty"`
-	RepositoryID string `json:"repositoryID,omitempty"`
-	Value        string `json:"value,omitempty"`
+	ID           string          `json:"ID,omitempty"`
+	Name         string          `json:"name,omitempty"`
+	RepositoryID string          `json:"repositoryID,omitempty"`
+	Value        string          `json:"value,omitempty"`
+	Schema       *PropertySchema `json:"schema,omitempty"`
 }
 
 func (t *PropertyCustom) Clone() *PropertyCustom {
-	return &PropertyCustom{
+	clone := &PropertyCustom{
 		ID:           t.ID,
 		Name:         t.Name,
 		RepositoryID: t.RepositoryID,
 		Value:        t.Value,
 	}
+	if t.Schema != nil {
+		clone.Schema = t.Schema.Clone()
+	}
+	return clone
 }
 
 func (t *PropertyCustom) DeepCompare(a *PropertyCustom) bool {
@@ -0,0 +1,33 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+// PropertyHistory is one property instance that was set on an object,
+// together with the jobs that created and removed it
+type PropertyHistory struct {
+	ObjectID   string   `json:"objectID"`
+	ObjectType string   `json:"objectType"`
+	Property   Property `json:"property"`
+	// RFC3339 timestamps
+	CreatedAt string `json:"createdAt"`
+	DeletedAt string `json:"deletedAt,omitempty"`
+	// properties that existed before the history was recorded have
+	// no creating job
+	CreatedByJob string `json:"createdByJob,omitempty"`
+	DeletedByJob string `json:"deletedByJob,omitempty"`
+}
+
+func NewPropertyHistoryResult() Result {
+	return Result{
+		Errors:          &[]string{},
+		PropertyHistory: &[]PropertyHistory{},
+	}
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -0,0 +1,162 @@
+/*-
+ * Copyright (c) 2019, Jörg Pernfuß
+ * All rights reserved
+ *
+ * Use of this source code is governed by a 2-clause BSD license
+ * that can be found in the LICENSE file.
+ */
+
+package proto // import "github.com/mjolnir42/soma/lib/proto"
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Value types of a PropertySchema
+const (
+	SchemaTypeString  = `string`
+	SchemaTypeInteger = `integer`
+	SchemaTypeNumber  = `number`
+	SchemaTypeBoolean = `boolean`
+)
+
+// PropertySchema restricts the values a custom property can be
+// set to. All set restrictions must be met.
+type PropertySchema struct {
+	// string (default), integer, number or boolean
+	Type string `json:"type,omitempty"`
+	// list of allowed values
+	Enum []string `json:"enum,omitempty"`
+	// regular expression the entire value must match
+	Regex string `json:"regex,omitempty"`
+	// numeric range, only for types integer and number
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+}
+
+func (s *PropertySchema) Clone() *PropertySchema {
+	clone := &PropertySchema{
+		Type:  s.Type,
+		Regex: s.Regex,
+	}
+	if s.Enum != nil {
+		clone.Enum = make([]string, len(s.Enum))
+		copy(clone.Enum, s.Enum)
+	}
+	if s.Minimum != nil {
+		min := *s.Minimum
+		clone.Minimum = &min
+	}
+	if s.Maximum != nil {
+		max := *s.Maximum
+		clone.Maximum = &max
+	}
+	return clone
+}
+
+// Verify checks that the schema itself is valid
+func (s *PropertySchema) Verify() error {
+	switch s.Type {
+	case ``, SchemaTypeString, SchemaTypeBoolean:
+		if s.Minimum != nil || s.Maximum != nil {
+			return fmt.Errorf("Schema type %s does not support a"+
+				" numeric range", s.typeName())
+		}
+	case SchemaTypeInteger, SchemaTypeNumber:
+	default:
+		return fmt.Errorf("Invalid schema type: %s", s.Type)
+	}
+	if s.Minimum != nil && s.Maximum != nil && *s.Minimum > *s.Maximum {
+		return fmt.Errorf("Schema minimum %g is larger than maximum %g",
+			*s.Minimum, *s.Maximum)
+	}
+	if s.Regex != `` {
+		if _, err := s.compile(); err != nil {
+			return fmt.Errorf("Invalid schema regex: %s", err.Error())
+		}
+	}
+
+	// the enum values themselves must be valid values
+	base := *s
+	base.Enum = nil
+	for _, value := range s.Enum {
+		if err := base.Validate(value); err != nil {
+			return fmt.Errorf("Invalid schema enum value: %s",
+				err.Error())
+		}
+	}
+	return nil
+}
+
+// Validate checks that value is allowed by the schema
+func (s *PropertySchema) Validate(value string) error {
+	var (
+		err error
+		num float64
+	)
+
+	switch s.Type {
+	case SchemaTypeInteger:
+		var i int64
+		if i, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("Value %s is not an integer", value)
+		}
+		num = float64(i)
+	case SchemaTypeNumber:
+		if num, err = strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("Value %s is not a number", value)
+		}
+	case SchemaTypeBoolean:
+		if _, err = strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("Value %s is not a boolean", value)
+		}
+	}
+
+	if s.Minimum != nil && num < *s.Minimum {
+		return fmt.Errorf("Value %s is smaller than the minimum %g",
+			value, *s.Minimum)
+	}
+	if s.Maximum != nil && num > *s.Maximum {
+		return fmt.Errorf("Value %s is larger than the maximum %g",
+			value, *s.Maximum)
+	}
+
+	if s.Regex != `` {
+		re, err := s.compile()
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("Value %s does not match %s",
+				value, s.Regex)
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		for _, allowed := range s.Enum {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("Value %s is not one of %v", value, s.Enum)
+	}
+	return nil
+}
+
+// compile returns the schema regex, anchored to match the entire
+// value
+func (s *PropertySchema) compile() (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + s.Regex + `)$`)
+}
+
+// typeName returns the effective type of the schema
+func (s *PropertySchema) typeName() string {
+	if s.Type == `` {
+		return SchemaTypeString
+	}
+	return s.Type
+}
+
+// vim: ts=4 sw=4 sts=4 noet fenc=utf-8 ffs=unix
@@ -13,12 +13,15 @@ type Request struct {
 	Filter *Filter `json:"filter,omitempty"`
 	Flags  *Flags  `json:"flags,omitempty"`
 
+	APIKey          *APIKey          `json:"apiKey,omitempty"`
 	Action          *Action          `json:"action,omitempty"`
 	Admin           *Admin           `json:"admin,omitempty"`
 	Attribute       *Attribute       `json:"attribute,omitempty"`
 	Bucket          *Bucket          `json:"bucket,omitempty"`
+	Bundle          *Bundle          `json:"bundle,omitempty"`
 	Capability      *Capability      `json:"capability,omitempty"`
 	Category        *Category        `json:"category,omitempty"`
+	ChangeSet       *ChangeSet       `json:"changeSet,omitempty"`
 	CheckConfig     *CheckConfig     `json:"checkConfig,omitempty"`
 	Cluster         *Cluster         `json:"cluster,omitempty"`
 	Datacenter      *Datacenter      `json:"datacenter,omitempty"`
@@ -31,6 +34,7 @@ type Request struct {
 	JobResult       *JobResult       `json:"jobResult,omitempty"`
 	JobStatus       *JobStatus       `json:"jobStatus,omitempty"`
 	JobType         *JobType         `json:"jobType,omitempty"`
+	JobWait         *JobWait         `json:"jobWait,omitempty"`
 	Level           *Level           `json:"level,omitempty"`
 	Metric          *Metric          `json:"metric,omitempty"`
 	Mode            *Mode            `json:"mode,omitempty"`
@@ -54,23 +54,30 @@ type Result struct {
 	DeploymentsList *[]string `json:"deploymentsList,omitempty"`
 
 	// Request dependent data
+	APIKeys          *[]APIKey          `json:"apiKeys,omitempty"`
 	Actions          *[]Action          `json:"actions,omitempty"`
 	Admins           *[]Admin           `json:"admins,omitempty"`
 	Attributes       *[]Attribute       `json:"attributes,omitempty"`
 	Buckets          *[]Bucket          `json:"buckets,omitempty"`
+	Bundles          *[]Bundle          `json:"bundles,omitempty"`
 	Capabilities     *[]Capability      `json:"capability,omitempty"`
 	Categories       *[]Category        `json:"categories,omitempty"`
 	CheckConfigs     *[]CheckConfig     `json:"checkConfigs,omitempty"`
+	CheckEvaluation  *[]CheckEvaluation `json:"checkEvaluation,omitempty"`
 	Clusters         *[]Cluster         `json:"clusters,omitempty"`
 	DatacenterGroups *[]DatacenterGroup `json:"datacenterGroups,omitempty"`
 	Datacenters      *[]Datacenter      `json:"datacenter,omitempty"`
 	Deployments      *[]Deployment      `json:"deployments,omitempty"`
+	DirectorySync    *[]DirectorySync   `json:"directorySync,omitempty"`
 	Entities         *[]Entity          `json:"entities,omitempty"`
 	Environments     *[]Environment     `json:"environment,omitempty"`
+	Fsck             *[]Fsck            `json:"fsck,omitempty"`
 	Grants           *[]Grant           `json:"grants,omitempty"`
 	Groups           *[]Group           `json:"groups,omitempty"`
 	HostDeployments  *[]HostDeployment  `json:"hostDeployments,omitempty"`
+	Imports          *[]BundleImport    `json:"imports,omitempty"`
 	Instances        *[]Instance        `json:"instances,omitempty"`
+	InventoryDrift   *[]InventoryDrift  `json:"inventoryDrift,omitempty"`
 	JobResults       *[]JobResult       `json:"jobResults,omitempty"`
 	JobStatus        *[]JobStatus       `json:"jobStatus,omitempty"`
 	JobTypes         *[]JobType         `json:"jobTypes,omitempty"`
@@ -84,6 +91,7 @@ type Result struct {
 	Permissions      *[]Permission      `json:"permissions,omitempty"`
 	Predicates       *[]Predicate       `json:"predicates,omitempty"`
 	Properties       *[]Property        `json:"properties,omitempty"`
+	PropertyHistory  *[]PropertyHistory `json:"propertyHistory,omitempty"`
 	Providers        *[]Provider        `json:"providers,omitempty"`
 	Repositories     *[]Repository      `json:"repositories,omitempty"`
 	Sections         *[]Section         `json:"sections,omitempty"`
@@ -196,23 +204,30 @@ func NewResult() Result {
 func (r *Result) DataClean() {
 	r.Errors = &[]string{`Internal server error forced empty result`}
 	r.DeploymentsList = nil
+	r.APIKeys = nil
 	r.Actions = nil
 	r.Admins = nil
 	r.Attributes = nil
 	r.Buckets = nil
+	r.Bundles = nil
 	r.Capabilities = nil
 	r.Categories = nil
 	r.CheckConfigs = nil
+	r.CheckEvaluation = nil
 	r.Clusters = nil
 	r.DatacenterGroups = nil
 	r.Datacenters = nil
 	r.Deployments = nil
+	r.DirectorySync = nil
 	r.Entities = nil
 	r.Environments = nil
+	r.Fsck = nil
 	r.Grants = nil
 	r.Groups = nil
 	r.HostDeployments = nil
+	r.Imports = nil
 	r.Instances = nil
+	r.InventoryDrift = nil
 	r.JobResults = nil
 	r.JobStatus = nil
 	r.JobTypes = nil
@@ -226,6 +241,7 @@ func (r *Result) DataClean() {
 	r.Permissions = nil
 	r.Predicates = nil
 	r.Properties = nil
+	r.PropertyHistory = nil
 	r.Providers = nil
 	r.Repositories = nil
 	r.Sections = nil
@@ -12,14 +12,37 @@ package proto // import "github.com/mjolnir42/soma/lib/proto"
 // State represents the states an object inside a configuration tree can
 // be in
 type State struct {
-	Name    string        `json:"name,omitempty"`
-	Details *StateDetails `json:"details,omitempty"`
+	Name          string        `json:"name,omitempty"`
+	CheckHandling string        `json:"checkHandling,omitempty"`
+	Views         []string      `json:"views,omitempty"`
+	UnassignAfter uint64        `json:"unassignAfter,omitempty"`
+	Details       *StateDetails `json:"details,omitempty"`
 }
 
+// Check handling of nodes in a lifecycle state
+const (
+	// StateChecksActive does not change the check instances of nodes
+	StateChecksActive = `active`
+	// StateChecksSuspend keeps the check instances of nodes, but
+	// deprovisions them from the monitoring systems
+	StateChecksSuspend = `suspend`
+	// StateChecksDeprovision removes the check instances of nodes
+	StateChecksDeprovision = `deprovision`
+	// StateChecksRestrict removes the check instances of nodes unless
+	// they are in one of the state's views
+	StateChecksRestrict = `restrict`
+)
+
 // Clone returns a copy of s
 func (s *State) Clone() State {
 	clone := State{
-		Name: s.Name,
+		Name:          s.Name,
+		CheckHandling: s.CheckHandling,
+		UnassignAfter: s.UnassignAfter,
+	}
+	if s.Views != nil {
+		clone.Views = make([]string, len(s.Views))
+		copy(clone.Views, s.Views)
 	}
 	if s.Details != nil {
 		clone.Details = s.Details.Clone()
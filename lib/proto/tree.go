@@ -17,6 +17,10 @@ type Tree struct {
 	Group      *Group      `json:"group,omitempty"`
 	Cluster    *Cluster    `json:"cluster,omitempty"`
 	Node       *Node       `json:"node,omitempty"`
+	// RFC3339 timestamp to show the properties of the tree at
+	At string `json:"at,omitempty"`
+	// export the tree as graph in this format instead of JSON
+	Format string `json:"format,omitempty"`
 }
 
 func NewTreeResult() Result {